@@ -0,0 +1,107 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The exports of the go-speech-recognition library (see go-speech-recognition.h),
+	the library is looked up as "go-speech-recognition" (go-speech-recognition.dll, libgo-speech-recognition.so, ...).
+*/
+
+using System;
+using System.Runtime.InteropServices;
+
+namespace GoSpeechRecognition
+{
+	internal static class NativeMethods
+	{
+		private const string Library = "go-speech-recognition";
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern void SetLegacyReturnCodes(int legacy);
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern int InitializeStream(
+			[MarshalAs(UnmanagedType.LPUTF8Str)] string language, int sampleRate,
+			[MarshalAs(UnmanagedType.LPUTF8Str)] string model, int maxAlternatives, int interimResults);
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern int CreateSessionWithCredentials(
+			[MarshalAs(UnmanagedType.LPUTF8Str)] string credentials, [MarshalAs(UnmanagedType.LPUTF8Str)] string quotaProject,
+			[MarshalAs(UnmanagedType.LPUTF8Str)] string language, int sampleRate,
+			[MarshalAs(UnmanagedType.LPUTF8Str)] string model, int maxAlternatives, int interimResults);
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern int Reconfigure(
+			[MarshalAs(UnmanagedType.LPUTF8Str)] string language, int sampleRate,
+			[MarshalAs(UnmanagedType.LPUTF8Str)] string model, int maxAlternatives, int interimResults);
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern int SendAudio(ref short recording, int recordingSize);
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern int SendAudioWithTimestamp(ref short recording, int recordingSize, long timestampUs);
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern int SendAudioBytes(ref byte data, int dataSize);
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern int ReceiveTranscript(out IntPtr output);
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern int ReceiveTranscriptJSON(out IntPtr output);
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern void CancelPendingReceive();
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern int PollEvent(out int speechEvent);
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern int GetStats(out IntPtr output);
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern IntPtr GetSessionID();
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern int SetSessionLabel([MarshalAs(UnmanagedType.LPUTF8Str)] string key, [MarshalAs(UnmanagedType.LPUTF8Str)] string value);
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern int AckResult(long sequence);
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern long GetLastResultSequence();
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern int IsInitialized();
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern IntPtr GetLog();
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern IntPtr GetLastErrorJSON();
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern void FreeString(IntPtr value);
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern void CloseStream();
+
+		[DllImport(Library, CallingConvention = CallingConvention.Cdecl)]
+		internal static extern void Shutdown();
+
+		// Takes the ownership of a string returned by the library (freed by the library).
+		internal static string Take(IntPtr value)
+		{
+			if (value == IntPtr.Zero)
+			{
+				return string.Empty;
+			}
+			try
+			{
+				return Marshal.PtrToStringUTF8(value);
+			}
+			finally
+			{
+				FreeString(value);
+			}
+		}
+	}
+}
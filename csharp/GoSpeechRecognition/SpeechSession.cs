@@ -0,0 +1,218 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The streaming session of the library: its SafeHandle closes the session, even if the host forgets to dispose it.
+	The library runs one session at a time, opening a session closes the running one.
+*/
+
+using System;
+using System.Collections.Generic;
+using System.Runtime.CompilerServices;
+using System.Runtime.InteropServices;
+using System.Threading;
+using System.Threading.Tasks;
+
+namespace GoSpeechRecognition
+{
+	// Owns the streaming session of the library (there is no native handle, the session is global).
+	internal sealed class SessionHandle : SafeHandle
+	{
+		public SessionHandle() : base(IntPtr.Zero, true)
+		{
+			SetHandle(new IntPtr(1));
+		}
+
+		public override bool IsInvalid => handle == IntPtr.Zero;
+
+		protected override bool ReleaseHandle()
+		{
+			NativeMethods.CloseStream();
+			return true;
+		}
+	}
+
+	public sealed class SpeechSession : IDisposable
+	{
+		private readonly SessionHandle handle;
+
+		private SpeechSession(SessionHandle handle)
+		{
+			this.handle = handle;
+		}
+
+		// Opens the session (see "InitializeStream()" and "CreateSessionWithCredentials()").
+		public static SpeechSession Open(SessionOptions options = null)
+		{
+			options = options ?? new SessionOptions();
+
+			// The binding relies on the negative error codes.
+			NativeMethods.SetLegacyReturnCodes(0);
+
+			int interimResults = options.InterimResults ? 1 : 0;
+			int code;
+			if (options.Credentials == null && options.QuotaProject == null)
+			{
+				code = NativeMethods.InitializeStream(options.Language, options.SampleRate, options.Model, options.MaxAlternatives, interimResults);
+			}
+			else
+			{
+				code = NativeMethods.CreateSessionWithCredentials(options.Credentials ?? "", options.QuotaProject ?? "",
+					options.Language, options.SampleRate, options.Model, options.MaxAlternatives, interimResults);
+			}
+			Check(code);
+			return new SpeechSession(new SessionHandle());
+		}
+
+		public bool IsOpen => !handle.IsClosed && NativeMethods.IsInitialized() == 1;
+
+		// The ID of the session (see "GetSessionID()").
+		public string Id => NativeMethods.Take(NativeMethods.GetSessionID());
+
+		// The sequence number of the last received result (see "GetLastResultSequence()").
+		public long LastResultSequence => NativeMethods.GetLastResultSequence();
+
+		// Sends 16 bit PCM samples (see "SendAudio()").
+		public void SendAudio(ReadOnlySpan<short> samples)
+		{
+			if (samples.IsEmpty)
+			{
+				return;
+			}
+			Check(NativeMethods.SendAudio(ref MemoryMarshal.GetReference(samples), samples.Length));
+		}
+
+		// Sends 16 bit PCM samples with the capture timestamp of the first sample (see "SendAudioWithTimestamp()").
+		public void SendAudio(ReadOnlySpan<short> samples, long timestampUs)
+		{
+			if (samples.IsEmpty)
+			{
+				return;
+			}
+			Check(NativeMethods.SendAudioWithTimestamp(ref MemoryMarshal.GetReference(samples), samples.Length, timestampUs));
+		}
+
+		// Sends encoded audio in the encoding of "SetAudioEncoding()" (see "SendAudioBytes()").
+		public void SendAudioBytes(ReadOnlySpan<byte> data)
+		{
+			if (data.IsEmpty)
+			{
+				return;
+			}
+			Check(NativeMethods.SendAudioBytes(ref MemoryMarshal.GetReference(data), data.Length));
+		}
+
+		// Waits for the next transcript (see "ReceiveTranscript()"), the alternatives are separated by ';'.
+		public string ReceiveTranscript()
+		{
+			int code = NativeMethods.ReceiveTranscript(out IntPtr output);
+			string transcript = NativeMethods.Take(output);
+			Check(code);
+			return transcript;
+		}
+
+		// Waits for the next results as JSON (see "ReceiveTranscriptJSON()").
+		public string ReceiveTranscriptJSON()
+		{
+			int code = NativeMethods.ReceiveTranscriptJSON(out IntPtr output);
+			string json = NativeMethods.Take(output);
+			Check(code);
+			return json;
+		}
+
+		// Streams the transcripts until the session ends, the blocking receive calls run on the thread pool
+		// and the cancellation aborts a pending call (see "CancelPendingReceive()").
+		public async IAsyncEnumerable<string> ReceiveTranscriptsAsync([EnumeratorCancellation] CancellationToken cancellationToken = default)
+		{
+			using (cancellationToken.Register(NativeMethods.CancelPendingReceive))
+			{
+				while (IsOpen)
+				{
+					cancellationToken.ThrowIfCancellationRequested();
+
+					int code = 0;
+					string transcript = await Task.Run(() =>
+					{
+						code = NativeMethods.ReceiveTranscript(out IntPtr output);
+						return NativeMethods.Take(output);
+					}).ConfigureAwait(false);
+
+					if (code == (int)SpeechResult.StreamEnded)
+					{
+						yield break;
+					}
+					if (code == (int)SpeechResult.Canceled)
+					{
+						cancellationToken.ThrowIfCancellationRequested();
+					}
+					Check(code);
+					// Responses without results (e.g. speech events) have an empty transcript.
+					if (transcript.Length > 0)
+					{
+						yield return transcript;
+					}
+				}
+			}
+		}
+
+		// Switches the configuration of the running session (see "Reconfigure()").
+		public void Reconfigure(string language, int sampleRate, string model, int maxAlternatives, bool interimResults)
+		{
+			Check(NativeMethods.Reconfigure(language, sampleRate, model, maxAlternatives, interimResults ? 1 : 0));
+		}
+
+		// Acknowledges a final result (see "AckResult()").
+		public void Ack(long sequence)
+		{
+			Check(NativeMethods.AckResult(sequence));
+		}
+
+		// Attaches a label to the session (see "SetSessionLabel()").
+		public void SetLabel(string key, string value)
+		{
+			Check(NativeMethods.SetSessionLabel(key, value));
+		}
+
+		// Retrieves the next event, false if none is pending (see "PollEvent()").
+		public bool TryPollEvent(out SpeechEvent speechEvent)
+		{
+			bool polled = NativeMethods.PollEvent(out int value) == 1;
+			speechEvent = (SpeechEvent)value;
+			return polled;
+		}
+
+		// The statistics of the session as JSON (see "GetStats()").
+		public string GetStats()
+		{
+			int code = NativeMethods.GetStats(out IntPtr output);
+			string stats = NativeMethods.Take(output);
+			Check(code);
+			return stats;
+		}
+
+		// Closes the session (see "CloseStream()").
+		public void Dispose()
+		{
+			handle.Dispose();
+		}
+
+		// Releases everything the library holds (see "Shutdown()"), has to be called before the process unloads the library.
+		public static void Shutdown()
+		{
+			NativeMethods.Shutdown();
+		}
+
+		// The error log of the last failure (see "GetLog()")
+		public static string LastLog => NativeMethods.Take(NativeMethods.GetLog());
+
+		// The last error as JSON (see "GetLastErrorJSON()")
+		public static string LastErrorJSON => NativeMethods.Take(NativeMethods.GetLastErrorJSON());
+
+		private static void Check(int code)
+		{
+			if (code != (int)SpeechResult.Ok)
+			{
+				throw new SpeechException((SpeechResult)code, LastLog);
+			}
+		}
+	}
+}
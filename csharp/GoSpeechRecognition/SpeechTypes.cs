@@ -0,0 +1,70 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The codes of the library (GO_SPEECH_RECOGNITION_RESULT and GO_SPEECH_RECOGNITION_EVENT of go-speech-recognition.h),
+	the exception of a failed call and the options of a session.
+*/
+
+using System;
+
+namespace GoSpeechRecognition
+{
+	public enum SpeechResult
+	{
+		Ok = 0,
+		Error = -1,
+		NotInitialized = -2,
+		InvalidArgument = -3,
+		Finalized = -4,
+		QueueFull = -5,
+		StreamEnded = -6,
+		BudgetExceeded = -7,
+		Canceled = -8,
+		RateLimited = -9,
+		QuotaExceeded = -10,
+		WrongThread = -11,
+		Busy = -12,
+		ModelUnavailable = -13,
+	}
+
+	public enum SpeechEvent
+	{
+		SilenceTimeout = 1,
+		MaxDuration = 2,
+		BudgetExceeded = 3,
+		Clipping = 4,
+		DCOffset = 5,
+		NearSilence = 6,
+		QuotaExceeded = 7,
+		Offline = 8,
+		Backfilled = 9,
+		FormatMismatch = 10,
+		WakeWord = 11,
+		WakeWordRearmed = 12,
+	}
+
+	// A failed call of the library with its result code and the error log ("GetLog()").
+	public class SpeechException : Exception
+	{
+		public SpeechException(SpeechResult result, string message) : base(message)
+		{
+			Result = result;
+		}
+
+		public SpeechResult Result { get; }
+	}
+
+	// The options of a session (see "InitializeStream()").
+	public class SessionOptions
+	{
+		public string Language { get; set; } = "en-US";
+		public int SampleRate { get; set; } = 16000;
+		public string Model { get; set; } = "default";
+		public int MaxAlternatives { get; set; } = 1;
+		public bool InterimResults { get; set; }
+
+		// The credentials (a key file or the JSON key) and the quota project of a customer, null for the defaults (see "CreateSessionWithCredentials()")
+		public string Credentials { get; set; }
+		public string QuotaProject { get; set; }
+	}
+}
@@ -0,0 +1,361 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Guarantees the delivery of the final results (see "SetResultAcknowledgment()"): every final result gets a sequence number
+	and is delivered again until the host acknowledges it with "AckResult()" (after storing it),
+	the unacknowledged results can be journaled to a file, so they even survive a crash of the host.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+
+	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+)
+
+// Default time until an unacknowledged result is delivered again
+const defaultRedeliveryTimeout = 30 * time.Second
+
+// The acknowledgment settings and the unacknowledged results (guarded by the ackMutex),
+// the results are kept across sessions (a result of a closed session is delivered by the next one)
+var ackMutex = &sync.Mutex{}
+var ackEnabled = false
+var redeliveryTimeout = defaultRedeliveryTimeout
+var ackJournalFile = ""
+var lastSequence int64 = 0
+var pendingResults = map[int64]*pendingResult{}
+
+// Sequence number of the result returned by the last receive call (0 if it has none)
+var lastDeliveredSequence int64 = 0
+
+// A final result waiting for its acknowledgment
+type pendingResult struct {
+	sequence int64
+	resp *speechpb.StreamingRecognizeResponse
+	// Nil for results restored from the journal (their timestamps are lost)
+	clock *streamClock
+	deliveredAt time.Time
+}
+
+// An entry of the journal (the response is encoded as protobuf JSON)
+type journaledResult struct {
+	Sequence int64 `json:"sequence"`
+	Response json.RawMessage `json:"response"`
+}
+
+
+/*
+	SetResultAcknowledgment(cEnabled C.int, cRedeliveryMs C.int, cJournalFile *C.char) (C.int):
+	enables acknowledged delivery of the final results: every final result gets a sequence number
+	("sequence" of "ReceiveTranscriptJSON()", "GetLastResultSequence()" after "ReceiveTranscript()" or "ReceiveAlternatives()")
+	and is returned again by the receive functions until it is acknowledged with "AckResult()",
+	so a host persisting the transcripts doesn't lose a result if it crashes between receiving and storing it
+	(interim results aren't acknowledged, a redelivered result keeps its sequence number, so duplicates can be detected)
+
+	Parameters:
+		cEnabled C.int
+			(1 to enable the acknowledgment, 0 to disable it and drop all unacknowledged results (default))
+		cRedeliveryMs C.int
+			(time in milliseconds until an unacknowledged result is delivered again, 0 for the default of 30 seconds)
+		cJournalFile *C.char
+			(a file the unacknowledged results are journaled to, they are loaded again by the next process
+			enabling the acknowledgment with the same file, or an empty string to keep them in memory only)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetResultAcknowledgment
+func SetResultAcknowledgment(cEnabled C.int, cRedeliveryMs C.int, cJournalFile *C.char) (C.int) {
+	if cEnabled != 0 && cEnabled != 1 {
+		logError("Invalid value for enabled (must be 0 or 1)", nil)
+		return result(resultInvalidArgument)
+	}
+	if cRedeliveryMs < 0 {
+		logError("Invalid redelivery timeout (must not be negative)", nil)
+		return result(resultInvalidArgument)
+	}
+	journalFile := C.GoString(cJournalFile)
+	if cEnabled == 1 && journalFile != "" && forbidsDiskWrites("The journal of the result acknowledgment") {
+		return result(resultInvalidArgument)
+	}
+
+	timeout := defaultRedeliveryTimeout
+	if cRedeliveryMs > 0 {
+		timeout = time.Duration(cRedeliveryMs) * time.Millisecond
+	}
+
+	// The journal of a previous process is loaded before anything is changed.
+	var restored map[int64]*pendingResult
+	if cEnabled == 1 && journalFile != "" {
+		var err error
+		restored, err = readResultJournal(journalFile)
+		if err != nil {
+			logError("Could not read the result journal: ", err)
+			return result(resultInvalidArgument)
+		}
+	}
+
+	ackMutex.Lock()
+	defer ackMutex.Unlock()
+
+	if cEnabled == 0 {
+		ackEnabled = false
+		ackJournalFile = ""
+		pendingResults = map[int64]*pendingResult{}
+		return result(resultOK)
+	}
+
+	ackEnabled = true
+	redeliveryTimeout = timeout
+	ackJournalFile = journalFile
+	for sequence, pending := range restored {
+		if _, exists := pendingResults[sequence]; !exists {
+			pendingResults[sequence] = pending
+		}
+		if sequence > lastSequence {
+			lastSequence = sequence
+		}
+	}
+	if err := writeResultJournal(); err != nil {
+		logError("Could not write the result journal: ", err)
+		return result(resultError)
+	}
+	return result(resultOK)
+}
+
+
+/*
+	AckResult(cSequence C.longlong) (C.int):
+	acknowledges a final result (see "SetResultAcknowledgment()"), so it isn't delivered again
+
+	Parameter:
+		cSequence C.longlong
+			(the sequence number of the result)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export AckResult
+func AckResult(cSequence C.longlong) (C.int) {
+	ackMutex.Lock()
+	defer ackMutex.Unlock()
+
+	if !ackEnabled {
+		logError("The result acknowledgment is disabled", nil)
+		return result(resultInvalidArgument)
+	}
+	sequence := int64(cSequence)
+	if _, exists := pendingResults[sequence]; !exists {
+		logError("Unknown or already acknowledged result sequence: " + strconv.FormatInt(sequence, 10), nil)
+		return result(resultInvalidArgument)
+	}
+
+	delete(pendingResults, sequence)
+	if err := writeResultJournal(); err != nil {
+		logError("Could not write the result journal: ", err)
+		return result(resultError)
+	}
+	return result(resultOK)
+}
+
+
+/*
+	GetLastResultSequence() (C.longlong):
+	returns the sequence number of the result returned by the last receive call (see "SetResultAcknowledgment()")
+
+	Return:
+		the sequence number (to be passed to "AckResult()")
+		0 if the result has none (an interim result or the acknowledgment is disabled)
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetLastResultSequence
+func GetLastResultSequence() (C.longlong) {
+	return C.longlong(atomic.LoadInt64(&lastDeliveredSequence))
+}
+
+
+// trackDelivery assigns a sequence number to a final result (if the acknowledgment is enabled) before it's queued,
+// the result is delivered again until it is acknowledged.
+func trackDelivery(resp *speechpb.StreamingRecognizeResponse, clock *streamClock) (int64) {
+	if resp == nil || !hasFinalResult(resp) {
+		return 0
+	}
+
+	ackMutex.Lock()
+	defer ackMutex.Unlock()
+
+	if !ackEnabled {
+		return 0
+	}
+	lastSequence++
+	pendingResults[lastSequence] = &pendingResult{
+		sequence: lastSequence,
+		resp: resp,
+		clock: clock,
+		deliveredAt: time.Now(),
+	}
+	if err := writeResultJournal(); err != nil {
+		logWarning("Could not write the result journal: " + err.Error())
+	}
+	return lastSequence
+}
+
+
+// markDelivered restarts the redelivery timeout of a result, when the host receives it.
+func markDelivered(sequence int64) {
+	if sequence == 0 {
+		return
+	}
+
+	ackMutex.Lock()
+		if pending, exists := pendingResults[sequence]; exists {
+			pending.deliveredAt = time.Now()
+		}
+	ackMutex.Unlock()
+}
+
+
+// dueRedelivery returns the oldest unacknowledged result whose redelivery is due (nil if there is none).
+func dueRedelivery() (*pendingResult) {
+	ackMutex.Lock()
+	defer ackMutex.Unlock()
+
+	if !ackEnabled {
+		return nil
+	}
+	var due *pendingResult
+	now := time.Now()
+	for _, pending := range pendingResults {
+		if now.Sub(pending.deliveredAt) < redeliveryTimeout {
+			continue
+		}
+		if due == nil || pending.sequence < due.sequence {
+			due = pending
+		}
+	}
+	if due != nil {
+		due.deliveredAt = now
+	}
+	return due
+}
+
+
+// redeliveryTimer returns a timer firing when the next redelivery is due (nil if nothing is unacknowledged),
+// the timer has to be stopped by the caller.
+func redeliveryTimer() (*time.Timer) {
+	ackMutex.Lock()
+	defer ackMutex.Unlock()
+
+	if !ackEnabled || len(pendingResults) == 0 {
+		return nil
+	}
+	var next time.Time
+	for _, pending := range pendingResults {
+		if next.IsZero() || pending.deliveredAt.Before(next) {
+			next = pending.deliveredAt
+		}
+	}
+	return time.NewTimer(time.Until(next.Add(redeliveryTimeout)))
+}
+
+
+// hasFinalResult reports whether a response contains a final result.
+func hasFinalResult(resp *speechpb.StreamingRecognizeResponse) (bool) {
+	for _, res := range resp.Results {
+		if res.IsFinal {
+			return true
+		}
+	}
+	return false
+}
+
+
+// writeResultJournal replaces the journal with the unacknowledged results (the ackMutex has to be held).
+func writeResultJournal() (error) {
+	if ackJournalFile == "" {
+		return nil
+	}
+
+	sequences := make([]int64, 0, len(pendingResults))
+	for sequence := range pendingResults {
+		sequences = append(sequences, sequence)
+	}
+	sort.Slice(sequences, func(i, j int) bool { return sequences[i] < sequences[j] })
+
+	marshaler := jsonpb.Marshaler{}
+	entries := make([]journaledResult, 0, len(sequences))
+	for _, sequence := range sequences {
+		response, err := marshaler.MarshalToString(pendingResults[sequence].resp)
+		if err != nil {
+			return err
+		}
+		// The sensitive terms are masked (see "AddSensitiveTerm()").
+		response = redactSensitive(response)
+		entries = append(entries, journaledResult{Sequence: sequence, Response: json.RawMessage(response)})
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	// The journal contains transcripts, so it's encrypted (see "SetEncryptionKey()").
+	if data, err = encryptData(data); err != nil {
+		return err
+	}
+
+	// Written to a temporary file first, so a crash can't leave it half written.
+	if err := os.WriteFile(ackJournalFile + ".tmp", data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(ackJournalFile + ".tmp", ackJournalFile)
+}
+
+
+// readResultJournal loads the unacknowledged results of a journal (none if it doesn't exist),
+// they are due for redelivery immediately.
+func readResultJournal(path string) (map[int64]*pendingResult, error) {
+	restored := map[int64]*pendingResult{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return restored, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if data, err = decryptData(data); err != nil {
+		return nil, err
+	}
+
+	var entries []journaledResult
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		resp := &speechpb.StreamingRecognizeResponse{}
+		if err := jsonpb.UnmarshalString(string(entry.Response), resp); err != nil {
+			return nil, err
+		}
+		restored[entry.Sequence] = &pendingResult{sequence: entry.Sequence, resp: resp}
+	}
+	return restored, nil
+}
@@ -0,0 +1,355 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Bridges a lost connection of the streaming session (see "SetBackfill()"): while google can't be reached,
+	the audio is spooled to disk and once the connection is back, the spooled audio is recognized by a batch request
+	and its results are delivered like the streaming results (with the timestamps of their audio), before the live results continue.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"context"
+	"crypto/cipher"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	speech "cloud.google.com/go/speech/apiv1"
+	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+)
+
+// Interval of the reconnection attempts (and of the retries of the backfill's recognition)
+const backfillRetryInterval = 5 * time.Second
+// The spooled audio is sent inline, so it's limited like the audio of the batches
+const maxBackfillMegabytes = maxInlineBytes / (1024 * 1024)
+
+// The backfill settings and the spool of the offline session (guarded by the sendMutex)
+var backfillDirectory = ""
+var backfillMaxBytes int64 = maxInlineBytes
+var spool *audioSpool
+var spoolCount = 0
+
+// Non-nil while the session is offline, closed when the connection is back (guarded by the streamMutex)
+var offlineResume chan struct{}
+// The spool handed over to the receive pump for the backfill (guarded by the streamMutex)
+var spoolToBackfill *audioSpool
+
+// The backfilled responses not yet delivered and the clock of their audio (only used by the receive pump)
+var backfillResponses []*speechpb.StreamingRecognizeResponse
+var backfillClock *streamClock
+
+// The audio spooled while offline
+type audioSpool struct {
+	file *os.File
+	path string
+	// The recognition settings of the session (the spool is raw audio in its encoding)
+	config *speechpb.RecognitionConfig
+	bytes int64
+	samples int64
+	// Maps the result times of the backfill to the timestamps of the spooled audio
+	clock *streamClock
+	// The cipher of the spool, nil if it isn't encrypted (see "SetEncryptionKey()")
+	cipher cipher.AEAD
+	full bool
+}
+
+
+/*
+	SetBackfill(cDirectory *C.char, cMaxMegabytes C.int) (C.int):
+	enables the backfill mode: if google can't be reached when the session is initialized or the connection gets lost
+	during the session, the session keeps running and the audio is spooled to the directory,
+	once the connection is back the spooled audio is recognized by a batch request and its final results are delivered
+	(with the timestamps of their audio) before the live results continue
+	(GO_SPEECH_RECOGNITION_EVENT_OFFLINE and GO_SPEECH_RECOGNITION_EVENT_BACKFILLED are reported, see "PollEvent()"),
+	has to be called before "InitializeStream()" to take effect
+
+	Parameters:
+		cDirectory *C.char
+			(the directory of the spool files, a spool that couldn't be recognized is kept there as raw audio in the session's encoding,
+			an empty string disables the backfill (default))
+		cMaxMegabytes C.int
+			(the maximum size of the spool in megabytes (audio exceeding it is dropped), between 1 and 10, 0 for the maximum of 10)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetBackfill
+func SetBackfill(cDirectory *C.char, cMaxMegabytes C.int) (C.int) {
+	directory := C.GoString(cDirectory)
+	if cMaxMegabytes < 0 || cMaxMegabytes > maxBackfillMegabytes {
+		logError("Invalid spool size (must be between 0 and " + strconv.Itoa(maxBackfillMegabytes) + " megabytes)", nil)
+		return result(resultInvalidArgument)
+	}
+	maxBytes := int64(maxInlineBytes)
+	if cMaxMegabytes > 0 {
+		maxBytes = int64(cMaxMegabytes) * 1024 * 1024
+	}
+
+	if directory != "" {
+		if forbidsDiskWrites("The backfill") {
+			return result(resultInvalidArgument)
+		}
+		if err := os.MkdirAll(directory, 0700); err != nil {
+			logError("Could not create the spool directory: ", err)
+			return result(resultInvalidArgument)
+		}
+	}
+
+	sendMutex.Lock()
+		backfillDirectory = directory
+		backfillMaxBytes = maxBytes
+	sendMutex.Unlock()
+	return result(resultOK)
+}
+
+
+// isConnectivityError reports whether the error means that google can't be reached.
+func isConnectivityError(err error) (bool) {
+	code := status.Code(err)
+	return code == codes.Unavailable || code == codes.DeadlineExceeded
+}
+
+
+// isOffline reports whether the session is offline.
+func isOffline() (bool) {
+	streamMutex.Lock()
+	defer streamMutex.Unlock()
+	return offlineResume != nil
+}
+
+
+// goOffline switches the session to offline (if the backfill is enabled): the current stream is dropped,
+// the audio is spooled from now on and the reconnection is attempted in the background.
+// Returns false if the session can't go offline. The caller has to hold the sendMutex.
+func goOffline(pumpCtx context.Context) (bool) {
+	if isOffline() {
+		return true
+	}
+	if backfillDirectory == "" || finalized == true || pumpCtx.Err() != nil {
+		return false
+	}
+
+	spoolCount++
+	path := filepath.Join(backfillDirectory, "backfill-" + sessionID + "-" + strconv.Itoa(spoolCount) + ".raw")
+	aead := currentCipher()
+	file, err := os.OpenFile(path, os.O_CREATE | os.O_WRONLY | os.O_TRUNC, 0600)
+	if err == nil {
+		// An encrypted spool starts with the magic (see "SetEncryptionKey()").
+		if aead != nil {
+			_, err = file.WriteString(encryptedFileMagic)
+		}
+	}
+	if err != nil {
+		if file != nil {
+			file.Close()
+		}
+		logError("Could not create the spool file: ", err)
+		return false
+	}
+	spool = &audioSpool{
+		file: file,
+		path: path,
+		config: proto.Clone(sessionConfig.Config).(*speechpb.RecognitionConfig),
+		clock: &streamClock{sampleRate: int64(sessionConfig.Config.SampleRateHertz)},
+		cipher: aead,
+	}
+
+	streamMutex.Lock()
+		oldStream := stream
+		stream = nil
+		offlineResume = make(chan struct{})
+	streamMutex.Unlock()
+	if oldStream != nil {
+		retireStream(oldStream)
+		closeStreamSend(oldStream)
+	}
+	sendFailure = nil
+
+	logWarning("Google can't be reached, spooling the audio to " + path)
+	reportEvent(eventOffline)
+
+	// "CloseStream()" waits until the reconnection stopped.
+	inFlight.Add(1)
+	go reconnect(pumpCtx)
+	return true
+}
+
+
+// write appends the chunk to the spool, audio exceeding the spool size is dropped.
+// The caller has to hold the sendMutex.
+func (spooled *audioSpool) write(chunk audioChunk) {
+	if spooled.bytes + int64(len(chunk.data)) > backfillMaxBytes {
+		if spooled.full == false {
+			logWarning("The spool is full, the following audio is dropped until the connection is back")
+			spooled.full = true
+		}
+		atomic.AddUint64(&audioOverflows, 1)
+		return
+	}
+	if err := appendRecord(spooled.file, spooled.cipher, false, chunk.data); err != nil {
+		logWarning("Could not write the spool file: " + err.Error())
+		return
+	}
+	spooled.bytes += int64(len(chunk.data))
+	spooled.samples += chunk.samples
+	spooled.clock.advance(chunk.samples, chunk.timestampUs)
+}
+
+
+// reconnect runs in its own goroutine while the session is offline and opens a new stream once google can be reached again,
+// the spool is handed over to the receive pump for the backfill (a finalized session needs no new stream).
+func reconnect(pumpCtx context.Context) {
+	defer inFlight.Done()
+
+	for {
+		select {
+		case <-time.After(backfillRetryInterval):
+			noteWakeup()
+		case <-pumpCtx.Done():
+			// The session has been closed, the spool is kept for the host.
+			sendMutex.Lock()
+				if spool != nil {
+					spool.file.Close()
+					logWarning("Session closed while offline, the spooled audio is kept in " + spool.path)
+					spool = nil
+				}
+			sendMutex.Unlock()
+			return
+		}
+
+		sendMutex.Lock()
+			config := sessionConfig
+			wasFinalized := finalized
+		sendMutex.Unlock()
+
+		// The stream is opened without holding the sendMutex, so a hanging connection doesn't block the session.
+		var newStream speechpb.Speech_StreamingRecognizeClient
+		if wasFinalized == false {
+			var err error
+			newStream, err = openStream(config)
+			if err != nil {
+				continue
+			}
+			recordRequest()
+		}
+
+		sendMutex.Lock()
+			// The session may have been finalized meanwhile.
+			if newStream != nil && finalized == true {
+				newStream.CloseSend()
+				newStream = nil
+			}
+			spooled := spool
+			spool = nil
+			spooled.file.Close()
+
+			streamMutex.Lock()
+				if newStream != nil {
+					stream = newStream
+				}
+				spoolToBackfill = spooled
+				close(offlineResume)
+				offlineResume = nil
+			streamMutex.Unlock()
+		sendMutex.Unlock()
+
+		logWarning("Connection is back, transcribing the spooled audio")
+		return
+	}
+}
+
+
+// transcribeSpool recognizes the spooled audio and returns its results as final streaming results,
+// failed recognitions because of the connection are retried. Only the receive pump may call it.
+func transcribeSpool(pumpCtx context.Context, spooled *audioSpool) ([]*speechpb.StreamingRecognizeResponse) {
+	if spooled.bytes == 0 {
+		removeLocalFile(spooled.path)
+		return nil
+	}
+	data, err := os.ReadFile(spooled.path)
+	if err == nil {
+		data, err = decryptWith(spooled.cipher, data)
+	}
+	if err != nil {
+		logError("Could not read the spool file: ", err)
+		return nil
+	}
+	audio := &speechpb.RecognitionAudio{AudioSource: &speechpb.RecognitionAudio_Content{Content: data}}
+
+	// Compressed audio has an unknown duration, so it's always recognized by an operation.
+	seconds := float64(spooled.samples) / float64(spooled.config.SampleRateHertz)
+	longRunning := spooled.samples == 0 || seconds > maxSyncSeconds
+
+	for {
+		var results []*speechpb.SpeechRecognitionResult
+		if longRunning {
+			var operation *speech.LongRunningRecognizeOperation
+			operation, err = client.LongRunningRecognize(pumpCtx, &speechpb.LongRunningRecognizeRequest{Config: spooled.config, Audio: audio})
+			if err == nil {
+				var resp *speechpb.LongRunningRecognizeResponse
+				resp, err = operation.Wait(pumpCtx)
+				if err == nil {
+					results = resp.Results
+				}
+			}
+		} else {
+			var resp *speechpb.RecognizeResponse
+			resp, err = client.Recognize(pumpCtx, &speechpb.RecognizeRequest{Config: spooled.config, Audio: audio})
+			if err == nil {
+				results = resp.Results
+			}
+		}
+
+		if err != nil && pumpCtx.Err() == nil && isConnectivityError(err) {
+			select {
+			case <-time.After(backfillRetryInterval):
+				continue
+			case <-pumpCtx.Done():
+			}
+		}
+		if pumpCtx.Err() != nil {
+			logWarning("Session closed during the backfill, the spooled audio is kept in " + spooled.path)
+			return nil
+		}
+		if err != nil {
+			logError("Could not transcribe the spooled audio (kept in " + spooled.path + "): ", err)
+			return nil
+		}
+
+		removeLocalFile(spooled.path)
+		if accountBilledAudio(seconds) {
+			finalizeStream(eventBudgetExceeded)
+		}
+		reportEvent(eventBackfilled)
+
+		// Every result is delivered as a response of its own (like the streaming results).
+		responses := make([]*speechpb.StreamingRecognizeResponse, 0, len(results))
+		for _, res := range results {
+			responses = append(responses, &speechpb.StreamingRecognizeResponse{
+				Results: []*speechpb.StreamingRecognitionResult{{
+					Alternatives: res.Alternatives,
+					IsFinal: true,
+					ResultEndTime: res.ResultEndTime,
+					ChannelTag: res.ChannelTag,
+					LanguageCode: res.LanguageCode,
+				}},
+			})
+		}
+		return responses
+	}
+}
@@ -0,0 +1,699 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Transcribes recorded audio files in batches (see "TranscribeFiles()"), independent of the streaming session:
+	every batch runs a pool of workers with its own client, short files are recognized synchronously,
+	long files (and files in Cloud Storage) by long-running operations, which can be resumed after a restart of the host.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/api/option"
+
+	speech "cloud.google.com/go/speech/apiv1"
+	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+)
+
+// Google recognizes up to a minute of audio synchronously (with some margin), longer audio needs a long-running operation
+const maxSyncSeconds = 55.0
+// Maximum size of audio sent inline (larger files have to be uploaded to Cloud Storage)
+const maxInlineBytes = 10 * 1024 * 1024
+// Interval of polling the progress of a long-running operation
+const operationPollInterval = 2 * time.Second
+
+// The batches by their handle, finished batches are kept for "GetOperationProgress()" (guarded by the batchMutex)
+var batchMutex = &sync.Mutex{}
+var batches = map[int32]*batch{}
+var lastBatchHandle int32
+
+// The recognition settings of the batches (see "SetBatchOptions()")
+var batchLanguage = "en-US"
+var batchModel = "default"
+
+// The client options of the next batches, nil for the default credentials (see "SetBatchCredentials()")
+var batchClientOptions []option.ClientOption
+
+// The running long-running operations are saved in the state file (if set, see "SetOperationStateFile()"),
+// so they can be resumed after a restart of the host (see "ResumeOperations()"), guarded by the operationStateMutex
+var operationStateMutex = &sync.Mutex{}
+var operationStateFile string
+
+type savedOperation struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// Callback getting the result of every file (see "SetBatchCallback()"), guarded by the callbackMutex
+var batchCallback unsafe.Pointer
+var batchUserData unsafe.Pointer
+
+type batch struct {
+	handle int32
+	paths []string
+	completed int32		// number of finished files (atomic)
+	ctx context.Context
+	cancel context.CancelFunc
+	done chan struct{}
+	operations []string	// the names of the resumed operations of the files (nil for new files)
+	started time.Time
+	progressMutex sync.Mutex
+	progress []int32	// progress of every file in percent (guarded by the progressMutex)
+}
+
+
+/*
+	SetBatchOptions(cLanguage *C.char, cModel *C.char):
+	sets the language (BCP-47) and the model (like "InitializeStream()") of the next batches (see "TranscribeFiles()"),
+	the phrase hints (see "LoadPhraseHintsFromFile()") and the post-processing of the transcripts apply as well
+
+	Parameters:
+		cLanguage *C.char
+			(e.g. "en-US" (default))
+		cModel *C.char
+			(e.g. "video" or "default" (default))
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetBatchOptions
+func SetBatchOptions(cLanguage *C.char, cModel *C.char) () {
+	batchMutex.Lock()
+		batchLanguage = C.GoString(cLanguage)
+		batchModel = C.GoString(cModel)
+	batchMutex.Unlock()
+}
+
+
+/*
+	SetBatchCredentials(cCredentials *C.char, cQuotaProject *C.char):
+	sets the credentials and the project of the next batches (see "TranscribeFiles()"), so batches of different customers
+	can run at the same time (every batch keeps the credentials it has been started with)
+
+	Parameters:
+		cCredentials *C.char
+			(the path of a service account key file or the JSON key itself, empty for the default credentials (default))
+		cQuotaProject *C.char
+			(the project the usage is billed to, empty for the project of the credentials (default))
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetBatchCredentials
+func SetBatchCredentials(cCredentials *C.char, cQuotaProject *C.char) () {
+	options := clientOptions(C.GoString(cCredentials), C.GoString(cQuotaProject))
+
+	batchMutex.Lock()
+		batchClientOptions = options
+	batchMutex.Unlock()
+}
+
+
+/*
+	SetBatchCallback(cCallback unsafe.Pointer, cUserData unsafe.Pointer):
+	registers a callback, which gets the result of every file of a batch (see "TranscribeFiles()"):
+	void callback(int handle, const char* path, int result, const char* transcript, int completed, int total, void* userData)
+	(result is 0 if the file has been transcribed, a negative error code if failed (see "GetLog()"),
+	completed and total are the number of finished files and all files of the batch),
+	the callback is invoked by the workers of the batch (concurrently, on threads of the library, unless the callbacks are queued, see "SetCallbackDispatch()"),
+	path and transcript are only valid during the call, cUserData is passed unchanged, nil removes the callback
+
+	Parameters:
+		cCallback unsafe.Pointer
+			(the callback)
+		cUserData unsafe.Pointer
+			(passed to the callback)
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetBatchCallback
+func SetBatchCallback(cCallback unsafe.Pointer, cUserData unsafe.Pointer) () {
+	callbackMutex.Lock()
+		batchCallback = cCallback
+		batchUserData = cUserData
+	callbackMutex.Unlock()
+}
+
+
+/*
+	TranscribeFiles(cPaths **C.char, cCount C.int, cConcurrency C.int) (C.int):
+	starts transcribing the audio files (WAV or FLAC files, or gs:// URIs of files in Cloud Storage) in the background,
+	up to cConcurrency files at a time, the results are reported by the batch callback (see "SetBatchCallback()"),
+	files up to a minute are recognized synchronously, longer files by long-running operations
+	(files above 10 MB have to be uploaded to Cloud Storage)
+
+	Parameters:
+		cPaths **C.char
+			(the paths of the files, they are copied)
+		cCount C.int
+			(the number of paths)
+		cConcurrency C.int
+			(the maximum number of files transcribed at a time, e.g. 4)
+
+	Return:
+		the handle of the batch (positive, see "GetOperationProgress()") if successful
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export TranscribeFiles
+func TranscribeFiles(cPaths **C.char, cCount C.int, cConcurrency C.int) (C.int) {
+	count := int(cCount)
+	concurrency := int(cConcurrency)
+	if cPaths == nil || count <= 0 || concurrency <= 0 {
+		logError("Invalid paths or concurrency", nil)
+		return result(resultInvalidArgument)
+	}
+
+	// Copy the paths, the host may release them after the call.
+	paths := make([]string, count)
+	for i, cPath := range (*[1 << 20]*C.char)(unsafe.Pointer(cPaths))[:count:count] {
+		paths[i] = C.GoString(cPath)
+	}
+
+	return startBatch(paths, nil, concurrency)
+}
+
+
+// startBatch starts a batch of new files or resumed operations (if set) and returns its handle.
+func startBatch(paths []string, operations []string, concurrency int) (C.int) {
+	batchMutex.Lock()
+		options := batchClientOptions
+	batchMutex.Unlock()
+
+	batchCtx, batchCancel := context.WithCancel(context.Background())
+	batchClient, err := speech.NewClient(batchCtx, options...)
+	if err != nil {
+		batchCancel()
+		logError("", err)
+		return result(resultError)
+	}
+
+	config := batchRecognitionConfig()
+
+	batchMutex.Lock()
+		lastBatchHandle++
+		newBatch := &batch{
+			handle:		lastBatchHandle,
+			paths:		paths,
+			ctx:		batchCtx,
+			cancel:		batchCancel,
+			done:		make(chan struct{}),
+			operations:	operations,
+			started:	time.Now(),
+			progress:	make([]int32, len(paths)),
+		}
+		batches[newBatch.handle] = newBatch
+	batchMutex.Unlock()
+
+	go newBatch.run(batchClient, config, concurrency)
+	return C.int(newBatch.handle)
+}
+
+
+// batchRecognitionConfig returns the recognition settings of the batches (see "SetBatchOptions()").
+func batchRecognitionConfig() (*speechpb.RecognitionConfig) {
+	sendMutex.Lock()
+		hints := phraseHints
+	sendMutex.Unlock()
+
+	batchMutex.Lock()
+	defer batchMutex.Unlock()
+	return &speechpb.RecognitionConfig{
+		LanguageCode:		batchLanguage,
+		Model:				batchModel,
+		SpeechContexts:		hints,
+	}
+}
+
+
+// run transcribes the files of the batch by a pool of workers and closes the client afterwards.
+func (job *batch) run(batchClient *speech.Client, config *speechpb.RecognitionConfig, concurrency int) {
+	defer func() {
+		batchClient.Close()
+		job.cancel()
+		close(job.done)
+	}()
+
+	// The workers get the indexes of the files.
+	files := make(chan int)
+	var workers sync.WaitGroup
+	for i := 0; i < concurrency && i < len(job.paths); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for file := range files {
+				onProgress := func(percent int32) {
+					job.setProgress(file, percent)
+				}
+				var transcript string
+				var code C.int
+				if job.operations != nil {
+					transcript, code = resumeOperation(job.ctx, batchClient, job.operations[file], job.paths[file], onProgress)
+				} else if code = acquireRecognition(job.ctx.Done()); code == resultOK {
+					// Every new file needs a free slot of the rate limits (see "SetRateLimits()").
+					transcript, code = transcribeFile(job.ctx, batchClient, config, job.paths[file], onProgress)
+					releaseRecognition()
+				}
+				job.report(file, transcript, code)
+			}
+		}()
+	}
+
+	for file := range job.paths {
+		files <- file
+	}
+	close(files)
+	workers.Wait()
+}
+
+
+// setProgress saves the progress of a file (in percent).
+func (job *batch) setProgress(file int, percent int32) {
+	job.progressMutex.Lock()
+		job.progress[file] = percent
+	job.progressMutex.Unlock()
+}
+
+
+// report finishes a file and passes its result to the batch callback.
+func (job *batch) report(file int, transcript string, code C.int) {
+	path := job.paths[file]
+	job.setProgress(file, 100)
+	completed := atomic.AddInt32(&job.completed, 1)
+
+	callbackMutex.Lock()
+		callback, userData := batchCallback, batchUserData
+	callbackMutex.Unlock()
+
+	if callback != nil {
+		callBatchCallback(callback, userData, job.handle, path, int(code), transcript, int(completed), len(job.paths))
+	}
+}
+
+
+// transcribeFile recognizes a file synchronously or by a long-running operation (depending on its duration)
+// and returns the post-processed transcript, the progress of an operation is passed to onProgress.
+func transcribeFile(batchCtx context.Context, batchClient *speech.Client, batchConfig *speechpb.RecognitionConfig, path string, onProgress func(int32)) (string, C.int) {
+	config := proto.Clone(batchConfig).(*speechpb.RecognitionConfig)
+	audio := &speechpb.RecognitionAudio{}
+	longRunning := true
+	cacheKey := ""
+
+	if strings.HasPrefix(path, "gs://") {
+		// Google reads the format from the file's header.
+		audio.AudioSource = &speechpb.RecognitionAudio_Uri{Uri: path}
+	} else {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logError("Could not read " + path + ": ", err)
+			return "", resultError
+		}
+		if len(data) > maxInlineBytes {
+			logError(path + " is too large, upload it to Cloud Storage (gs://)", nil)
+			return "", resultInvalidArgument
+		}
+
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".wav":
+			header, err := parseWavHeader(data)
+			if err == nil && header == nil {
+				err = errors.New("no RIFF header")
+			}
+			if err != nil {
+				logError("Could not parse the WAV header of " + path + ": ", err)
+				return "", resultInvalidArgument
+			}
+			// The format is read from the header, the duration decides about the operation.
+			config.AudioChannelCount = int32(header.channels)
+			bytesPerSecond := float64(header.sampleRate) * float64(header.channels) * float64(header.bitsPerSample) / 8
+			longRunning = bytesPerSecond == 0 || float64(len(data) - header.dataOffset) / bytesPerSecond > maxSyncSeconds
+		case ".flac":
+			// The duration of FLAC files isn't known without decoding, so they're always recognized by an operation.
+		default:
+			logError("Unsupported audio file " + path + " (only WAV and FLAC files)", nil)
+			return "", resultInvalidArgument
+		}
+		audio.AudioSource = &speechpb.RecognitionAudio_Content{Content: data}
+
+		// Identical audio with the same settings is only transcribed once (see "SetResultCache()").
+		cacheKey = resultCacheKey(data, config)
+		if transcript, found := lookupCachedResult(cacheKey); found {
+			return postProcess(transcript), resultOK
+		}
+	}
+
+	var results []*speechpb.SpeechRecognitionResult
+	if longRunning {
+		operation, err := batchClient.LongRunningRecognize(batchCtx, &speechpb.LongRunningRecognizeRequest{Config: config, Audio: audio})
+		if err != nil {
+			return "", batchError(batchCtx, path, err)
+		}
+		saveOperation(operation.Name(), path)
+
+		var code C.int
+		results, code = pollOperation(batchCtx, operation, path, onProgress)
+		if code != resultOK {
+			return "", code
+		}
+	} else {
+		resp, err := batchClient.Recognize(batchCtx, &speechpb.RecognizeRequest{Config: config, Audio: audio})
+		if err != nil {
+			return "", batchError(batchCtx, path, err)
+		}
+		results = resp.Results
+		accountBatchBilledTime(resp.TotalBilledTime)
+	}
+
+	transcript := joinResults(results)
+	storeCachedResult(cacheKey, transcript)
+	return postProcess(transcript), resultOK
+}
+
+
+// resumeOperation continues polling a saved operation (see "ResumeOperations()") and returns the post-processed transcript.
+func resumeOperation(batchCtx context.Context, batchClient *speech.Client, name string, path string, onProgress func(int32)) (string, C.int) {
+	results, code := pollOperation(batchCtx, batchClient.LongRunningRecognizeOperation(name), path, onProgress)
+	if code != resultOK {
+		return "", code
+	}
+	return postProcess(joinResults(results)), resultOK
+}
+
+
+// pollOperation polls the operation until it's done (instead of just waiting, so its progress can be reported),
+// the operation is removed from the state file when it's done or failed (not when the batch has been canceled).
+func pollOperation(batchCtx context.Context, operation *speech.LongRunningRecognizeOperation, path string, onProgress func(int32)) ([]*speechpb.SpeechRecognitionResult, C.int) {
+	for {
+		resp, err := operation.Poll(batchCtx)
+		if err != nil {
+			code := batchError(batchCtx, path, err)
+			if code != resultCanceled {
+				removeOperation(operation.Name())
+			}
+			return nil, code
+		}
+		if operation.Done() {
+			removeOperation(operation.Name())
+			accountBatchBilledTime(resp.TotalBilledTime)
+			return resp.Results, resultOK
+		}
+		if metadata, err := operation.Metadata(); err == nil && metadata != nil {
+			onProgress(metadata.ProgressPercent)
+		}
+
+		select {
+		case <-time.After(operationPollInterval):
+		case <-batchCtx.Done():
+			return nil, resultCanceled
+		}
+	}
+}
+
+
+// joinResults joins the transcripts of the results (consecutive parts of the audio).
+func joinResults(results []*speechpb.SpeechRecognitionResult) (string) {
+	var parts []string
+	for _, result := range results {
+		if len(result.Alternatives) > 0 {
+			parts = append(parts, strings.TrimSpace(result.Alternatives[0].Transcript))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+
+// batchError logs the failure of a file and returns its error code.
+func batchError(batchCtx context.Context, path string, err error) (C.int) {
+	if batchCtx.Err() != nil {
+		return resultCanceled
+	}
+	logError("Could not transcribe " + path + ": ", err)
+	if status.Code(err) == codes.ResourceExhausted {
+		reportEvent(eventQuotaExceeded)
+		return resultQuotaExceeded
+	}
+	return resultError
+}
+
+
+/*
+	GetOperationProgress(cHandle C.int, percent *C.double, etaSeconds *C.double) (C.int):
+	retrieves the progress of a batch (see "TranscribeFiles()") for progress bars: the percentage of the batch
+	(the average of its files, long-running operations report their progress while they're running)
+	and the estimated remaining time (-1 if unknown yet)
+
+	Parameters:
+		cHandle C.int
+			(the handle of the batch)
+		percent *C.double
+			(the pointer which is used to store the percentage between 0.0 and 100.0)
+		etaSeconds *C.double
+			(the pointer which is used to store the estimated remaining seconds)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetOperationProgress
+func GetOperationProgress(cHandle C.int, percent *C.double, etaSeconds *C.double) (C.int) {
+	batchMutex.Lock()
+		job := batches[int32(cHandle)]
+	batchMutex.Unlock()
+
+	if job == nil {
+		logError("Unknown batch handle", nil)
+		return result(resultInvalidArgument)
+	}
+
+	job.progressMutex.Lock()
+		var sum float64
+		for _, filePercent := range job.progress {
+			sum += float64(filePercent)
+		}
+	job.progressMutex.Unlock()
+	batchPercent := sum / float64(len(job.progress))
+
+	// The remaining time is extrapolated from the elapsed time.
+	eta := -1.0
+	switch {
+	case batchPercent >= 100:
+		eta = 0
+	case batchPercent > 0:
+		elapsed := time.Since(job.started).Seconds()
+		eta = elapsed / batchPercent * (100 - batchPercent)
+	}
+
+	*percent = C.double(batchPercent)
+	*etaSeconds = C.double(eta)
+	return result(resultOK)
+}
+
+
+/*
+	SetOperationStateFile(cPath *C.char):
+	sets the state file, which keeps the names of the running long-running operations of the batches,
+	so a restarted host can resume them (see "ResumeOperations()") instead of sending the audio again,
+	an empty path disables it (default)
+
+	Parameter:
+		cPath *C.char
+			(e.g. "C:\\transcripts\\operations.json")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetOperationStateFile
+func SetOperationStateFile(cPath *C.char) () {
+	path := C.GoString(cPath)
+	// The path is ignored in the in-memory only mode (see "SetInMemoryOnly()").
+	if path != "" && forbidsDiskWrites("The operation state file") {
+		return
+	}
+
+	operationStateMutex.Lock()
+		operationStateFile = path
+	operationStateMutex.Unlock()
+}
+
+
+/*
+	ResumeOperations(handle *C.int) (C.int):
+	resumes the long-running operations saved in the state file (see "SetOperationStateFile()") by a previous run of the host
+	as a new batch: they're polled until they're done and their results are reported like the results of "TranscribeFiles()",
+	has to be called once after the start (with the credentials of the operations, see "SetBatchCredentials()")
+
+	Parameter:
+		handle:
+			The pointer which is used to store the handle of the batch (positive, see "GetOperationProgress()"),
+			0 if there are no operations to resume
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export ResumeOperations
+func ResumeOperations(handle *C.int) (C.int) {
+	if handle == nil {
+		logError("Invalid pointer of the batch handle", nil)
+		return result(resultInvalidArgument)
+	}
+	*handle = 0
+
+	operationStateMutex.Lock()
+		saved, err := readOperationState()
+	operationStateMutex.Unlock()
+
+	if err != nil {
+		logError("Could not read the operation state file: ", err)
+		return result(resultError)
+	}
+	if len(saved) == 0 {
+		return result(resultOK)
+	}
+
+	paths := make([]string, len(saved))
+	names := make([]string, len(saved))
+	for i, operation := range saved {
+		paths[i] = operation.Path
+		names[i] = operation.Name
+	}
+	// Polling is cheap, so all operations are polled at a time.
+	started := startBatch(paths, names, len(saved))
+	if started <= 0 {
+		// The error code of the failed start
+		return started
+	}
+	*handle = started
+	return result(resultOK)
+}
+
+
+// saveOperation adds a started operation to the state file (if set).
+func saveOperation(name string, path string) {
+	operationStateMutex.Lock()
+	defer operationStateMutex.Unlock()
+
+	if operationStateFile == "" {
+		return
+	}
+	saved, err := readOperationState()
+	if err == nil {
+		err = writeOperationState(append(saved, savedOperation{Name: name, Path: path}))
+	}
+	if err != nil {
+		logError("Could not save the operation in the state file: ", err)
+	}
+}
+
+
+// removeOperation removes a finished operation from the state file (if set).
+func removeOperation(name string) {
+	operationStateMutex.Lock()
+	defer operationStateMutex.Unlock()
+
+	if operationStateFile == "" {
+		return
+	}
+	saved, err := readOperationState()
+	if err == nil {
+		remaining := []savedOperation{}
+		for _, operation := range saved {
+			if operation.Name != name {
+				remaining = append(remaining, operation)
+			}
+		}
+		err = writeOperationState(remaining)
+	}
+	if err != nil {
+		logError("Could not remove the operation from the state file: ", err)
+	}
+}
+
+
+// readOperationState reads the saved operations, a missing state file has none (the operationStateMutex has to be locked).
+func readOperationState() ([]savedOperation, error) {
+	if operationStateFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(operationStateFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var saved []savedOperation
+	err = json.Unmarshal(data, &saved)
+	return saved, err
+}
+
+
+// writeOperationState replaces the state file (the operationStateMutex has to be locked),
+// it's written to a temporary file first, so a crash can't leave it half written.
+func writeOperationState(saved []savedOperation) (error) {
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	temporaryFile := operationStateFile + ".tmp"
+	if err := os.WriteFile(temporaryFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(temporaryFile, operationStateFile)
+}
+
+
+// runningBatches returns the batches, whose workers haven't stopped yet.
+// The caller has to hold the batchMutex.
+func runningBatches() ([]*batch) {
+	running := make([]*batch, 0, len(batches))
+	for _, job := range batches {
+		select {
+		case <-job.done:
+		default:
+			running = append(running, job)
+		}
+	}
+	return running
+}
+
+
+// cancelBatches cancels the running batches and waits until they stopped (or the timeout is done).
+func cancelBatches(timeout <-chan struct{}) {
+	batchMutex.Lock()
+		running := runningBatches()
+	batchMutex.Unlock()
+
+	for _, job := range running {
+		job.cancel()
+	}
+	for _, job := range running {
+		select {
+		case <-job.done:
+		case <-timeout:
+			return
+		}
+	}
+}
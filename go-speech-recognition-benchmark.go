@@ -0,0 +1,297 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The benchmark harness (see "RunBenchmark()"): streams a reference WAV file to google like a live session and measures
+	the time to the first interim result, the time to the final results, the throughput and the reconnects,
+	so configurations (e.g. models, chunk sizes, preprocessing) and network paths can be compared objectively.
+	The benchmark has its own client and stream, the streaming session isn't affected.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/status"
+
+	speech "cloud.google.com/go/speech/apiv1"
+	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+)
+
+// The audio of the benchmark is sent in chunks of this duration
+const benchmarkChunkMs = 100
+
+// The benchmark is aborted after the audio's duration plus this time
+const benchmarkGrace = time.Minute
+
+// The result of "RunBenchmark()", the times are -1 if they couldn't be measured
+type benchmarkReport struct {
+	File string `json:"file"`
+	Model string `json:"model"`
+	Realtime bool `json:"realtime"`
+	AudioSeconds float64 `json:"audioSeconds"`
+	WallSeconds float64 `json:"wallSeconds"`
+	RealtimeFactor float64 `json:"realtimeFactor"`
+	ThroughputKBps float64 `json:"throughputKBps"`
+	TimeToFirstInterimMs float64 `json:"timeToFirstInterimMs"`
+	TimeToFirstFinalMs float64 `json:"timeToFirstFinalMs"`
+	TimeToFinalMs float64 `json:"timeToFinalMs"`
+	FinalResults int `json:"finalResults"`
+	Reconnects int `json:"reconnects"`
+	Transcript string `json:"transcript"`
+}
+
+
+// sendBenchmarkAudio sends the audio from the position on (advancing it) and ends the stream's audio,
+// paced in real time if the byte rate isn't 0, the time the audio has been sent completely is saved in sentAt.
+func sendBenchmarkAudio(benchStream speechpb.Speech_StreamingRecognizeClient, audio []byte, position *int64, chunkBytes int, byteRate float64, start time.Time, sentAt *int64) (error) {
+	for {
+		offset := int(atomic.LoadInt64(position))
+		if offset >= len(audio) {
+			break
+		}
+		if byteRate > 0 {
+			time.Sleep(time.Until(start.Add(time.Duration(float64(offset) / byteRate * float64(time.Second)))))
+		}
+		end := offset + chunkBytes
+		if end > len(audio) {
+			end = len(audio)
+		}
+		if err := benchStream.Send(&speechpb.StreamingRecognizeRequest{
+					StreamingRequest: &speechpb.StreamingRecognizeRequest_AudioContent{AudioContent: audio[offset:end]},
+					}); err != nil {
+			return err
+		}
+		atomic.StoreInt64(position, int64(end))
+	}
+	atomic.CompareAndSwapInt64(sentAt, 0, time.Now().UnixNano())
+	return benchStream.CloseSend()
+}
+
+
+// runBenchmark streams the audio and measures the report's times (reopening the stream on retryable errors).
+func runBenchmark(benchCtx context.Context, benchClient *speech.Client, config *speechpb.StreamingRecognitionConfig, audio []byte, byteRate float64, realtime bool, report *benchmarkReport) (error) {
+	chunkBytes := int(byteRate * benchmarkChunkMs / 1000)
+	chunkBytes -= chunkBytes % 2
+	pacing := 0.0
+	if realtime {
+		pacing = byteRate
+	}
+
+	var position, sentAt int64
+	var transcripts []string
+	var lastFinal time.Time
+	sinceStart := func(start time.Time, at time.Time) (float64) {
+		return float64(at.Sub(start)) / float64(time.Millisecond)
+	}
+
+	start := time.Now()
+	for {
+		streamCtx, streamCancel := context.WithCancel(benchCtx)
+		recordRequest()
+		benchStream, err := benchClient.StreamingRecognize(streamCtx)
+		if err == nil {
+			err = benchStream.Send(&speechpb.StreamingRecognizeRequest{
+				StreamingRequest: &speechpb.StreamingRecognizeRequest_StreamingConfig{StreamingConfig: config},
+				})
+		}
+		if err != nil {
+			streamCancel()
+			return err
+		}
+
+		sendDone := make(chan error, 1)
+		go func() {
+			sendDone <- sendBenchmarkAudio(benchStream, audio, &position, chunkBytes, pacing, start, &sentAt)
+		}()
+
+		for err == nil {
+			var resp *speechpb.StreamingRecognizeResponse
+			resp, err = benchStream.Recv()
+			if err != nil {
+				break
+			}
+			if resp.Error != nil {
+				err = status.ErrorProto(resp.Error)
+				break
+			}
+			for _, res := range resp.Results {
+				now := time.Now()
+				if res.IsFinal == false && report.TimeToFirstInterimMs < 0 {
+					report.TimeToFirstInterimMs = sinceStart(start, now)
+				}
+				if res.IsFinal {
+					if report.TimeToFirstFinalMs < 0 {
+						report.TimeToFirstFinalMs = sinceStart(start, now)
+					}
+					report.FinalResults++
+					lastFinal = now
+					if len(res.Alternatives) > 0 {
+						transcripts = append(transcripts, strings.TrimSpace(res.Alternatives[0].Transcript))
+					}
+				}
+			}
+		}
+		streamCancel()
+		sendErr := <-sendDone
+
+		if err == io.EOF {
+			err = sendErr
+		}
+		if err == nil || err == io.EOF {
+			break
+		}
+		// The audio continues on a new stream where the failed one stopped.
+		if isRetryable(err) && report.Reconnects < maxRetries {
+			report.Reconnects++
+			continue
+		}
+		return err
+	}
+
+	wall := time.Since(start)
+	report.WallSeconds = wall.Seconds()
+	if report.WallSeconds > 0 {
+		report.RealtimeFactor = report.AudioSeconds / report.WallSeconds
+	}
+	if sent := atomic.LoadInt64(&sentAt); sent != 0 {
+		sentDuration := time.Unix(0, sent).Sub(start)
+		if sentDuration > 0 {
+			report.ThroughputKBps = float64(len(audio)) / 1024 / sentDuration.Seconds()
+		}
+		if lastFinal.IsZero() == false {
+			report.TimeToFinalMs = sinceStart(time.Unix(0, sent), lastFinal)
+		}
+	}
+	report.Transcript = postProcess(strings.Join(transcripts, " "))
+	return nil
+}
+
+
+/*
+	RunBenchmark(cWavPath *C.char, cTranscriptLanguage *C.char, cTranscriptionModel *C.char, cRealtime C.int, output **C.char) (C.int):
+	streams a reference WAV file (16 bit PCM) to google like a live session and returns a report as a JSON object, e.g.:
+	{"file":"reference.wav","model":"latest_long","realtime":true,"audioSeconds":30.2,"wallSeconds":31.1,"realtimeFactor":0.97,
+	"throughputKBps":31.6,"timeToFirstInterimMs":412,"timeToFirstFinalMs":3380,"timeToFinalMs":640,"finalResults":6,"reconnects":0,
+	"transcript":"..."}
+	(the times are measured from the start of the streaming, timeToFinalMs from the end of the audio to the last final result,
+	-1 if they couldn't be measured, realtimeFactor is the audio's duration divided by the wall time),
+	the settings made before apply like in "InitializeStream()" (with interim results), the benchmark has its own
+	connection (default credentials) and doesn't affect the streaming session, the call blocks until the benchmark is done
+
+	Parameters:
+		cWavPath *C.char
+			(the path of the reference WAV file)
+		cTranscriptLanguage, cTranscriptionModel:
+			the same as "InitializeStream()"
+		cRealtime C.int
+			(1 to send the audio in real time like a live capture, 0 to send it as fast as possible)
+		output:
+			The pointer which is used to store the report
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export RunBenchmark
+func RunBenchmark(cWavPath *C.char, cTranscriptLanguage *C.char, cTranscriptionModel *C.char, cRealtime C.int, output **C.char) (C.int) {
+	if output == nil {
+		logError("Invalid output pointer", nil)
+		return result(resultInvalidArgument)
+	}
+	if code := checkCallbackThread("RunBenchmark"); code != resultOK {
+		return result(code)
+	}
+
+	path := C.GoString(cWavPath)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logError("Could not read " + path + ": ", err)
+		return result(resultError)
+	}
+	header, err := parseWavHeader(data)
+	if err == nil && header == nil {
+		err = errors.New("no RIFF header")
+	}
+	if err != nil {
+		logError("Could not parse the WAV header of " + path + ": ", err)
+		return result(resultInvalidArgument)
+	}
+	if header.format != wavFormatPCM || header.bitsPerSample != 16 || header.channels == 0 {
+		logError("Unsupported WAV format of " + path + " (only 16 bit PCM)", nil)
+		return result(resultInvalidArgument)
+	}
+	audio := data[header.dataOffset:]
+	byteRate := float64(header.sampleRate) * float64(header.channels) * 2
+
+	if reason := modelUnavailable(C.GoString(cTranscriptLanguage), C.GoString(cTranscriptionModel)); reason != "" {
+		logError(reason, nil)
+		return result(resultModelUnavailable)
+	}
+	if budgetUsedUp() {
+		logError("Billed seconds budget is exceeded", nil)
+		return result(resultBudgetExceeded)
+	}
+
+	sendMutex.Lock()
+		config := newStreamingConfig(C.GoString(cTranscriptLanguage), int32(header.sampleRate), C.GoString(cTranscriptionModel), 1, true)
+	sendMutex.Unlock()
+	// The benchmark measures the whole file.
+	config.Config.Encoding = speechpb.RecognitionConfig_LINEAR16
+	config.Config.AudioChannelCount = int32(header.channels)
+	config.SingleUtterance = false
+
+	report := benchmarkReport{
+		File:					path,
+		Model:					config.Config.Model,
+		Realtime:				cRealtime == 1,
+		AudioSeconds:			float64(len(audio)) / byteRate,
+		TimeToFirstInterimMs:	-1,
+		TimeToFirstFinalMs:		-1,
+		TimeToFinalMs:			-1,
+	}
+
+	benchCtx, benchCancel := context.WithTimeout(context.Background(), time.Duration(report.AudioSeconds * float64(time.Second)) + benchmarkGrace)
+	defer benchCancel()
+
+	if code := acquireRecognition(benchCtx.Done()); code != resultOK {
+		return result(code)
+	}
+	defer releaseRecognition()
+
+	benchClient, err := speech.NewClient(benchCtx)
+	if err != nil {
+		logError("", err)
+		return result(resultError)
+	}
+	defer benchClient.Close()
+
+	err = runBenchmark(benchCtx, benchClient, config, audio, byteRate, report.Realtime, &report)
+	accountDailyBilledAudio(report.AudioSeconds)
+	if err != nil {
+		logError("Benchmark failed: ", err)
+		return result(resultError)
+	}
+
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		logError("Could not encode benchmark report: ", err)
+		return result(resultError)
+	}
+	*output = cString(string(encoded))
+	return result(resultOK)
+}
@@ -0,0 +1,176 @@
+//go:build bridge && !cgo
+
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Experimental WebSocket bridge for the wasm build: browsers can't open the gRPC stream of the Speech API,
+	so the bridge opens it with its own (default) credentials and relays the audio and the results
+	(see go-speech-recognition-websocket.go for the protocol).
+	It's built without cgo (the files of the library are left out) and the "bridge" tag:
+	"CGO_ENABLED=0 go build -tags bridge -o go-speech-recognition-bridge ."
+*/
+
+package main
+
+import (
+	// Standard packages:
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+
+	// WebSocket package (download with "go get -u golang.org/x/net/websocket"):
+	"golang.org/x/net/websocket"
+
+	// External (Google) packages (download with "go get -u cloud.google.com/go/speech/apiv1"):
+	speech "cloud.google.com/go/speech/apiv1"
+	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+)
+
+// A WebSocket message with its payload type (text or binary)
+type bridgeFrame struct {
+	payloadType byte
+	data []byte
+}
+
+// Receives the frames of a connection (websocket.Message doesn't tell text and binary messages apart).
+var frameCodec = websocket.Codec{
+	Marshal: func(v interface{}) ([]byte, byte, error) {
+		data, err := json.Marshal(v)
+		return data, websocket.TextFrame, err
+	},
+	Unmarshal: func(data []byte, payloadType byte, v interface{}) error {
+		frame := v.(*bridgeFrame)
+		frame.payloadType = payloadType
+		frame.data = data
+		return nil
+	},
+}
+
+func main() {
+	address := flag.String("address", "localhost:8080", "the address the bridge listens on")
+	origin := flag.String("origin", "", "the origin of the pages allowed to connect (empty for the bridge's own origin)")
+	static := flag.String("static", "", "a directory served besides the bridge (e.g. wasm with the demo)")
+	flag.Parse()
+
+	client, err := speech.NewClient(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	http.Handle("/stream", websocket.Server{
+		Handshake: func(config *websocket.Config, request *http.Request) error {
+			return checkOrigin(request, *origin)
+		},
+		Handler: func(conn *websocket.Conn) {
+			bridgeSession(client, conn)
+		},
+	})
+	if *static != "" {
+		http.Handle("/", http.FileServer(http.Dir(*static)))
+	}
+
+	log.Printf("Bridge listening on ws://%s/stream", *address)
+	log.Fatal(http.ListenAndServe(*address, nil))
+}
+
+// checkOrigin only lets the allowed origin connect (other pages would use the bridge's credentials).
+func checkOrigin(request *http.Request, allowed string) (error) {
+	origin, err := url.Parse(request.Header.Get("Origin"))
+	if err != nil || origin.Host == "" {
+		return errors.New("Missing origin")
+	}
+	if allowed == "" && origin.Host == request.Host || allowed != "" && origin.Scheme + "://" + origin.Host == allowed {
+		return nil
+	}
+	return errors.New("Origin not allowed: " + origin.String())
+}
+
+// bridgeSession runs the session of a connection until the stream ended or the browser disconnected.
+func bridgeSession(client *speech.Client, conn *websocket.Conn) {
+	defer conn.Close()
+
+	var frame bridgeFrame
+	var config bridgeConfig
+	if err := frameCodec.Receive(conn, &frame); err != nil {
+		return
+	}
+	if frame.payloadType != websocket.TextFrame || json.Unmarshal(frame.data, &config) != nil || config.SampleRate <= 0 {
+		frameCodec.Send(conn, bridgeMessage{Error: "Invalid configuration"})
+		return
+	}
+
+	sessionCtx, sessionCancel := context.WithCancel(context.Background())
+	defer sessionCancel()
+
+	stream, err := client.StreamingRecognize(sessionCtx)
+	if err == nil {
+		err = stream.Send(&speechpb.StreamingRecognizeRequest{
+			StreamingRequest: &speechpb.StreamingRecognizeRequest_StreamingConfig{
+				StreamingConfig: &speechpb.StreamingRecognitionConfig{
+					Config: &speechpb.RecognitionConfig{
+						Encoding:			speechpb.RecognitionConfig_LINEAR16,
+						SampleRateHertz:	config.SampleRate,
+						LanguageCode:		config.Language,
+						Model:				config.Model,
+						MaxAlternatives:	config.MaxAlternatives,
+						},
+					InterimResults:	config.InterimResults,
+					},
+				},
+			})
+	}
+	if err != nil {
+		frameCodec.Send(conn, bridgeMessage{Error: err.Error()})
+		return
+	}
+
+	// The result times are relative to the start of the session (there's no clock of the host).
+	clock := &streamClock{sampleRate: int64(config.SampleRate), anchors: []clockAnchor{{sample: 0, timestampUs: 0}}}
+
+	// Relay the results until the stream ended, then close the connection.
+	go func() {
+		defer conn.Close()
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				if sessionCtx.Err() == nil {
+					frameCodec.Send(conn, bridgeMessage{Error: err.Error()})
+				}
+				return
+			}
+			if results := transcriptResults(resp, clock); len(results) > 0 {
+				if frameCodec.Send(conn, bridgeMessage{Results: results}) != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	// Relay the audio until the browser finalized the stream or disconnected.
+	for {
+		if err := frameCodec.Receive(conn, &frame); err != nil {
+			return
+		}
+		if frame.payloadType == websocket.BinaryFrame {
+			if err := stream.Send(&speechpb.StreamingRecognizeRequest{
+						StreamingRequest: &speechpb.StreamingRecognizeRequest_AudioContent{AudioContent: frame.data},
+						}); err != nil {
+				return
+			}
+			continue
+		}
+		var control bridgeControl
+		if json.Unmarshal(frame.data, &control) == nil && control.Finalize {
+			stream.CloseSend()
+		}
+	}
+}
@@ -0,0 +1,354 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Caches the transcripts of the batches by the hash of the audio and the recognition settings (see "SetResultCache()"),
+	so identical files (e.g. of test suites and re-runs) are only transcribed (and billed) once.
+	The cache is a local directory or a memcached or redis server (spoken to by their plain text protocols).
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+)
+
+// Timeout of a request to a cache server (a slow cache mustn't slow the batches down)
+const cacheTimeout = 2 * time.Second
+// Prefix of the keys on a cache server (shared with other applications)
+const cacheKeyPrefix = "go-speech-recognition:"
+
+// The cache of the batches, nil if disabled (guarded by the cacheMutex)
+var cacheMutex = &sync.Mutex{}
+var cache resultCache
+
+// A cache of transcripts by their key, failures are treated as misses
+type resultCache interface {
+	get(key string) (string, bool, error)
+	set(key string, transcript string) (error)
+}
+
+type directoryCache struct {
+	directory string
+}
+
+type memcachedCache struct {
+	address string
+}
+
+type redisCache struct {
+	address string
+	password string
+}
+
+
+/*
+	SetResultCache(cLocation *C.char) (C.int):
+	enables the result cache of the batches (see "TranscribeFiles()"): the transcripts are cached by the hash of the audio
+	and the recognition settings, so identical files are returned instantly without transcribing (and billing) them again
+	(the post-processing is applied to the cached transcripts as well, files in Cloud Storage aren't cached)
+
+	Parameter:
+		cLocation *C.char
+			(a local directory (e.g. "C:\\transcripts\\cache"), a memcached server ("memcached://localhost:11211"),
+			a redis server ("redis://:password@localhost:6379") or an empty string to disable the cache (default))
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetResultCache
+func SetResultCache(cLocation *C.char) (C.int) {
+	location := C.GoString(cLocation)
+
+	var newCache resultCache
+	switch {
+	case location == "":
+	case strings.HasPrefix(location, "memcached://"), strings.HasPrefix(location, "redis://"):
+		server, err := url.Parse(location)
+		if err != nil || server.Host == "" {
+			logError("Invalid cache server URL", err)
+			return result(resultInvalidArgument)
+		}
+		if server.Scheme == "memcached" {
+			newCache = &memcachedCache{address: server.Host}
+		} else {
+			password, _ := server.User.Password()
+			newCache = &redisCache{address: server.Host, password: password}
+		}
+	default:
+		if forbidsDiskWrites("The result cache directory") {
+			return result(resultInvalidArgument)
+		}
+		if err := os.MkdirAll(location, 0755); err != nil {
+			logError("Could not create the cache directory: ", err)
+			return result(resultInvalidArgument)
+		}
+		newCache = &directoryCache{directory: location}
+	}
+
+	cacheMutex.Lock()
+		cache = newCache
+	cacheMutex.Unlock()
+	return result(resultOK)
+}
+
+
+// resultCacheKey hashes the audio and the recognition settings.
+func resultCacheKey(audio []byte, config *speechpb.RecognitionConfig) (string) {
+	hash := sha256.New()
+	hash.Write(audio)
+	settings, _ := proto.Marshal(config)
+	hash.Write(settings)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+
+// lookupCachedResult returns the cached transcript of the key (if the cache is enabled).
+func lookupCachedResult(key string) (string, bool) {
+	cacheMutex.Lock()
+		current := cache
+	cacheMutex.Unlock()
+
+	if current == nil || key == "" {
+		return "", false
+	}
+	transcript, found, err := current.get(key)
+	if err != nil {
+		logWarning("Could not read the result cache: " + err.Error())
+		return "", false
+	}
+	// Encrypted transcripts (see "SetEncryptionKey()") which can't be decrypted count as misses.
+	decrypted, err := decryptData([]byte(transcript))
+	if err != nil {
+		logWarning("Could not decrypt the result cache: " + err.Error())
+		return "", false
+	}
+	return string(decrypted), found
+}
+
+
+// storeCachedResult caches the transcript of the key (if the cache is enabled).
+func storeCachedResult(key string, transcript string) {
+	cacheMutex.Lock()
+		current := cache
+	cacheMutex.Unlock()
+
+	// A masked transcript would be delivered on a hit (see "AddSensitiveTerm()").
+	if current == nil || key == "" || containsSensitive(transcript) {
+		return
+	}
+	encrypted, err := encryptData([]byte(transcript))
+	if err != nil {
+		logWarning("Could not encrypt the result cache: " + err.Error())
+		return
+	}
+	if err := current.set(key, string(encrypted)); err != nil {
+		logWarning("Could not write the result cache: " + err.Error())
+	}
+	// The cache directory is subject to the retention policy (see "SetDataRetention()").
+	enforceRetention()
+}
+
+
+func (local *directoryCache) get(key string) (string, bool, error) {
+	data, err := os.ReadFile(filepath.Join(local.directory, key + ".txt"))
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return string(data), true, nil
+}
+
+func (local *directoryCache) set(key string, transcript string) (error) {
+	// Written to a temporary file first, so a concurrent reader can't get it half written.
+	path := filepath.Join(local.directory, key + ".txt")
+	if err := os.WriteFile(path + ".tmp", []byte(transcript), 0644); err != nil {
+		return err
+	}
+	return os.Rename(path + ".tmp", path)
+}
+
+
+// dialCacheServer connects to a cache server, the connection has to be closed by the caller.
+func dialCacheServer(address string) (net.Conn, *bufio.Reader, error) {
+	connection, err := net.DialTimeout("tcp", address, cacheTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	connection.SetDeadline(time.Now().Add(cacheTimeout))
+	return connection, bufio.NewReader(connection), nil
+}
+
+// readLine reads a line of a text protocol (without the "\r\n").
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+
+func (server *memcachedCache) get(key string) (string, bool, error) {
+	connection, reader, err := dialCacheServer(server.address)
+	if err != nil {
+		return "", false, err
+	}
+	defer connection.Close()
+
+	if _, err := io.WriteString(connection, "get " + cacheKeyPrefix + key + "\r\n"); err != nil {
+		return "", false, err
+	}
+	// "VALUE <key> <flags> <bytes>", the data and "END", or just "END" if the key is missing
+	line, err := readLine(reader)
+	if err != nil {
+		return "", false, err
+	}
+	if line == "END" {
+		return "", false, nil
+	}
+	fields := strings.Fields(line)
+	if len(fields) != 4 || fields[0] != "VALUE" {
+		return "", false, errors.New("unexpected memcached response: " + line)
+	}
+	size, err := strconv.Atoi(fields[3])
+	if err != nil {
+		return "", false, err
+	}
+	data := make([]byte, size + 2)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return "", false, err
+	}
+	return string(data[:size]), true, nil
+}
+
+func (server *memcachedCache) set(key string, transcript string) (error) {
+	connection, reader, err := dialCacheServer(server.address)
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	// "set <key> <flags> <exptime> <bytes>" (no expiry) and the data
+	command := "set " + cacheKeyPrefix + key + " 0 0 " + strconv.Itoa(len(transcript)) + "\r\n" + transcript + "\r\n"
+	if _, err := io.WriteString(connection, command); err != nil {
+		return err
+	}
+	line, err := readLine(reader)
+	if err != nil {
+		return err
+	}
+	if line != "STORED" {
+		return errors.New("unexpected memcached response: " + line)
+	}
+	return nil
+}
+
+
+// redisCommand encodes a command in the redis protocol (an array of bulk strings).
+func redisCommand(arguments ...string) (string) {
+	command := "*" + strconv.Itoa(len(arguments)) + "\r\n"
+	for _, argument := range arguments {
+		command += "$" + strconv.Itoa(len(argument)) + "\r\n" + argument + "\r\n"
+	}
+	return command
+}
+
+// dial connects to the redis server and authenticates (if a password is set).
+func (server *redisCache) dial() (net.Conn, *bufio.Reader, error) {
+	connection, reader, err := dialCacheServer(server.address)
+	if err != nil || server.password == "" {
+		return connection, reader, err
+	}
+
+	if _, err := io.WriteString(connection, redisCommand("AUTH", server.password)); err != nil {
+		connection.Close()
+		return nil, nil, err
+	}
+	line, err := readLine(reader)
+	if err == nil && line != "+OK" {
+		err = errors.New("redis authentication failed: " + line)
+	}
+	if err != nil {
+		connection.Close()
+		return nil, nil, err
+	}
+	return connection, reader, nil
+}
+
+func (server *redisCache) get(key string) (string, bool, error) {
+	connection, reader, err := server.dial()
+	if err != nil {
+		return "", false, err
+	}
+	defer connection.Close()
+
+	if _, err := io.WriteString(connection, redisCommand("GET", cacheKeyPrefix + key)); err != nil {
+		return "", false, err
+	}
+	// "$<bytes>" and the data, or "$-1" if the key is missing
+	line, err := readLine(reader)
+	if err != nil {
+		return "", false, err
+	}
+	if !strings.HasPrefix(line, "$") {
+		return "", false, errors.New("unexpected redis response: " + line)
+	}
+	size, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return "", false, err
+	}
+	if size < 0 {
+		return "", false, nil
+	}
+	data := make([]byte, size + 2)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return "", false, err
+	}
+	return string(data[:size]), true, nil
+}
+
+func (server *redisCache) set(key string, transcript string) (error) {
+	connection, reader, err := server.dial()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	if _, err := io.WriteString(connection, redisCommand("SET", cacheKeyPrefix + key, transcript)); err != nil {
+		return err
+	}
+	line, err := readLine(reader)
+	if err != nil {
+		return err
+	}
+	if line != "+OK" {
+		return errors.New("unexpected redis response: " + line)
+	}
+	return nil
+}
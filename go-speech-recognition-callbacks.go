@@ -0,0 +1,160 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Invokes the callbacks registered by the host (e.g. "SetFinalResultCallback()").
+	Go can't call C function pointers directly, so every callback type needs a small C helper.
+	(cgo only allows C definitions in files without exported functions, so this needs its own file.)
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+
+// The depth of the callbacks invoked immediately on the calling thread (see "insideCallback()")
+static __thread int callbackDepth = 0;
+
+static void enterCallback() {
+	callbackDepth++;
+}
+
+static void leaveCallback() {
+	callbackDepth--;
+}
+
+static int getCallbackDepth() {
+	return callbackDepth;
+}
+
+typedef void (*finalResultCallback)(char* transcript, double durationSeconds, float confidence, void* userData);
+
+static void invokeFinalResultCallback(void* callback, char* transcript, double durationSeconds, float confidence, void* userData) {
+	((finalResultCallback)callback)(transcript, durationSeconds, confidence, userData);
+}
+
+typedef void (*levelCallback)(double rmsDB, double peakDB, void* userData);
+
+static void invokeLevelCallback(void* callback, double rmsDB, double peakDB, void* userData) {
+	((levelCallback)callback)(rmsDB, peakDB, userData);
+}
+
+// The same layout as GO_SPEECH_RECOGNITION_ALTERNATIVE in the header
+typedef struct {
+	char* transcript;
+	float confidence;
+} goSpeechRecognitionAlternative;
+
+typedef int (*rerankCallback)(goSpeechRecognitionAlternative* list, int count, void* userData);
+
+static int invokeRerankCallback(void* callback, goSpeechRecognitionAlternative* list, int count, void* userData) {
+	return ((rerankCallback)callback)(list, count, userData);
+}
+
+typedef void (*turnCallback)(int state, char* transcript, void* userData);
+
+static void invokeTurnCallback(void* callback, int state, char* transcript, void* userData) {
+	((turnCallback)callback)(state, transcript, userData);
+}
+
+typedef void (*batchCallback)(int handle, char* path, int result, char* transcript, int completed, int total, void* userData);
+
+static void invokeBatchCallback(void* callback, int handle, char* path, int result, char* transcript, int completed, int total, void* userData) {
+	((batchCallback)callback)(handle, path, result, transcript, completed, total, userData);
+}
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"runtime"
+	"unsafe"
+)
+
+
+// invokeMarked invokes a callback immediately and marks its thread meanwhile (see "insideCallback()"),
+// the goroutine stays on the thread, so the mark is seen by the exports the callback calls.
+func invokeMarked(invoke func()) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	C.enterCallback()
+	defer C.leaveCallback()
+	invoke()
+}
+
+
+// insideCallback returns whether the calling thread runs a callback invoked immediately by the library
+// (an export called by a callback runs on the callback's thread).
+func insideCallback() (bool) {
+	return C.getCallbackDepth() > 0
+}
+
+
+// callFinalResultCallback invokes the host's final result callback (see "dispatchCallback()"), the transcript is only valid during the call.
+func callFinalResultCallback(callback unsafe.Pointer, userData unsafe.Pointer, transcript string, durationSeconds float64, confidence float32) {
+	dispatchCallback(func() {
+		cTranscript := cString(transcript)
+		defer C.free(unsafe.Pointer(cTranscript))
+
+		C.invokeFinalResultCallback(callback, cTranscript, C.double(durationSeconds), C.float(confidence), userData)
+	})
+}
+
+
+// callRerankCallback passes the alternatives to the host's re-ranking callback and returns the index of the chosen one,
+// the list is only valid during the call.
+func callRerankCallback(callback unsafe.Pointer, userData unsafe.Pointer, transcripts []string, confidences []float32) (int) {
+	count := len(transcripts)
+	list := (*C.goSpeechRecognitionAlternative)(C.malloc(C.size_t(unsafe.Sizeof(C.goSpeechRecognitionAlternative{})) * C.size_t(count)))
+	defer C.free(unsafe.Pointer(list))
+
+	entries := (*[1 << 20]C.goSpeechRecognitionAlternative)(unsafe.Pointer(list))[:count:count]
+	for i := range entries {
+		entries[i].transcript = cString(transcripts[i])
+		entries[i].confidence = C.float(confidences[i])
+	}
+	defer func() {
+		for _, entry := range entries {
+			C.free(unsafe.Pointer(entry.transcript))
+		}
+	}()
+
+	var chosen C.int
+	invokeMarked(func() {
+		chosen = C.invokeRerankCallback(callback, list, C.int(count), userData)
+	})
+	return int(chosen)
+}
+
+
+// callLevelCallback passes the input level to the host's level callback (see "dispatchCallback()").
+func callLevelCallback(callback unsafe.Pointer, userData unsafe.Pointer, rmsDB float64, peakDB float64) {
+	dispatchCallback(func() {
+		C.invokeLevelCallback(callback, C.double(rmsDB), C.double(peakDB), userData)
+	})
+}
+
+
+// callBatchCallback passes the result of a file to the host's batch callback (see "dispatchCallback()"),
+// the strings are only valid during the call.
+func callBatchCallback(callback unsafe.Pointer, userData unsafe.Pointer, handle int32, path string, code int, transcript string, completed int, total int) {
+	dispatchCallback(func() {
+		cPath := cString(path)
+		defer C.free(unsafe.Pointer(cPath))
+		cTranscript := cString(transcript)
+		defer C.free(unsafe.Pointer(cTranscript))
+
+		C.invokeBatchCallback(callback, C.int(handle), cPath, C.int(code), cTranscript, C.int(completed), C.int(total), userData)
+	})
+}
+
+
+// callTurnCallback passes the new state of the conversation turns to the host's turn callback (see "dispatchCallback()"),
+// the transcript is only valid during the call.
+func callTurnCallback(callback unsafe.Pointer, userData unsafe.Pointer, state int32, transcript string) {
+	dispatchCallback(func() {
+		cTranscript := cString(transcript)
+		defer C.free(unsafe.Pointer(cTranscript))
+
+		C.invokeTurnCallback(callback, C.int(state), cTranscript, userData)
+	})
+}
@@ -0,0 +1,520 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The conversation mode (see "StartConversation()"): two linked streams, one per party (e.g. the local microphone and
+	the remote VoIP audio of an interview), whose final results are merged into one interleaved timeline attributed
+	to the speakers (see "GetConversationTranscript()").
+	The conversation has its own client and runs besides the streaming session, its streams are reopened when google
+	ends them (e.g. after the maximum stream duration).
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/golang/protobuf/ptypes/duration"
+
+	speech "cloud.google.com/go/speech/apiv1"
+	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+)
+
+// The parties of a conversation (see GO_SPEECH_RECOGNITION_PARTY in the header)
+const (
+	partyLocal = 0
+	partyRemote = 1
+	conversationParties = 2
+)
+
+// Time "EndConversation()" waits for the remaining final results of the parties
+const conversationDrainTimeout = 10 * time.Second
+
+// A party of the conversation, its stream is guarded by its mutex (which also keeps its audio from interleaving)
+type conversationParty struct {
+	speaker string
+	mutex sync.Mutex
+	stream speechpb.Speech_StreamingRecognizeClient
+	clock *streamClock
+	// The end of the party's last final result in the current stream (the start of its next result)
+	lastEnd *duration.Duration
+	failure error
+	ending bool
+	done chan struct{}
+}
+
+// A final result of the merged timeline
+type conversationTurn struct {
+	Party int `json:"party"`
+	Speaker string `json:"speaker"`
+	Transcript string `json:"transcript"`
+	Confidence float32 `json:"confidence"`
+	StartTimestamp int64 `json:"startTimestamp"`
+	EndTimestamp int64 `json:"endTimestamp"`
+}
+
+// No other conversation may be started or ended meanwhile.
+var conversationLifecycleMutex = &sync.Mutex{}
+
+// The running conversation (parties[0] is nil if there's none) and the timeline of the current (or last) one,
+// guarded by the conversationMutex, "EndConversation()" waits until conversationCalls is done
+var conversationMutex = &sync.Mutex{}
+var conversationCancel context.CancelFunc
+var conversationClient *speech.Client
+var conversationConfig *speechpb.StreamingRecognitionConfig
+var parties [conversationParties]*conversationParty
+var conversationTurns []conversationTurn
+var conversationCalls sync.WaitGroup
+
+
+// openConversationStream opens a stream for a party and sends the configuration.
+func openConversationStream(convCtx context.Context, convClient *speech.Client, config *speechpb.StreamingRecognitionConfig) (speechpb.Speech_StreamingRecognizeClient, error) {
+	recordRequest()
+	newStream, err := convClient.StreamingRecognize(convCtx)
+	if err != nil {
+		return nil, err
+	}
+	if err := newStream.Send(&speechpb.StreamingRecognizeRequest{
+				StreamingRequest: &speechpb.StreamingRecognizeRequest_StreamingConfig{StreamingConfig: config},
+				}); err != nil {
+		return nil, err
+	}
+	return newStream, nil
+}
+
+
+// receiveConversation runs in its own goroutine per party (started by "StartConversation()") and merges the party's
+// final results into the timeline, a stream google ended is replaced by a new one until the conversation ends.
+func receiveConversation(convCtx context.Context, convClient *speech.Client, config *speechpb.StreamingRecognitionConfig, index int, party *conversationParty) {
+	defer close(party.done)
+
+	// Consecutive retries without receiving a response
+	retries := 0
+
+	for {
+		party.mutex.Lock()
+			currentStream := party.stream
+			clock := party.clock
+		party.mutex.Unlock()
+
+		resp, err := currentStream.Recv()
+		if err == nil {
+			retries = 0
+			collectConversationTurns(index, party, resp, clock)
+			continue
+		}
+		if convCtx.Err() != nil {
+			return
+		}
+
+		party.mutex.Lock()
+			ending := party.ending
+		party.mutex.Unlock()
+		if err == io.EOF && ending {
+			return
+		}
+		if err != io.EOF && (isRetryable(err) == false || retries >= maxRetries) {
+			logError("Conversation stream of \"" + party.speaker + "\" failed: ", err)
+			party.mutex.Lock()
+				party.failure = err
+			party.mutex.Unlock()
+			return
+		}
+		if err != io.EOF {
+			retries++
+		}
+
+		// Google ended the stream (e.g. after the maximum stream duration), the party continues on a new one.
+		party.mutex.Lock()
+			if party.ending {
+				party.mutex.Unlock()
+				return
+			}
+			newStream, openErr := openConversationStream(convCtx, convClient, config)
+			if openErr != nil {
+				party.failure = openErr
+			} else {
+				party.stream = newStream
+				party.clock = &streamClock{sampleRate: int64(config.Config.SampleRateHertz)}
+				party.lastEnd = nil
+			}
+		party.mutex.Unlock()
+		if openErr != nil {
+			logError("Could not reopen the conversation stream of \"" + party.speaker + "\": ", openErr)
+			return
+		}
+	}
+}
+
+
+// collectConversationTurns adds the final results of the response to the timeline (in the order of their start),
+// a result starts with its first word (if the word time offsets are enabled) or at the end of the party's previous result.
+func collectConversationTurns(index int, party *conversationParty, resp *speechpb.StreamingRecognizeResponse, clock *streamClock) {
+	turns := []conversationTurn{}
+	for _, res := range resp.Results {
+		if res.IsFinal == false || len(res.Alternatives) == 0 {
+			continue
+		}
+		alternative := res.Alternatives[0]
+
+		party.mutex.Lock()
+			start := party.lastEnd
+			party.lastEnd = res.ResultEndTime
+		party.mutex.Unlock()
+		if start == nil {
+			start = &duration.Duration{}
+		}
+		if len(alternative.Words) > 0 && alternative.Words[0].StartTime != nil {
+			start = alternative.Words[0].StartTime
+		}
+
+		transcript := postProcess(strings.TrimSpace(alternative.Transcript))
+		if transcript == "" {
+			continue
+		}
+		turns = append(turns, conversationTurn{
+			Party:			index,
+			Speaker:		party.speaker,
+			Transcript:		transcript,
+			Confidence:		alternative.Confidence,
+			StartTimestamp:	clock.timestamp(start),
+			EndTimestamp:	clock.timestamp(res.ResultEndTime),
+		})
+	}
+	if len(turns) == 0 {
+		return
+	}
+
+	conversationMutex.Lock()
+		for _, turn := range turns {
+			position := sort.Search(len(conversationTurns), func(i int) bool {
+				return conversationTurns[i].StartTimestamp > turn.StartTimestamp
+			})
+			conversationTurns = append(conversationTurns, conversationTurn{})
+			copy(conversationTurns[position + 1:], conversationTurns[position:])
+			conversationTurns[position] = turn
+		}
+	conversationMutex.Unlock()
+}
+
+
+// endConversation ends the running conversation, drain lets the parties finish their remaining final results first
+// (otherwise the streams are canceled right away, e.g. by "Shutdown()").
+// The caller has to hold the conversationLifecycleMutex.
+func endConversation(drain bool) {
+	conversationMutex.Lock()
+		running := parties
+		parties = [conversationParties]*conversationParty{}
+		convCancel := conversationCancel
+		convClient := conversationClient
+		conversationCancel = nil
+		conversationClient = nil
+	conversationMutex.Unlock()
+	if running[0] == nil {
+		return
+	}
+
+	// No more audio follows, google returns the remaining results and ends the streams.
+	for _, party := range running {
+		party.mutex.Lock()
+			party.ending = true
+			party.stream.CloseSend()
+		party.mutex.Unlock()
+	}
+	if drain {
+		timeout := time.After(conversationDrainTimeout)
+		for _, party := range running {
+			select {
+			case <-party.done:
+			case <-timeout:
+			}
+		}
+	}
+
+	convCancel()
+	for _, party := range running {
+		<-party.done
+	}
+	conversationCalls.Wait()
+	convClient.Close()
+	for range running {
+		releaseRecognition()
+	}
+}
+
+
+/*
+	StartConversation(cTranscriptLanguage *C.char, cSampleRate C.int, cTranscriptionModel *C.char, cLocalSpeaker *C.char, cRemoteSpeaker *C.char) (C.int):
+	starts a conversation of two parties (e.g. the local microphone and the remote audio of a VoIP call),
+	each party's audio is recognized by its own stream (see "SendConversationAudio()") and the final results
+	of both are merged into one interleaved timeline attributed to the speakers (see "GetConversationTranscript()"),
+	the conversation runs besides the streaming session with its own connection (default credentials),
+	the settings made before apply like in "InitializeStream()" (the audio has to be LINEAR16), a running conversation is ended
+
+	Parameters:
+		cTranscriptLanguage, cSampleRate, cTranscriptionModel:
+			the same as "InitializeStream()" (for both parties)
+		cLocalSpeaker *C.char
+			(the name of the local party in the transcript, e.g. "Interviewer")
+		cRemoteSpeaker *C.char
+			(the name of the remote party in the transcript, e.g. "Guest")
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export StartConversation
+func StartConversation(cTranscriptLanguage *C.char, cSampleRate C.int, cTranscriptionModel *C.char, cLocalSpeaker *C.char, cRemoteSpeaker *C.char) (C.int) {
+	if code := checkCallbackThread("StartConversation"); code != resultOK {
+		return result(code)
+	}
+	speakers := [conversationParties]string{C.GoString(cLocalSpeaker), C.GoString(cRemoteSpeaker)}
+	if speakers[partyLocal] == "" || speakers[partyRemote] == "" {
+		logError("Invalid speaker names (must not be empty)", nil)
+		return result(resultInvalidArgument)
+	}
+	if reason := modelUnavailable(C.GoString(cTranscriptLanguage), C.GoString(cTranscriptionModel)); reason != "" {
+		logError(reason, nil)
+		return result(resultModelUnavailable)
+	}
+	if budgetUsedUp() {
+		logError("Billed seconds budget is exceeded", nil)
+		return result(resultBudgetExceeded)
+	}
+
+	sendMutex.Lock()
+		config := newStreamingConfig(C.GoString(cTranscriptLanguage), int32(cSampleRate), C.GoString(cTranscriptionModel), 1, false)
+	sendMutex.Unlock()
+	// The parties send samples, the results of both streams are merged instead of restarting after an utterance.
+	config.Config.Encoding = speechpb.RecognitionConfig_LINEAR16
+	config.SingleUtterance = false
+	config.Config.DiarizationConfig = nil
+
+	conversationLifecycleMutex.Lock()
+	defer conversationLifecycleMutex.Unlock()
+
+	endConversation(true)
+
+	// Every party is a recognition of the rate limits.
+	acquired := 0
+	releaseAcquired := func() {
+		for ; acquired > 0; acquired-- {
+			releaseRecognition()
+		}
+	}
+	for ; acquired < conversationParties; acquired++ {
+		if code := acquireRecognition(nil); code != resultOK {
+			releaseAcquired()
+			return result(code)
+		}
+	}
+
+	convCtx, convCancel := context.WithCancel(context.Background())
+	convClient, err := speech.NewClient(convCtx)
+	if err != nil {
+		convCancel()
+		releaseAcquired()
+		logError("", err)
+		return result(resultError)
+	}
+
+	var started [conversationParties]*conversationParty
+	for i := range started {
+		newStream, err := openConversationStream(convCtx, convClient, config)
+		if err != nil {
+			convCancel()
+			convClient.Close()
+			releaseAcquired()
+			logError("Could not open the conversation stream of \"" + speakers[i] + "\": ", err)
+			return result(resultError)
+		}
+		started[i] = &conversationParty{
+			speaker:	speakers[i],
+			stream:		newStream,
+			clock:		&streamClock{sampleRate: int64(config.Config.SampleRateHertz)},
+			done:		make(chan struct{}),
+		}
+	}
+
+	conversationMutex.Lock()
+		parties = started
+		conversationCancel = convCancel
+		conversationClient = convClient
+		conversationConfig = config
+		conversationTurns = nil
+	conversationMutex.Unlock()
+
+	for i, party := range started {
+		go receiveConversation(convCtx, convClient, config, i, party)
+	}
+	return result(resultOK)
+}
+
+
+/*
+	SendConversationAudio(cParty C.int, recording *C.short, recordingLength C.int, timestampUs C.longlong) (C.int):
+	sends audio of a party of the conversation (see "StartConversation()"), both parties' timestamps have to be
+	on the same clock, so their results interleave correctly
+
+	Parameters:
+		cParty C.int
+			(0: the local party, 1: the remote party, see GO_SPEECH_RECOGNITION_PARTY in the header)
+		recording, recordingLength:
+			the same as "SendAudio()"
+		timestampUs:
+			the timestamp of the first sample in microseconds (see "SendAudioWithTimestamp()"), -1 for the time of the call
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SendConversationAudio
+func SendConversationAudio(cParty C.int, recording *C.short, recordingLength C.int, timestampUs C.longlong) (C.int) {
+	if code := checkCallbackThread("SendConversationAudio"); code != resultOK {
+		return result(code)
+	}
+	if cParty != partyLocal && cParty != partyRemote {
+		logError("Invalid party (must be 0 or 1)", nil)
+		return result(resultInvalidArgument)
+	}
+	if recordingLength < 0 || recording == nil && recordingLength > 0 {
+		logError("Invalid audio data", nil)
+		return result(resultInvalidArgument)
+	}
+	if timestampUs < 0 {
+		timestampUs = C.longlong(time.Now().UnixNano() / 1000)
+	}
+
+	conversationMutex.Lock()
+		party := parties[cParty]
+		if party == nil {
+			conversationMutex.Unlock()
+			logError("Conversation is not started", nil)
+			return result(resultNotInitialized)
+		}
+		sampleRate := int64(conversationConfig.Config.SampleRateHertz)
+		// "EndConversation()" waits until this call returned.
+		conversationCalls.Add(1)
+	conversationMutex.Unlock()
+	defer conversationCalls.Done()
+
+	samples := unsafe.Slice((*int16)(unsafe.Pointer(recording)), int(recordingLength))
+	audio := new(bytes.Buffer)
+	if err := binary.Write(audio, binary.LittleEndian, samples); err != nil {
+		logError("binary.Write failed:", err)
+		return result(resultError)
+	}
+
+	party.mutex.Lock()
+	defer party.mutex.Unlock()
+
+	if party.failure != nil {
+		logError("Could not send conversation audio: ", party.failure)
+		return result(resultError)
+	}
+	if party.ending {
+		logError("Conversation has ended", nil)
+		return result(resultFinalized)
+	}
+
+	// The audio is sent in chunks of the maximum chunk size (a request is limited by google).
+	chunkSamples := int(maxChunkMs * sampleRate / 1000)
+	for sent := 0; sent < len(samples); sent += chunkSamples {
+		end := sent + chunkSamples
+		if end > len(samples) {
+			end = len(samples)
+		}
+		err := party.stream.Send(&speechpb.StreamingRecognizeRequest{
+				StreamingRequest: &speechpb.StreamingRecognizeRequest_AudioContent{
+					AudioContent: audio.Bytes()[sent * 2:end * 2],
+					},
+				})
+		// The reason of an ended stream is handled by the receiving (which reopens it).
+		if err != nil && err != io.EOF {
+			logError("Could not send conversation audio: ", err)
+			return result(resultError)
+		}
+		party.clock.advance(int64(end - sent), int64(timestampUs) + int64(sent) * 1000000 / sampleRate)
+	}
+
+	if accountDailyBilledAudio(float64(len(samples)) / float64(sampleRate)) {
+		logError("Billed seconds budget is exceeded", nil)
+		return result(resultBudgetExceeded)
+	}
+	return result(resultOK)
+}
+
+
+/*
+	GetConversationTranscript(output **C.char) (C.int):
+	retrieves the merged transcript of the current (or last) conversation as a JSON array of the final results
+	in the order of their start, e.g.:
+	[{"party":0,"speaker":"Interviewer","transcript":"How did you start?","confidence":0.93,"startTimestamp":1559383200000000,"endTimestamp":1559383201800000},
+	{"party":1,"speaker":"Guest","transcript":"By accident.","confidence":0.88,"startTimestamp":1559383202100000,"endTimestamp":1559383203000000}]
+	(the timestamps are in microseconds of the host's clock (see "SendConversationAudio()"), the start of a result is its first word
+	with the word time offsets enabled (see "SetWordTimeOffsets()"), otherwise the end of the party's previous result)
+
+	Parameters:
+		output:
+			The pointer which is used to store the transcript
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetConversationTranscript
+func GetConversationTranscript(output **C.char) (C.int) {
+	if output == nil {
+		logError("Invalid output pointer", nil)
+		return result(resultInvalidArgument)
+	}
+
+	conversationMutex.Lock()
+		turns := append([]conversationTurn{}, conversationTurns...)
+	conversationMutex.Unlock()
+
+	encoded, err := json.Marshal(turns)
+	if err != nil {
+		logError("Could not encode conversation transcript: ", err)
+		return result(resultError)
+	}
+	*output = cString(string(encoded))
+	return result(resultOK)
+}
+
+
+/*
+	EndConversation():
+	ends the conversation (see "StartConversation()"): the parties' remaining final results are added to the
+	transcript (waiting at most 10 seconds), the transcript can still be retrieved until the next conversation starts
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export EndConversation
+func EndConversation() () {
+	if checkCallbackThread("EndConversation") != resultOK {
+		return
+	}
+	conversationLifecycleMutex.Lock()
+		endConversation(true)
+	conversationLifecycleMutex.Unlock()
+}
@@ -0,0 +1,126 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The debug server (see "EnableDebugServer()"): serves pprof and expvar of the Go runtime embedded in the host,
+	so memory growth or goroutine leaks inside the library can be profiled in production
+	(e.g. "go tool pprof http://localhost:6060/debug/pprof/heap").
+	The server only listens on the loopback interface.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"context"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The time the server gets to finish the pending requests when it's stopped
+const debugServerShutdownTimeout = 2 * time.Second
+
+// The debug server and its listener (guarded by the debugServerMutex), nil if disabled
+var debugServerMutex = &sync.Mutex{}
+var debugServer *http.Server
+var debugListener net.Listener
+
+// The library's variables are published once (expvar can't unpublish them)
+var publishDebugVariables sync.Once
+
+
+// newDebugHandler returns the handler of the pprof and expvar endpoints (not the default mux of the host).
+func newDebugHandler() (http.Handler) {
+	publishDebugVariables.Do(func() {
+		expvar.Publish("speechStats", expvar.Func(func() (interface{}) {
+			return currentStats()
+		}))
+		expvar.Publish("speechLogSequence", expvar.Func(func() (interface{}) {
+			logMutex.Lock()
+			defer logMutex.Unlock()
+			return logSequence
+		}))
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
+
+
+// stopDebugServer stops the debug server (if running).
+// The caller has to hold the debugServerMutex.
+func stopDebugServer() {
+	if debugServer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), debugServerShutdownTimeout)
+	defer cancel()
+	if debugServer.Shutdown(ctx) != nil {
+		debugServer.Close()
+	}
+	// The server may not serve yet (then it doesn't close the listener), the port is free for the next server.
+	debugListener.Close()
+	debugServer, debugListener = nil, nil
+}
+
+
+/*
+	EnableDebugServer(cPort C.int) (C.int):
+	serves pprof ("/debug/pprof/") and expvar ("/debug/vars", including the statistics of "GetStats()" as "speechStats")
+	of the Go runtime embedded in the host on the loopback interface (127.0.0.1), e.g. to profile memory growth
+	or goroutine leaks with "go tool pprof http://localhost:6060/debug/pprof/heap", a running server is replaced
+
+	Parameter:
+		cPort C.int
+			(the port of the server (1 - 65535), 0 stops the server (default))
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export EnableDebugServer
+func EnableDebugServer(cPort C.int) (C.int) {
+	if cPort < 0 || cPort > 65535 {
+		logError("Invalid port (must be 0 - 65535)", nil)
+		return result(resultInvalidArgument)
+	}
+
+	debugServerMutex.Lock()
+	defer debugServerMutex.Unlock()
+
+	stopDebugServer()
+	if cPort == 0 {
+		return result(resultOK)
+	}
+
+	// The port is bound right away, so a port in use is reported to the host.
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(cPort))))
+	if err != nil {
+		logError("Could not start debug server: ", err)
+		return result(resultError)
+	}
+	server := &http.Server{Handler: newDebugHandler()}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logError("Debug server failed: ", err)
+		}
+	}()
+	debugServer, debugListener = server, listener
+	return result(resultOK)
+}
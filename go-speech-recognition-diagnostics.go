@@ -0,0 +1,233 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The diagnostics bundle (see "WriteDiagnosticsBundle()"): a zip file with everything a bug report needs,
+	i.e. the configuration of the session, the recent log, the statistics, the requests to google, a dump of the goroutines
+	and the environment (the platform, the versions and the relevant environment variables).
+	Secrets aren't included: the credentials and keys set via the calls are never written, the values of environment
+	variables with a secret name are replaced and so are the passwords of proxy URLs,
+	the sensitive terms are masked (see "AddSensitiveTerm()").
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"net/url"
+	"os"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+)
+
+// The environment variables included in the bundle (by the prefix of their name)
+var diagnosticsEnvironmentPrefixes = []string{"GOOGLE_", "GRPC_", "GO", "HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY"}
+
+// The values of environment variables with such a name are replaced
+var secretNamePattern = regexp.MustCompile(`(?i)(KEY|SECRET|TOKEN|PASSWORD|PASSWD|AUTH)`)
+const redacted = "REDACTED"
+
+// The environment of the process
+type diagnosticsEnvironment struct {
+	Created string `json:"created"`
+	OS string `json:"os"`
+	Arch string `json:"arch"`
+	GoVersion string `json:"goVersion"`
+	CPUs int `json:"cpus"`
+	GoMaxProcs int `json:"gomaxprocs"`
+	Goroutines int `json:"goroutines"`
+	HeapAllocBytes uint64 `json:"heapAllocBytes"`
+	SysBytes uint64 `json:"sysBytes"`
+	GCCycles uint32 `json:"gcCycles"`
+	GCPercent int64 `json:"gcPercent"`
+	MemoryLimitBytes int64 `json:"memoryLimitBytes"`
+	Modules map[string]string `json:"modules,omitempty"`
+	LegacyReturnCodes bool `json:"legacyReturnCodes"`
+	InMemoryOnly bool `json:"inMemoryOnly"`
+	Variables map[string]string `json:"variables"`
+}
+
+
+// diagnosticsVariables returns the relevant environment variables, without their secrets.
+func diagnosticsVariables() (map[string]string) {
+	variables := map[string]string{}
+	for _, variable := range os.Environ() {
+		name, value, _ := strings.Cut(variable, "=")
+		relevant := false
+		for _, prefix := range diagnosticsEnvironmentPrefixes {
+			relevant = relevant || strings.HasPrefix(strings.ToUpper(name), prefix)
+		}
+		if relevant == false {
+			continue
+		}
+
+		if secretNamePattern.MatchString(name) {
+			value = redacted
+		} else if parsed, err := url.Parse(value); err == nil && parsed.User != nil {
+			// e.g. the password of a proxy
+			parsed.User = url.User(redacted)
+			value = parsed.String()
+		}
+		variables[name] = value
+	}
+	return variables
+}
+
+
+// currentEnvironment describes the process and the library.
+func currentEnvironment() (diagnosticsEnvironment) {
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+
+	environment := diagnosticsEnvironment{
+		Created:			time.Now().Format(time.RFC3339Nano),
+		OS:					runtime.GOOS,
+		Arch:				runtime.GOARCH,
+		GoVersion:			runtime.Version(),
+		CPUs:				runtime.NumCPU(),
+		GoMaxProcs:			runtime.GOMAXPROCS(0),
+		Goroutines:			runtime.NumGoroutine(),
+		HeapAllocBytes:		memory.HeapAlloc,
+		SysBytes:			memory.Sys,
+		GCCycles:			memory.NumGC,
+		LegacyReturnCodes:	atomic.LoadInt32(&legacyReturnCodes) != 0,
+		InMemoryOnly:		atomic.LoadInt32(&inMemoryOnly) != 0,
+		Variables:			diagnosticsVariables(),
+	}
+	environment.GCPercent, environment.MemoryLimitBytes = gcSettings()
+	// The versions of the modules the library has been built with (e.g. the client library of google)
+	if build, ok := debug.ReadBuildInfo(); ok {
+		environment.Modules = map[string]string{}
+		for _, module := range build.Deps {
+			environment.Modules[module.Path] = module.Version
+		}
+	}
+	return environment
+}
+
+
+// sessionConfigJSON returns the configuration of the current (or last) session, "{}" before the first session.
+func sessionConfigJSON() ([]byte, error) {
+	sendMutex.Lock()
+	defer sendMutex.Unlock()
+
+	if sessionConfig == nil {
+		return []byte("{}"), nil
+	}
+	config, err := (&jsonpb.Marshaler{Indent: "\t"}).MarshalToString(sessionConfig)
+	return []byte(config), err
+}
+
+
+// writeDiagnostics writes the files of the bundle into the zip.
+func writeDiagnostics(archive *zip.Writer) (error) {
+	indented := func(value interface{}) ([]byte, error) {
+		return json.MarshalIndent(value, "", "\t")
+	}
+	logMutex.Lock()
+		lastErrorCopy := lastError
+	logMutex.Unlock()
+
+	files := []struct {
+		name string
+		content func() ([]byte, error)
+	}{
+		{"environment.json", func() ([]byte, error) { return indented(currentEnvironment()) }},
+		{"config.json", sessionConfigJSON},
+		{"stats.json", func() ([]byte, error) { return indented(currentStats()) }},
+		{"session.json", encodeSessionInfo},
+		{"log.json", func() ([]byte, error) { return indented(logEntriesSince(0)) }},
+		{"last-error.json", func() ([]byte, error) { return indented(lastErrorCopy) }},
+	}
+	for _, file := range files {
+		content, err := file.content()
+		if err != nil {
+			return err
+		}
+		// e.g. the phrase hints of the configuration (see "AddSensitiveTerm()")
+		content = []byte(redactSensitive(string(content)))
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write(content); err != nil {
+			return err
+		}
+	}
+
+	// The stacks of all goroutines (e.g. to find a hanging call).
+	writer, err := archive.Create("goroutines.txt")
+	if err != nil {
+		return err
+	}
+	return pprof.Lookup("goroutine").WriteTo(writer, 2)
+}
+
+
+/*
+	WriteDiagnosticsBundle(cPath *C.char) (C.int):
+	writes a zip file for a bug report with the configuration of the current (or last) session (config.json),
+	the recent log (log.json, like "GetLogEntries()", and last-error.json, like "GetLastErrorJSON()"),
+	the statistics (stats.json, like "GetStats()"), the requests to google (session.json, like "GetSessionInfo()"),
+	the stacks of the goroutines (goroutines.txt) and the environment (environment.json: the platform,
+	the versions of the modules, the memory, the settings of the collector and the environment variables of google, gRPC, Go and the proxies),
+	secrets aren't included (the credentials and keys set via the calls are never written, the values of environment
+	variables whose name contains e.g. KEY, SECRET or TOKEN are replaced by "REDACTED" and so are the passwords of URLs)
+
+	Parameter:
+		cPath *C.char
+			(the path of the zip file as a C string, an existing file gets replaced)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export WriteDiagnosticsBundle
+func WriteDiagnosticsBundle(cPath *C.char) (C.int) {
+	path := C.GoString(cPath)
+	if path == "" {
+		logError("Invalid path of the diagnostics bundle", nil)
+		return result(resultInvalidArgument)
+	}
+	if forbidsDiskWrites("The diagnostics bundle") {
+		return result(resultInvalidArgument)
+	}
+
+	// The bundle is written to a temporary file first, so there's never a half-written bundle.
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		logError("Could not create diagnostics bundle: ", err)
+		return result(resultError)
+	}
+	archive := zip.NewWriter(file)
+	err = writeDiagnostics(archive)
+	if closeErr := archive.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(path + ".tmp", path)
+	}
+	if err != nil {
+		os.Remove(path + ".tmp")
+		logError("Could not write diagnostics bundle: ", err)
+		return result(resultError)
+	}
+	return result(resultOK)
+}
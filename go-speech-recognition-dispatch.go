@@ -0,0 +1,141 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Controls the thread the callbacks are invoked on (see "SetCallbackDispatch()"): by default they're invoked immediately
+	on the library's threads, hosts which may only be called on their main thread (UI toolkits, game engines) queue them
+	and deliver them on the thread calling "PumpCallbacks()".
+	The re-ranking callback (see "SetRerankCallback()") is always invoked immediately, the library waits for its result.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// Dispatch modes of the callbacks (see "SetCallbackDispatch()")
+const (
+	dispatchImmediate int32 = 0
+	dispatchQueued int32 = 1
+)
+
+// The maximum of queued callbacks, the oldest are dropped when the host doesn't pump them
+const callbackQueueSize = 1024
+
+// The dispatch mode and the queued callbacks (guarded by the dispatchMutex)
+var dispatchMutex = &sync.Mutex{}
+var dispatchMode = dispatchImmediate
+var queuedCallbacks []func()
+var droppedCallbacks = 0
+
+
+// dispatchCallback invokes the callback immediately or queues it for "PumpCallbacks()" (depending on the dispatch mode).
+func dispatchCallback(invoke func()) {
+	dispatchMutex.Lock()
+	if dispatchMode == dispatchImmediate {
+		dispatchMutex.Unlock()
+		invokeMarked(invoke)
+		return
+	}
+	defer dispatchMutex.Unlock()
+
+	if len(queuedCallbacks) == callbackQueueSize {
+		queuedCallbacks = queuedCallbacks[1:]
+		droppedCallbacks++
+	}
+	queuedCallbacks = append(queuedCallbacks, invoke)
+}
+
+
+// clearCallbacks drops the queued callbacks (their functions may be gone with the host, see "Shutdown()").
+func clearCallbacks() {
+	dispatchMutex.Lock()
+		queuedCallbacks = nil
+		droppedCallbacks = 0
+	dispatchMutex.Unlock()
+}
+
+
+/*
+	SetCallbackDispatch(cMode C.int) (C.int):
+	sets the thread the callbacks (see "SetFinalResultCallback()", "SetLevelCallback()" and "SetBatchCallback()") are invoked on
+
+	Parameter:
+		cMode C.int
+			(0 to invoke them immediately on the library's threads (default),
+			1 to queue them until the host calls "PumpCallbacks()" on the thread it chose,
+			switching back to 0 keeps the queued callbacks until they're pumped)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetCallbackDispatch
+func SetCallbackDispatch(cMode C.int) (C.int) {
+	mode := int32(cMode)
+	if mode != dispatchImmediate && mode != dispatchQueued {
+		logError("Invalid callback dispatch mode (must be 0 or 1)", nil)
+		return result(resultInvalidArgument)
+	}
+
+	dispatchMutex.Lock()
+		dispatchMode = mode
+	dispatchMutex.Unlock()
+	return result(resultOK)
+}
+
+
+/*
+	PumpCallbacks(cMaxCallbacks C.int) (C.int):
+	invokes the queued callbacks on the calling thread in the order they were queued
+	(a callback may call the library, e.g. to close the session, but can't pump the callbacks itself)
+
+	Parameter:
+		cMaxCallbacks C.int
+			(the maximum of callbacks to invoke, 0 for all queued callbacks)
+
+	Return:
+		the number of callbacks invoked
+		a negative error code if another "PumpCallbacks()" is in progress (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export PumpCallbacks
+func PumpCallbacks(cMaxCallbacks C.int) (C.int) {
+	// The callbacks are delivered in order, so they're pumped by one thread at a time.
+	if !atomic.CompareAndSwapInt32(&pumping, 0, 1) {
+		logError("Another \"PumpCallbacks()\" call is in progress", nil)
+		return result(resultBusy)
+	}
+	defer atomic.StoreInt32(&pumping, 0)
+
+	dispatchMutex.Lock()
+		count := len(queuedCallbacks)
+		if cMaxCallbacks > 0 && int(cMaxCallbacks) < count {
+			count = int(cMaxCallbacks)
+		}
+		pending := queuedCallbacks[:count]
+		queuedCallbacks = queuedCallbacks[count:]
+		dropped := droppedCallbacks
+		droppedCallbacks = 0
+	dispatchMutex.Unlock()
+
+	if dropped > 0 {
+		logWarning(strconv.Itoa(dropped) + " callbacks were dropped (the callbacks weren't pumped fast enough)")
+	}
+
+	// The mutex isn't held, so the callbacks can queue new ones.
+	for _, invoke := range pending {
+		invoke()
+	}
+	return C.int(count)
+}
@@ -0,0 +1,163 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Measures the drift between the capture clock (the samples the host sends) and the clock of the timestamps
+	(the wall clock for "SendAudio()", the host's clock for "SendAudioWithTimestamp()", see "GetClockDrift()"):
+	a device whose real sample rate deviates from the nominal one delivers more or less audio than time passes,
+	so in long sessions (e.g. hours of captions) google's result times drift away from the video timeline.
+	With the compensation enabled (see "SetDriftCompensation()") the result times are mapped with the measured sample rate.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"math"
+	"sync"
+)
+
+// The sample rate is measured after this much audio (shorter windows are dominated by the jitter of the calls)
+const minDriftWindowUs = 10 * 1000000
+// A deviation beyond this (and beyond maxDriftRatio of the window) is a gap in the audio, not drift,
+// and starts a new measuring window
+const driftGapUs = 500000
+const maxDriftRatio = 0.001
+
+// The measurement of the session (guarded by the driftMutex): the current window (since the last gap), i.e. its start,
+// the samples sent in it, the time until the last audio and the samples sent before it, and the drift
+// of the previous windows in microseconds (positive if the capture clock is slower than the timestamps)
+var driftMutex = &sync.Mutex{}
+var driftCompensation = false
+var driftWindowStartUs int64 = -1
+var driftWindowSamples int64
+var driftWindowUs int64
+var driftMeasuredSamples int64
+var driftNominalRate int64
+var previousDriftUs int64
+
+
+// resetDrift starts the measurement of a new session.
+func resetDrift() {
+	driftMutex.Lock()
+		driftWindowStartUs = -1
+		driftWindowSamples = 0
+		driftWindowUs = 0
+		driftMeasuredSamples = 0
+		previousDriftUs = 0
+	driftMutex.Unlock()
+}
+
+
+// windowDriftUs returns the drift of the current window (the driftMutex has to be held).
+func windowDriftUs() (int64) {
+	if driftWindowStartUs < 0 || driftNominalRate <= 0 {
+		return 0
+	}
+	return driftWindowUs - driftMeasuredSamples * 1000000 / driftNominalRate
+}
+
+
+// measureDrift accounts audio of the session: its samples at the nominal sample rate and the timestamp of its first sample.
+func measureDrift(samples int64, timestampUs int64, sampleRate int64) {
+	if samples <= 0 || timestampUs < 0 || sampleRate <= 0 {
+		return
+	}
+	driftMutex.Lock()
+	defer driftMutex.Unlock()
+
+	if driftWindowStartUs >= 0 && sampleRate == driftNominalRate {
+		elapsedUs := timestampUs - driftWindowStartUs
+		deviationUs := elapsedUs - driftWindowSamples * 1000000 / sampleRate
+		if math.Abs(float64(deviationUs)) <= math.Max(driftGapUs, maxDriftRatio * float64(elapsedUs)) {
+			driftWindowUs = elapsedUs
+			driftMeasuredSamples = driftWindowSamples
+			driftWindowSamples += samples
+			return
+		}
+		// A gap (e.g. the host paused sending), the drift measured so far is kept.
+		previousDriftUs += windowDriftUs()
+	}
+	driftWindowStartUs = timestampUs
+	driftWindowSamples = samples
+	driftWindowUs = 0
+	driftMeasuredSamples = 0
+	driftNominalRate = sampleRate
+}
+
+
+// compensatedSampleRate returns the measured sample rate of the capture clock, 0 if the compensation is disabled
+// or nothing has been measured yet.
+func compensatedSampleRate() (float64) {
+	driftMutex.Lock()
+	defer driftMutex.Unlock()
+
+	if driftCompensation == false || driftWindowUs < minDriftWindowUs {
+		return 0
+	}
+	return float64(driftMeasuredSamples) * 1000000 / float64(driftWindowUs)
+}
+
+
+/*
+	SetDriftCompensation(cEnabled C.int):
+	maps google's result times to the timestamps with the measured sample rate of the capture clock
+	(see "GetClockDrift()") instead of the nominal one, so the timestamps of long sessions stay aligned
+	with the timeline (e.g. of a video), takes effect once 10 seconds of audio have been measured
+
+	Parameter:
+		cEnabled C.int
+			(1 to enable, 0 to disable the drift compensation (default))
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetDriftCompensation
+func SetDriftCompensation(cEnabled C.int) () {
+	driftMutex.Lock()
+		driftCompensation = int32(cEnabled) == int32(1)
+	driftMutex.Unlock()
+}
+
+
+/*
+	GetClockDrift(driftMs *C.double, ppm *C.double) (C.int):
+	retrieves the cumulative drift between the capture clock (the samples sent at the nominal sample rate) and the clock
+	of the timestamps (the wall clock for "SendAudio()", the host's clock for "SendAudioWithTimestamp()") in the current session,
+	gaps in the audio (e.g. a paused capture) aren't counted as drift
+
+	Parameters:
+		driftMs:
+			The pointer which is used to store the drift in milliseconds
+			(positive if the capture clock is slower, i.e. less audio arrived than time passed)
+		ppm:
+			The pointer which is used to store the deviation of the sample rate in parts per million
+			(positive if the capture clock is slower, 0 until 10 seconds of audio have been measured)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetClockDrift
+func GetClockDrift(driftMs *C.double, ppm *C.double) (C.int) {
+	if driftMs == nil || ppm == nil {
+		logError("Invalid output pointer", nil)
+		return result(resultInvalidArgument)
+	}
+
+	driftMutex.Lock()
+		drift := previousDriftUs + windowDriftUs()
+		deviation := 0.0
+		if driftWindowUs >= minDriftWindowUs {
+			deviation = float64(windowDriftUs()) / float64(driftWindowUs) * 1000000
+		}
+	driftMutex.Unlock()
+
+	*driftMs = C.double(float64(drift) / 1000)
+	*ppm = C.double(deviation)
+	return result(resultOK)
+}
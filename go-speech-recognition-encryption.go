@@ -0,0 +1,220 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Encrypts the files the library writes (see "SetEncryptionKey()") with AES-GCM and the host's key,
+	so voice data and transcripts at rest meet compliance requirements.
+	An encrypted file starts with the magic "GSRENC1\n" followed by records (files which are appended to have one record per append):
+	the length of the sealed data (4 bytes, big endian), the nonce (12 bytes) and the sealed data (the ciphertext and the 16 bytes tag).
+*/
+
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"os"
+	"sync"
+	"unsafe"
+)
+
+// The magic at the start of every encrypted file
+const encryptedFileMagic = "GSRENC1\n"
+// Suffix of the encrypted transcript files (so appending never mixes them with plain text files)
+const encryptedFileSuffix = ".enc"
+
+// The cipher of the host's key, nil if the encryption is disabled (guarded by the encryptionMutex)
+var encryptionMutex = &sync.Mutex{}
+var fileCipher cipher.AEAD
+
+
+/*
+	SetEncryptionKey(cKey *C.uint8_t, cKeyLength C.int) (C.int):
+	enables the encryption of the files the library writes (the backfill's spool, the transcript files,
+	the journal of the result acknowledgment and the result cache) with AES-GCM,
+	encrypted transcript files get the suffix ".enc", the key is needed to read the files again (see "DecryptFile()")
+
+	Parameters:
+		cKey *C.uint8_t
+			(the AES key, which is copied)
+		cKeyLength C.int
+			(the length of the key: 16, 24 or 32 bytes for AES-128, AES-192 or AES-256, 0 disables the encryption (default))
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetEncryptionKey
+func SetEncryptionKey(cKey *C.uint8_t, cKeyLength C.int) (C.int) {
+	if cKeyLength == 0 {
+		encryptionMutex.Lock()
+			fileCipher = nil
+		encryptionMutex.Unlock()
+		return result(resultOK)
+	}
+	if cKey == nil || (cKeyLength != 16 && cKeyLength != 24 && cKeyLength != 32) {
+		logError("Invalid encryption key (must be 16, 24 or 32 bytes)", nil)
+		return result(resultInvalidArgument)
+	}
+
+	block, err := aes.NewCipher(C.GoBytes(unsafe.Pointer(cKey), cKeyLength))
+	if err != nil {
+		logError("Invalid encryption key: ", err)
+		return result(resultInvalidArgument)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		logError("Could not create the cipher: ", err)
+		return result(resultError)
+	}
+
+	encryptionMutex.Lock()
+		fileCipher = aead
+	encryptionMutex.Unlock()
+	return result(resultOK)
+}
+
+
+/*
+	DecryptFile(cPath *C.char, cOutputPath *C.char) (C.int):
+	decrypts a file written by the library with the key of "SetEncryptionKey()" (e.g. a transcript file for an export),
+	a file which isn't encrypted is copied as is
+
+	Parameters:
+		cPath *C.char
+			(the path of the encrypted file)
+		cOutputPath *C.char
+			(the path of the decrypted file, an existing file is replaced)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export DecryptFile
+func DecryptFile(cPath *C.char, cOutputPath *C.char) (C.int) {
+	if forbidsDiskWrites("DecryptFile") {
+		return result(resultInvalidArgument)
+	}
+	data, err := os.ReadFile(C.GoString(cPath))
+	if err != nil {
+		logError("Could not read the file: ", err)
+		return result(resultInvalidArgument)
+	}
+	plaintext, err := decryptData(data)
+	if err != nil {
+		logError("Could not decrypt the file: ", err)
+		return result(resultInvalidArgument)
+	}
+	if err := os.WriteFile(C.GoString(cOutputPath), plaintext, 0600); err != nil {
+		logError("Could not write the decrypted file: ", err)
+		return result(resultError)
+	}
+	return result(resultOK)
+}
+
+
+// currentCipher returns the cipher of the host's key (nil if the encryption is disabled).
+func currentCipher() (cipher.AEAD) {
+	encryptionMutex.Lock()
+	defer encryptionMutex.Unlock()
+	return fileCipher
+}
+
+
+// sealRecord encrypts the data as a record of an encrypted file.
+func sealRecord(aead cipher.AEAD, data []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nil, nonce, data, nil)
+
+	record := make([]byte, 4, 4 + len(nonce) + len(sealed))
+	binary.BigEndian.PutUint32(record, uint32(len(sealed)))
+	record = append(record, nonce...)
+	return append(record, sealed...), nil
+}
+
+
+// encryptData encrypts the content of a file (if the encryption is enabled, otherwise it's returned as is).
+func encryptData(data []byte) ([]byte, error) {
+	aead := currentCipher()
+	if aead == nil {
+		return data, nil
+	}
+	record, err := sealRecord(aead, data)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(encryptedFileMagic), record...), nil
+}
+
+
+// decryptData decrypts the content of an encrypted file with the host's key (any other content is returned as is).
+func decryptData(data []byte) ([]byte, error) {
+	return decryptWith(currentCipher(), data)
+}
+
+
+// decryptWith decrypts the content of an encrypted file with the given cipher (any other content is returned as is).
+func decryptWith(aead cipher.AEAD, data []byte) ([]byte, error) {
+	if bytes.HasPrefix(data, []byte(encryptedFileMagic)) == false {
+		return data, nil
+	}
+	if aead == nil {
+		return nil, errors.New("the file is encrypted, but no encryption key is set")
+	}
+
+	var plaintext []byte
+	records := data[len(encryptedFileMagic):]
+	for len(records) > 0 {
+		if len(records) < 4 + aead.NonceSize() {
+			return nil, errors.New("truncated record")
+		}
+		size := int(binary.BigEndian.Uint32(records))
+		records = records[4:]
+		if len(records) < aead.NonceSize() + size {
+			return nil, errors.New("truncated record")
+		}
+		nonce, sealed := records[:aead.NonceSize()], records[aead.NonceSize():aead.NonceSize() + size]
+		opened, err := aead.Open(nil, nonce, sealed, nil)
+		if err != nil {
+			return nil, err
+		}
+		plaintext = append(plaintext, opened...)
+		records = records[aead.NonceSize() + size:]
+	}
+	return plaintext, nil
+}
+
+
+// appendRecord appends the data to a file, encrypted as a record if a cipher is given
+// (the magic is written first into a new file).
+func appendRecord(file *os.File, aead cipher.AEAD, created bool, data []byte) (error) {
+	if aead == nil {
+		_, err := file.Write(data)
+		return err
+	}
+	record, err := sealRecord(aead, data)
+	if err != nil {
+		return err
+	}
+	if created {
+		record = append([]byte(encryptedFileMagic), record...)
+	}
+	_, err = file.Write(record)
+	return err
+}
@@ -0,0 +1,77 @@
+//go:build cgo
+
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Tests of the file encryption (the records of encrypted files).
+*/
+
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+)
+
+
+// testCipher creates a cipher like "SetEncryptionKey()" with a fixed key.
+func testCipher(t *testing.T, keyByte byte) (cipher.AEAD) {
+	t.Helper()
+
+	block, err := aes.NewCipher(bytes.Repeat([]byte{keyByte}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return aead
+}
+
+
+// TestDecryptWith decrypts files of several records and rejects broken ones.
+func TestDecryptWith(t *testing.T) {
+	aead := testCipher(t, 1)
+	encrypted := []byte(encryptedFileMagic)
+	for _, part := range []string{"first record, ", "", "second record"} {
+		record, err := sealRecord(aead, []byte(part))
+		if err != nil {
+			t.Fatal(err)
+		}
+		encrypted = append(encrypted, record...)
+	}
+	tampered := append([]byte{}, encrypted...)
+	tampered[len(tampered) - 1] ^= 1
+
+	tests := []struct {
+		name string
+		aead cipher.AEAD
+		data []byte
+		want string
+		fails bool
+	}{
+		{"records", aead, encrypted, "first record, second record", false},
+		{"plain", aead, []byte("not encrypted"), "not encrypted", false},
+		{"plain without key", nil, []byte("not encrypted"), "not encrypted", false},
+		{"no records", aead, []byte(encryptedFileMagic), "", false},
+		{"no key", nil, encrypted, "", true},
+		{"wrong key", testCipher(t, 2), encrypted, "", true},
+		{"tampered", aead, tampered, "", true},
+		{"truncated", aead, encrypted[:len(encrypted) - 1], "", true},
+		{"truncated size", aead, encrypted[:len(encryptedFileMagic) + 2], "", true},
+	}
+
+	for _, test := range tests {
+		plaintext, err := decryptWith(test.aead, test.data)
+		if (err != nil) != test.fails {
+			t.Errorf("%s: error %v", test.name, err)
+			continue
+		}
+		if err == nil && string(plaintext) != test.want {
+			t.Errorf("%s: %q, want %q", test.name, plaintext, test.want)
+		}
+	}
+}
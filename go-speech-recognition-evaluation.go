@@ -0,0 +1,287 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Accuracy evaluation against reference transcripts (see "EvaluateTranscript()" and "EvaluateFile()"):
+	the word error rate (WER) and the character error rate (CER) of a transcript and the alignment of its words
+	with the reference, so models, phrase hints and preprocessing options can be compared quantitatively.
+	Both texts are normalized before they're compared (case, punctuation and whitespace don't count as errors).
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"unicode"
+
+	speech "cloud.google.com/go/speech/apiv1"
+)
+
+// The edit operations of the alignment
+const (
+	alignEqual = "equal"
+	alignSubstitute = "substitute"
+	alignDelete = "delete"		// a reference word missing in the transcript
+	alignInsert = "insert"		// a transcript word not in the reference
+)
+
+// A word of the alignment (the missing side is empty)
+type alignedWord struct {
+	Op string `json:"op"`
+	Reference string `json:"reference,omitempty"`
+	Hypothesis string `json:"hypothesis,omitempty"`
+}
+
+// The result of the evaluation
+type evaluationReport struct {
+	File string `json:"file,omitempty"`
+	WER float64 `json:"wer"`
+	CER float64 `json:"cer"`
+	ReferenceWords int `json:"referenceWords"`
+	Substitutions int `json:"substitutions"`
+	Deletions int `json:"deletions"`
+	Insertions int `json:"insertions"`
+	Hypothesis string `json:"hypothesis"`
+	Reference string `json:"reference"`
+	Diff string `json:"diff"`
+	Alignment []alignedWord `json:"alignment"`
+}
+
+
+// normalizeForEvaluation lowercases the text and removes the punctuation (apostrophes and hyphens within words are kept),
+// the words are separated by single spaces.
+func normalizeForEvaluation(text string) (string) {
+	runes := []rune(strings.ToLower(text))
+	normalized := make([]rune, 0, len(runes))
+	for i, r := range runes {
+		inWord := i > 0 && i < len(runes) - 1 && unicode.IsLetter(runes[i - 1]) && unicode.IsLetter(runes[i + 1])
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			normalized = append(normalized, r)
+		case (r == '\'' || r == '-') && inWord:
+			normalized = append(normalized, r)
+		default:
+			normalized = append(normalized, ' ')
+		}
+	}
+	return strings.Join(strings.Fields(string(normalized)), " ")
+}
+
+
+// editDistance aligns the hypothesis with the reference (minimum number of substitutions, deletions and insertions)
+// and returns the operation of every position of the alignment.
+func editDistance[T comparable](reference []T, hypothesis []T) ([]string) {
+	// costs[i][j] is the distance between the first i reference and the first j hypothesis tokens.
+	costs := make([][]int, len(reference) + 1)
+	for i := range costs {
+		costs[i] = make([]int, len(hypothesis) + 1)
+		costs[i][0] = i
+	}
+	for j := range costs[0] {
+		costs[0][j] = j
+	}
+	for i := 1; i <= len(reference); i++ {
+		for j := 1; j <= len(hypothesis); j++ {
+			substitution := costs[i - 1][j - 1]
+			if reference[i - 1] != hypothesis[j - 1] {
+				substitution++
+			}
+			costs[i][j] = min(substitution, costs[i - 1][j] + 1, costs[i][j - 1] + 1)
+		}
+	}
+
+	// The operations are traced back from the end (preferring matches and substitutions).
+	ops := []string{}
+	for i, j := len(reference), len(hypothesis); i > 0 || j > 0; {
+		switch {
+		case i > 0 && j > 0 && reference[i - 1] == hypothesis[j - 1] && costs[i][j] == costs[i - 1][j - 1]:
+			ops = append(ops, alignEqual)
+			i, j = i - 1, j - 1
+		case i > 0 && j > 0 && costs[i][j] == costs[i - 1][j - 1] + 1:
+			ops = append(ops, alignSubstitute)
+			i, j = i - 1, j - 1
+		case i > 0 && costs[i][j] == costs[i - 1][j] + 1:
+			ops = append(ops, alignDelete)
+			i--
+		default:
+			ops = append(ops, alignInsert)
+			j--
+		}
+	}
+	for left, right := 0, len(ops) - 1; left < right; left, right = left + 1, right - 1 {
+		ops[left], ops[right] = ops[right], ops[left]
+	}
+	return ops
+}
+
+
+// evaluate compares the transcript with the reference.
+func evaluate(hypothesis string, reference string) (evaluationReport) {
+	report := evaluationReport{Hypothesis: normalizeForEvaluation(hypothesis), Reference: normalizeForEvaluation(reference)}
+	hypothesisWords := strings.Fields(report.Hypothesis)
+	referenceWords := strings.Fields(report.Reference)
+	report.ReferenceWords = len(referenceWords)
+
+	diff := []string{}
+	report.Alignment = []alignedWord{}
+	i, j := 0, 0
+	for _, op := range editDistance(referenceWords, hypothesisWords) {
+		word := alignedWord{Op: op}
+		switch op {
+		case alignEqual:
+			word.Reference, word.Hypothesis = referenceWords[i], hypothesisWords[j]
+			diff = append(diff, word.Reference)
+			i, j = i + 1, j + 1
+		case alignSubstitute:
+			word.Reference, word.Hypothesis = referenceWords[i], hypothesisWords[j]
+			diff = append(diff, "[-" + word.Reference + "+" + word.Hypothesis + "]")
+			report.Substitutions++
+			i, j = i + 1, j + 1
+		case alignDelete:
+			word.Reference = referenceWords[i]
+			diff = append(diff, "[-" + word.Reference + "]")
+			report.Deletions++
+			i++
+		case alignInsert:
+			word.Hypothesis = hypothesisWords[j]
+			diff = append(diff, "[+" + word.Hypothesis + "]")
+			report.Insertions++
+			j++
+		}
+		report.Alignment = append(report.Alignment, word)
+	}
+	report.Diff = strings.Join(diff, " ")
+
+	// The rates are relative to the reference (they exceed 1.0 with many insertions).
+	if len(referenceWords) > 0 {
+		report.WER = float64(report.Substitutions + report.Deletions + report.Insertions) / float64(len(referenceWords))
+	} else if len(hypothesisWords) > 0 {
+		report.WER = 1
+	}
+	// The characters are compared without the spaces.
+	referenceChars := []rune(strings.ReplaceAll(report.Reference, " ", ""))
+	hypothesisChars := []rune(strings.ReplaceAll(report.Hypothesis, " ", ""))
+	charErrors := 0
+	for _, op := range editDistance(referenceChars, hypothesisChars) {
+		if op != alignEqual {
+			charErrors++
+		}
+	}
+	if len(referenceChars) > 0 {
+		report.CER = float64(charErrors) / float64(len(referenceChars))
+	} else if len(hypothesisChars) > 0 {
+		report.CER = 1
+	}
+	return report
+}
+
+
+// writeEvaluation stores the report in the output.
+func writeEvaluation(report evaluationReport, output **C.char) (C.int) {
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		logError("Could not encode evaluation: ", err)
+		return resultError
+	}
+	*output = cString(string(encoded))
+	return resultOK
+}
+
+
+/*
+	EvaluateTranscript(cHypothesis *C.char, cReference *C.char, output **C.char) (C.int):
+	compares a transcript with its reference transcript and returns the word error rate (WER), the character error rate (CER)
+	and the alignment of the words as a JSON object, e.g.:
+	{"wer":0.25,"cer":0.1,"referenceWords":4,"substitutions":1,"deletions":0,"insertions":0,"hypothesis":"the cat sad down",
+	"reference":"the cat sat down","diff":"the cat [-sat+sad] down","alignment":[{"op":"equal","reference":"the","hypothesis":"the"},...]}
+	(both texts are normalized first: lowercase, without punctuation, so only the words count,
+	op is "equal", "substitute", "delete" (a reference word missing in the transcript) or "insert" (a word not in the reference))
+
+	Parameters:
+		cHypothesis *C.char
+			(the transcript to evaluate)
+		cReference *C.char
+			(the reference transcript, i.e. what has really been said)
+		output:
+			The pointer which is used to store the evaluation
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export EvaluateTranscript
+func EvaluateTranscript(cHypothesis *C.char, cReference *C.char, output **C.char) (C.int) {
+	if output == nil {
+		logError("Invalid output pointer", nil)
+		return result(resultInvalidArgument)
+	}
+	return result(writeEvaluation(evaluate(C.GoString(cHypothesis), C.GoString(cReference)), output))
+}
+
+
+/*
+	EvaluateFile(cPath *C.char, cReference *C.char, output **C.char) (C.int):
+	transcribes an audio file like a batch (see "TranscribeFiles()", with the settings of "SetBatchOptions()"
+	and "SetBatchCredentials()") and compares the transcript with the reference transcript like "EvaluateTranscript()",
+	the evaluation additionally contains the "file", the call blocks until the file is transcribed
+
+	Parameters:
+		cPath *C.char
+			(the path of the audio file (WAV or FLAC) or its Cloud Storage URI (gs://))
+		cReference *C.char
+			(the reference transcript, i.e. what has really been said)
+		output:
+			The pointer which is used to store the evaluation
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export EvaluateFile
+func EvaluateFile(cPath *C.char, cReference *C.char, output **C.char) (C.int) {
+	if output == nil {
+		logError("Invalid output pointer", nil)
+		return result(resultInvalidArgument)
+	}
+	if code := checkCallbackThread("EvaluateFile"); code != resultOK {
+		return result(code)
+	}
+	path := C.GoString(cPath)
+
+	batchMutex.Lock()
+		options := batchClientOptions
+	batchMutex.Unlock()
+
+	evalCtx, evalCancel := context.WithCancel(context.Background())
+	defer evalCancel()
+	evalClient, err := speech.NewClient(evalCtx, options...)
+	if err != nil {
+		logError("", err)
+		return result(resultError)
+	}
+	defer evalClient.Close()
+
+	// The file needs a free slot of the rate limits (see "SetRateLimits()").
+	if code := acquireRecognition(nil); code != resultOK {
+		return result(code)
+	}
+	transcript, code := transcribeFile(evalCtx, evalClient, batchRecognitionConfig(), path, func(int32) {})
+	releaseRecognition()
+	if code != resultOK {
+		return result(code)
+	}
+
+	report := evaluate(transcript, C.GoString(cReference))
+	report.File = path
+	return result(writeEvaluation(report, output))
+}
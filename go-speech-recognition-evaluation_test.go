@@ -0,0 +1,75 @@
+//go:build cgo
+
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Tests of the accuracy evaluation (the alignment and the error rates).
+*/
+
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+
+// TestEditDistance aligns token sequences (the operations are given from the start).
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		reference string
+		hypothesis string
+		want []string
+	}{
+		{"", "", []string{}},
+		{"abc", "abc", []string{alignEqual, alignEqual, alignEqual}},
+		{"abc", "", []string{alignDelete, alignDelete, alignDelete}},
+		{"", "ab", []string{alignInsert, alignInsert}},
+		{"abc", "axc", []string{alignEqual, alignSubstitute, alignEqual}},
+		{"abc", "ac", []string{alignEqual, alignDelete, alignEqual}},
+		{"ac", "abc", []string{alignEqual, alignInsert, alignEqual}},
+		{"kitten", "sitting", []string{alignSubstitute, alignEqual, alignEqual, alignEqual, alignSubstitute, alignEqual, alignInsert}},
+	}
+
+	for _, test := range tests {
+		got := editDistance([]rune(test.reference), []rune(test.hypothesis))
+		if slices.Equal(got, test.want) == false {
+			t.Errorf("editDistance(%q, %q) = %v, want %v", test.reference, test.hypothesis, got, test.want)
+		}
+	}
+}
+
+
+// TestEvaluate compares transcripts with their references.
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		hypothesis string
+		reference string
+		wer float64
+		cer float64
+		diff string
+	}{
+		{"Hello, World!", "hello world", 0, 0, "hello world"},
+		{"the cat sat", "the cat sat on the mat", 0.5, 8.0 / 17, "the cat sat [-on] [-the] [-mat]"},
+		{"the bat sat", "the cat sat", 1.0 / 3, 1.0 / 9, "the [-cat+bat] sat"},
+		{"so the cat", "the cat", 0.5, 2.0 / 6, "[+so] the cat"},
+		{"it's a well-known fact", "its a well known fact", 0.6, 2.0 / 17, "[-its+it's] a [-well] [-known+well-known] fact"},
+		{"", "", 0, 0, ""},
+		{"words", "", 1, 1, "[+words]"},
+		{"", "two words", 1, 1, "[-two] [-words]"},
+	}
+
+	for _, test := range tests {
+		report := evaluate(test.hypothesis, test.reference)
+		if math.Abs(report.WER - test.wer) > 1e-9 || math.Abs(report.CER - test.cer) > 1e-9 {
+			t.Errorf("evaluate(%q, %q): WER %.3f, CER %.3f, want %.3f, %.3f", test.hypothesis, test.reference, report.WER, report.CER, test.wer, test.cer)
+		}
+		if report.Diff != test.diff {
+			t.Errorf("evaluate(%q, %q): diff %q, want %q", test.hypothesis, test.reference, report.Diff, test.diff)
+		}
+		if errors := report.Substitutions + report.Deletions + report.Insertions; report.ReferenceWords > 0 && math.Abs(float64(errors) / float64(report.ReferenceWords) - report.WER) > 1e-9 {
+			t.Errorf("evaluate(%q, %q): %d errors don't match the WER %.3f", test.hypothesis, test.reference, errors, report.WER)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Detects audio whose format doesn't match the session (the samples of "SendAudio()" are 16 bit little-endian PCM,
+	mono, at the sample rate of "InitializeStream()"): google recognizes such audio anyway and returns nonsense or nothing,
+	so the mismatch is found heuristically in the first seconds of the session and reported once as a warning
+	with its likely cause and the event GO_SPEECH_RECOGNITION_EVENT_FORMAT_MISMATCH (see "PollEvent()"):
+	- interleaved stereo: every other sample repeats its predecessor or is silent
+	- not 16 bit little-endian PCM (e.g. big endian, float or 8 bit samples): the audio is loud, but sounds like noise
+	  (neighbouring samples are uncorrelated, unlike in any real recording)
+	- a different sample rate (or channel count): more samples arrive than time passes, at the rate of a common device
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"math"
+	"strconv"
+	"sync"
+)
+
+// The interleaving and the encoding are judged after this much loud audio (in seconds)
+const formatCheckSeconds = 1
+// Only samples above this level count (silence would hide every mismatch)
+const formatLoudLevel = 256.0
+// Interleaved stereo: the ratio of repeated samples, resp. the energy ratio between the channels when one is silent
+const repeatedSamplesRatio = 0.95
+const silentChannelRatio = 1000.0
+// Noise: loud audio (RMS above -20 dBFS) whose neighbouring samples correlate less than this
+const noiseLevel = 3277.0
+const noiseCorrelation = 0.1
+// The sample rate is judged after this much time (in microseconds), a rate deviating more than sampleRateDeviation
+// from the session's and within sampleRateTolerance of a common device's is a mismatch
+const sampleRateCheckUs = 5 * 1000000
+const sampleRateDeviation = 0.05
+const sampleRateTolerance = 0.02
+var commonSampleRates = []float64{8000, 11025, 16000, 22050, 24000, 32000, 44100, 48000, 88200, 96000}
+
+// The audio of the session accumulated for the checks (guarded by the formatMutex): the parity of the next sample,
+// the last sample, the loud samples, the repeated ones and the energy (of the even and the odd samples each),
+// the sum of the products of neighbours, the samples and the timestamp of the first audio, and whether the checks are done
+var formatMutex = &sync.Mutex{}
+var formatParity int
+var formatLastSample float64
+var formatLoudSamples [2]int64
+var formatRepeatedSamples [2]int64
+var formatEnergy [2]float64
+var formatNeighbourProduct float64
+var formatSamples int64
+var formatStartUs int64 = -1
+var formatContentChecked = false
+var formatRateChecked = false
+var formatMismatchReported = false
+
+
+// resetFormatCheck starts the checks of a new session.
+func resetFormatCheck() {
+	formatMutex.Lock()
+		formatParity = 0
+		formatLastSample = 0
+		formatLoudSamples = [2]int64{}
+		formatRepeatedSamples = [2]int64{}
+		formatEnergy = [2]float64{}
+		formatNeighbourProduct = 0
+		formatSamples = 0
+		formatStartUs = -1
+		formatContentChecked = false
+		formatRateChecked = false
+		formatMismatchReported = false
+	formatMutex.Unlock()
+}
+
+
+// contentMismatch judges the accumulated samples, "" if they look like mono 16 bit PCM (the formatMutex has to be held).
+func contentMismatch() (string) {
+	// The second sample of every frame repeats the first one (whichever parity the frames start with).
+	for parity := 0; parity < 2; parity++ {
+		if formatLoudSamples[parity] > 0 && float64(formatRepeatedSamples[parity]) / float64(formatLoudSamples[parity]) > repeatedSamplesRatio {
+			return "every other sample repeats its predecessor, it looks like interleaved stereo with two identical channels"
+		}
+	}
+	if formatEnergy[0] > silentChannelRatio * formatEnergy[1] || formatEnergy[1] > silentChannelRatio * formatEnergy[0] {
+		return "every other sample is silent, it looks like interleaved stereo with a silent channel"
+	}
+	energy := formatEnergy[0] + formatEnergy[1]
+	if math.Sqrt(energy / float64(formatLoudSamples[0] + formatLoudSamples[1])) > noiseLevel && formatNeighbourProduct / energy < noiseCorrelation {
+		return "it sounds like loud noise, it looks like it isn't 16 bit little-endian PCM (e.g. big endian, float or 8 bit samples)"
+	}
+	return ""
+}
+
+
+// rateMismatch judges the rate at which the samples arrived, "" if it matches the session's (the formatMutex has to be held).
+func rateMismatch(elapsedUs int64, sampleRate int64) (string) {
+	measured := float64(formatSamples) * 1000000 / float64(elapsedUs)
+	// Less audio than time passed may just be a pause of the host.
+	if measured < float64(sampleRate) * (1 + sampleRateDeviation) {
+		return ""
+	}
+	for _, rate := range commonSampleRates {
+		if math.Abs(measured - rate) <= rate * sampleRateTolerance {
+			return "about " + strconv.Itoa(int(rate)) + " samples arrive per second, but the session expects " + strconv.FormatInt(sampleRate, 10) +
+				" (check the sample rate and the channel count of the capture)"
+		}
+	}
+	// Other rates are audio sent faster than in real time (e.g. from a file).
+	return ""
+}
+
+
+// detectFormatMismatch accumulates the samples of the session and reports a mismatch of their format once,
+// timestampUs is the time of the first sample (see "sendAudio()").
+func detectFormatMismatch(samples []C.short, timestampUs int64) {
+	if len(samples) == 0 {
+		return
+	}
+
+	sendMutex.Lock()
+		if initialized == false {
+			sendMutex.Unlock()
+			return
+		}
+		sampleRate := int64(sessionConfig.Config.SampleRateHertz)
+	sendMutex.Unlock()
+
+	formatMutex.Lock()
+		if formatMismatchReported || formatContentChecked && formatRateChecked {
+			formatMutex.Unlock()
+			return
+		}
+
+		for _, sample := range samples {
+			value := float64(sample)
+			if formatContentChecked == false && (math.Abs(value) >= formatLoudLevel || math.Abs(formatLastSample) >= formatLoudLevel) {
+				formatLoudSamples[formatParity]++
+				if value == formatLastSample {
+					formatRepeatedSamples[formatParity]++
+				}
+				formatEnergy[formatParity] += value * value
+				formatNeighbourProduct += value * formatLastSample
+			}
+			formatLastSample = value
+			formatParity ^= 1
+		}
+
+		mismatch := ""
+		if formatContentChecked == false && formatLoudSamples[0] + formatLoudSamples[1] >= formatCheckSeconds * sampleRate {
+			formatContentChecked = true
+			mismatch = contentMismatch()
+		}
+
+		if formatStartUs < 0 && timestampUs >= 0 {
+			formatStartUs = timestampUs
+		} else if elapsedUs := timestampUs - formatStartUs; formatStartUs >= 0 && formatRateChecked == false && elapsedUs >= sampleRateCheckUs {
+			formatRateChecked = true
+			if mismatch == "" {
+				mismatch = rateMismatch(elapsedUs, sampleRate)
+			}
+		}
+		formatSamples += int64(len(samples))
+
+		formatMismatchReported = mismatch != ""
+	formatMutex.Unlock()
+
+	if mismatch != "" {
+		logWarning("The audio doesn't match the format of the session: " + mismatch)
+		reportEvent(eventFormatMismatch)
+	}
+}
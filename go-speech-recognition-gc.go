@@ -0,0 +1,98 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Tuning of the garbage collector of the Go runtime embedded in the host (see "SetGCPercent()" and "SetMemoryLimit()"),
+	so latency-sensitive hosts (e.g. games and audio applications) can trade memory for fewer collections
+	in the recognition path. The settings are the same as the GOGC and GOMEMLIMIT environment variables.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+)
+
+// The settings of the collector as reported by the runtime
+const gcPercentMetric = "/gc/gogc:percent"
+const memoryLimitMetric = "/gc/gomemlimit:bytes"
+
+
+// gcSettings returns the current GC percentage and the soft memory limit in bytes (math.MaxInt64 if there's none).
+func gcSettings() (int64, int64) {
+	samples := []metrics.Sample{{Name: gcPercentMetric}, {Name: memoryLimitMetric}}
+	metrics.Read(samples)
+
+	percent, limit := int64(-1), int64(math.MaxInt64)
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		percent = int64(samples[0].Value.Uint64())
+	}
+	if samples[1].Value.Kind() == metrics.KindUint64 {
+		limit = int64(samples[1].Value.Uint64())
+	}
+	return percent, limit
+}
+
+
+/*
+	SetGCPercent(cPercent C.int) (C.int):
+	sets the garbage collection target percentage of the Go runtime embedded in the host (like GOGC): a collection
+	is triggered when the heap has grown by this percentage since the last one, higher values mean fewer collections
+	(and pauses) at the cost of memory
+
+	Parameter:
+		cPercent C.int
+			(the percentage (default 100 or the GOGC environment variable), -1 disables the collection
+			(then only the memory limit triggers it, see "SetMemoryLimit()"))
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetGCPercent
+func SetGCPercent(cPercent C.int) (C.int) {
+	if cPercent < -1 {
+		logError("Invalid GC percentage (must be -1 or greater)", nil)
+		return result(resultInvalidArgument)
+	}
+	debug.SetGCPercent(int(cPercent))
+	return result(resultOK)
+}
+
+
+/*
+	SetMemoryLimit(cLimitMB C.int) (C.int):
+	sets the soft memory limit of the Go runtime embedded in the host (like GOMEMLIMIT): the collector runs more often
+	when the memory approaches the limit, so with a high (or disabled) GC percentage the memory stays bounded
+
+	Parameter:
+		cLimitMB C.int
+			(the limit in megabytes, 0 removes the limit (default, unless set by the GOMEMLIMIT environment variable))
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetMemoryLimit
+func SetMemoryLimit(cLimitMB C.int) (C.int) {
+	if cLimitMB < 0 {
+		logError("Invalid memory limit (must not be negative)", nil)
+		return result(resultInvalidArgument)
+	}
+	if cLimitMB == 0 {
+		debug.SetMemoryLimit(math.MaxInt64)
+	} else {
+		debug.SetMemoryLimit(int64(cLimitMB) * 1024 * 1024)
+	}
+	return result(resultOK)
+}
@@ -0,0 +1,49 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The idle instrumentation: the library's goroutines block on channels and timers while nothing happens
+	(the send pump waits for audio, the receive pump for google, the real-time drain parks when the ring stays empty),
+	so an idle session costs no CPU. Every wakeup of a goroutine is counted, so hosts running all the time
+	(e.g. desktop assistants) can verify it: while the session is idle "wakeups" of "GetStats()" stands still
+	and "idleMs" grows (unless the keep-alive, the snapshots or the watchdog are enabled, which wake up periodically).
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// The wakeups of the library's goroutines in the current session and the time of the last one (in unix nanoseconds)
+var wakeups uint64
+var lastWakeup int64
+
+
+// noteWakeup counts a wakeup of one of the library's goroutines (to do work, not to stop).
+func noteWakeup() {
+	atomic.AddUint64(&wakeups, 1)
+	atomic.StoreInt64(&lastWakeup, time.Now().UnixNano())
+}
+
+
+// resetWakeups starts the counting of a new session.
+func resetWakeups() {
+	atomic.StoreUint64(&wakeups, 0)
+	atomic.StoreInt64(&lastWakeup, time.Now().UnixNano())
+}
+
+
+// wakeupStats returns the wakeups of the session and the milliseconds since the last one (resp. since the session started).
+func wakeupStats() (uint64, int64) {
+	last := atomic.LoadInt64(&lastWakeup)
+	if last == 0 {
+		return 0, 0
+	}
+	return atomic.LoadUint64(&wakeups), time.Since(time.Unix(0, last)).Milliseconds()
+}
@@ -0,0 +1,159 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Keeps the identifiers of every request to google (i.e. every stream of the session, see "GetSessionInfo()"):
+	the library's request ID, the request ID google returns in its responses and the gRPC metadata of the response
+	(headers and trailers), so a support ticket can reference the exact requests.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+)
+
+// The information of the streams kept per session (the oldest are dropped, e.g. in single utterance mode)
+const maxStreamInfos = 100
+
+// A request to google (a stream of the session)
+type streamInfo struct {
+	RequestID string `json:"requestId"`
+	Opened string `json:"opened"`
+	Model string `json:"model"`
+	GoogleRequestID int64 `json:"googleRequestId,string,omitempty"`
+	Headers metadata.MD `json:"headers,omitempty"`
+	Trailers metadata.MD `json:"trailers,omitempty"`
+	stream speechpb.Speech_StreamingRecognizeClient
+}
+
+// The result of "GetSessionInfo()"
+type sessionInfo struct {
+	SessionID string `json:"sessionId"`
+	Active bool `json:"active"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Streams []*streamInfo `json:"streams"`
+}
+
+// The streams of the current (or last) session in the order they were opened, guarded by the infoMutex
+var infoMutex = &sync.Mutex{}
+var streamInfos []*streamInfo
+var streamInfoOf = map[speechpb.Speech_StreamingRecognizeClient]*streamInfo{}
+
+
+// resetStreamInfos forgets the streams of the previous session.
+func resetStreamInfos() {
+	infoMutex.Lock()
+		streamInfos = nil
+		streamInfoOf = map[speechpb.Speech_StreamingRecognizeClient]*streamInfo{}
+	infoMutex.Unlock()
+}
+
+
+// recordStream adds a new stream of the session with the library's ID of its request and its model.
+func recordStream(newStream speechpb.Speech_StreamingRecognizeClient, requestID string, model string) {
+	info := &streamInfo{RequestID: requestID, Opened: time.Now().Format(time.RFC3339Nano), Model: model, stream: newStream}
+
+	infoMutex.Lock()
+		streamInfos = append(streamInfos, info)
+		streamInfoOf[newStream] = info
+		if len(streamInfos) > maxStreamInfos {
+			delete(streamInfoOf, streamInfos[0].stream)
+			streamInfos = streamInfos[1:]
+		}
+	infoMutex.Unlock()
+}
+
+
+// captureStreamMetadata keeps the identifiers of a received response: the headers arrive with the first response,
+// the trailers once the stream ended (err isn't nil), neither call blocks after "Recv()" returned.
+// Only the receive pump may call it.
+func captureStreamMetadata(currentStream speechpb.Speech_StreamingRecognizeClient, resp *speechpb.StreamingRecognizeResponse, err error) {
+	infoMutex.Lock()
+		info := streamInfoOf[currentStream]
+		needsHeaders := info != nil && info.Headers == nil
+	infoMutex.Unlock()
+	if info == nil {
+		return
+	}
+
+	var headers, trailers metadata.MD
+	if needsHeaders {
+		headers, _ = currentStream.Header()
+	}
+	if err != nil {
+		trailers = currentStream.Trailer()
+	}
+
+	infoMutex.Lock()
+		if headers != nil {
+			info.Headers = headers
+		}
+		if trailers != nil {
+			info.Trailers = trailers
+		}
+		if resp != nil && resp.RequestId != 0 {
+			info.GoogleRequestID = resp.RequestId
+		}
+	infoMutex.Unlock()
+}
+
+
+// encodeSessionInfo returns the information of the current (or last) session as JSON (see "GetSessionInfo()").
+func encodeSessionInfo() ([]byte, error) {
+	info := sessionInfo{}
+	logMutex.Lock()
+		info.SessionID = sessionID
+		info.Active = sessionActive
+		info.Labels = copyLabels()
+	logMutex.Unlock()
+
+	// The metadata of the streams is encoded while it's guarded.
+	infoMutex.Lock()
+	defer infoMutex.Unlock()
+	info.Streams = append([]*streamInfo{}, streamInfos...)
+	return json.Marshal(info)
+}
+
+
+/*
+	GetSessionInfo (output **C.char) (C.int):
+	retrieves the identifiers of the current (or last) session and its requests to google as a JSON object, e.g.:
+	{"sessionId":"3f2a...","active":true,"labels":{"customer":"42"},"streams":[{"requestId":"3f2a...-1",
+	"opened":"2019-06-01T12:00:00.000+02:00","model":"latest_long","googleRequestId":"4711","headers":{"x-goog-...":["..."]},"trailers":{...}}]}
+	(requestId is the library's ID of the request (see "GetSessionID()"), model the model of the stream (e.g. picked for "auto"),
+	googleRequestId the ID google returned in its responses,
+	headers and trailers are the gRPC metadata of google's response, so a support ticket can reference the exact requests,
+	the last 100 streams of the session are kept)
+
+	Parameters:
+		output:
+			The pointer which is used to store the information
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetSessionInfo
+func GetSessionInfo (output **C.char) (C.int) {
+	encoded, err := encodeSessionInfo()
+	if err != nil {
+		logError("Could not encode session info: ", err)
+		return result(resultError)
+	}
+
+	*output = cString(string(encoded))
+	return result(resultOK)
+}
@@ -0,0 +1,134 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The structured log history (see "GetLogEntries()"): every error and warning is kept with a sequence number,
+	its timestamp, its level and the correlation IDs of the session, so a host polling the log reads all entries
+	since its last poll instead of only the last logged event of "GetLog()" (intermittent errors aren't lost).
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// The levels of the log entries
+const (
+	logLevelError = "error"
+	logLevelWarning = "warning"
+)
+
+// The number of entries kept in the history (the oldest ones get overwritten)
+const logHistorySize = 256
+
+// An entry of the structured log
+type logEntry struct {
+	Sequence uint64 `json:"sequence"`
+	Timestamp string `json:"timestamp"`
+	Level string `json:"level"`
+	Message string `json:"message"`
+	SessionID string `json:"sessionId,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// The history of the entries, the entry with the sequence number n is stored at (n - 1) % logHistorySize
+// (guarded by the logMutex), logSequence is the sequence number of the last entry (the first one is 1)
+var logHistory = make([]logEntry, logHistorySize)
+var logSequence uint64
+
+// The answer of "GetLogEntries()"
+type logEntries struct {
+	Entries []logEntry `json:"entries"`
+	LastSequence uint64 `json:"lastSequence"`
+	Dropped uint64 `json:"dropped"`
+}
+
+
+// recordLogEntry adds the message to the history (and the log file and the system log, see "EnableFileLogging()"
+// and "EnableSystemLog()") with the correlation IDs of the active session.
+// The caller has to hold the logMutex.
+func recordLogEntry(level string, message string, timestamp time.Time) {
+	logSequence++
+	entry := logEntry{
+		Sequence:	logSequence,
+		Timestamp:	timestamp.Format(time.RFC3339Nano),
+		Level:		level,
+		Message:	message,
+		Labels:		copyLabels(),
+	}
+	if sessionActive {
+		entry.SessionID, entry.RequestID = sessionID, currentRequestID()
+	}
+	logHistory[(logSequence - 1) % logHistorySize] = entry
+	writeLogFile(entry)
+	writeSystemLog(entry)
+}
+
+
+// logEntriesSince returns the entries of the history after the sequence number.
+func logEntriesSince(since uint64) (logEntries) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	answer := logEntries{Entries: []logEntry{}, LastSequence: logSequence}
+	oldest := uint64(1)
+	if logSequence > logHistorySize {
+		oldest = logSequence - logHistorySize + 1
+	}
+	if since + 1 < oldest {
+		answer.Dropped = oldest - since - 1
+		since = oldest - 1
+	}
+	for sequence := since + 1; sequence <= logSequence; sequence++ {
+		answer.Entries = append(answer.Entries, logHistory[(sequence - 1) % logHistorySize])
+	}
+	return answer
+}
+
+
+/*
+	GetLogEntries(cSinceSequence C.longlong, output **C.char) (C.int):
+	retrieves the logged errors and warnings after the given sequence number (oldest first) as a JSON object, e.g.:
+	{"entries":[{"sequence":41,"timestamp":"2019-06-01T12:00:00.000+02:00","level":"error","message":"...",
+	"sessionId":"1f2e3d4c5b6a7988","requestId":"1f2e3d4c5b6a7988-2","labels":{"customer":"42"}}],"lastSequence":41,"dropped":0}
+	(poll with the lastSequence of the previous call to get the new entries only, the last 256 entries are kept,
+	dropped counts the entries after the given sequence number which have been overwritten since)
+
+	Parameters:
+		cSinceSequence C.longlong
+			(the sequence number of the last entry already read, 0 for the whole history)
+		output:
+			The pointer which is used to store the entries
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetLogEntries
+func GetLogEntries(cSinceSequence C.longlong, output **C.char) (C.int) {
+	if output == nil {
+		logError("Invalid output pointer", nil)
+		return result(resultInvalidArgument)
+	}
+	if cSinceSequence < 0 {
+		logError("Invalid sequence number", nil)
+		return result(resultInvalidArgument)
+	}
+
+	encoded, err := json.Marshal(logEntriesSince(uint64(cSinceSequence)))
+	if err != nil {
+		logError("Could not encode log entries: ", err)
+		return result(resultError)
+	}
+	*output = cString(string(encoded))
+	return result(resultOK)
+}
@@ -0,0 +1,172 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Writes the structured log (see "GetLogEntries()") to rotating files (see "EnableFileLogging()"), one JSON object
+	per line, since hosts loading the library (e.g. a DLL in a game engine) often can't capture its output.
+	The file is written while the entry is logged, so the order of the entries is kept.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"time"
+)
+
+// The limits of the rotation
+const maxLogFileMegabytes = 1024
+const maxLogFiles = 100
+
+// The log file (guarded by the logMutex, the entries are written while it's held), no file if logFile is nil:
+// the current file is logFilePath, the rotated ones are "<path>.1" (the newest) to "<path>.<logFileCount - 1>"
+var logFile *os.File
+var logFilePath string
+var logFileSize int64
+var logFileMaxBytes int64
+var logFileCount int
+
+
+// rotatedLogFile returns the path of the rotated file with the number.
+func rotatedLogFile(path string, number int) (string) {
+	return path + "." + strconv.Itoa(number)
+}
+
+
+// rotateLogFile shifts the rotated files (dropping the oldest one) and starts a new current file.
+// The caller has to hold the logMutex.
+func rotateLogFile() (error) {
+	logFile.Close()
+	logFile = nil
+
+	if logFileCount > 1 {
+		os.Remove(rotatedLogFile(logFilePath, logFileCount - 1))
+		for number := logFileCount - 2; number >= 1; number-- {
+			os.Rename(rotatedLogFile(logFilePath, number), rotatedLogFile(logFilePath, number + 1))
+		}
+		if err := os.Rename(logFilePath, rotatedLogFile(logFilePath, 1)); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(logFilePath, os.O_CREATE | os.O_WRONLY | os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	logFile = file
+	logFileSize = 0
+	return nil
+}
+
+
+// writeLogFile appends the entry to the log file (if enabled), a failing file gets disabled.
+// The caller has to hold the logMutex.
+func writeLogFile(entry logEntry) {
+	if logFile == nil {
+		return
+	}
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	line := append(encoded, '\n')
+
+	if logFileSize > 0 && logFileSize + int64(len(line)) > logFileMaxBytes {
+		err = rotateLogFile()
+	}
+	if err == nil {
+		var written int
+		written, err = logFile.Write(line)
+		logFileSize += int64(written)
+	}
+	if err != nil {
+		// The entry about the failure isn't written to the file anymore.
+		closeLogFile()
+		addLogEntry(logLevelError, "Could not write log file, file logging is disabled: " + err.Error(), time.Now())
+	}
+}
+
+
+// closeLogFile closes the log file and disables the file logging.
+// The caller has to hold the logMutex.
+func closeLogFile() {
+	if logFile != nil {
+		logFile.Close()
+	}
+	logFile = nil
+	logFilePath = ""
+}
+
+
+/*
+	EnableFileLogging(cPath *C.char, cMaxMB C.int, cMaxFiles C.int) (C.int):
+	writes the logged errors and warnings to a file, one JSON object per line like the entries of "GetLogEntries()"
+	(e.g. {"sequence":41,"timestamp":"...","level":"error","message":"...","sessionId":"1f2e3d4c5b6a7988",...}),
+	when the file exceeds its maximum size it's renamed to "<path>.1" (the older files to "<path>.2" and so on)
+	and a new file is started, the oldest file is deleted beyond the maximum number of files,
+	an existing file is continued
+
+	Parameters:
+		cPath *C.char
+			(the path of the log file as a C string (its directory has to exist), an empty string disables the file logging (default))
+		cMaxMB C.int
+			(the maximum size of a file in megabytes (1 - 1024))
+		cMaxFiles C.int
+			(the maximum number of files including the current one (1 - 100), with 1 the file starts anew when it's full)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export EnableFileLogging
+func EnableFileLogging(cPath *C.char, cMaxMB C.int, cMaxFiles C.int) (C.int) {
+	path := C.GoString(cPath)
+	if path == "" {
+		logMutex.Lock()
+			closeLogFile()
+		logMutex.Unlock()
+		return result(resultOK)
+	}
+
+	if cMaxMB < 1 || cMaxMB > maxLogFileMegabytes {
+		logError("Invalid maximum size of the log file (must be 1 - " + strconv.Itoa(maxLogFileMegabytes) + " MB)", nil)
+		return result(resultInvalidArgument)
+	}
+	if cMaxFiles < 1 || cMaxFiles > maxLogFiles {
+		logError("Invalid maximum number of log files (must be 1 - " + strconv.Itoa(maxLogFiles) + ")", nil)
+		return result(resultInvalidArgument)
+	}
+	if forbidsDiskWrites("The file logging") {
+		return result(resultInvalidArgument)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND | os.O_CREATE | os.O_WRONLY, 0644)
+	if err != nil {
+		logError("Could not open log file: ", err)
+		return result(resultError)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		logError("Could not open log file: ", err)
+		return result(resultError)
+	}
+
+	logMutex.Lock()
+		closeLogFile()
+		logFile = file
+		logFilePath = path
+		logFileSize = info.Size()
+		logFileMaxBytes = int64(cMaxMB) * 1024 * 1024
+		logFileCount = int(cMaxFiles)
+	logMutex.Unlock()
+	return result(resultOK)
+}
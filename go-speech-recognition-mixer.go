@@ -0,0 +1,260 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Mixes several PCM sources (e.g. the microphone and the loopback of a desktop call, see "AddMixerSource()") with their
+	own gain into the audio of the streaming session, so both sides of a call end up in one transcript.
+	The sources deliver their audio independently, the audio is mixed as soon as every source delivered it
+	(a source lagging behind is filled up with silence).
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"unsafe"
+)
+
+// The range of the gain of a source in dB
+const minMixerGainDB = minLevelDB
+const maxMixerGainDB = 20.0
+
+// A source waits at most this long (in milliseconds of audio) for the other sources, then they're filled up with silence
+const maxMixerLagMs = maxChunkMs
+
+// A source of the mixer: its linear gain and its audio not yet mixed
+type mixerSource struct {
+	gain float64
+	pending []int16
+}
+
+// The sources of the mixer (guarded by the mixerMutex, which also keeps the mixed audio in order),
+// the pending audio belongs to the session of mixerGeneration
+var mixerMutex = &sync.Mutex{}
+var mixerSources = map[int32]*mixerSource{}
+var nextMixerSourceID int32 = 1
+var mixerGeneration uint64
+
+
+// mixerGain converts a gain in dB to the linear gain of a source.
+func mixerGain(gainDB float64) (float64, C.int) {
+	if math.IsNaN(gainDB) || gainDB < minMixerGainDB || gainDB > maxMixerGainDB {
+		logError("Invalid gain (must be " + strconv.FormatFloat(minMixerGainDB, 'f', -1, 64) + " - " + strconv.FormatFloat(maxMixerGainDB, 'f', -1, 64) + " dB)", nil)
+		return 0, resultInvalidArgument
+	}
+	return math.Pow(10, gainDB / 20), resultOK
+}
+
+
+// mixPending mixes the audio every source delivered (the mixerMutex has to be held), a source lagging behind more than
+// lagSamples is filled up with silence.
+func mixPending(lagSamples int) ([]C.short) {
+	available, longest := -1, 0
+	for _, source := range mixerSources {
+		if available < 0 || len(source.pending) < available {
+			available = len(source.pending)
+		}
+		if len(source.pending) > longest {
+			longest = len(source.pending)
+		}
+	}
+	if longest - available > lagSamples {
+		available = longest - lagSamples
+	}
+	if available <= 0 {
+		return nil
+	}
+
+	mixed := make([]C.short, available)
+	for _, source := range mixerSources {
+		count := available
+		if count > len(source.pending) {
+			count = len(source.pending)
+		}
+		for i := 0; i < count; i++ {
+			sum := float64(mixed[i]) + float64(source.pending[i]) * source.gain
+			mixed[i] = C.short(math.Max(math.MinInt16, math.Min(math.MaxInt16, sum)))
+		}
+		source.pending = source.pending[count:]
+	}
+	return mixed
+}
+
+
+/*
+	AddMixerSource(cGainDB C.double, sourceID *C.int) (C.int):
+	registers a source of the mixer (e.g. the microphone or the loopback of a call), the audio of all sources
+	(see "SendMixerAudio()") is mixed into the audio of the streaming session
+
+	Parameters:
+		cGainDB C.double
+			(the gain of the source in dB (-96.0 - 20.0), 0.0 keeps its level)
+		sourceID:
+			The pointer which is used to store the ID of the source
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export AddMixerSource
+func AddMixerSource(cGainDB C.double, sourceID *C.int) (C.int) {
+	if sourceID == nil {
+		logError("Invalid output pointer", nil)
+		return result(resultInvalidArgument)
+	}
+	gain, code := mixerGain(float64(cGainDB))
+	if code != resultOK {
+		return result(code)
+	}
+
+	mixerMutex.Lock()
+		id := nextMixerSourceID
+		nextMixerSourceID++
+		mixerSources[id] = &mixerSource{gain: gain}
+	mixerMutex.Unlock()
+
+	*sourceID = C.int(id)
+	return result(resultOK)
+}
+
+
+/*
+	SetMixerSourceGain(cSourceID C.int, cGainDB C.double) (C.int):
+	changes the gain of a source of the mixer (takes effect with its next audio)
+
+	Parameters:
+		cSourceID C.int
+			(the ID of the source (see "AddMixerSource()"))
+		cGainDB C.double
+			(the gain of the source in dB (-96.0 - 20.0))
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetMixerSourceGain
+func SetMixerSourceGain(cSourceID C.int, cGainDB C.double) (C.int) {
+	gain, code := mixerGain(float64(cGainDB))
+	if code != resultOK {
+		return result(code)
+	}
+
+	mixerMutex.Lock()
+	defer mixerMutex.Unlock()
+
+	source, found := mixerSources[int32(cSourceID)]
+	if found == false {
+		logError("Unknown mixer source " + strconv.Itoa(int(cSourceID)), nil)
+		return result(resultInvalidArgument)
+	}
+	source.gain = gain
+	return result(resultOK)
+}
+
+
+/*
+	RemoveMixerSource(cSourceID C.int) (C.int):
+	removes a source of the mixer, its audio not yet mixed is dropped
+
+	Parameter:
+		cSourceID C.int
+			(the ID of the source (see "AddMixerSource()"))
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export RemoveMixerSource
+func RemoveMixerSource(cSourceID C.int) (C.int) {
+	mixerMutex.Lock()
+	defer mixerMutex.Unlock()
+
+	if _, found := mixerSources[int32(cSourceID)]; found == false {
+		logError("Unknown mixer source " + strconv.Itoa(int(cSourceID)), nil)
+		return result(resultInvalidArgument)
+	}
+	delete(mixerSources, int32(cSourceID))
+	return result(resultOK)
+}
+
+
+/*
+	SendMixerAudio(cSourceID C.int, recording *C.short, recordingLength C.int) (C.int):
+	sends audio of a source of the mixer (at the session's sample rate), the sources' audio is mixed with their gains
+	and sent like "SendAudio()" as soon as every source delivered it, a source lagging behind more than 200 ms
+	is filled up with silence (e.g. a loopback that only delivers audio while something plays)
+
+	Parameters:
+		cSourceID C.int
+			(the ID of the source (see "AddMixerSource()"))
+		recording, recordingLength:
+			the same as "SendAudio()"
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SendMixerAudio
+func SendMixerAudio(cSourceID C.int, recording *C.short, recordingLength C.int) (C.int) {
+	if code := checkCallbackThread("SendMixerAudio"); code != resultOK {
+		return result(code)
+	}
+	if recordingLength < 0 || recording == nil && recordingLength > 0 {
+		logError("Invalid audio data", nil)
+		return result(resultInvalidArgument)
+	}
+
+	sendMutex.Lock()
+		if initialized == false {
+			sendMutex.Unlock()
+			logError("Stream is not initialized", nil)
+			return result(resultNotInitialized)
+		}
+		generation := sessionGeneration
+		sampleRate := int(sessionConfig.Config.SampleRateHertz)
+	sendMutex.Unlock()
+
+	mixerMutex.Lock()
+	defer mixerMutex.Unlock()
+
+	source, found := mixerSources[int32(cSourceID)]
+	if found == false {
+		logError("Unknown mixer source " + strconv.Itoa(int(cSourceID)), nil)
+		return result(resultInvalidArgument)
+	}
+
+	// The audio of a previous session isn't mixed into the new one.
+	if generation != mixerGeneration {
+		for _, other := range mixerSources {
+			other.pending = nil
+		}
+		mixerGeneration = generation
+	}
+
+	samples := unsafe.Slice((*int16)(unsafe.Pointer(recording)), int(recordingLength))
+	source.pending = append(source.pending, samples...)
+
+	mixed := mixPending(maxMixerLagMs * sampleRate / 1000)
+	if len(mixed) == 0 {
+		return result(resultOK)
+	}
+
+	span := startSpan("SendAudio")
+	code := sendAudio(&mixed[0], C.int(len(mixed)), libraryTimestamp(int64(len(mixed))))
+	endSpan(span, code)
+	return result(code)
+}
@@ -0,0 +1,159 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The documented availability of the models per language (see "IsModelAvailable()"), so a model google doesn't offer
+	for the language fails before the session is initialized instead of in the middle of the stream.
+	Only the documented constraints are checked: a model without constraints (or one the library doesn't know yet)
+	is passed to google as it is.
+	The model "auto" lets the library pick the model from the declared audio (see "SetModelHints()").
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"strings"
+)
+
+// The languages of the models only offered for some languages (see https://cloud.google.com/speech-to-text/docs/speech-to-text-supported-languages)
+var enhancedLanguages = []string{
+	"de-DE", "en-AU", "en-GB", "en-IN", "en-US", "es-ES", "es-US", "fr-CA", "fr-FR",
+	"it-IT", "ja-JP", "ko-KR", "pt-BR", "ru-RU",
+}
+var modelLanguages = map[string][]string{
+	"video": enhancedLanguages,
+	"phone_call": enhancedLanguages,
+	"medical_dictation": {"en-US"},
+	"medical_conversation": {"en-US"},
+}
+
+
+// The declared audio of the model "auto" (see "SetModelHints()"), guarded by the sendMutex
+const (
+	audioSourceUnknown int32 = 0
+	audioSourceMicrophone int32 = 1
+	audioSourceTelephone int32 = 2
+	audioSourceMedia int32 = 3
+)
+var declaredAudioSource = audioSourceUnknown
+var expectedUtteranceSeconds int32
+
+// Utterances up to this length are short (commands, answers to a prompt), google's short model fits them better
+const shortUtteranceSeconds = 10
+
+
+// autoModel picks the model for the declared audio (the caller has to hold the sendMutex):
+// telephone audio (or audio sampled at 8 kHz) gets "phone_call", media "video", short utterances (or the single utterance mode)
+// "latest_short" and everything else "latest_long", a model google doesn't offer for the language is replaced by a latest one.
+func autoModel(language string, sampleRate int32) (string) {
+	short := singleUtterance || expectedUtteranceSeconds > 0 && expectedUtteranceSeconds <= shortUtteranceSeconds
+	fallback := "latest_long"
+	if short {
+		fallback = "latest_short"
+	}
+
+	model := fallback
+	switch {
+	case declaredAudioSource == audioSourceTelephone || sampleRate > 0 && sampleRate <= 8000:
+		model = "phone_call"
+	case declaredAudioSource == audioSourceMedia:
+		model = "video"
+	}
+	if modelUnavailable(language, model) != "" {
+		return fallback
+	}
+	return model
+}
+
+
+/*
+	SetModelHints(cAudioSource C.int, cExpectedUtteranceSeconds C.int) (C.int):
+	declares the audio of the next sessions, so the library can pick the model if the model "auto" is passed
+	to "InitializeStream()" or "Reconfigure()": telephone audio (or audio sampled at 8 kHz) is transcribed with "phone_call",
+	media with "video", short utterances (or in single utterance mode) with "latest_short", everything else with "latest_long"
+	(a model google doesn't offer for the language is replaced by "latest_short" or "latest_long"),
+	has to be called before "InitializeStream()" or "Reconfigure()" to take effect
+
+	Parameters:
+		cAudioSource C.int
+			(0: unknown (default), 1: microphone, 2: telephone, 3: media (e.g. videos or broadcasts))
+		cExpectedUtteranceSeconds C.int
+			(the expected length of the utterances in seconds (e.g. 3 for voice commands), 0 if unknown (default))
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetModelHints
+func SetModelHints(cAudioSource C.int, cExpectedUtteranceSeconds C.int) (C.int) {
+	source := int32(cAudioSource)
+	if source < audioSourceUnknown || source > audioSourceMedia {
+		logError("Unknown audio source (must be 0 - 3)", nil)
+		return result(resultInvalidArgument)
+	}
+	if cExpectedUtteranceSeconds < 0 {
+		logError("Expected utterance length can't be negative", nil)
+		return result(resultInvalidArgument)
+	}
+
+	sendMutex.Lock()
+		declaredAudioSource = source
+		expectedUtteranceSeconds = int32(cExpectedUtteranceSeconds)
+	sendMutex.Unlock()
+	return result(resultOK)
+}
+
+
+// modelUnavailable returns why google doesn't offer the model for the language, an empty string if it's available
+// (or not documented otherwise).
+func modelUnavailable(language string, model string) (string) {
+	languages, constrained := modelLanguages[strings.ToLower(model)]
+	if constrained == false {
+		return ""
+	}
+	for _, supported := range languages {
+		if strings.EqualFold(supported, language) {
+			return ""
+		}
+	}
+	return "The model \"" + model + "\" is not available for the language \"" + language + "\" (only for " + strings.Join(languages, ", ") + ")"
+}
+
+
+/*
+	IsModelAvailable(cTranscriptLanguage *C.char, cTranscriptionModel *C.char) (C.int):
+	checks whether google offers the model for the language (according to its documentation),
+	"InitializeStream()" and "Reconfigure()" fail the same way for a model that isn't available
+
+	Parameters:
+		cTranscriptLanguage *C.char
+			(transcription language as a C string (use BCP-47 language tag))
+		cTranscriptionModel *C.char
+			(the model like in "InitializeStream()", models without documented constraints (and "auto") are available)
+
+	Return:
+		0 if the model is available (1 with legacy return codes)
+		a negative error code if not (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export IsModelAvailable
+func IsModelAvailable(cTranscriptLanguage *C.char, cTranscriptionModel *C.char) (C.int) {
+	language := C.GoString(cTranscriptLanguage)
+	if language == "" {
+		logError("Invalid language (must not be empty)", nil)
+		return result(resultInvalidArgument)
+	}
+
+	if reason := modelUnavailable(language, C.GoString(cTranscriptionModel)); reason != "" {
+		logError(reason, nil)
+		return result(resultModelUnavailable)
+	}
+	return result(resultOK)
+}
@@ -0,0 +1,90 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Half-duplex operation (see "MuteRecognition()"): while the host plays a prompt (e.g. text-to-speech), the microphone
+	hears it too and the assistant would recognize itself. While muted, the audio is replaced by silence, so the stream
+	stays alive and the timestamps stay aligned, but nothing of the prompt reaches google. After unmuting, the echo window
+	keeps the audio muted a little longer for the prompt's tail (the reverb of the room and the latency of the capture).
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The maximum echo window in milliseconds
+const maxEchoWindowMs = 5000
+
+// Set while the recognition is muted and the end of the echo window after the last unmuting (guarded by the muteMutex)
+var muteMutex = &sync.Mutex{}
+var recognitionMuted = false
+var echoWindowEnd time.Time
+
+
+// muteRecognition mutes or unmutes the recognition, the echo window applies when it's unmuted.
+func muteRecognition(muted bool, echoWindow time.Duration) {
+	muteMutex.Lock()
+		if muted {
+			echoWindowEnd = time.Time{}
+		} else if recognitionMuted {
+			echoWindowEnd = time.Now().Add(echoWindow)
+		}
+		recognitionMuted = muted
+	muteMutex.Unlock()
+
+	// The no speech timeout shouldn't count the prompt.
+	if muted {
+		sendMutex.Lock()
+			silentSamples = 0
+		sendMutex.Unlock()
+	}
+}
+
+
+// isRecognitionMuted reports whether the audio is muted right now (muted or within the echo window).
+func isRecognitionMuted() (bool) {
+	muteMutex.Lock()
+	defer muteMutex.Unlock()
+
+	return recognitionMuted || time.Now().Before(echoWindowEnd)
+}
+
+
+/*
+	MuteRecognition(cMuted C.int, cEchoWindowMs C.int) (C.int):
+	mutes the recognition while the host plays a prompt (e.g. text-to-speech), so the assistant doesn't hear itself:
+	the audio passed to "SendAudio()" (and its variants) is replaced by silence, so the stream stays alive and the timestamps
+	stay aligned, the diagnostics and the no speech timeout skip it (which starts anew after the prompt),
+	encoded audio (see "SendAudioBytes()") is dropped instead (enable the keep-alive to keep the stream alive, see "SetKeepAlive()"),
+	the level metering still measures the microphone, the recognition stays muted (also for the following sessions) until it's unmuted
+
+	Parameters:
+		cMuted C.int
+			(1 to mute, 0 to unmute the recognition)
+		cEchoWindowMs C.int
+			(only when unmuting: the audio stays muted for this many milliseconds (0 - 5000) to drop the tail of the prompt,
+			i.e. the reverb of the room and the audio still buffered by the capture, e.g. 300)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export MuteRecognition
+func MuteRecognition(cMuted C.int, cEchoWindowMs C.int) (C.int) {
+	if cEchoWindowMs < 0 || cEchoWindowMs > maxEchoWindowMs {
+		logError("Invalid echo window (must be 0 - " + strconv.Itoa(maxEchoWindowMs) + " ms)", nil)
+		return result(resultInvalidArgument)
+	}
+	muteRecognition(int32(cMuted) == int32(1), time.Duration(cEchoWindowMs) * time.Millisecond)
+	return result(resultOK)
+}
@@ -0,0 +1,240 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Protobuf passthrough (see "SendRawRequest()" and "ReceiveRawResponse()"): the host sends serialized
+	StreamingRecognizeRequest messages and receives the serialized StreamingRecognizeResponse messages of google,
+	so features of google can be used before the library supports them (the library neither maps the configuration
+	nor the results, retries or reconnects).
+	The passthrough stream runs on the client of the session (its credentials and connection) besides the session's own stream
+	and is closed together with the session.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+#include <stdint.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/golang/protobuf/proto"
+
+	speech "cloud.google.com/go/speech/apiv1"
+	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+)
+
+// The passthrough stream of the session (nil if there's none) and its cancellation, guarded by the rawStreamMutex
+// (which also keeps the requests of concurrent "SendRawRequest()" calls from interleaving)
+var rawStreamMutex = &sync.Mutex{}
+var rawStream speechpb.Speech_StreamingRecognizeClient
+var rawCancel context.CancelFunc
+
+// Set while a "ReceiveRawResponse()" call is in progress
+var rawReceiving int32
+
+
+// closeRawStream closes the passthrough stream (called by "closeStream()" once the calls using it returned).
+func closeRawStream() {
+	rawStreamMutex.Lock()
+		if rawCancel != nil {
+			rawCancel()
+		}
+		rawStream = nil
+		rawCancel = nil
+	rawStreamMutex.Unlock()
+}
+
+
+// beginRawCall returns the context and the client of the session, "closeStream()" waits until inFlight is done.
+func beginRawCall() (context.Context, *speech.Client, C.int) {
+	receiveMutex.Lock()
+	defer receiveMutex.Unlock()
+
+	if initialized == false {
+		logError("Stream is not initialized", nil)
+		return nil, nil, resultNotInitialized
+	}
+	inFlight.Add(1)
+	return ctx, client, resultOK
+}
+
+
+/*
+	SendRawRequest(data *C.uint8_t, dataLength C.int) (C.int):
+	sends a serialized StreamingRecognizeRequest to google as it is:
+	a request with a streaming configuration opens a new passthrough stream (a running one is closed),
+	the following requests (e.g. with the audio) are sent on it, an empty request (dataLength 0) ends its audio
+	(google returns the remaining responses and ends the stream),
+	the session has to be initialized (see "InitializeStream()"), its own stream keeps running
+
+	Parameters:
+		data *C.uint8_t
+			(the serialized google.cloud.speech.v1.StreamingRecognizeRequest)
+		dataLength C.int
+			(the length of the request in bytes)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SendRawRequest
+func SendRawRequest(data *C.uint8_t, dataLength C.int) (C.int) {
+	if code := checkCallbackThread("SendRawRequest"); code != resultOK {
+		return result(code)
+	}
+	if dataLength < 0 || data == nil && dataLength > 0 {
+		logError("Invalid request", nil)
+		return result(resultInvalidArgument)
+	}
+
+	request := &speechpb.StreamingRecognizeRequest{}
+	if dataLength > 0 {
+		if err := proto.Unmarshal(C.GoBytes(unsafe.Pointer(data), dataLength), request); err != nil {
+			logError("Could not decode request: ", err)
+			return result(resultInvalidArgument)
+		}
+	}
+
+	sessionCtx, sessionClient, code := beginRawCall()
+	if code != resultOK {
+		return result(code)
+	}
+	defer inFlight.Done()
+
+	rawStreamMutex.Lock()
+	defer rawStreamMutex.Unlock()
+
+	// A configuration opens a new stream.
+	if request.GetStreamingConfig() != nil {
+		if rawCancel != nil {
+			rawCancel()
+		}
+		rawStream = nil
+		rawCancel = nil
+
+		streamCtx, streamCancel := context.WithCancel(sessionCtx)
+		newStream, err := sessionClient.StreamingRecognize(streamCtx)
+		if err == nil {
+			err = newStream.Send(request)
+		}
+		if err != nil {
+			streamCancel()
+			logError("Could not open passthrough stream: ", err)
+			return result(resultError)
+		}
+		rawStream = newStream
+		rawCancel = streamCancel
+		return result(resultOK)
+	}
+
+	if rawStream == nil {
+		logError("Passthrough stream is not opened (the first request needs a streaming configuration)", nil)
+		return result(resultNotInitialized)
+	}
+
+	var err error
+	if dataLength == 0 {
+		err = rawStream.CloseSend()
+	} else {
+		err = rawStream.Send(request)
+	}
+	// The reason is returned by "ReceiveRawResponse()".
+	if err == io.EOF {
+		logError("Passthrough stream has ended", nil)
+		return result(resultStreamEnded)
+	}
+	if err != nil {
+		logError("Could not send request: ", err)
+		return result(resultError)
+	}
+	return result(resultOK)
+}
+
+
+/*
+	ReceiveRawResponse(output **C.char, outputLength *C.int) (C.int):
+	waits for the next response of the passthrough stream (see "SendRawRequest()") and retrieves it as it is,
+	a failure of the stream is returned as an error (its status can be retrieved with "GetLastErrorJSON()"),
+	a pending call returns no response if the session is closed or a new passthrough stream is opened meanwhile
+
+	Parameters:
+		output:
+			The pointer which is used to store the serialized google.cloud.speech.v1.StreamingRecognizeResponse
+			(binary, not terminated by a null character), free it with "FreeString()"
+		outputLength:
+			The pointer which is used to store the length of the response in bytes (0 if there's none)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()"),
+		the error code of an ended stream once google ended the stream regularly
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export ReceiveRawResponse
+func ReceiveRawResponse(output **C.char, outputLength *C.int) (C.int) {
+	if output == nil || outputLength == nil {
+		logError("Invalid output pointer", nil)
+		return result(resultInvalidArgument)
+	}
+	if code := checkCallbackThread("ReceiveRawResponse"); code != resultOK {
+		return result(code)
+	}
+	// The responses of a stream can't be received concurrently.
+	if !atomic.CompareAndSwapInt32(&rawReceiving, 0, 1) {
+		logError("Another \"ReceiveRawResponse()\" call is in progress", nil)
+		return result(resultBusy)
+	}
+	defer atomic.StoreInt32(&rawReceiving, 0)
+
+	sessionCtx, _, code := beginRawCall()
+	if code != resultOK {
+		return result(code)
+	}
+	defer inFlight.Done()
+
+	rawStreamMutex.Lock()
+		currentStream := rawStream
+	rawStreamMutex.Unlock()
+	if currentStream == nil {
+		logError("Passthrough stream is not opened (the first request needs a streaming configuration)", nil)
+		return result(resultNotInitialized)
+	}
+
+	resp, err := currentStream.Recv()
+	if err == io.EOF {
+		logError("Passthrough stream has ended", nil)
+		return result(resultStreamEnded)
+	}
+	// The session has been closed or a new stream has been opened meanwhile.
+	rawStreamMutex.Lock()
+		replaced := rawStream != currentStream
+	rawStreamMutex.Unlock()
+	if err != nil && (sessionCtx.Err() != nil || replaced) {
+		*output = (*C.char)(C.CBytes([]byte{0}))
+		*outputLength = 0
+		return result(resultOK)
+	}
+	if err != nil {
+		logError("Cannot receive passthrough response: ", err)
+		return result(resultError)
+	}
+
+	encoded, err := proto.Marshal(resp)
+	if err != nil {
+		logError("Could not encode response: ", err)
+		return result(resultError)
+	}
+	*output = (*C.char)(C.CBytes(append(encoded, 0)))
+	*outputLength = C.int(len(encoded))
+	return result(resultOK)
+}
@@ -0,0 +1,132 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The batched retrieval of the results (see "ReceiveAllPending()"): hosts polling at their frame rate get every
+	result queued since the last poll in one call instead of one call (and FFI round-trip) per result, and the call
+	never waits.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"encoding/json"
+	"sync/atomic"
+
+	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+)
+
+// An error found by "ReceiveAllPending()" after it already took results from the queue,
+// it's returned by the next receive call, so the results aren't lost
+var deferredReceiveCode int32
+
+
+// receivePending takes all queued results (and the due redeliveries) without waiting,
+// an error after some results is deferred to the next receive call.
+func receivePending() ([]transcriptResult, C.int) {
+
+	// One receive call at a time, never by a callback (see go-speech-recognition-threads.go).
+	if code := checkCallbackThread("ReceiveAllPending"); code != resultOK {
+		return nil, code
+	}
+	if code := beginReceive(); code != resultOK {
+		return nil, code
+	}
+	defer endReceive()
+
+	receiveMutex.Lock()
+		// Check if the stream is initialized
+		if initialized == false {
+			receiveMutex.Unlock()
+			logError("Stream is not initialized", nil)
+			return nil, resultNotInitialized
+		}
+		queue := resultQueue
+		queueCtx := ctx
+		// "CloseStream()" waits until this call returned.
+		inFlight.Add(1)
+	receiveMutex.Unlock()
+	defer inFlight.Done()
+	defer rearmResultEvent(queue)
+
+	if code := C.int(atomic.SwapInt32(&deferredReceiveCode, 0)); code != resultOK {
+		return nil, code
+	}
+
+	results := []transcriptResult{}
+	for queueCtx.Err() == nil {
+		var resp *speechpb.StreamingRecognizeResponse
+		var clock *streamClock
+		var sequence int64
+		code := resultOK
+
+		// Unacknowledged results are delivered again like by "ReceiveTranscriptJSON()" (see "SetResultAcknowledgment()").
+		if pending := dueRedelivery(); pending != nil {
+			resp, clock, sequence = pending.resp, pending.clock, pending.sequence
+		} else {
+			select {
+			case received, open := <-queue:
+				resp, clock, sequence, code = checkReceived(received, open)
+			default:
+				return results, resultOK
+			}
+		}
+
+		if code != resultOK {
+			if len(results) == 0 {
+				return nil, code
+			}
+			atomic.StoreInt32(&deferredReceiveCode, int32(code))
+			return results, resultOK
+		}
+		// The session has been closed.
+		if resp == nil {
+			break
+		}
+
+		atomic.StoreInt64(&lastDeliveredSequence, sequence)
+		for _, result := range transcriptResults(resp, clock) {
+			result.Sequence = sequence
+			results = append(results, result)
+		}
+	}
+	return results, resultOK
+}
+
+
+/*
+	ReceiveAllPending(output **C.char) (C.int):
+	retrieves all results received since the last call like "ReceiveTranscriptJSON()" as one JSON array (in the order of their
+	arrival, "[]" if there is none), but without waiting, so a host polling at its frame rate needs one call per frame,
+	if an error ends the results, they are retrieved anyway and the error is returned by the next receive call
+
+	Parameters:
+		output:
+			The pointer which is used to store the results
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export ReceiveAllPending
+func ReceiveAllPending(output **C.char) (C.int) {
+	results, code := receivePending()
+	if code != resultOK {
+		return result(code)
+	}
+
+	encoded, err := json.Marshal(results)
+	if err != nil {
+		logError("Could not encode results: ", err)
+		return result(resultError)
+	}
+
+	*output = cString(string(encoded))
+	return result(resultOK)
+}
@@ -0,0 +1,133 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The privacy mode (see "AddSensitiveTerm()"): sensitive terms (e.g. names of patients or account numbers) are masked
+	as "***" before anything is persisted by the library, i.e. in the log (including the log file, the system log and
+	the diagnostics bundle), in the transcript files and in the journal of the result acknowledgment.
+	Transcripts containing a sensitive term aren't cached (the cache would deliver them masked).
+	The results delivered to the host stay untouched (use "AddReplacement()" to mask them as well).
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// The mask replacing the sensitive terms
+const sensitiveMask = "***"
+
+// The sensitive terms as regular expressions and all of them combined (nil without terms), guarded by the privacyMutex
+var privacyMutex = &sync.Mutex{}
+var sensitiveTerms []string
+var sensitivePattern *regexp.Regexp
+
+
+// currentSensitivePattern returns the pattern of the sensitive terms, nil without terms.
+func currentSensitivePattern() (*regexp.Regexp) {
+	privacyMutex.Lock()
+	defer privacyMutex.Unlock()
+
+	return sensitivePattern
+}
+
+
+// redactSensitive masks the sensitive terms in the text.
+func redactSensitive(text string) (string) {
+	pattern := currentSensitivePattern()
+	if pattern == nil {
+		return text
+	}
+	return pattern.ReplaceAllLiteralString(text, sensitiveMask)
+}
+
+
+// containsSensitive reports whether the text contains a sensitive term.
+func containsSensitive(text string) (bool) {
+	pattern := currentSensitivePattern()
+	return pattern != nil && pattern.MatchString(text)
+}
+
+
+/*
+	AddSensitiveTerm(cTerm *C.char, cRegex C.int) (C.int):
+	adds a term which must never be persisted by the library: it's masked as "***" in the log (including the log file,
+	the system log and the diagnostics bundle), in the transcript files and in the journal of the result acknowledgment
+	(redelivered results of a journal are masked), transcripts containing it aren't cached,
+	the results delivered to the host stay untouched, the terms should be added before the session starts
+	(entries logged before aren't masked)
+
+	Parameters:
+		cTerm *C.char
+			(the term as a C string, matched case-insensitively and with any whitespace between its words (e.g. "John Smith"),
+			or a regular expression (e.g. "\\b\\d{4} ?\\d{4} ?\\d{4} ?\\d{4}\\b" for card numbers))
+		cRegex C.int
+			(1 if cTerm is a regular expression, 0 if it's an exact text)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export AddSensitiveTerm
+func AddSensitiveTerm(cTerm *C.char, cRegex C.int) (C.int) {
+	term := C.GoString(cTerm)
+	if strings.TrimSpace(term) == "" {
+		// The term itself isn't logged.
+		logError("Sensitive term must not be empty", nil)
+		return result(resultInvalidArgument)
+	}
+
+	expression := term
+	if int32(cRegex) != int32(1) {
+		words := strings.Fields(term)
+		for i, word := range words {
+			words[i] = regexp.QuoteMeta(word)
+		}
+		expression = "(?i:" + strings.Join(words, `\s+`) + ")"
+	} else if _, err := regexp.Compile(expression); err != nil {
+		logError("Invalid regular expression of a sensitive term", nil)
+		return result(resultInvalidArgument)
+	}
+
+	privacyMutex.Lock()
+		terms := append(append([]string{}, sensitiveTerms...), "(?:" + expression + ")")
+		// The longest terms first, so a term containing a shorter one is masked completely.
+		sort.SliceStable(terms, func(i, j int) bool { return len(terms[i]) > len(terms[j]) })
+		pattern, err := regexp.Compile(strings.Join(terms, "|"))
+		if err == nil {
+			sensitiveTerms = terms
+			sensitivePattern = pattern
+		}
+	privacyMutex.Unlock()
+
+	if err != nil {
+		logError("Could not combine the sensitive terms", nil)
+		return result(resultError)
+	}
+	return result(resultOK)
+}
+
+
+/*
+	ClearSensitiveTerms():
+	removes all sensitive terms (see "AddSensitiveTerm()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export ClearSensitiveTerms
+func ClearSensitiveTerms() () {
+	privacyMutex.Lock()
+		sensitiveTerms = nil
+		sensitivePattern = nil
+	privacyMutex.Unlock()
+}
@@ -0,0 +1,80 @@
+//go:build cgo
+
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Tests of the privacy mode (the masking of the sensitive terms).
+*/
+
+package main
+
+import (
+	"testing"
+	"unsafe"
+)
+
+
+// testCString passes a Go string as a C string (test files can't use cgo).
+func testCString(text string) (*_Ctype_char) {
+	terminated := append([]byte(text), 0)
+	return (*_Ctype_char)(unsafe.Pointer(&terminated[0]))
+}
+
+
+// TestRedactSensitive masks exact terms and regular expressions.
+func TestRedactSensitive(t *testing.T) {
+	SetLegacyReturnCodes(0)
+	defer ClearSensitiveTerms()
+
+	if got := redactSensitive("John Smith called"); got != "John Smith called" {
+		t.Errorf("Without terms: %q", got)
+	}
+
+	terms := []struct {
+		term string
+		regex int
+	}{
+		{"John Smith", 0},
+		{"Smith", 0},
+		{"a.b", 0},
+		{`\b\d{4} ?\d{4}\b`, 1},
+	}
+	for _, term := range terms {
+		if code := AddSensitiveTerm(testCString(term.term), _Ctype_int(term.regex)); code != resultOK {
+			t.Fatalf("AddSensitiveTerm(%q) failed: %d", term.term, code)
+		}
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"john  SMITH called", "*** called"},
+		{"John\nSmith", "***"},
+		{"Mrs. Smith", "Mrs. ***"},
+		{"Smithson", "***son"},
+		{"a.b and axb", "*** and axb"},
+		{"card 1234 5678, code 12345", "card ***, code 12345"},
+		{"nothing sensitive", "nothing sensitive"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := redactSensitive(test.text); got != test.want {
+			t.Errorf("redactSensitive(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+
+	for _, invalid := range []struct {
+		term string
+		regex int
+	}{{"  ", 0}, {"(", 1}} {
+		if code := AddSensitiveTerm(testCString(invalid.term), _Ctype_int(invalid.regex)); code != resultInvalidArgument {
+			t.Errorf("AddSensitiveTerm(%q) = %d, want %d", invalid.term, code, resultInvalidArgument)
+		}
+	}
+
+	ClearSensitiveTerms()
+	if got := redactSensitive("John Smith"); got != "John Smith" {
+		t.Errorf("After clearing: %q", got)
+	}
+}
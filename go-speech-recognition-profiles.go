@@ -0,0 +1,164 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Latency-vs-accuracy profiles (see "ApplyProfile()"): presets bundling the settings of a use case
+	(the model picked for "auto", the interim results, the chunk size, the stability of the interim results and the voice activity handling),
+	so integrators get sensible defaults with one setting and can still adjust single settings afterwards.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"sort"
+	"strings"
+	"sync/atomic"
+	"unsafe"
+)
+
+// The settings of a profile
+type profile struct {
+	audioSource int32			// the declared audio picking the model for "auto" (see "SetModelHints()")
+	utteranceSeconds int32
+	interimResults bool
+	maxChunkMs int32			// the ceiling of the adaptive chunk size
+	minStability float32		// see "SetMinStability()"
+	singleUtterance bool		// see "SetSingleUtterance()"
+	voiceActivityEvents bool	// see "SetVoiceActivityEvents()"
+	noSpeechTimeoutSeconds int32	// see "SetNoSpeechTimeout()"
+	keepAliveMs int32			// see "SetKeepAlive()", 0 disables it
+}
+
+var profiles = map[string]profile{
+	// Voice commands and assistants: short utterances, results as fast as possible, the stream ends after silence.
+	"interactive": {
+		audioSource: audioSourceMicrophone, utteranceSeconds: 5, interimResults: true, maxChunkMs: 50,
+		minStability: 0, singleUtterance: true, voiceActivityEvents: true, noSpeechTimeoutSeconds: 8, keepAliveMs: 0,
+	},
+	// Dictation: long utterances, interim results for the typing effect, pauses to think don't end the stream.
+	"dictation": {
+		audioSource: audioSourceMicrophone, utteranceSeconds: 0, interimResults: true, maxChunkMs: 100,
+		minStability: 0.5, singleUtterance: false, voiceActivityEvents: false, noSpeechTimeoutSeconds: 0, keepAliveMs: 1000,
+	},
+	// Live captions of media: stable interim results, so the captions don't jitter.
+	"captioning": {
+		audioSource: audioSourceMedia, utteranceSeconds: 0, interimResults: true, maxChunkMs: 100,
+		minStability: 0.8, singleUtterance: false, voiceActivityEvents: false, noSpeechTimeoutSeconds: 0, keepAliveMs: 1000,
+	},
+	// Phone calls: accuracy over latency, only final results, the speech events mark the turns.
+	"telephony": {
+		audioSource: audioSourceTelephone, utteranceSeconds: 0, interimResults: false, maxChunkMs: maxChunkMs,
+		minStability: 0, singleUtterance: false, voiceActivityEvents: true, noSpeechTimeoutSeconds: 0, keepAliveMs: 1000,
+	},
+}
+
+// The ceiling of the adaptive chunk size in milliseconds of audio (set by the profiles, atomic)
+var chunkCeilingMs int32 = maxChunkMs
+
+
+// lookupProfile returns the profile of the name (case-insensitive).
+func lookupProfile(name string) (profile, C.int) {
+	preset, found := profiles[strings.ToLower(name)]
+	if found == false {
+		names := []string{}
+		for known := range profiles {
+			names = append(names, known)
+		}
+		sort.Strings(names)
+		logError("Unknown profile \"" + name + "\" (must be one of " + strings.Join(names, ", ") + ")", nil)
+		return profile{}, resultInvalidArgument
+	}
+	return preset, resultOK
+}
+
+
+// applyProfile applies the settings of the profile (like the corresponding exports).
+func applyProfile(preset profile) {
+	SetModelHints(C.int(preset.audioSource), C.int(preset.utteranceSeconds))
+	atomic.StoreInt32(&chunkCeilingMs, preset.maxChunkMs)
+	SetMinStability(C.float(preset.minStability))
+	SetVoiceActivityEvents(boolToC(preset.voiceActivityEvents))
+	SetSingleUtterance(boolToC(preset.singleUtterance))
+	SetNoSpeechTimeout(C.int(preset.noSpeechTimeoutSeconds))
+	SetKeepAlive(boolToC(preset.keepAliveMs > 0), C.int(preset.keepAliveMs))
+}
+
+func boolToC(value bool) (C.int) {
+	if value {
+		return C.int(1)
+	}
+	return C.int(0)
+}
+
+
+/*
+	ApplyProfile(cProfile *C.char) (C.int):
+	applies a preset of settings for a use case, the settings can still be adjusted afterwards,
+	the model is picked by passing "auto" to "InitializeStream()" (see "SetModelHints()"):
+		"interactive": voice commands, "latest_short", chunks up to 50 ms, single utterance mode, voice activity events,
+			the stream is finalized after 8 seconds without speech (interim results recommended)
+		"dictation": "latest_long", chunks up to 100 ms, interim results with a stability of at least 0.5, keep-alive
+			(interim results recommended)
+		"captioning": "video", chunks up to 100 ms, interim results with a stability of at least 0.8, keep-alive
+			(interim results recommended)
+		"telephony": "phone_call", chunks up to 200 ms, voice activity events, keep-alive (final results only)
+	has to be called before "InitializeStream()" or "Reconfigure()" to take effect
+
+	Parameter:
+		cProfile *C.char
+			(the name of the profile)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export ApplyProfile
+func ApplyProfile(cProfile *C.char) (C.int) {
+	preset, code := lookupProfile(C.GoString(cProfile))
+	if code != resultOK {
+		return result(code)
+	}
+	applyProfile(preset)
+	return result(resultOK)
+}
+
+
+/*
+	InitializeStreamWithProfile(cProfile *C.char, cTranscriptLanguage *C.char, cSampleRate C.int) (C.int):
+	applies the profile (see "ApplyProfile()") and initializes the streaming session like "InitializeStream()"
+	with the model picked for the profile, one alternative and the profile's interim results
+
+	Parameters:
+		cProfile *C.char
+			(the name of the profile)
+		the others:
+			the same as "InitializeStream()"
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export InitializeStreamWithProfile
+func InitializeStreamWithProfile(cProfile *C.char, cTranscriptLanguage *C.char, cSampleRate C.int) (C.int) {
+	preset, code := lookupProfile(C.GoString(cProfile))
+	if code != resultOK {
+		return result(code)
+	}
+	applyProfile(preset)
+
+	cModel := C.CString("auto")
+	defer C.free(unsafe.Pointer(cModel))
+
+	span := startSpan("InitializeStream")
+	code = initializeStream(cTranscriptLanguage, cSampleRate, cModel, C.int(1), boolToC(preset.interimResults), nil)
+	endSpan(span, code)
+	return result(code)
+}
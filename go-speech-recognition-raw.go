@@ -0,0 +1,107 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Keeps the last response of google as it was received (before the results are filtered, re-ranked or post-processed),
+	so fields the library doesn't surface yet can be inspected when diagnosing unexpected results (see "GetLastResponseRaw()").
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"sync"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+
+	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+)
+
+// Formats of "GetLastResponseRaw()"
+const (
+	rawFormatJSON = 0
+	rawFormatProtobuf = 1
+)
+
+// The last response received from google (nil if there's none yet), guarded by the rawMutex
+var rawMutex = &sync.Mutex{}
+var lastRawResponse *speechpb.StreamingRecognizeResponse
+
+
+// recordRawResponse keeps a copy of the response (the receive pump modifies the results afterwards).
+func recordRawResponse(resp *speechpb.StreamingRecognizeResponse) {
+	raw := proto.Clone(resp).(*speechpb.StreamingRecognizeResponse)
+	rawMutex.Lock()
+		lastRawResponse = raw
+	rawMutex.Unlock()
+}
+
+
+/*
+	GetLastResponseRaw(cFormat C.int, output **C.char, outputLength *C.int) (C.int):
+	retrieves the last StreamingRecognizeResponse received from google, unmodified by the library
+	(it's kept after the session has been closed, until the next response is received)
+
+	Parameters:
+		cFormat C.int
+			(0 for JSON (the JSON mapping of protobuf, e.g. {"results":[{"alternatives":[...],"isFinal":true,...}],...}),
+			1 for the serialized protobuf message (binary, not terminated by a null character))
+		output:
+			The pointer which is used to store the response ("{}" or no bytes if no response has been received yet),
+			free it with "FreeString()"
+		outputLength:
+			The pointer which is used to store the length of the response in bytes (may be NULL for JSON)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetLastResponseRaw
+func GetLastResponseRaw(cFormat C.int, output **C.char, outputLength *C.int) (C.int) {
+	if output == nil || cFormat == rawFormatProtobuf && outputLength == nil {
+		logError("Invalid output pointer", nil)
+		return result(resultInvalidArgument)
+	}
+	if cFormat != rawFormatJSON && cFormat != rawFormatProtobuf {
+		logError("Invalid format (must be 0 or 1)", nil)
+		return result(resultInvalidArgument)
+	}
+
+	rawMutex.Lock()
+		resp := lastRawResponse
+	rawMutex.Unlock()
+	if resp == nil {
+		resp = &speechpb.StreamingRecognizeResponse{}
+	}
+
+	var encoded []byte
+	var err error
+	if cFormat == rawFormatJSON {
+		var text string
+		text, err = (&jsonpb.Marshaler{}).MarshalToString(resp)
+		encoded = []byte(text)
+	} else {
+		encoded, err = proto.Marshal(resp)
+	}
+	if err != nil {
+		logError("Could not encode response: ", err)
+		return result(resultError)
+	}
+
+	if cFormat == rawFormatJSON {
+		*output = cString(string(encoded))
+	} else {
+		// The terminating null character keeps an empty message a valid allocation (freed like the strings).
+		*output = (*C.char)(C.CBytes(append(encoded, 0)))
+	}
+	if outputLength != nil {
+		*outputLength = C.int(len(encoded))
+	}
+	return result(resultOK)
+}
@@ -0,0 +1,248 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The real-time audio path (see "SetRealtimeAudio()" and "SendAudioRealtime()"): hosts calling from a real-time
+	audio callback must not wait for locks or the allocator, so the samples go into a lock-free single-producer
+	single-consumer ring, which a goroutine of the session drains into the pipeline of "SendAudio()".
+	The ring is allocated when the session starts, the producer side only copies the samples and publishes them
+	with an atomic store. The entry of the cgo call isn't free of locks though: the first call from a thread Go didn't
+	create binds the thread to the Go runtime (which locks and allocates), so the host has to pre-warm its thread.
+	When no audio arrives for a while, the drain goroutine parks until the next write wakes it
+	(see go-speech-recognition-idle.go).
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// The range of the ring's capacity in milliseconds of audio
+const minRealtimeCapacityMs = 100
+const maxRealtimeCapacityMs = 10000
+
+// The drain goroutine checks the ring in this interval and parks when it stayed empty for realtimeParkDelay
+const realtimeDrainInterval = 10 * time.Millisecond
+const realtimeParkDelay = time.Second
+
+// The capacity of the ring of the next session in milliseconds, 0 if the real-time path is disabled (see "SetRealtimeAudio()")
+var realtimeCapacityMs int32
+
+// The ring of the current session (nil without a session or if the real-time path is disabled),
+// realtimePumpDone is closed when its drain goroutine stopped (guarded by the sendMutex)
+var realtimeRing atomic.Pointer[audioRing]
+var realtimePumpDone chan struct{}
+
+// A lock-free ring of samples for one producer and one consumer: the producer only advances head, the consumer only tail
+// (both count the samples ever written resp. read, the position in the buffer is masked), failure is the result
+// of the consumer's last send (reported to the producer), parked is set while the consumer waits for wake
+type audioRing struct {
+	head uint64
+	tail uint64
+	failure int32
+	parked int32
+	wake chan struct{}
+	buffer []int16
+	mask uint64
+}
+
+
+// newAudioRing allocates a ring of at least the given number of samples (rounded up to a power of two).
+func newAudioRing(samples int) (*audioRing) {
+	size := 1
+	for size < samples {
+		size <<= 1
+	}
+	return &audioRing{wake: make(chan struct{}, 1), buffer: make([]int16, size), mask: uint64(size - 1)}
+}
+
+
+// write copies the samples into the ring (the producer side, no locks, no allocations),
+// false if there's not enough space left (nothing is written then).
+func (ring *audioRing) write(samples []int16) (bool) {
+	head := atomic.LoadUint64(&ring.head)
+	free := uint64(len(ring.buffer)) - (head - atomic.LoadUint64(&ring.tail))
+	if uint64(len(samples)) > free {
+		return false
+	}
+	for i, sample := range samples {
+		ring.buffer[(head + uint64(i)) & ring.mask] = sample
+	}
+	// The samples are visible to the consumer once head is published.
+	atomic.StoreUint64(&ring.head, head + uint64(len(samples)))
+	// Only the first write after a pause finds the consumer parked (the send never blocks).
+	if atomic.LoadInt32(&ring.parked) == 1 {
+		select {
+		case ring.wake <- struct{}{}:
+		default:
+		}
+	}
+	return true
+}
+
+
+// park waits until the producer writes again (the consumer side), false if the session ended meanwhile.
+func (ring *audioRing) park(pumpCtx context.Context) (bool) {
+	atomic.StoreInt32(&ring.parked, 1)
+	defer atomic.StoreInt32(&ring.parked, 0)
+
+	// A write before parked was set didn't wake the consumer, so its samples are checked here.
+	if atomic.LoadUint64(&ring.head) != atomic.LoadUint64(&ring.tail) {
+		return true
+	}
+	select {
+	case <-ring.wake:
+		return true
+	case <-pumpCtx.Done():
+		return false
+	}
+}
+
+
+// read moves the samples written so far (at most len(samples)) out of the ring (the consumer side)
+// and returns their number.
+func (ring *audioRing) read(samples []C.short) (int) {
+	tail := atomic.LoadUint64(&ring.tail)
+	available := atomic.LoadUint64(&ring.head) - tail
+	count := min(int(available), len(samples))
+	for i := 0; i < count; i++ {
+		samples[i] = C.short(ring.buffer[(tail + uint64(i)) & ring.mask])
+	}
+	// The space is free for the producer once tail is published.
+	atomic.StoreUint64(&ring.tail, tail + uint64(count))
+	return count
+}
+
+
+// startRealtimeAudio allocates the ring of a new session and starts draining it (if the real-time path is enabled).
+// The caller has to hold the sendMutex.
+func startRealtimeAudio(pumpCtx context.Context, sampleRate int32) {
+	capacityMs := atomic.LoadInt32(&realtimeCapacityMs)
+	if capacityMs == 0 {
+		realtimeRing.Store(nil)
+		realtimePumpDone = nil
+		return
+	}
+	ring := newAudioRing(int(int64(capacityMs) * int64(sampleRate) / 1000))
+	realtimePumpDone = make(chan struct{})
+	go realtimePump(pumpCtx, ring, realtimePumpDone)
+	realtimeRing.Store(ring)
+}
+
+
+// realtimePump runs in its own goroutine and sends the samples of the ring like "SendAudio()" until the session ends.
+func realtimePump(pumpCtx context.Context, ring *audioRing, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(realtimeDrainInterval)
+	defer ticker.Stop()
+
+	samples := make([]C.short, len(ring.buffer))
+	idleSince := time.Now()
+	for {
+		select {
+		case <-pumpCtx.Done():
+			return
+		case <-ticker.C:
+		}
+		noteWakeup()
+
+		count := ring.read(samples)
+		if count == 0 {
+			// No audio flows (e.g. the host paused the capture), so the goroutine parks instead of polling.
+			if time.Since(idleSince) >= realtimeParkDelay {
+				ticker.Stop()
+				if ring.park(pumpCtx) == false {
+					return
+				}
+				ticker.Reset(realtimeDrainInterval)
+				idleSince = time.Now()
+			}
+			continue
+		}
+		idleSince = time.Now()
+		// The timestamp is the time of the drain (at most the interval after the producer's call).
+		code := sendAudio(&samples[0], C.int(count), libraryTimestamp(int64(count)))
+		atomic.StoreInt32(&ring.failure, int32(code))
+	}
+}
+
+
+/*
+	SetRealtimeAudio(cCapacityMs C.int) (C.int):
+	enables the real-time audio path of the following sessions (see "SendAudioRealtime()"), the ring buffering the audio
+	between the host's audio callback and the library is allocated with the given capacity when "InitializeStream()" starts the session
+
+	Parameter:
+		cCapacityMs C.int
+			(the capacity of the ring in milliseconds of audio (100 - 10000), 0 disables the real-time path (default))
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetRealtimeAudio
+func SetRealtimeAudio(cCapacityMs C.int) (C.int) {
+	if cCapacityMs != 0 && (cCapacityMs < minRealtimeCapacityMs || cCapacityMs > maxRealtimeCapacityMs) {
+		logError("Invalid capacity (must be 0 or " + strconv.Itoa(minRealtimeCapacityMs) + " - " + strconv.Itoa(maxRealtimeCapacityMs) + " ms)", nil)
+		return result(resultInvalidArgument)
+	}
+	atomic.StoreInt32(&realtimeCapacityMs, int32(cCapacityMs))
+	return result(resultOK)
+}
+
+
+/*
+	SendAudioRealtime(recording *C.short, recordingLength C.int) (C.int):
+	sends the audio like "SendAudio()", but suited for real-time threads (e.g. the host's audio callback): the samples are
+	copied into a lock-free ring (the copying doesn't lock, allocate or wait), which the library drains every 10 milliseconds
+	into the pipeline of "SendAudio()" (the timestamps are the times of the drain, the draining parks after a second
+	without audio and the first call after the pause wakes it),
+	the real-time path has to be enabled before the session (see "SetRealtimeAudio()"),
+	only one thread may call it at a time (single producer) and it doesn't log (the log takes a lock),
+	so the error codes are its only report (the details of a failed send are logged by the drain),
+	the first call from a thread, which hasn't been created by Go, enters the Go runtime through cgo's callback entry,
+	which takes locks and may allocate to bind the thread, so the host has to pre-warm the real-time thread with one call
+	(e.g. with recordingLength 0) before its real-time processing starts, even then the entry may briefly wait for the
+	Go scheduler when the process is under heavy load
+
+	Parameters:
+		recording, recordingLength:
+			the same as "SendAudio()"
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes): GO_SPEECH_RECOGNITION_ERROR_NOT_INITIALIZED without a session
+		(or with the real-time path disabled), GO_SPEECH_RECOGNITION_ERROR_QUEUE_FULL if the ring is full (the audio is dropped),
+		otherwise the error of the last drained audio (e.g. GO_SPEECH_RECOGNITION_ERROR_FINALIZED, see "GetLog()"),
+		the audio of this call has been accepted regardless
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SendAudioRealtime
+func SendAudioRealtime(recording *C.short, recordingLength C.int) (C.int) {
+	if recordingLength < 0 || recording == nil && recordingLength > 0 {
+		return result(resultInvalidArgument)
+	}
+	ring := realtimeRing.Load()
+	if ring == nil {
+		return result(resultNotInitialized)
+	}
+	if ring.write(unsafe.Slice((*int16)(unsafe.Pointer(recording)), int(recordingLength))) == false {
+		atomic.AddUint64(&audioOverflows, 1)
+		return result(resultQueueFull)
+	}
+	// The audio is drained anyway, so the report clears once the sending recovers (e.g. after "Reconfigure()").
+	return result(C.int(atomic.LoadInt32(&ring.failure)))
+}
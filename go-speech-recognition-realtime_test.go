@@ -0,0 +1,98 @@
+//go:build cgo
+
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Tests of the real-time path (the lock-free ring of samples).
+*/
+
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+
+// TestAudioRing writes and reads across the end of the buffer and rejects writes exceeding the free space.
+func TestAudioRing(t *testing.T) {
+	ring := newAudioRing(6)
+	if len(ring.buffer) != 8 {
+		t.Fatalf("Capacity %d, want 8 (the next power of two)", len(ring.buffer))
+	}
+
+	read := make([]_Ctype_short, 8)
+	steps := []struct {
+		write []int16
+		written bool
+		read int
+		want []int16
+	}{
+		{[]int16{1, 2, 3, 4, 5}, true, 3, []int16{1, 2, 3}},
+		{[]int16{6, 7, 8, 9, 10, 11}, true, 0, nil},
+		{[]int16{12}, false, 8, []int16{4, 5, 6, 7, 8, 9, 10, 11}},
+		{[]int16{12, 13}, true, 5, []int16{12, 13}},
+		{nil, true, 1, []int16{}},
+	}
+
+	for i, step := range steps {
+		if written := ring.write(step.write); written != step.written {
+			t.Fatalf("Step %d: write returned %t, want %t", i, written, step.written)
+		}
+		if step.read == 0 {
+			continue
+		}
+		count := ring.read(read[:step.read])
+		if count != len(step.want) {
+			t.Fatalf("Step %d: read %d samples, want %d", i, count, len(step.want))
+		}
+		for j, sample := range step.want {
+			if int16(read[j]) != sample {
+				t.Fatalf("Step %d: sample %d is %d, want %d", i, j, read[j], sample)
+			}
+		}
+	}
+}
+
+
+// TestAudioRingPark wakes the parked consumer by a write and ends the parking with the session.
+func TestAudioRingPark(t *testing.T) {
+	ring := newAudioRing(16)
+
+	// Samples written before the parking aren't waited for.
+	ring.write([]int16{1})
+	if ring.park(context.Background()) == false {
+		t.Fatal("park with written samples returned false")
+	}
+	ring.read(make([]_Ctype_short, 16))
+
+	pumpCtx, endSession := context.WithCancel(context.Background())
+	defer endSession()
+	for _, wake := range []func(){
+		func() { ring.write([]int16{2}) },
+		endSession,
+	} {
+		woken := make(chan bool)
+		go func() { woken <- ring.park(pumpCtx) }()
+		deadline := time.Now().Add(testDeadline)
+		for atomic.LoadInt32(&ring.parked) == 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("The consumer didn't park")
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		wake()
+		select {
+		case written := <-woken:
+			if written != (pumpCtx.Err() == nil) {
+				t.Errorf("park returned %t, the session ended: %t", written, pumpCtx.Err() != nil)
+			}
+		case <-time.After(testDeadline):
+			t.Fatal("The consumer wasn't woken")
+		}
+		ring.read(make([]_Ctype_short, 16))
+	}
+}
@@ -0,0 +1,59 @@
+//go:build cgo && linux
+
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The result event on Linux: an eventfd, which is readable while its counter isn't zero (see "GetResultEventHandle()").
+*/
+
+package main
+
+import (
+	"encoding/binary"
+
+	// Unix package (download with "go get -u golang.org/x/sys/unix"):
+	"golang.org/x/sys/unix"
+)
+
+// An eventfd
+type eventFD struct {
+	fd int
+}
+
+
+// newResultEvent creates a non-blocking eventfd with a counter of zero.
+func newResultEvent() (resultEvent, error) {
+	fd, err := unix.Eventfd(0, unix.EFD_CLOEXEC | unix.EFD_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	return &eventFD{fd: fd}, nil
+}
+
+
+func (event *eventFD) handle() (int64) {
+	return int64(event.fd)
+}
+
+
+func (event *eventFD) signal() (error) {
+	value := make([]byte, 8)
+	binary.NativeEndian.PutUint64(value, 1)
+	_, err := unix.Write(event.fd, value)
+	return err
+}
+
+
+// reset reads the counter, which sets it to zero (EAGAIN if it's zero already).
+func (event *eventFD) reset() (error) {
+	value := make([]byte, 8)
+	if _, err := unix.Read(event.fd, value); err != nil && err != unix.EAGAIN {
+		return err
+	}
+	return nil
+}
+
+
+func (event *eventFD) close() (error) {
+	return unix.Close(event.fd)
+}
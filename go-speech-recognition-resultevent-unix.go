@@ -0,0 +1,71 @@
+//go:build cgo && !windows && !linux
+
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The result event on macOS and the BSDs: a pipe, whose read end is readable while a byte is in it
+	(see "GetResultEventHandle()").
+*/
+
+package main
+
+import (
+	// Unix package (download with "go get -u golang.org/x/sys/unix"):
+	"golang.org/x/sys/unix"
+)
+
+// A pipe, the host waits for its read end
+type eventPipe struct {
+	read int
+	write int
+}
+
+
+// newResultEvent creates an empty non-blocking pipe.
+func newResultEvent() (resultEvent, error) {
+	fds := make([]int, 2)
+	if err := unix.Pipe(fds); err != nil {
+		return nil, err
+	}
+	for _, fd := range fds {
+		unix.CloseOnExec(fd)
+		if err := unix.SetNonblock(fd, true); err != nil {
+			unix.Close(fds[0])
+			unix.Close(fds[1])
+			return nil, err
+		}
+	}
+	return &eventPipe{read: fds[0], write: fds[1]}, nil
+}
+
+
+func (event *eventPipe) handle() (int64) {
+	return int64(event.read)
+}
+
+
+// signal writes a single byte (only an unsignaled event is signaled, so the pipe never fills up).
+func (event *eventPipe) signal() (error) {
+	_, err := unix.Write(event.write, []byte{1})
+	return err
+}
+
+
+// reset empties the pipe.
+func (event *eventPipe) reset() (error) {
+	buffer := make([]byte, 64)
+	for {
+		if _, err := unix.Read(event.read, buffer); err != nil {
+			if err == unix.EAGAIN {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+
+func (event *eventPipe) close() (error) {
+	unix.Close(event.write)
+	return unix.Close(event.read)
+}
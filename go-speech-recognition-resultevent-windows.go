@@ -0,0 +1,49 @@
+//go:build cgo && windows
+
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The result event on Windows: a manual-reset event object (see "GetResultEventHandle()").
+*/
+
+package main
+
+import (
+	// Windows package (download with "go get -u golang.org/x/sys/windows"):
+	"golang.org/x/sys/windows"
+)
+
+// An event object
+type eventObject struct {
+	event windows.Handle
+}
+
+
+// newResultEvent creates an unsignaled manual-reset event, so every waiting thread sees it until it's reset.
+func newResultEvent() (resultEvent, error) {
+	event, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &eventObject{event: event}, nil
+}
+
+
+func (object *eventObject) handle() (int64) {
+	return int64(object.event)
+}
+
+
+func (object *eventObject) signal() (error) {
+	return windows.SetEvent(object.event)
+}
+
+
+func (object *eventObject) reset() (error) {
+	return windows.ResetEvent(object.event)
+}
+
+
+func (object *eventObject) close() (error) {
+	return windows.CloseHandle(object.event)
+}
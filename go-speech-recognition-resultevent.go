@@ -0,0 +1,132 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The waitable result event (see "GetResultEventHandle()"): a handle of the operating system, which is signaled
+	while results are queued, so hosts can wait for the results in their own event loop (select, poll, epoll,
+	WaitForMultipleObjects) together with their other handles instead of polling or blocking a thread in a receive call.
+	The events of the platforms are implemented in go-speech-recognition-resultevent-windows.go (an event object),
+	go-speech-recognition-resultevent-linux.go (an eventfd) and go-speech-recognition-resultevent-unix.go (a pipe).
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"sync"
+)
+
+// A waitable handle of the operating system
+type resultEvent interface {
+	// handle returns the handle passed to the host.
+	handle() (int64)
+	// signal makes the handle signaled (resp. readable), reset reverts it.
+	signal() (error)
+	reset() (error)
+	close() (error)
+}
+
+// The event of the results (guarded by the resultEventMutex), nil until the host requests it,
+// resultEventSignaled mirrors the state of the handle
+var resultEventMutex = &sync.Mutex{}
+var currentResultEvent resultEvent
+var resultEventSignaled = false
+
+
+// signalResultEvent signals the event (if requested), the receive pump calls it after queueing a result.
+func signalResultEvent() {
+	resultEventMutex.Lock()
+	defer resultEventMutex.Unlock()
+
+	if currentResultEvent == nil || resultEventSignaled {
+		return
+	}
+	if err := currentResultEvent.signal(); err != nil {
+		logError("Could not signal the result event: ", err)
+		return
+	}
+	resultEventSignaled = true
+}
+
+
+// rearmResultEvent resets the event once the receive calls emptied the queue.
+// The pump queues a result before signaling, so a result queued meanwhile signals the event again.
+func rearmResultEvent(queue chan receiveResult) {
+	resultEventMutex.Lock()
+	defer resultEventMutex.Unlock()
+
+	if currentResultEvent == nil || resultEventSignaled == false || len(queue) > 0 {
+		return
+	}
+	if err := currentResultEvent.reset(); err != nil {
+		logError("Could not reset the result event: ", err)
+		return
+	}
+	resultEventSignaled = false
+}
+
+
+// closeResultEvent closes the event (if requested).
+// The caller has to hold the resultEventMutex.
+func closeResultEvent() {
+	if currentResultEvent == nil {
+		return
+	}
+	currentResultEvent.close()
+	currentResultEvent = nil
+	resultEventSignaled = false
+}
+
+
+/*
+	GetResultEventHandle(handle *C.longlong) (C.int):
+	retrieves a waitable handle, which is signaled while results are queued (and when the receiving ended), so the host
+	can wait for it in its own event loop instead of polling and then retrieve the results without blocking
+	(e.g. with "ReceiveAllPending()"): on Windows an event object (a HANDLE for WaitForMultipleObjects), on Linux an eventfd
+	and on the other platforms the read end of a pipe (file descriptors, readable while signaled, for select, poll or epoll),
+	the library resets it when the receive calls emptied the queue, so the host must neither read nor reset nor close it,
+	the handle stays the same for all sessions until "Shutdown()" closes it
+
+	Parameter:
+		handle:
+			The pointer which is used to store the handle (resp. file descriptor)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetResultEventHandle
+func GetResultEventHandle(handle *C.longlong) (C.int) {
+	if handle == nil {
+		logError("Invalid pointer of the result event handle", nil)
+		return result(resultInvalidArgument)
+	}
+
+	receiveMutex.Lock()
+		queued := initialized && len(resultQueue) > 0
+	receiveMutex.Unlock()
+
+	resultEventMutex.Lock()
+	defer resultEventMutex.Unlock()
+
+	if currentResultEvent == nil {
+		event, err := newResultEvent()
+		if err != nil {
+			logError("Could not create the result event: ", err)
+			return result(resultError)
+		}
+		currentResultEvent = event
+		// The results queued before the event existed
+		if queued && event.signal() == nil {
+			resultEventSignaled = true
+		}
+	}
+
+	*handle = C.longlong(currentResultEvent.handle())
+	return result(resultOK)
+}
@@ -0,0 +1,149 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The results in their structured form (see "ReceiveTranscriptJSON()") and the clocks mapping their times to the host's clock.
+	This file doesn't need cgo, so the builds without it (the WebSocket bridge and the wasm build) share the result model
+	with the library.
+*/
+
+package main
+
+import (
+	// Standard packages:
+	"strings"
+	"sync"
+
+	// Protocol buffer helpers (needed to convert durations):
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/duration"
+
+	// External (Google) packages:
+	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+)
+
+// A result in the structured form of "ReceiveTranscriptJSON()"
+type transcriptResult struct {
+	Transcript string `json:"transcript"`
+	IsFinal bool `json:"isFinal"`
+	Stability float32 `json:"stability"`
+	Confidence float32 `json:"confidence"`
+	EndTimestamp int64 `json:"endTimestamp"`
+	Sequence int64 `json:"sequence,omitempty"`
+	Words []transcriptWord `json:"words"`
+}
+type transcriptWord struct {
+	Word string `json:"word"`
+	Confidence float32 `json:"confidence"`
+	Speaker int32 `json:"speaker"`
+	StartTimestamp int64 `json:"startTimestamp"`
+	EndTimestamp int64 `json:"endTimestamp"`
+}
+
+// Maps the audio offsets of a stream (google's result times are relative to the stream's start) to the host's timestamps,
+// an anchor is only added when the timestamps don't continue the previous audio (e.g. after a gap)
+type streamClock struct {
+	mutex sync.Mutex
+	sampleRate int64
+	sentSamples int64
+	anchors []clockAnchor
+	// The measured sample rate of the capture clock (see "SetDriftCompensation()"), 0 for the nominal one
+	measuredRate float64
+}
+type clockAnchor struct {
+	sample int64
+	timestampUs int64
+}
+const clockToleranceUs = 1000
+
+
+// transcriptResults converts the response's results into their structured form (using the most likely alternative),
+// the result times are mapped to the host's clock by the clock of the stream.
+func transcriptResults(resp *speechpb.StreamingRecognizeResponse, clock *streamClock) ([]transcriptResult) {
+	results := []transcriptResult{}
+	for _, result := range resp.Results {
+		if len(result.Alternatives) == 0 {
+			continue
+		}
+		alternative := result.Alternatives[0]
+
+		words := []transcriptWord{}
+		for _, word := range alternative.Words {
+			words = append(words, transcriptWord{
+				Word:		word.Word,
+				Confidence:	word.Confidence,
+				Speaker:	word.SpeakerTag,
+				StartTimestamp:	clock.timestamp(word.StartTime),
+				EndTimestamp:	clock.timestamp(word.EndTime),
+			})
+		}
+
+		results = append(results, transcriptResult{
+			Transcript:	strings.TrimSpace(alternative.Transcript),
+			IsFinal:	result.IsFinal,
+			Stability:	result.Stability,
+			Confidence:	alternative.Confidence,
+			EndTimestamp:	clock.timestamp(result.ResultEndTime),
+			Words:		words,
+		})
+	}
+	return results
+}
+
+
+// advance accounts the samples sent on the stream, the timestamp of their first sample (-1 to continue the previous
+// audio) only adds an anchor if it deviates from the extrapolated one.
+func (clock *streamClock) advance(samples int64, timestampUs int64) {
+	if clock == nil {
+		return
+	}
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	if timestampUs >= 0 {
+		extrapolated := clock.timestampAt(clock.sentSamples)
+		deviation := timestampUs - extrapolated
+		if extrapolated < 0 || deviation > clockToleranceUs || deviation < -clockToleranceUs {
+			clock.anchors = append(clock.anchors, clockAnchor{sample: clock.sentSamples, timestampUs: timestampUs})
+		}
+	}
+	clock.sentSamples += samples
+}
+
+// timestamp maps a result time of the stream to the host's clock (in microseconds), -1 if unknown.
+func (clock *streamClock) timestamp(offset *duration.Duration) (int64) {
+	if clock == nil || offset == nil {
+		return -1
+	}
+	offsetDuration, err := ptypes.Duration(offset)
+	if err != nil {
+		return -1
+	}
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	return clock.timestampAt(int64(offsetDuration.Seconds() * float64(clock.sampleRate)))
+}
+
+// timestampAt extrapolates the timestamp of a sample from the last anchor before it (the mutex has to be locked).
+func (clock *streamClock) timestampAt(sample int64) (int64) {
+	for i := len(clock.anchors) - 1; i >= 0; i-- {
+		anchor := clock.anchors[i]
+		if anchor.sample <= sample {
+			if clock.measuredRate > 0 {
+				return anchor.timestampUs + int64(float64(sample - anchor.sample) * 1000000 / clock.measuredRate)
+			}
+			return anchor.timestampUs + (sample - anchor.sample) * 1000000 / clock.sampleRate
+		}
+	}
+	return -1
+}
+
+// compensate maps the following result times with the measured sample rate of the capture clock (0 for the nominal one).
+func (clock *streamClock) compensate(measuredRate float64) {
+	if clock == nil {
+		return
+	}
+	clock.mutex.Lock()
+		clock.measuredRate = measuredRate
+	clock.mutex.Unlock()
+}
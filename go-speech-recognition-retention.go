@@ -0,0 +1,331 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Limits how long and how much data the library keeps on disk (see "SetDataRetention()") and deletes it on request
+	(see "PurgeLocalData()"), optionally overwriting the files before they're deleted, for GDPR-style data minimization.
+	Only the files written by the library are touched: the transcript files, the backfill's spool files,
+	the result cache (if it's a directory) and the journal of the result acknowledgment.
+	The in-memory only mode (see "SetInMemoryOnly()") forbids these files altogether.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Size of the blocks overwriting a file before it's deleted
+const wipeBlockSize = 64 * 1024
+
+// The retention policy, 0 means unlimited (guarded by the retentionMutex)
+var retentionMutex = &sync.Mutex{}
+var retentionMaxAge time.Duration
+var retentionMaxBytes int64
+var secureDelete = false
+
+// Set while the in-memory only mode forbids writing to disk
+var inMemoryOnly int32
+
+// Names of the files written by the library (other files in the directories are never touched)
+var transcriptFilePattern = regexp.MustCompile(`^[0-9a-f]{16}-(speaker|channel)-.+\.txt(\.enc)?$`)
+var spoolFilePattern = regexp.MustCompile(`^backfill-[0-9a-f]{16}-[0-9]+\.raw$`)
+var cacheFilePattern = regexp.MustCompile(`^[0-9a-f]{64}\.txt(\.tmp)?$`)
+
+// A file written by the library
+type localFile struct {
+	path string
+	size int64
+	modified time.Time
+}
+
+
+/*
+	SetDataRetention(cMaxAgeSeconds C.int, cMaxMegabytes C.int, cSecureDelete C.int) (C.int):
+	sets the retention policy of the files the library writes (the transcript files, the backfill's spool files and the result cache directory):
+	older files and the oldest files exceeding the total size are deleted, the policy is applied immediately,
+	whenever a session is initialized or closed and whenever the result cache grows
+
+	Parameters:
+		cMaxAgeSeconds C.int
+			(the maximum age of a file in seconds, 0 for unlimited (default))
+		cMaxMegabytes C.int
+			(the maximum total size of the files in megabytes, 0 for unlimited (default))
+		cSecureDelete C.int
+			(1 to overwrite the files with zeros before they're deleted (by the retention policy and "PurgeLocalData()"), 0 to just delete them (default),
+			note that SSDs and copy-on-write file systems may keep copies of the data anyway)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetDataRetention
+func SetDataRetention(cMaxAgeSeconds C.int, cMaxMegabytes C.int, cSecureDelete C.int) (C.int) {
+	if cMaxAgeSeconds < 0 || cMaxMegabytes < 0 {
+		logError("Invalid retention policy (the limits must not be negative)", nil)
+		return result(resultInvalidArgument)
+	}
+	if cSecureDelete != 0 && cSecureDelete != 1 {
+		logError("Invalid value for secure delete (must be 0 or 1)", nil)
+		return result(resultInvalidArgument)
+	}
+
+	retentionMutex.Lock()
+		retentionMaxAge = time.Duration(cMaxAgeSeconds) * time.Second
+		retentionMaxBytes = int64(cMaxMegabytes) * 1024 * 1024
+		secureDelete = cSecureDelete == 1
+	retentionMutex.Unlock()
+
+	enforceRetention()
+	return result(resultOK)
+}
+
+
+/*
+	PurgeLocalData() (C.int):
+	deletes all data the library keeps on disk: the transcript files, the backfill's spool files (except the spool of an offline session),
+	the result cache directory and the journal of the result acknowledgment (the unacknowledged results are dropped as well),
+	the files are overwritten before if secure deletion is enabled (see "SetDataRetention()")
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if a file couldn't be deleted (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export PurgeLocalData
+func PurgeLocalData() (C.int) {
+	code := resultOK
+	for _, file := range localDataFiles() {
+		if err := removeLocalFile(file.path); err != nil {
+			logError("Could not delete " + file.path + ": ", err)
+			code = resultError
+		}
+	}
+
+	// The journal is gone, so the unacknowledged results are dropped as well.
+	ackMutex.Lock()
+		journalFile := ackJournalFile
+		pendingResults = map[int64]*pendingResult{}
+	ackMutex.Unlock()
+	if journalFile != "" {
+		if err := removeLocalFile(journalFile); err != nil && os.IsNotExist(err) == false {
+			logError("Could not delete " + journalFile + ": ", err)
+			code = resultError
+		}
+	}
+	return result(code)
+}
+
+
+/*
+	SetInMemoryOnly(cEnabled C.int) (C.int):
+	enables the in-memory only mode for hosts under "no recording" policies: the library never writes audio or transcripts to disk,
+	every feature that would (the transcript files, the backfill's spool, a result cache directory, the journal of the result acknowledgment,
+	the operation state file of the batches, the log file, the diagnostics bundle and "DecryptFile()") fails with an error instead of silently writing
+
+	Parameter:
+		cEnabled C.int
+			(1 to enable the mode, 0 to disable it (default))
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if a feature writing to disk is already configured (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetInMemoryOnly
+func SetInMemoryOnly(cEnabled C.int) (C.int) {
+	if cEnabled != 0 && cEnabled != 1 {
+		logError("Invalid value for enabled (must be 0 or 1)", nil)
+		return result(resultInvalidArgument)
+	}
+	if cEnabled == 0 {
+		atomic.StoreInt32(&inMemoryOnly, 0)
+		return result(resultOK)
+	}
+
+	// The mode can't be guaranteed while a feature is still configured to write to disk.
+	speakerMutex.Lock()
+		transcriptFiles := transcriptFilesDirectory != ""
+	speakerMutex.Unlock()
+	sendMutex.Lock()
+		backfill := backfillDirectory != ""
+	sendMutex.Unlock()
+	cacheMutex.Lock()
+		_, cacheDirectory := cache.(*directoryCache)
+	cacheMutex.Unlock()
+	ackMutex.Lock()
+		journal := ackJournalFile != ""
+	ackMutex.Unlock()
+	operationStateMutex.Lock()
+		stateFile := operationStateFile != ""
+	operationStateMutex.Unlock()
+	logMutex.Lock()
+		logging := logFile != nil
+	logMutex.Unlock()
+
+	for feature, configured := range map[string]bool{
+		"the transcript files": transcriptFiles,
+		"the backfill": backfill,
+		"the result cache directory": cacheDirectory,
+		"the journal of the result acknowledgment": journal,
+		"the operation state file": stateFile,
+		"the file logging": logging,
+	} {
+		if configured {
+			logError("Cannot enable the in-memory only mode, " + feature + " would write to disk (disable it first)", nil)
+			return result(resultInvalidArgument)
+		}
+	}
+
+	atomic.StoreInt32(&inMemoryOnly, 1)
+	return result(resultOK)
+}
+
+
+// forbidsDiskWrites reports (and logs) whether the in-memory only mode forbids the feature to write to disk.
+func forbidsDiskWrites(feature string) (bool) {
+	if atomic.LoadInt32(&inMemoryOnly) == 0 {
+		return false
+	}
+	logError(feature + " would write to disk, which the in-memory only mode forbids", nil)
+	return true
+}
+
+
+// localDataFiles lists the files written by the library (without the spool of an offline session, which is still written).
+func localDataFiles() ([]localFile) {
+	speakerMutex.Lock()
+		transcriptDirectory := transcriptFilesDirectory
+	speakerMutex.Unlock()
+	sendMutex.Lock()
+		spoolDirectory := backfillDirectory
+		activeSpool := ""
+		if spool != nil {
+			activeSpool = spool.path
+		}
+	sendMutex.Unlock()
+	cacheMutex.Lock()
+		cacheDirectory := ""
+		if local, ok := cache.(*directoryCache); ok {
+			cacheDirectory = local.directory
+		}
+	cacheMutex.Unlock()
+
+	var files []localFile
+	files = appendLocalFiles(files, transcriptDirectory, transcriptFilePattern, "")
+	files = appendLocalFiles(files, spoolDirectory, spoolFilePattern, activeSpool)
+	files = appendLocalFiles(files, cacheDirectory, cacheFilePattern, "")
+	return files
+}
+
+
+// appendLocalFiles appends the files of the directory matching the pattern (except the excluded path).
+func appendLocalFiles(files []localFile, directory string, pattern *regexp.Regexp, excluded string) ([]localFile) {
+	if directory == "" {
+		return files
+	}
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return files
+	}
+	for _, entry := range entries {
+		path := filepath.Join(directory, entry.Name())
+		if entry.IsDir() || pattern.MatchString(entry.Name()) == false || path == excluded {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, localFile{path: path, size: info.Size(), modified: info.ModTime()})
+	}
+	return files
+}
+
+
+// enforceRetention deletes the files exceeding the retention policy (the oldest first).
+func enforceRetention() {
+	retentionMutex.Lock()
+		maxAge := retentionMaxAge
+		maxBytes := retentionMaxBytes
+	retentionMutex.Unlock()
+
+	if maxAge == 0 && maxBytes == 0 {
+		return
+	}
+
+	files := localDataFiles()
+	sort.Slice(files, func(i, j int) bool { return files[i].modified.Before(files[j].modified) })
+
+	var total int64
+	for _, file := range files {
+		total += file.size
+	}
+	for _, file := range files {
+		expired := maxAge > 0 && time.Since(file.modified) > maxAge
+		oversize := maxBytes > 0 && total > maxBytes
+		if expired == false && oversize == false {
+			continue
+		}
+		if err := removeLocalFile(file.path); err != nil {
+			logWarning("Could not delete " + file.path + ": " + err.Error())
+			continue
+		}
+		total -= file.size
+	}
+}
+
+
+// removeLocalFile deletes a file written by the library, it's overwritten with zeros before if secure deletion is enabled.
+func removeLocalFile(path string) (error) {
+	retentionMutex.Lock()
+		wipe := secureDelete
+	retentionMutex.Unlock()
+
+	if wipe {
+		if err := wipeFile(path); err != nil {
+			return err
+		}
+	}
+	return os.Remove(path)
+}
+
+
+// wipeFile overwrites the content of the file with zeros and flushes it to the disk.
+func wipeFile(path string) (error) {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	zeros := make([]byte, wipeBlockSize)
+	for remaining := info.Size(); remaining > 0; remaining -= wipeBlockSize {
+		block := zeros
+		if remaining < wipeBlockSize {
+			block = zeros[:remaining]
+		}
+		if _, err := file.Write(block); err != nil {
+			return err
+		}
+	}
+	return file.Sync()
+}
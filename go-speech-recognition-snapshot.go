@@ -0,0 +1,326 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Snapshots of the streaming session for the crash recovery of the host (see "GetSessionSnapshot()"): the session is
+	checkpointed periodically and after every final result (its configuration, the sent audio and the time of the last
+	final result), the host persists the snapshot and after a crash or restart "RestoreSession()" continues
+	the logical session (its ID, its request numbers and its timestamps).
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+
+	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+)
+
+// The version of the snapshot format (a snapshot of another version can't be restored)
+const snapshotVersion = 1
+
+// Default interval of the checkpoints in seconds
+const defaultSnapshotSeconds = 5
+
+// The state of a session needed to continue it (the result of "GetSessionSnapshot()")
+type sessionSnapshot struct {
+	Version int `json:"version"`
+	SessionID string `json:"sessionId"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Requests uint64 `json:"requests"`
+	Config json.RawMessage `json:"config"`
+	AudioSeconds float64 `json:"audioSeconds"`
+	AudioEndTimestampUs int64 `json:"audioEndTimestampUs"`
+	LastFinalTimestampUs int64 `json:"lastFinalTimestampUs"`
+	Taken string `json:"taken"`
+}
+
+// The checkpoint interval in seconds (atomic), 0 only checkpoints after the final results
+var snapshotSeconds int32 = defaultSnapshotSeconds
+
+// The latest checkpoint and the session's timeline (guarded by the snapshotMutex):
+// the timestamp of the end of the last final result (-1 if unknown) and the timestamp "SendAudio()" assigns next
+// in a restored session (-1 to use the time of the call)
+var snapshotMutex = &sync.Mutex{}
+var latestSnapshot []byte
+var lastFinalTimestampUs int64 = -1
+var timelineUs int64 = -1
+var timelineSampleRate int64
+
+
+// resetSnapshotState starts the checkpoints of a new session, a restored session continues the sent audio
+// and the timeline of the snapshot.
+func resetSnapshotState(restored *sessionSnapshot, sampleRate int32) {
+	snapshotMutex.Lock()
+		latestSnapshot = nil
+		lastFinalTimestampUs = -1
+		timelineUs = -1
+		if restored != nil {
+			lastFinalTimestampUs = restored.LastFinalTimestampUs
+			// The audio after the last final result is sent again, so the timeline continues there.
+			timelineUs = restored.LastFinalTimestampUs
+			if timelineUs < 0 {
+				timelineUs = restored.AudioEndTimestampUs
+			}
+			timelineSampleRate = int64(sampleRate)
+		}
+	snapshotMutex.Unlock()
+
+	if restored != nil {
+		billingMutex.Lock()
+			sessionBilledSeconds = restored.AudioSeconds
+		billingMutex.Unlock()
+	}
+}
+
+
+// restoreSessionID continues the ID, the request numbers and the labels of the snapshot's session.
+func restoreSessionID(restored *sessionSnapshot) {
+	useSessionID(restored.SessionID, restored.Requests)
+
+	logMutex.Lock()
+		for key, value := range restored.Labels {
+			sessionLabels[key] = value
+		}
+	logMutex.Unlock()
+}
+
+
+// libraryTimestamp returns the timestamp of audio sent by "SendAudio()": the time of the call,
+// in a restored session the timeline of the snapshot continued by the duration of the audio.
+func libraryTimestamp(samples int64) (int64) {
+	snapshotMutex.Lock()
+	defer snapshotMutex.Unlock()
+
+	if timelineUs < 0 || timelineSampleRate <= 0 {
+		return time.Now().UnixNano() / 1000
+	}
+	timestampUs := timelineUs
+	timelineUs += samples * 1000000 / timelineSampleRate
+	return timestampUs
+}
+
+
+// checkpointPump runs in its own goroutine (started by "InitializeStream()") and checkpoints the session
+// once it started and then periodically.
+func checkpointPump(pumpCtx context.Context) {
+	takeSnapshot()
+
+	seconds := atomic.LoadInt32(&snapshotSeconds)
+	if seconds <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(seconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			noteWakeup()
+			takeSnapshot()
+		case <-pumpCtx.Done():
+			return
+		}
+	}
+}
+
+
+// checkpointFinalResults keeps the end of the response's last final result and checkpoints the session.
+// Only the receive pump may call it.
+func checkpointFinalResults(resp *speechpb.StreamingRecognizeResponse, clock *streamClock) {
+	endUs := int64(-1)
+	for _, res := range resp.Results {
+		if res.IsFinal {
+			endUs = clock.timestamp(res.ResultEndTime)
+		}
+	}
+	if endUs < 0 {
+		return
+	}
+
+	snapshotMutex.Lock()
+		if endUs > lastFinalTimestampUs {
+			lastFinalTimestampUs = endUs
+		}
+	snapshotMutex.Unlock()
+	takeSnapshot()
+}
+
+
+// takeSnapshot checkpoints the running session (see "GetSessionSnapshot()").
+func takeSnapshot() {
+	snapshot := sessionSnapshot{Version: snapshotVersion, AudioEndTimestampUs: -1, Taken: time.Now().Format(time.RFC3339Nano)}
+
+	sendMutex.Lock()
+		if initialized == false {
+			sendMutex.Unlock()
+			return
+		}
+		config, err := (&jsonpb.Marshaler{}).MarshalToString(sessionConfig)
+		streamMutex.Lock()
+			clock := streamClocks[stream]
+		streamMutex.Unlock()
+	sendMutex.Unlock()
+	if err != nil {
+		logError("Could not encode the session's configuration: ", err)
+		return
+	}
+	snapshot.Config = json.RawMessage(config)
+
+	if clock != nil {
+		clock.mutex.Lock()
+			snapshot.AudioEndTimestampUs = clock.timestampAt(clock.sentSamples)
+		clock.mutex.Unlock()
+	}
+
+	logMutex.Lock()
+		snapshot.SessionID = sessionID
+		snapshot.Requests = requestCount
+		snapshot.Labels = copyLabels()
+	logMutex.Unlock()
+
+	billingMutex.Lock()
+		snapshot.AudioSeconds = sessionBilledSeconds
+	billingMutex.Unlock()
+
+	snapshotMutex.Lock()
+	defer snapshotMutex.Unlock()
+	snapshot.LastFinalTimestampUs = lastFinalTimestampUs
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		logError("Could not encode snapshot: ", err)
+		return
+	}
+	latestSnapshot = encoded
+}
+
+
+/*
+	SetSnapshotInterval(cSeconds C.int) (C.int):
+	sets the interval of the session's checkpoints (see "GetSessionSnapshot()"), the session is also checkpointed
+	after every final result,
+	has to be called before "InitializeStream()" to take effect
+
+	Parameter:
+		cSeconds C.int
+			(the interval in seconds (default 5), 0 to checkpoint only after the final results)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetSnapshotInterval
+func SetSnapshotInterval(cSeconds C.int) (C.int) {
+	if cSeconds < 0 {
+		logError("Invalid snapshot interval (must not be negative)", nil)
+		return result(resultInvalidArgument)
+	}
+	atomic.StoreInt32(&snapshotSeconds, int32(cSeconds))
+	return result(resultOK)
+}
+
+
+/*
+	GetSessionSnapshot(output **C.char) (C.int):
+	retrieves the latest checkpoint of the current (or last) session as a JSON string the host can persist
+	(e.g. with its own state), so the session can be continued by "RestoreSession()" after a crash or restart, e.g.:
+	{"version":1,"sessionId":"3f2a...","labels":{"customer":"42"},"requests":3,"config":{"config":{"encoding":"LINEAR16",...}},
+	"audioSeconds":83.2,"audioEndTimestampUs":1559383283200000,"lastFinalTimestampUs":1559383281900000,"taken":"2019-06-01T12:01:23.4+02:00"}
+	(audioSeconds is the audio sent in the session, audioEndTimestampUs the timestamp of the end of the sent audio and
+	lastFinalTimestampUs the end of the last final result (-1 if unknown), the audio after it hasn't been transcribed yet
+	(see "RestoreSession()"), the snapshot contains no credentials and no transcripts)
+
+	Parameters:
+		output:
+			The pointer which is used to store the snapshot
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetSessionSnapshot
+func GetSessionSnapshot(output **C.char) (C.int) {
+	if output == nil {
+		logError("Invalid output pointer", nil)
+		return result(resultInvalidArgument)
+	}
+
+	snapshotMutex.Lock()
+		encoded := latestSnapshot
+	snapshotMutex.Unlock()
+	if encoded == nil {
+		logError("No snapshot has been taken yet", nil)
+		return result(resultNotInitialized)
+	}
+
+	*output = cString(string(encoded))
+	return result(resultOK)
+}
+
+
+/*
+	RestoreSession(cSnapshot *C.char) (C.int):
+	initializes the streaming session like "InitializeStream()", but continues the logical session of a snapshot
+	(see "GetSessionSnapshot()"), e.g. after a crash or restart of the host: the session keeps its ID, its labels and
+	its configuration (including the settings made before it was initialized), its requests are numbered on,
+	the timestamps "SendAudio()" assigns continue at the end of the last final result and the sent audio continues
+	at the snapshot's audioSeconds,
+	the host should send the audio after lastFinalTimestampUs again (its results got lost with the crash),
+	the default credentials are used (the snapshot contains none)
+
+	Parameter:
+		cSnapshot *C.char
+			(the snapshot as retrieved by "GetSessionSnapshot()")
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export RestoreSession
+func RestoreSession(cSnapshot *C.char) (C.int) {
+	restored := &sessionSnapshot{}
+	if err := json.Unmarshal([]byte(C.GoString(cSnapshot)), restored); err != nil {
+		logError("Invalid snapshot: ", err)
+		return result(resultInvalidArgument)
+	}
+	if restored.Version != snapshotVersion {
+		logError("Unsupported snapshot version " + strconv.Itoa(restored.Version) + " (must be " + strconv.Itoa(snapshotVersion) + ")", nil)
+		return result(resultInvalidArgument)
+	}
+	if restored.SessionID == "" {
+		logError("Invalid snapshot (the session ID is missing)", nil)
+		return result(resultInvalidArgument)
+	}
+
+	config := &speechpb.StreamingRecognitionConfig{}
+	if err := jsonpb.UnmarshalString(string(restored.Config), config); err != nil {
+		logError("Invalid snapshot (the configuration can't be decoded): ", err)
+		return result(resultInvalidArgument)
+	}
+	if config.Config == nil {
+		logError("Invalid snapshot (the configuration is missing)", nil)
+		return result(resultInvalidArgument)
+	}
+
+	span := startSpan("InitializeStream")
+	code := startSession(config, nil, restored)
+	endSpan(span, code)
+	return result(code)
+}
@@ -0,0 +1,92 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Speech events of google with their timestamps (see "PollSpeechEvent()"): the end of an utterance in single utterance mode
+	and, if enabled (see "SetVoiceActivityEvents()"), the begin and the end of speech, so hosts can measure the
+	utterance boundaries precisely (the results only tell when a result ended).
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+)
+
+// A speech event (the speech event type of google) and its timestamp on the host's clock in microseconds (-1 if unknown)
+type speechEvent struct {
+	event int32
+	timestampUs int64
+}
+const speechEventQueueSize = 64
+var speechEventQueue = make(chan speechEvent, speechEventQueueSize)
+
+// Whether google reports the begin and the end of speech (guarded by the sendMutex)
+var voiceActivityEvents = false
+
+
+// reportSpeechEvent queues the speech event of the response for "PollSpeechEvent()"
+// (if the host doesn't poll the events, new ones get dropped).
+// Only the receive pump may call it.
+func reportSpeechEvent(resp *speechpb.StreamingRecognizeResponse, clock *streamClock) {
+	if resp.SpeechEventType == speechpb.StreamingRecognizeResponse_SPEECH_EVENT_UNSPECIFIED {
+		return
+	}
+	select {
+	case speechEventQueue <- speechEvent{event: int32(resp.SpeechEventType), timestampUs: clock.timestamp(resp.SpeechEventTime)}:
+	default:
+	}
+}
+
+
+/*
+	SetVoiceActivityEvents(cEnabled C.int):
+	lets google report the begin and the end of speech (see "PollSpeechEvent()"),
+	has to be called before "InitializeStream()" or "Reconfigure()" to take effect
+
+	Parameter:
+		cEnabled C.int
+			(1 to enable, 0 to disable the voice activity events (default))
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetVoiceActivityEvents
+func SetVoiceActivityEvents(cEnabled C.int) () {
+	sendMutex.Lock()
+		voiceActivityEvents = int32(cEnabled) == int32(1)
+	sendMutex.Unlock()
+}
+
+
+/*
+	PollSpeechEvent(event *C.int, timestampUs *C.longlong) (C.int):
+	retrieves the next speech event reported by google (doesn't block)
+
+	Parameters:
+		event:
+			The pointer which is used to store the event (see GO_SPEECH_RECOGNITION_SPEECH_EVENT in the header)
+		timestampUs:
+			The pointer which is used to store the time of the event in microseconds of the host's clock
+			(see "SendAudioWithTimestamp()"), -1 if unknown
+
+	Return:
+		1 if an event has been retrieved
+		0 if no event is pending
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export PollSpeechEvent
+func PollSpeechEvent(event *C.int, timestampUs *C.longlong) (C.int) {
+	select {
+	case next := <-speechEventQueue:
+		*event = C.int(next.event)
+		*timestampUs = C.longlong(next.timestampUs)
+		return C.int(1)
+	default:
+		return C.int(0)
+	}
+}
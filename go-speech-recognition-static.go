@@ -0,0 +1,30 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Support for linking the library statically (built with "go build -buildmode=c-archive", e.g. for iOS,
+	which doesn't allow apps to load their own dynamic libraries): without DllMain or the loader of a shared library
+	the host initializes the library with "InitializeLibrary()" and tears it down with "Shutdown()".
+*/
+
+package main
+
+/*
+extern void goSpeechRecognitionLinkUnload();
+*/
+import "C" // Needed to feature cgo compatibility
+
+
+/*
+	InitializeLibrary ():
+	prepares the library, needed for static links (the shared library doesn't need it, but it doesn't hurt):
+	returns once the Go runtime has started (so its startup doesn't delay the first session)
+	and links the teardown calling "Shutdown()" when the process exits,
+	the host should still call "Shutdown()" itself when it terminates (e.g. in applicationWillTerminate on iOS)
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export InitializeLibrary
+func InitializeLibrary () () {
+	// The Go runtime is started by a constructor of the archive, the exports wait until it's ready.
+	C.goSpeechRecognitionLinkUnload()
+}
@@ -0,0 +1,44 @@
+//go:build cgo && !windows
+
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The system log on Linux and macOS: syslog (see "EnableSystemLog()").
+*/
+
+package main
+
+import (
+	"log/syslog"
+)
+
+// The system log of the platform
+const nativeSystemLog = systemLogSyslog
+
+// A syslog connection
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+
+// openSystemLog connects to the local syslog daemon, the entries are tagged with the source.
+func openSystemLog(source string) (systemLogSink, error) {
+	writer, err := syslog.New(syslog.LOG_USER | syslog.LOG_WARNING, source)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{writer: writer}, nil
+}
+
+
+func (sink *syslogSink) write(level string, message string) (error) {
+	if level == logLevelError {
+		return sink.writer.Err(message)
+	}
+	return sink.writer.Warning(message)
+}
+
+
+func (sink *syslogSink) close() (error) {
+	return sink.writer.Close()
+}
@@ -0,0 +1,54 @@
+//go:build cgo && windows
+
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The system log on Windows: the Windows Event Log (see "EnableSystemLog()").
+*/
+
+package main
+
+import (
+	// Event Log package (download with "go get -u golang.org/x/sys/windows/svc/eventlog"):
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// The system log of the platform
+const nativeSystemLog = systemLogEventLog
+
+// The event IDs of the entries
+const (
+	eventIDError = 1
+	eventIDWarning = 2
+)
+
+// A source of the Application log
+type eventLogSink struct {
+	log *eventlog.Log
+}
+
+
+// openSystemLog opens the source of the Application log, registering it first if it doesn't exist yet
+// (which needs administrator rights, an unregistered source still logs, but without the message file).
+func openSystemLog(source string) (systemLogSink, error) {
+	eventlog.InstallAsEventCreate(source, eventlog.Error | eventlog.Warning)
+
+	log, err := eventlog.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	return &eventLogSink{log: log}, nil
+}
+
+
+func (sink *eventLogSink) write(level string, message string) (error) {
+	if level == logLevelError {
+		return sink.log.Error(eventIDError, message)
+	}
+	return sink.log.Warning(eventIDWarning, message)
+}
+
+
+func (sink *eventLogSink) close() (error) {
+	return sink.log.Close()
+}
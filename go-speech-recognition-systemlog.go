@@ -0,0 +1,123 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Forwards the logged errors and warnings to the log of the operating system (see "EnableSystemLog()"):
+	the Windows Event Log or syslog (which journald collects on systemd systems), so operations teams can aggregate
+	the library's errors with the rest of the system's logs.
+	The sinks of the platforms are implemented in go-speech-recognition-systemlog-windows.go
+	and go-speech-recognition-systemlog-unix.go.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"strconv"
+	"time"
+)
+
+// The system logs (see GO_SPEECH_RECOGNITION_SYSTEM_LOG in the header)
+const (
+	systemLogNone = 0
+	systemLogEventLog = 1
+	systemLogSyslog = 2
+)
+
+// The source (resp. tag) of the entries, if the host doesn't name one
+const defaultSystemLogSource = "go-speech-recognition"
+
+// A log of the operating system
+type systemLogSink interface {
+	// write adds the message with the level (see logLevelError and logLevelWarning).
+	write(level string, message string) (error)
+	close() (error)
+}
+
+// The system log the entries are forwarded to (guarded by the logMutex), nil if disabled
+var systemLog systemLogSink
+
+
+// writeSystemLog forwards the entry to the system log (if enabled), a failing system log gets disabled.
+// The caller has to hold the logMutex.
+func writeSystemLog(entry logEntry) {
+	if systemLog == nil {
+		return
+	}
+	message := entry.Message
+	if entry.SessionID != "" {
+		message = "[session " + entry.SessionID + ", request " + entry.RequestID + formatLabels(", ") + "] " + message
+	}
+
+	if err := systemLog.write(entry.Level, message); err != nil {
+		// The entry about the failure isn't forwarded anymore.
+		closeSystemLog()
+		addLogEntry(logLevelError, "Could not write system log, it is disabled: " + err.Error(), time.Now())
+	}
+}
+
+
+// closeSystemLog closes the system log and disables the forwarding.
+// The caller has to hold the logMutex.
+func closeSystemLog() {
+	if systemLog != nil {
+		systemLog.close()
+	}
+	systemLog = nil
+}
+
+
+/*
+	EnableSystemLog(cSystemLog C.int, cSource *C.char) (C.int):
+	forwards the logged errors and warnings (with the correlation IDs of their session) to the log of the operating system,
+	the Windows Event Log (the "Application" log, the source is registered on the first use, which needs administrator rights once,
+	e.g. by the installer) or syslog (journald collects it on systemd systems)
+
+	Parameters:
+		cSystemLog C.int
+			(0 to disable the forwarding (default), 1 for the Windows Event Log (Windows only), 2 for syslog (Linux and macOS only))
+		cSource *C.char
+			(the source of the entries in the Event Log resp. the tag in syslog as a C string, empty for "go-speech-recognition")
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export EnableSystemLog
+func EnableSystemLog(cSystemLog C.int, cSource *C.char) (C.int) {
+	if cSystemLog == systemLogNone {
+		logMutex.Lock()
+			closeSystemLog()
+		logMutex.Unlock()
+		return result(resultOK)
+	}
+	if cSystemLog != systemLogEventLog && cSystemLog != systemLogSyslog {
+		logError("Unknown system log " + strconv.Itoa(int(cSystemLog)), nil)
+		return result(resultInvalidArgument)
+	}
+	if cSystemLog != nativeSystemLog {
+		logError("The system log " + strconv.Itoa(int(cSystemLog)) + " isn't available on this platform", nil)
+		return result(resultInvalidArgument)
+	}
+
+	source := C.GoString(cSource)
+	if source == "" {
+		source = defaultSystemLogSource
+	}
+	sink, err := openSystemLog(source)
+	if err != nil {
+		logError("Could not open system log: ", err)
+		return result(resultError)
+	}
+
+	logMutex.Lock()
+		closeSystemLog()
+		systemLog = sink
+	logMutex.Unlock()
+	return result(resultOK)
+}
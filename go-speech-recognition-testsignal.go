@@ -0,0 +1,194 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Synthetic test signals (see "GenerateTestSignal()" and "StreamTestSignal()"): a sine tone and a sequence of
+	DTMF digits generated in the library, so integrators can verify the audio path, the session and the credentials
+	without any recording or external file. Google doesn't transcribe tones, the test succeeds when the stream
+	accepts the audio without an error (the level meter and the billed audio show that it arrived).
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"math"
+	"strconv"
+	"time"
+	"unsafe"
+)
+
+// The test signals (see GO_SPEECH_RECOGNITION_TEST_SIGNAL in the header)
+const (
+	testSignalTone = 0
+	testSignalDigits = 1
+)
+
+// The tone's frequency and the amplitude of the signals (-12 dBFS, clear of the clipping detection)
+const testToneHz = 1000.0
+const testSignalAmplitude = 0.25 * math.MaxInt16
+
+// A DTMF digit sounds for digitToneMs and is followed by digitPauseMs of silence
+const digitToneMs = 200
+const digitPauseMs = 100
+
+// The digits of the sequence (repeated) and the frequencies of the DTMF keypad
+const testDigits = "1234567890"
+var dtmfRows = [4]float64{697, 770, 852, 941}
+var dtmfColumns = [3]float64{1209, 1336, 1477}
+
+// The maximum duration of "StreamTestSignal()"
+const maxTestSignalMs = 60000
+
+
+// dtmfFrequencies returns the row and column frequency of a digit.
+func dtmfFrequencies(digit byte) (float64, float64) {
+	if digit == '0' {
+		return dtmfRows[3], dtmfColumns[1]
+	}
+	key := int(digit - '1')
+	return dtmfRows[key / 3], dtmfColumns[key % 3]
+}
+
+
+// testSignalSample returns the sample of the signal at the position (in samples from its start).
+func testSignalSample(signal int, position int64, sampleRate int64) (C.short) {
+	seconds := float64(position) / float64(sampleRate)
+	switch signal {
+	case testSignalDigits:
+		msPerDigit := int64(digitToneMs + digitPauseMs)
+		ms := position * 1000 / sampleRate
+		if ms % msPerDigit >= digitToneMs {
+			return 0
+		}
+		row, column := dtmfFrequencies(testDigits[(ms / msPerDigit) % int64(len(testDigits))])
+		return C.short(testSignalAmplitude / 2 * (math.Sin(2 * math.Pi * row * seconds) + math.Sin(2 * math.Pi * column * seconds)))
+	default:
+		return C.short(testSignalAmplitude * math.Sin(2 * math.Pi * testToneHz * seconds))
+	}
+}
+
+
+// fillTestSignal writes the signal from the position on into the samples.
+func fillTestSignal(samples []C.short, signal int, position int64, sampleRate int64) {
+	for i := range samples {
+		samples[i] = testSignalSample(signal, position + int64(i), sampleRate)
+	}
+}
+
+
+// checkTestSignal validates the signal and the sample rate.
+func checkTestSignal(signal C.int, sampleRate int64) (C.int) {
+	if signal != testSignalTone && signal != testSignalDigits {
+		logError("Unknown test signal " + strconv.Itoa(int(signal)), nil)
+		return resultInvalidArgument
+	}
+	if sampleRate < 8000 || sampleRate > 48000 {
+		logError("Invalid sample rate (must be 8000 - 48000 Hz)", nil)
+		return resultInvalidArgument
+	}
+	return resultOK
+}
+
+
+/*
+	GenerateTestSignal(cSignal C.int, cSampleRate C.int, buffer *C.short, bufferLength C.int) (C.int):
+	fills the buffer with a synthetic test signal (16 bit PCM, mono), e.g. to feed it through the host's own audio path
+	into "SendAudio()"
+
+	Parameters:
+		cSignal C.int
+			(0 for a sine tone of 1 kHz, 1 for a sequence of DTMF digits ("1234567890", 200 ms each with 100 ms pauses))
+		cSampleRate C.int
+			(the sample rate of the signal (8000 - 48000 Hz))
+		buffer:
+			The pointer to the buffer which is used to store the samples
+		bufferLength C.int
+			(the number of samples to generate)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GenerateTestSignal
+func GenerateTestSignal(cSignal C.int, cSampleRate C.int, buffer *C.short, bufferLength C.int) (C.int) {
+	if bufferLength < 0 || buffer == nil && bufferLength > 0 {
+		logError("Invalid output buffer", nil)
+		return result(resultInvalidArgument)
+	}
+	if code := checkTestSignal(cSignal, int64(cSampleRate)); code != resultOK {
+		return result(code)
+	}
+
+	fillTestSignal(unsafe.Slice(buffer, int(bufferLength)), int(cSignal), 0, int64(cSampleRate))
+	return result(resultOK)
+}
+
+
+/*
+	StreamTestSignal(cSignal C.int, cDurationMs C.int) (C.int):
+	streams a synthetic test signal (see "GenerateTestSignal()") at the session's sample rate through the full pipeline
+	like "SendAudio()" (in real time like a live capture), so the audio path and the credentials can be verified
+	without a microphone or a file, tones aren't transcribed (no results are expected), the call blocks until
+	the signal has been sent
+
+	Parameters:
+		cSignal C.int
+			(the same as "GenerateTestSignal()")
+		cDurationMs C.int
+			(the duration of the signal in milliseconds (1 - 60000))
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export StreamTestSignal
+func StreamTestSignal(cSignal C.int, cDurationMs C.int) (C.int) {
+	if code := checkCallbackThread("StreamTestSignal"); code != resultOK {
+		return result(code)
+	}
+	if cDurationMs < 1 || cDurationMs > maxTestSignalMs {
+		logError("Invalid duration (must be 1 - " + strconv.Itoa(maxTestSignalMs) + " ms)", nil)
+		return result(resultInvalidArgument)
+	}
+
+	sendMutex.Lock()
+		if initialized == false {
+			sendMutex.Unlock()
+			logError("Stream is not initialized", nil)
+			return result(resultNotInitialized)
+		}
+		sampleRate := int64(sessionConfig.Config.SampleRateHertz)
+	sendMutex.Unlock()
+
+	if code := checkTestSignal(cSignal, sampleRate); code != resultOK {
+		return result(code)
+	}
+
+	total := int64(cDurationMs) * sampleRate / 1000
+	chunkSamples := int64(maxChunkMs) * sampleRate / 1000
+	chunk := make([]C.short, chunkSamples)
+	start := time.Now()
+	for position := int64(0); position < total; position += chunkSamples {
+		// The chunks are paced like a live capture.
+		time.Sleep(time.Until(start.Add(time.Duration(position * int64(time.Second) / sampleRate))))
+
+		count := min(chunkSamples, total - position)
+		fillTestSignal(chunk[:count], int(cSignal), position, sampleRate)
+
+		span := startSpan("SendAudio")
+		code := sendAudio(&chunk[0], C.int(count), libraryTimestamp(count))
+		endSpan(span, code)
+		if code != resultOK {
+			return result(code)
+		}
+	}
+	return result(resultOK)
+}
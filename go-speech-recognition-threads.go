@@ -0,0 +1,73 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The thread-safety contract of the exports, checked instead of deadlocking or interleaving:
+	- all exports may be called from any thread and concurrently, except:
+	- one receive call at a time ("ReceiveTranscript()", "ReceiveTranscriptJSON()", "ReceiveAlternatives()" and "ReceiveAllPending()"),
+	  a concurrent call fails with resultBusy (the results would be split arbitrarily between the threads)
+	- one "PumpCallbacks()" at a time, a concurrent or nested call fails with resultBusy
+	- callbacks invoked immediately (on the library's threads or in "SendAudio()", see "SetCallbackDispatch()") run
+	  while the library waits for them: the calls initializing or reconfiguring the session and the sending and receiving calls
+	  fail with resultWrongThread, "CloseStream()" closes the session once the callback returned and "Shutdown()" is ignored
+	  (the queued callbacks of "PumpCallbacks()" may call everything)
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"sync/atomic"
+)
+
+// Set while a receive call or "PumpCallbacks()" is in progress
+var receiving int32
+var pumping int32
+
+
+// checkCallbackThread fails with resultWrongThread if a callback invoked immediately makes the call,
+// which would wait for the library (which waits for the callback).
+func checkCallbackThread(call string) (C.int) {
+	if insideCallback() {
+		logError("\"" + call + "()\" can't be called by a callback invoked immediately (see \"SetCallbackDispatch()\")", nil)
+		return resultWrongThread
+	}
+	return resultOK
+}
+
+
+// beginReceive fails with resultBusy if another receive call is in progress, otherwise endReceive has to follow.
+func beginReceive() (C.int) {
+	if !atomic.CompareAndSwapInt32(&receiving, 0, 1) {
+		logError("Another receive call is in progress", nil)
+		return resultBusy
+	}
+	return resultOK
+}
+
+func endReceive() {
+	atomic.StoreInt32(&receiving, 0)
+}
+
+
+// closeStreamLater closes the session once the callback calling "CloseStream()" returned,
+// unless the session has been replaced meanwhile.
+func closeStreamLater() {
+	sendMutex.Lock()
+		generation := sessionGeneration
+	sendMutex.Unlock()
+	logWarning("\"CloseStream()\" has been called by a callback, the session is closed once the callback returned")
+
+	go func() {
+		lifecycleMutex.Lock()
+		defer lifecycleMutex.Unlock()
+
+		if sessionGeneration == generation {
+			closeStream()
+			enforceRetention()
+		}
+	}()
+}
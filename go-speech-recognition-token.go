@@ -0,0 +1,127 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Authenticates the session with an OAuth access token of the host (see "CreateSessionWithAccessToken()"),
+	e.g. the token of the user's Google sign-in in a mobile app, which can't ship a service account key.
+	The library never refreshes the token itself, the host sets a new one before it expires (see "SetAccessToken()"),
+	every request to google (including the reconnects of the session) uses the current token.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/option"
+)
+
+// The access token set by the host (guarded by the tokenMutex), nil if there's none
+var tokenMutex = &sync.Mutex{}
+var accessToken *oauth2.Token
+
+// hostTokenSource passes the current access token of the host to the client.
+type hostTokenSource struct{}
+
+func (hostTokenSource) Token() (*oauth2.Token, error) {
+	tokenMutex.Lock()
+	defer tokenMutex.Unlock()
+	if accessToken == nil {
+		return nil, errors.New("No access token has been set (see \"SetAccessToken()\")")
+	}
+	if !accessToken.Valid() {
+		return nil, errors.New("The access token expired, the host has to set a new one (see \"SetAccessToken()\")")
+	}
+	token := *accessToken
+	return &token, nil
+}
+
+// setAccessToken replaces the access token of the host, an expiry of 0 means the host doesn't know it.
+func setAccessToken(cAccessToken *C.char, cExpiresInSeconds C.int) (C.int) {
+	if C.GoString(cAccessToken) == "" {
+		logError("Invalid access token (must not be empty)", nil)
+		return resultInvalidArgument
+	}
+	if cExpiresInSeconds < 0 {
+		logError("Invalid expiry of the access token (must not be negative)", nil)
+		return resultInvalidArgument
+	}
+
+	token := &oauth2.Token{AccessToken: C.GoString(cAccessToken), TokenType: "Bearer"}
+	if cExpiresInSeconds > 0 {
+		token.Expiry = time.Now().Add(time.Duration(cExpiresInSeconds) * time.Second)
+	}
+	tokenMutex.Lock()
+		accessToken = token
+	tokenMutex.Unlock()
+	return resultOK
+}
+
+
+/*
+	CreateSessionWithAccessToken(cAccessToken *C.char, cExpiresInSeconds C.int, cQuotaProject *C.char, cTranscriptLanguage *C.char, cSampleRate C.int, cTranscriptionModel *C.char, cMaxAlternatives C.int, cInterimResults C.int) (C.int):
+	initializes the streaming session like "InitializeStream()", but authenticated with an OAuth access token of the host
+	(e.g. of the user's Google sign-in, with the scope "https://www.googleapis.com/auth/cloud-platform")
+	instead of the default credentials of the environment, the token has to be replaced with "SetAccessToken()" before it expires
+
+	Parameters:
+		cAccessToken *C.char
+			(the access token)
+		cExpiresInSeconds C.int
+			(the seconds until the token expires, 0 if unknown)
+		cQuotaProject *C.char
+			(the project the usage is billed to, empty for the project of the token)
+		the others:
+			the same as "InitializeStream()"
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export CreateSessionWithAccessToken
+func CreateSessionWithAccessToken(cAccessToken *C.char, cExpiresInSeconds C.int, cQuotaProject *C.char, cTranscriptLanguage *C.char, cSampleRate C.int, cTranscriptionModel *C.char, cMaxAlternatives C.int, cInterimResults C.int) (C.int) {
+	if code := setAccessToken(cAccessToken, cExpiresInSeconds); code != resultOK {
+		return result(code)
+	}
+
+	span := startSpan("InitializeStream")
+	options := []option.ClientOption{option.WithTokenSource(hostTokenSource{})}
+	if quotaProject := C.GoString(cQuotaProject); quotaProject != "" {
+		options = append(options, option.WithQuotaProject(quotaProject))
+	}
+	code := initializeStream(cTranscriptLanguage, cSampleRate, cTranscriptionModel, cMaxAlternatives, cInterimResults, options)
+	endSpan(span, code)
+	return result(code)
+}
+
+
+/*
+	SetAccessToken(cAccessToken *C.char, cExpiresInSeconds C.int) (C.int):
+	replaces the access token of a session created with "CreateSessionWithAccessToken()" (e.g. after the host refreshed the sign-in),
+	the next request to google uses the new token, the running stream keeps its connection
+
+	Parameters:
+		cAccessToken *C.char
+			(the access token)
+		cExpiresInSeconds C.int
+			(the seconds until the token expires, 0 if unknown)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetAccessToken
+func SetAccessToken(cAccessToken *C.char, cExpiresInSeconds C.int) (C.int) {
+	return result(setAccessToken(cAccessToken, cExpiresInSeconds))
+}
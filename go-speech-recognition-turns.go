@@ -0,0 +1,224 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The turn-taking of a simple voice assistant (see "EnableTurnTaking()"): the library listens to the user until google's
+	voice activity detection ends the utterance (single utterance mode), then it mutes the recognition while the host
+	thinks about the answer and speaks it (e.g. with text-to-speech), and listens again once the host's turn ended
+	(after the echo window, see "MuteRecognition()"). Every change of the state is passed to the turn callback:
+
+		LISTENING --(final result of the user)--> THINKING --(host)--> SPEAKING --(host)--> LISTENING
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+)
+
+// The states of the turns (see GO_SPEECH_RECOGNITION_TURN_STATE in the header)
+const (
+	turnIdle int32 = 0
+	turnListening int32 = 1
+	turnThinking int32 = 2
+	turnSpeaking int32 = 3
+)
+
+// The turn-taking (guarded by the turnMutex): the current state (turnIdle if disabled), the echo window applied
+// when listening again after speaking and the turn callback, nil if not set
+var turnMutex = &sync.Mutex{}
+var turnState = turnIdle
+var turnEchoWindow time.Duration
+var turnCallback unsafe.Pointer
+var turnUserData unsafe.Pointer
+
+
+// changeTurn sets the state of the turns, mutes resp. unmutes the recognition and invokes the turn callback.
+func changeTurn(state int32, transcript string) {
+	turnMutex.Lock()
+		previous := turnState
+		turnState = state
+		echoWindow := turnEchoWindow
+		callback, userData := turnCallback, turnUserData
+	turnMutex.Unlock()
+
+	if state == previous {
+		return
+	}
+	// The recognition only hears the user while listening, the echo window drops the tail of the host's answer.
+	if state == turnListening || state == turnIdle {
+		if previous != turnSpeaking {
+			echoWindow = 0
+		}
+		muteRecognition(false, echoWindow)
+	} else {
+		muteRecognition(true, 0)
+	}
+
+	if callback != nil {
+		callTurnCallback(callback, userData, state, transcript)
+	}
+}
+
+
+// advanceTurn ends the user's turn with the response's final result (while listening).
+// Only the receive pump may call it.
+func advanceTurn(resp *speechpb.StreamingRecognizeResponse) {
+	turnMutex.Lock()
+		listening := turnState == turnListening
+	turnMutex.Unlock()
+	if listening == false {
+		return
+	}
+
+	var transcript []string
+	for _, result := range resp.Results {
+		if result.IsFinal && len(result.Alternatives) > 0 {
+			transcript = append(transcript, strings.TrimSpace(result.Alternatives[0].Transcript))
+		}
+	}
+	if len(transcript) == 0 {
+		return
+	}
+	changeTurn(turnThinking, strings.Join(transcript, " "))
+}
+
+
+/*
+	EnableTurnTaking(cEnabled C.int, cEchoWindowMs C.int) (C.int):
+	enables the turn-taking of a voice assistant: the library starts listening to the user (LISTENING), the first final result
+	ends the user's turn and mutes the recognition (THINKING, the turn callback gets the user's transcript), the host then speaks
+	its answer (SPEAKING) and listens again (LISTENING) by "SetTurnState()", the recognition is unmuted after the echo window,
+	enabling also enables the single utterance mode (see "SetSingleUtterance()"), so google's voice activity detection ends
+	the user's utterance (takes effect with the next "InitializeStream()" or "Reconfigure()"),
+	disabling unmutes the recognition and leaves the single utterance mode as it is
+
+	Parameters:
+		cEnabled C.int
+			(1 to enable, 0 to disable the turn-taking (default))
+		cEchoWindowMs C.int
+			(the recognition stays muted for this many milliseconds (0 - 5000) after speaking to drop the tail of the answer
+			(see "MuteRecognition()"), e.g. 300)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export EnableTurnTaking
+func EnableTurnTaking(cEnabled C.int, cEchoWindowMs C.int) (C.int) {
+	if cEchoWindowMs < 0 || cEchoWindowMs > maxEchoWindowMs {
+		logError("Invalid echo window (must be 0 - " + strconv.Itoa(maxEchoWindowMs) + " ms)", nil)
+		return result(resultInvalidArgument)
+	}
+
+	turnMutex.Lock()
+		turnEchoWindow = time.Duration(cEchoWindowMs) * time.Millisecond
+	turnMutex.Unlock()
+
+	if int32(cEnabled) != int32(1) {
+		changeTurn(turnIdle, "")
+		return result(resultOK)
+	}
+	SetSingleUtterance(C.int(1))
+
+	turnMutex.Lock()
+		enabled := turnState != turnIdle
+	turnMutex.Unlock()
+	if enabled == false {
+		changeTurn(turnListening, "")
+	}
+	return result(resultOK)
+}
+
+
+/*
+	SetTurnState(cState C.int) (C.int):
+	changes the state of the turns when the host's turn progresses: SPEAKING when it starts to speak its answer,
+	LISTENING when it finished (the recognition is unmuted after the echo window) or when it doesn't answer at all,
+	THINKING to mute the recognition without answering (e.g. while a dialog is shown),
+	the turn callback is invoked with the new state
+
+	Parameter:
+		cState C.int
+			(the new state, see GO_SPEECH_RECOGNITION_TURN_STATE in the header (not IDLE, see "EnableTurnTaking()"))
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetTurnState
+func SetTurnState(cState C.int) (C.int) {
+	state := int32(cState)
+	if state != turnListening && state != turnThinking && state != turnSpeaking {
+		logError("Invalid turn state", nil)
+		return result(resultInvalidArgument)
+	}
+
+	turnMutex.Lock()
+		enabled := turnState != turnIdle
+	turnMutex.Unlock()
+	if enabled == false {
+		logError("Turn-taking is not enabled (see \"EnableTurnTaking()\")", nil)
+		return result(resultInvalidArgument)
+	}
+
+	changeTurn(state, "")
+	return result(resultOK)
+}
+
+
+/*
+	GetTurnState () (C.int):
+	returns the current state of the turns
+
+	Return:
+		the state (see GO_SPEECH_RECOGNITION_TURN_STATE in the header), IDLE if the turn-taking is disabled
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetTurnState
+func GetTurnState () (C.int) {
+	turnMutex.Lock()
+	defer turnMutex.Unlock()
+
+	return C.int(turnState)
+}
+
+
+/*
+	SetTurnCallback(cCallback unsafe.Pointer, cUserData unsafe.Pointer):
+	registers a callback, which is invoked whenever the state of the turns changes (see "EnableTurnTaking()"),
+	by the library's threads (the end of the user's turn) or by the calls changing the state on the host's thread,
+	unless the callbacks are queued (see "SetCallbackDispatch()"), the transcript is only valid during the call
+
+	Parameter:
+		cCallback unsafe.Pointer
+			(the callback as a C function pointer "void callback(int state, char* transcript, void* userData)",
+			the transcript is the user's utterance when the state changes to THINKING, otherwise empty,
+			NULL removes the callback)
+		cUserData unsafe.Pointer
+			(passed to the callback unchanged, e.g. a pointer to the host's object)
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetTurnCallback
+func SetTurnCallback(cCallback unsafe.Pointer, cUserData unsafe.Pointer) () {
+	turnMutex.Lock()
+		turnCallback = cCallback
+		turnUserData = cUserData
+	turnMutex.Unlock()
+}
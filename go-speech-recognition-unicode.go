@@ -0,0 +1,83 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The encoding of the library's outputs: every string passed to the host is valid UTF-8 (invalid bytes, e.g. of a host's
+	path in its local code page echoed by the log, are replaced by U+FFFD) and the transcripts are normalized to the
+	Unicode normalization form chosen by the host (see "SetUnicodeNormalization()"), since google may deliver accented
+	characters composed or decomposed and some hosts (e.g. older MFC applications or file systems) can't handle the mix.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// The Unicode normalization forms (see GO_SPEECH_RECOGNITION_NORMALIZATION in the header)
+const (
+	normalizationNone int32 = 0
+	normalizationNFC int32 = 1
+	normalizationNFD int32 = 2
+)
+
+// The normalization form of the transcripts
+var unicodeNormalization = normalizationNone
+
+
+// cString converts the string into a C string passed to the host, invalid UTF-8 is replaced.
+func cString(text string) (*C.char) {
+	return C.CString(strings.ToValidUTF8(text, "\uFFFD"))
+}
+
+
+// normalizeTranscript makes the transcript valid UTF-8 and normalizes it (see "SetUnicodeNormalization()").
+func normalizeTranscript(transcript string) (string) {
+	transcript = strings.ToValidUTF8(transcript, "\uFFFD")
+
+	switch atomic.LoadInt32(&unicodeNormalization) {
+	case normalizationNFC:
+		return norm.NFC.String(transcript)
+	case normalizationNFD:
+		return norm.NFD.String(transcript)
+	}
+	return transcript
+}
+
+
+/*
+	SetUnicodeNormalization(cForm C.int) (C.int):
+	normalizes the transcripts (and their words) to a Unicode normalization form before delivery, e.g. "é" is
+	one code point (U+00E9) in NFC and two (U+0065 U+0301) in NFD, the normalization is applied after the formatting
+	and the replacement dictionary (see "AddReplacement()"), all strings passed to the host are valid UTF-8 anyway
+
+	Parameter:
+		cForm C.int
+			(the normalization form (see GO_SPEECH_RECOGNITION_NORMALIZATION in the header):
+			0 to keep the transcripts as delivered by google (default), 1 for NFC (composed, e.g. for Windows and Linux),
+			2 for NFD (decomposed, e.g. like the file names of macOS's HFS+))
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetUnicodeNormalization
+func SetUnicodeNormalization(cForm C.int) (C.int) {
+	form := int32(cForm)
+	if form != normalizationNone && form != normalizationNFC && form != normalizationNFD {
+		logError("Invalid Unicode normalization form", nil)
+		return result(resultInvalidArgument)
+	}
+
+	atomic.StoreInt32(&unicodeNormalization, form)
+	return result(resultOK)
+}
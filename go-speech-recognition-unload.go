@@ -0,0 +1,59 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Cleans the library up, when the host unloads it or exits without calling "CloseStream()",
+	otherwise the goroutines and the gRPC connection linger and can crash on teardown.
+	(cgo only allows C definitions in files without exported functions, so this needs its own file.)
+*/
+
+package main
+
+/*
+extern void unloadLibrary();
+
+// Runs when the library is unloaded (on Windows when the DLL gets detached from the process).
+__attribute__((destructor)) static void goSpeechRecognitionUnload() {
+	unloadLibrary();
+}
+
+// Referenced by "InitializeLibrary()": a static link only includes the object file of the destructor
+// if one of its symbols is used (see go-speech-recognition-static.go).
+void goSpeechRecognitionLinkUnload() {
+}
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"sync"
+)
+
+
+// libraryIdle reports whether the library holds nothing to release (see "unloadLibrary()") without waiting:
+// a locked mutex counts as in use.
+func libraryIdle() (bool) {
+	checks := []struct {
+		mutex *sync.Mutex
+		idle func() bool
+	}{
+		{streamMutex, func() bool { return client == nil }},
+		{batchMutex, func() bool { return len(runningBatches()) == 0 }},
+		{conversationMutex, func() bool { return parties[0] == nil }},
+		{exportMutex, func() bool { return tracerProvider == nil && meterProvider == nil }},
+		{debugServerMutex, func() bool { return debugServer == nil }},
+		{watchdogMutex, func() bool { return watchdogStop == nil }},
+		{resultEventMutex, func() bool { return currentResultEvent == nil }},
+		{logMutex, func() bool { return logFile == nil && systemLog == nil }},
+	}
+
+	for _, check := range checks {
+		if check.mutex.TryLock() == false {
+			return false
+		}
+		idle := check.idle()
+		check.mutex.Unlock()
+		if idle == false {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,194 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Dry run of a configuration (see "ValidateConfig()"): the parameters are checked locally, then a stream is opened
+	with the configuration but without audio, so google checks the credentials, the language and the model (e.g. whether
+	it's available in the region) without billing anything, all problems are reported at once.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	rpccode "google.golang.org/genproto/googleapis/rpc/code"
+
+	speech "cloud.google.com/go/speech/apiv1"
+	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+	"google.golang.org/api/option"
+)
+
+// The time google has to answer the dry run
+const validationTimeout = 15 * time.Second
+
+// A BCP-47 language tag (e.g. "en-US" or "cmn-Hans-CN")
+var languageTagPattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// A problem of the configuration: the parameter ("request" for the ones reported by google) and the gRPC status
+type configProblem struct {
+	Parameter string `json:"parameter"`
+	Message string `json:"message"`
+	Status string `json:"status,omitempty"`
+}
+
+
+// localConfigProblems checks the configuration against the constraints of google.
+func localConfigProblems(config *speechpb.StreamingRecognitionConfig) ([]configProblem) {
+	problems := []configProblem{}
+	recognition := config.Config
+
+	if languageTagPattern.MatchString(recognition.LanguageCode) == false {
+		problems = append(problems, configProblem{Parameter: "language", Message: "\"" + recognition.LanguageCode + "\" is not a BCP-47 language tag"})
+	}
+
+	rate := recognition.SampleRateHertz
+	switch recognition.Encoding {
+	case speechpb.RecognitionConfig_AMR:
+		if rate != 8000 {
+			problems = append(problems, configProblem{Parameter: "sampleRate", Message: "AMR requires 8000 Hz"})
+		}
+	case speechpb.RecognitionConfig_AMR_WB, speechpb.RecognitionConfig_SPEEX_WITH_HEADER_BYTE:
+		if rate != 16000 {
+			problems = append(problems, configProblem{Parameter: "sampleRate", Message: recognition.Encoding.String() + " requires 16000 Hz"})
+		}
+	case speechpb.RecognitionConfig_OGG_OPUS:
+		if rate != 8000 && rate != 12000 && rate != 16000 && rate != 24000 && rate != 48000 {
+			problems = append(problems, configProblem{Parameter: "sampleRate", Message: "OGG_OPUS requires 8000, 12000, 16000, 24000 or 48000 Hz"})
+		}
+	default:
+		if rate < 8000 || rate > 48000 {
+			problems = append(problems, configProblem{Parameter: "sampleRate", Message: strconv.Itoa(int(rate)) + " Hz is outside of 8000 - 48000 Hz"})
+		}
+	}
+
+	if reason := modelUnavailable(recognition.LanguageCode, recognition.Model); reason != "" {
+		problems = append(problems, configProblem{Parameter: "model", Message: reason})
+	}
+
+	if recognition.MaxAlternatives < 0 || recognition.MaxAlternatives > 30 {
+		problems = append(problems, configProblem{Parameter: "maxAlternatives", Message: "must be 0 - 30"})
+	}
+	return problems
+}
+
+
+// probeConfig opens a stream with the configuration and ends it without audio, google's objection is returned.
+func probeConfig(config *speechpb.StreamingRecognitionConfig, options []option.ClientOption) (error) {
+	probeCtx, probeCancel := context.WithTimeout(context.Background(), validationTimeout)
+	defer probeCancel()
+
+	probeClient, err := speech.NewClient(probeCtx, options...)
+	if err != nil {
+		return err
+	}
+	defer probeClient.Close()
+
+	recordRequest()
+	probeStream, err := probeClient.StreamingRecognize(probeCtx)
+	if err != nil {
+		return err
+	}
+	if err := probeStream.Send(&speechpb.StreamingRecognizeRequest{
+				StreamingRequest: &speechpb.StreamingRecognizeRequest_StreamingConfig{StreamingConfig: config},
+				}); err != nil && err != io.EOF {
+		return err
+	}
+	probeStream.CloseSend()
+
+	for {
+		resp, err := probeStream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if resp.Error != nil {
+			return status.ErrorProto(resp.Error)
+		}
+	}
+}
+
+
+/*
+	ValidateConfig(cCredentials *C.char, cQuotaProject *C.char, cTranscriptLanguage *C.char, cSampleRate C.int, cTranscriptionModel *C.char, cMaxAlternatives C.int, cInterimResults C.int, output **C.char) (C.int):
+	checks a configuration without streaming (a dry run of "CreateSessionWithCredentials()" including the settings
+	made before, e.g. the audio encoding): the parameters are checked locally, then google checks the credentials,
+	the language and the model with a stream without audio (no audio is billed),
+	all problems are reported at once as a JSON array, e.g.:
+	[{"parameter":"sampleRate","message":"AMR requires 8000 Hz"},
+	{"parameter":"request","message":"The requested model is currently not supported for language : de-DE.","status":"INVALID_ARGUMENT"}]
+
+	Parameters:
+		the same as "CreateSessionWithCredentials()"
+		output:
+			The pointer which is used to store the problems ("[]" if the configuration is valid)
+
+	Return:
+		0 if the configuration is valid (1 with legacy return codes)
+		a negative error code if not (0 with legacy return codes, error log can be retrieved with "GetLog()"),
+		the error code of an invalid argument for problems of the configuration, of an error if google couldn't check it
+		(e.g. invalid credentials or no connection)
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export ValidateConfig
+func ValidateConfig(cCredentials *C.char, cQuotaProject *C.char, cTranscriptLanguage *C.char, cSampleRate C.int, cTranscriptionModel *C.char, cMaxAlternatives C.int, cInterimResults C.int, output **C.char) (C.int) {
+	if output == nil {
+		logError("Invalid output pointer", nil)
+		return result(resultInvalidArgument)
+	}
+
+	// The settings are read like "Reconfigure()" does.
+	sendMutex.Lock()
+		config := newStreamingConfig(C.GoString(cTranscriptLanguage), int32(cSampleRate), C.GoString(cTranscriptionModel), int32(cMaxAlternatives), int32(cInterimResults) == int32(1))
+	sendMutex.Unlock()
+
+	problems := localConfigProblems(config)
+	code := resultOK
+	if len(problems) > 0 {
+		code = resultInvalidArgument
+	}
+
+	if err := probeConfig(config, clientOptions(C.GoString(cCredentials), C.GoString(cQuotaProject))); err != nil {
+		problem := configProblem{Parameter: "request", Message: err.Error()}
+		if st, ok := status.FromError(err); ok {
+			problem.Message = st.Message()
+			problem.Status = rpccode.Code_name[int32(st.Code())]
+		}
+		problems = append(problems, problem)
+
+		switch status.Code(err) {
+		case codes.InvalidArgument, codes.NotFound, codes.FailedPrecondition, codes.OutOfRange:
+			code = resultInvalidArgument
+		default:
+			code = resultError
+		}
+	}
+
+	encoded, err := json.Marshal(problems)
+	if err != nil {
+		logError("Could not encode problems: ", err)
+		return result(resultError)
+	}
+	*output = cString(string(encoded))
+
+	if code == resultInvalidArgument {
+		logError("Invalid configuration (" + problems[0].Parameter + "): " + problems[0].Message, nil)
+	} else if code == resultError {
+		logError("Could not validate configuration: " + problems[len(problems) - 1].Message, nil)
+	}
+	return result(code)
+}
@@ -0,0 +1,484 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The wake word gate (see "SetWakeWord()"): the audio is analyzed locally and only streamed to google after the wake word
+	has been spoken, which saves the costs of the idle time and keeps everything else said near the microphone private.
+	The detector is a simple keyword model without any dependencies: a recording of the wake word is the template,
+	its MFCC features (the spectral envelope, independent of the loudness) are matched against the features of the audio
+	by a streaming subsequence dynamic time warping, which tolerates a faster or slower pronunciation.
+	It's speaker dependent (the template should be recorded by the user with the microphone used later)
+	and less robust against noise than trained detectors.
+	After the wake word, the audio is streamed until the first final result or until no result arrived for a while,
+	then the gate closes again.
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"sync"
+
+	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+)
+
+// The frames of the features: 25 ms long every 10 ms
+const wakeFrameMs = 25
+const wakeHopMs = 10
+// The mel bands between wakeLowHz and wakeHighHz and the number of coefficients (without the first one, the loudness)
+const wakeMelBands = 24
+const wakeLowHz = 100.0
+const wakeHighHz = 4000.0
+const wakeCoefficients = 12
+const wakeLifter = 22
+// Frames below this level (in dBFS) are silence, the template is trimmed to the frames within wakeTrimDB of its loudest one
+const wakeSilenceDB = -55.0
+const wakeTrimDB = 30.0
+// The length of the trimmed template (in frames)
+const minWakeFrames = 20
+const maxWakeFrames = 300
+// The distance (of the features, between 0 and 2) below which the wake word is detected,
+// at the lowest and the highest sensitivity
+const wakeMinDistance = 0.05
+const wakeMaxDistance = 0.35
+// The gate closes again when no result arrived for this many seconds of streamed audio
+const wakeListenSeconds = 8
+
+// The feature extraction of an audio stream
+type featureExtractor struct {
+	frameSize int
+	hopSize int
+	// The triangular mel filters (the weights of the bins of the spectrum)
+	filters [][]float64
+	fftSize int
+	// The samples of the next frames (pre-emphasized) and the last sample
+	pending []float64
+	lastSample float64
+}
+
+// The streaming subsequence dynamic time warping against the template: the cost and the first frame of the best path ending
+// at every frame of the template and whether it ended by repeating the template's frame, the average distance of the best match
+// so far (+Inf if none is below the threshold)
+type wakeDetector struct {
+	template [][]float64
+	threshold float64
+	cost []float64
+	start []int64
+	repeated []bool
+	frame int64
+	match float64
+}
+
+// The wake word gate (guarded by the wakeMutex): the features of the template (nil if disabled) and the detection threshold,
+// the detector of the session (nil until its first audio), whether the wake word has been detected
+// and the samples streamed since (resp. since the last result)
+var wakeMutex = &sync.Mutex{}
+var wakeTemplate [][]float64
+var wakeThreshold float64
+var wakeExtractor *featureExtractor
+var wakeWordDetector *wakeDetector
+var wakeAwake = false
+var wakeListenedSamples int64
+
+
+// newFeatureExtractor creates the feature extraction for the sample rate.
+func newFeatureExtractor(sampleRate float64) (*featureExtractor) {
+	fe := &featureExtractor{
+		frameSize: int(sampleRate) * wakeFrameMs / 1000,
+		hopSize: int(sampleRate) * wakeHopMs / 1000,
+		fftSize: 1,
+	}
+	for fe.fftSize < fe.frameSize {
+		fe.fftSize <<= 1
+	}
+
+	// The band edges are evenly spaced on the mel scale.
+	mel := func(hz float64) (float64) { return 2595 * math.Log10(1 + hz / 700) }
+	hz := func(mel float64) (float64) { return 700 * (math.Pow(10, mel / 2595) - 1) }
+	high := math.Min(wakeHighHz, sampleRate / 2)
+	edges := make([]float64, wakeMelBands + 2)
+	for i := range edges {
+		edges[i] = hz(mel(wakeLowHz) + (mel(high) - mel(wakeLowHz)) * float64(i) / float64(wakeMelBands + 1))
+	}
+	for band := 0; band < wakeMelBands; band++ {
+		weights := make([]float64, fe.fftSize / 2 + 1)
+		for bin := range weights {
+			frequency := float64(bin) * sampleRate / float64(fe.fftSize)
+			if frequency > edges[band] && frequency <= edges[band + 1] {
+				weights[bin] = (frequency - edges[band]) / (edges[band + 1] - edges[band])
+			} else if frequency > edges[band + 1] && frequency < edges[band + 2] {
+				weights[bin] = (edges[band + 2] - frequency) / (edges[band + 2] - edges[band + 1])
+			}
+		}
+		fe.filters = append(fe.filters, weights)
+	}
+	return fe
+}
+
+
+// process extracts the features of the samples, onFrame gets every complete frame: its features, its level in dBFS
+// and the index of the sample following it (in this call's samples).
+func (fe *featureExtractor) process(samples []C.short, onFrame func(features []float64, levelDB float64, end int)) {
+	for _, sample := range samples {
+		value := float64(sample)
+		fe.pending = append(fe.pending, value - 0.97 * fe.lastSample)
+		fe.lastSample = value
+	}
+
+	for len(fe.pending) >= fe.frameSize {
+		end := len(samples) - (len(fe.pending) - fe.frameSize)
+		features, levelDB := fe.frameFeatures(fe.pending[:fe.frameSize])
+		fe.pending = fe.pending[fe.hopSize:]
+		onFrame(features, levelDB, max(end, 0))
+	}
+	fe.pending = append([]float64{}, fe.pending...)
+}
+
+
+// frameFeatures returns the MFCCs of the frame (without the first one) and its level in dBFS.
+func (fe *featureExtractor) frameFeatures(frame []float64) ([]float64, float64) {
+	spectrum := make([]complex128, fe.fftSize)
+	var energy float64
+	for i, sample := range frame {
+		// Hamming window
+		spectrum[i] = complex(sample * (0.54 - 0.46 * math.Cos(2 * math.Pi * float64(i) / float64(len(frame) - 1))), 0)
+		energy += sample * sample
+	}
+	fft(spectrum, false)
+
+	logEnergies := make([]float64, wakeMelBands)
+	for band, weights := range fe.filters {
+		var bandEnergy float64
+		for bin, weight := range weights {
+			if weight > 0 {
+				bandEnergy += weight * (real(spectrum[bin]) * real(spectrum[bin]) + imag(spectrum[bin]) * imag(spectrum[bin]))
+			}
+		}
+		logEnergies[band] = math.Log(bandEnergy + 1e-3)
+	}
+
+	// The discrete cosine transform of the log energies, liftered (the lowest coefficients, the spectral tilt common
+	// to all speech, would dominate the distance)
+	features := make([]float64, wakeCoefficients)
+	for k := range features {
+		for band, logEnergy := range logEnergies {
+			features[k] += logEnergy * math.Cos(math.Pi * float64(k + 1) * (float64(band) + 0.5) / wakeMelBands)
+		}
+		features[k] *= 1 + wakeLifter / 2 * math.Sin(math.Pi * float64(k + 1) / wakeLifter)
+	}
+	return features, levelDB(math.Sqrt(energy / float64(len(frame))))
+}
+
+
+// featureDistance returns the cosine distance of the features (between 0 and 2).
+func featureDistance(a []float64, b []float64) (float64) {
+	var product, normA, normB float64
+	for i := range a {
+		product += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 1
+	}
+	return 1 - product / math.Sqrt(normA * normB)
+}
+
+
+// newWakeDetector creates the detector of a session.
+func newWakeDetector(template [][]float64, threshold float64) (*wakeDetector) {
+	wd := &wakeDetector{
+		template: template,
+		threshold: threshold,
+		cost: make([]float64, len(template)),
+		start: make([]int64, len(template)),
+		repeated: make([]bool, len(template)),
+	}
+	wd.reset()
+	return wd
+}
+
+
+// reset discards the paths matched so far.
+func (wd *wakeDetector) reset() {
+	for i := range wd.cost {
+		wd.cost[i] = math.Inf(1)
+	}
+	wd.match = math.Inf(1)
+}
+
+
+// step matches the next frame and returns true, if the wake word ended with the previous one.
+func (wd *wakeDetector) step(features []float64, silent bool) (bool) {
+	count := len(wd.template)
+	cost := make([]float64, count)
+	start := make([]int64, count)
+	repeated := make([]bool, count)
+
+	for i, templateFeatures := range wd.template {
+		distance := 1.0
+		if silent == false {
+			distance = featureDistance(templateFeatures, features)
+		}
+		// Every frame may start the wake word.
+		if i == 0 {
+			cost[i], start[i] = distance, wd.frame
+			continue
+		}
+
+		// Every frame of the audio advances the path by one or two frames of the template or repeats its frame (not twice in a row),
+		// so the wake word may be spoken up to twice as fast or as slow as the template.
+		// The best predecessor has the lowest average distance.
+		cost[i] = math.Inf(1)
+		best := math.Inf(1)
+		for _, previous := range []int{i - 1, i - 2, i} {
+			if previous < 0 || (previous == i && wd.repeated[i]) || math.IsInf(wd.cost[previous], 1) {
+				continue
+			}
+			if average := (wd.cost[previous] + distance) / float64(wd.frame - wd.start[previous] + 1); average < best {
+				best = average
+				cost[i], start[i], repeated[i] = wd.cost[previous] + distance, wd.start[previous], previous == i
+			}
+		}
+	}
+	wd.cost, wd.start, wd.repeated = cost, start, repeated
+	wd.frame++
+
+	// The match may still improve with the next frame (e.g. a drawn-out last syllable), so the end of the wake word
+	// is the frame after which it got worse.
+	if average := cost[count - 1] / float64(wd.frame - start[count - 1]); average < wd.threshold && average < wd.match {
+		wd.match = average
+		return false
+	}
+	if math.IsInf(wd.match, 1) == false {
+		wd.reset()
+		return true
+	}
+	return false
+}
+
+
+// loadWakeTemplate returns the features of the wake word recorded in the WAV file (16 bit PCM, mono), trimmed to the speech.
+func loadWakeTemplate(path string) ([][]float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	header, err := parseWavHeader(data)
+	if err == nil && header == nil {
+		err = errors.New("no RIFF header")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if header.format != wavFormatPCM || header.bitsPerSample != 16 || header.channels != 1 || header.sampleRate < 8000 {
+		return nil, errors.New("unsupported WAV format (only 16 bit PCM, mono, at least 8000 Hz)")
+	}
+
+	audio := data[header.dataOffset:]
+	samples := make([]C.short, len(audio) / 2)
+	for i := range samples {
+		samples[i] = C.short(int16(binary.LittleEndian.Uint16(audio[i * 2:])))
+	}
+
+	var frames [][]float64
+	var levels []float64
+	newFeatureExtractor(float64(header.sampleRate)).process(samples, func(features []float64, levelDB float64, end int) {
+		frames = append(frames, features)
+		levels = append(levels, levelDB)
+	})
+
+	// Only the frames of the wake word (the silence before and after it is cut off)
+	loudest := math.Inf(-1)
+	for _, level := range levels {
+		loudest = math.Max(loudest, level)
+	}
+	first, last := -1, -1
+	for i, level := range levels {
+		if level >= wakeSilenceDB && level >= loudest - wakeTrimDB {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return nil, errors.New("the recording is silent")
+	}
+	frames = frames[first:last + 1]
+	if len(frames) < minWakeFrames || len(frames) > maxWakeFrames {
+		return nil, errors.New("the wake word has to last between 0.2 and 3 seconds")
+	}
+	return frames, nil
+}
+
+
+// wakeWordEnabled reports whether the wake word gate is enabled.
+func wakeWordEnabled() (bool) {
+	wakeMutex.Lock()
+	defer wakeMutex.Unlock()
+
+	return wakeTemplate != nil
+}
+
+
+// resetWakeWord closes the gate for a new session.
+func resetWakeWord() {
+	wakeMutex.Lock()
+		wakeExtractor = nil
+		wakeWordDetector = nil
+		wakeAwake = false
+		wakeListenedSamples = 0
+	wakeMutex.Unlock()
+}
+
+
+// gateWakeWord returns the samples to stream: all of them without a wake word (or session), the ones following the wake word
+// when it's detected and all of them until the gate closes again, awake is false if nothing is streamed.
+func gateWakeWord(samples []C.short) ([]C.short, bool) {
+	sendMutex.Lock()
+		if initialized == false {
+			sendMutex.Unlock()
+			return samples, true
+		}
+		sampleRate := int64(sessionConfig.Config.SampleRateHertz)
+	sendMutex.Unlock()
+
+	wakeMutex.Lock()
+		if wakeTemplate == nil {
+			wakeMutex.Unlock()
+			return samples, true
+		}
+
+		// No result for too long (e.g. the wake word was detected by mistake), so the gate closes.
+		closed := false
+		if wakeAwake {
+			wakeListenedSamples += int64(len(samples))
+			if wakeListenedSamples <= wakeListenSeconds * sampleRate {
+				wakeMutex.Unlock()
+				return samples, true
+			}
+			wakeAwake = false
+			closed = true
+		}
+
+		if wakeExtractor == nil {
+			wakeExtractor = newFeatureExtractor(float64(sampleRate))
+			wakeWordDetector = newWakeDetector(wakeTemplate, wakeThreshold)
+		}
+		wokeAt := -1
+		wakeExtractor.process(samples, func(features []float64, levelDB float64, end int) {
+			if wokeAt < 0 && wakeWordDetector.step(features, levelDB < wakeSilenceDB) {
+				wokeAt = end
+			}
+		})
+		if wokeAt >= 0 {
+			wakeAwake = true
+			wakeListenedSamples = int64(len(samples) - wokeAt)
+		}
+	wakeMutex.Unlock()
+
+	if closed {
+		reportEvent(eventWakeWordRearmed)
+	}
+	if wokeAt < 0 {
+		return nil, false
+	}
+	reportEvent(eventWakeWord)
+	return samples[wokeAt:], true
+}
+
+
+// noteWakeWordResult keeps the gate open while results arrive and closes it after the first final result.
+// Only the receive pump may call it.
+func noteWakeWordResult(resp *speechpb.StreamingRecognizeResponse) {
+	if len(resp.Results) == 0 {
+		return
+	}
+
+	wakeMutex.Lock()
+		if wakeTemplate == nil || wakeAwake == false {
+			wakeMutex.Unlock()
+			return
+		}
+		wakeListenedSamples = 0
+		for _, result := range resp.Results {
+			if result.IsFinal {
+				wakeAwake = false
+			}
+		}
+		closed := wakeAwake == false
+		if closed && wakeWordDetector != nil {
+			wakeWordDetector.reset()
+		}
+	wakeMutex.Unlock()
+
+	if closed {
+		reportEvent(eventWakeWordRearmed)
+	}
+}
+
+
+/*
+	SetWakeWord(cPath *C.char, cSensitivity C.double) (C.int):
+	enables the wake word gate: the audio of "SendAudio()" (and its variants) is analyzed locally and only streamed to google
+	after the wake word has been spoken (the event GO_SPEECH_RECOGNITION_EVENT_WAKE_WORD is reported, see "PollEvent()"),
+	beginning right after the wake word, until the first final result or until no result arrived for 8 seconds of audio,
+	then the gate closes again (GO_SPEECH_RECOGNITION_EVENT_WAKE_WORD_REARMED),
+	the detector is a simple keyword model matching the audio against a recording of the wake word, so it's speaker dependent,
+	encoded audio (see "SendAudioBytes()") can't be analyzed and is rejected while the gate is enabled,
+	the time offsets of the results only count the streamed audio, the stream should be kept alive meanwhile (see "SetKeepAlive()"),
+	takes effect immediately (the gate is closed)
+
+	Parameters:
+		cPath *C.char
+			(the WAV file (16 bit PCM, mono) of the wake word recorded with the microphone used later, 0.2 - 3 seconds of speech,
+			silence before and after it is cut off, NULL or "" to disable the gate (default))
+		cSensitivity C.double
+			(between 0.0 and 1.0, e.g. 0.5, higher values detect the wake word more reliably, but also detect similar words)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetWakeWord
+func SetWakeWord(cPath *C.char, cSensitivity C.double) (C.int) {
+	path := ""
+	if cPath != nil {
+		path = C.GoString(cPath)
+	}
+	sensitivity := float64(cSensitivity)
+
+	var template [][]float64
+	if path != "" {
+		if !(sensitivity >= 0 && sensitivity <= 1) {
+			logError("Wake word sensitivity has to be between 0.0 and 1.0", nil)
+			return result(resultInvalidArgument)
+		}
+		var err error
+		template, err = loadWakeTemplate(path)
+		if err != nil {
+			logError("Could not load the wake word " + path + ": ", err)
+			return result(resultInvalidArgument)
+		}
+	}
+
+	wakeMutex.Lock()
+		wakeTemplate = template
+		wakeThreshold = wakeMinDistance + (wakeMaxDistance - wakeMinDistance) * sensitivity
+		wakeExtractor = nil
+		wakeWordDetector = nil
+		wakeAwake = false
+		wakeListenedSamples = 0
+	wakeMutex.Unlock()
+	return result(resultOK)
+}
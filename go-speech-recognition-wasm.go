@@ -0,0 +1,299 @@
+//go:build js && wasm
+
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Experimental wasm build for browsers: the session of the library (the same calls, return codes and results)
+	running on the WebSocket bridge (see go-speech-recognition-bridge.go), since browsers can't open the gRPC stream of google.
+	It's built without cgo (the files of the library are left out):
+	"GOOS=js GOARCH=wasm go build -o go-speech-recognition.wasm ."
+	and loaded with the wasm_exec.js of Go, which registers the global object goSpeechRecognition:
+		initializeStream(bridgeURL, language, sampleRate, model, maxAlternatives, interimResults) -> Promise of the result code
+		sendAudio(samples (Int16Array)) -> result code
+		receiveTranscriptJSON() -> Promise of {code, output}
+		finalizeStream(), closeStream(), isInitialized(), getLog()
+	The return codes are always the negative ones (see "SetLegacyReturnCodes()"), the timestamps of the results
+	are the microseconds of audio since the start of the session.
+*/
+
+package main
+
+import (
+	// Standard packages:
+	"encoding/json"
+	"sync"
+	"syscall/js"
+)
+
+// Return codes of the calls (the same as the library's)
+const (
+	resultOK = 0
+	resultError = -1
+	resultNotInitialized = -2
+	resultInvalidArgument = -3
+	resultQueueFull = -5
+	resultStreamEnded = -6
+)
+
+// The audio the browser may buffer on the connection before "sendAudio()" fails with resultQueueFull
+const maxBufferedAudioBytes = 1024 * 1024
+
+// The responses of the bridge waiting to be received
+const resultQueueSize = 64
+
+// A session running on the bridge
+type wasmSession struct {
+	socket js.Value
+	results chan bridgeMessage
+	closed chan struct{}
+	callbacks []js.Func
+	sequence int64
+}
+
+// The running session (nil if there's none) and the error log, guarded by the sessionMutex
+var sessionMutex = &sync.Mutex{}
+var session *wasmSession
+var logStatus string
+
+func logError(message string) {
+	sessionMutex.Lock()
+		logStatus = message
+	sessionMutex.Unlock()
+}
+
+func main() {
+	js.Global().Set("goSpeechRecognition", js.ValueOf(map[string]interface{}{
+		"initializeStream": js.FuncOf(initializeStream),
+		"sendAudio": js.FuncOf(sendAudio),
+		"receiveTranscriptJSON": js.FuncOf(receiveTranscriptJSON),
+		"finalizeStream": js.FuncOf(finalizeStream),
+		"closeStream": js.FuncOf(closeStream),
+		"isInitialized": js.FuncOf(isInitialized),
+		"getLog": js.FuncOf(getLog),
+	}))
+
+	// The exports have to stay available.
+	select {}
+}
+
+// newPromise returns a promise settled by the function running in its own goroutine (JavaScript mustn't be blocked).
+func newPromise(run func() (interface{})) (js.Value) {
+	var executor js.Func
+	executor = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resolve := args[0]
+		go func() {
+			resolve.Invoke(run())
+		}()
+		executor.Release()
+		return nil
+	})
+	return js.Global().Get("Promise").New(executor)
+}
+
+/*
+	initializeStream(bridgeURL, language, sampleRate, model, maxAlternatives, interimResults):
+	connects to the bridge and sends the configuration like "InitializeStream()", a running session is closed first,
+	the promise is resolved with the result code once the connection is open
+*/
+func initializeStream(this js.Value, args []js.Value) (interface{}) {
+	if len(args) < 6 || args[0].Type() != js.TypeString || args[2].Type() != js.TypeNumber || args[2].Int() <= 0 || args[4].Type() != js.TypeNumber {
+		logError("Invalid arguments (bridgeURL, language, sampleRate, model, maxAlternatives, interimResults)")
+		return newPromise(func() (interface{}) { return resultInvalidArgument })
+	}
+	config, _ := json.Marshal(bridgeConfig{
+		Language: args[1].String(),
+		SampleRate: int32(args[2].Int()),
+		Model: args[3].String(),
+		MaxAlternatives: int32(args[4].Int()),
+		InterimResults: args[5].Truthy(),
+	})
+	bridgeURL := args[0].String()
+
+	return newPromise(func() (interface{}) {
+		closeSession()
+
+		newSession := &wasmSession{
+			socket: js.Global().Get("WebSocket").New(bridgeURL),
+			results: make(chan bridgeMessage, resultQueueSize),
+			closed: make(chan struct{}),
+		}
+		newSession.socket.Set("binaryType", "arraybuffer")
+		opened := make(chan bool, 1)
+
+		newSession.on("open", func(event js.Value) {
+			newSession.socket.Call("send", string(config))
+			opened <- true
+		})
+		newSession.on("message", func(event js.Value) {
+			var message bridgeMessage
+			if err := json.Unmarshal([]byte(event.Get("data").String()), &message); err != nil {
+				message = bridgeMessage{Error: "Invalid message of the bridge: " + err.Error()}
+			}
+			// The callback mustn't block, a full queue drops the response (like the library's result queue).
+			select {
+			case newSession.results <- message:
+			default:
+			}
+		})
+		newSession.on("close", func(event js.Value) {
+			select {
+			case opened <- false:
+			default:
+			}
+			close(newSession.results)
+			// No events follow the close event.
+			go newSession.releaseCallbacks()
+		})
+
+		if !<-opened {
+			logError("Could not connect to the bridge " + bridgeURL)
+			return resultError
+		}
+		sessionMutex.Lock()
+			session = newSession
+		sessionMutex.Unlock()
+		return resultOK
+	})
+}
+
+// on adds a listener of the session's socket (released once the socket is closed).
+func (s *wasmSession) on(event string, listener func(event js.Value)) {
+	callback := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		listener(args[0])
+		return nil
+	})
+	s.callbacks = append(s.callbacks, callback)
+	s.socket.Call("addEventListener", event, callback)
+}
+
+// releaseCallbacks releases the listeners once the socket is closed.
+func (s *wasmSession) releaseCallbacks() {
+	for _, callback := range s.callbacks {
+		callback.Release()
+	}
+}
+
+// currentSession returns the running session, nil if there's none.
+func currentSession() (*wasmSession) {
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+	return session
+}
+
+/*
+	sendAudio(samples):
+	sends 16 bit PCM samples (an Int16Array) like "SendAudio()",
+	fails with resultQueueFull when the connection can't keep up
+*/
+func sendAudio(this js.Value, args []js.Value) (interface{}) {
+	current := currentSession()
+	if current == nil {
+		logError("Stream is not initialized")
+		return resultNotInitialized
+	}
+	if len(args) < 1 || !args[0].InstanceOf(js.Global().Get("Int16Array")) {
+		logError("Invalid audio (must be an Int16Array)")
+		return resultInvalidArgument
+	}
+	if current.socket.Get("readyState").Int() != 1 {
+		logError("The connection to the bridge is closed")
+		return resultStreamEnded
+	}
+	if current.socket.Get("bufferedAmount").Int() > maxBufferedAudioBytes {
+		logError("Audio queue is full")
+		return resultQueueFull
+	}
+	// The samples are little endian in every browser.
+	current.socket.Call("send", args[0])
+	return resultOK
+}
+
+/*
+	receiveTranscriptJSON():
+	waits for the next results like "ReceiveTranscriptJSON()", the promise is resolved with {code, output},
+	a pending call returns an empty array when the session is closed
+*/
+func receiveTranscriptJSON(this js.Value, args []js.Value) (interface{}) {
+	return newPromise(func() (interface{}) {
+		output := func(code int, results string) (interface{}) {
+			return map[string]interface{}{"code": code, "output": results}
+		}
+
+		current := currentSession()
+		if current == nil {
+			logError("Stream is not initialized")
+			return output(resultNotInitialized, "")
+		}
+
+		var message bridgeMessage
+		var open bool
+		select {
+		case message, open = <-current.results:
+		case <-current.closed:
+			return output(resultOK, "[]")
+		}
+		if !open {
+			logError("Stream ended")
+			return output(resultStreamEnded, "")
+		}
+		if message.Error != "" {
+			logError(message.Error)
+			return output(resultError, "")
+		}
+
+		sessionMutex.Lock()
+			current.sequence++
+			sequence := current.sequence
+		sessionMutex.Unlock()
+		for i := range message.Results {
+			message.Results[i].Sequence = sequence
+		}
+		encoded, err := json.Marshal(message.Results)
+		if err != nil {
+			logError("Could not encode results: " + err.Error())
+			return output(resultError, "")
+		}
+		return output(resultOK, string(encoded))
+	})
+}
+
+// finalizeStream(): ends the audio of the session, google returns the remaining results and ends the stream.
+func finalizeStream(this js.Value, args []js.Value) (interface{}) {
+	current := currentSession()
+	if current == nil {
+		logError("Stream is not initialized")
+		return resultNotInitialized
+	}
+	control, _ := json.Marshal(bridgeControl{Finalize: true})
+	current.socket.Call("send", string(control))
+	return resultOK
+}
+
+// closeStream(): closes the session like "CloseStream()", a pending receive call returns.
+func closeStream(this js.Value, args []js.Value) (interface{}) {
+	go closeSession()
+	return nil
+}
+
+func closeSession() {
+	sessionMutex.Lock()
+		current := session
+		session = nil
+	sessionMutex.Unlock()
+	if current != nil {
+		close(current.closed)
+		current.socket.Call("close")
+	}
+}
+
+// isInitialized(): whether a session is running.
+func isInitialized(this js.Value, args []js.Value) (interface{}) {
+	return currentSession() != nil
+}
+
+// getLog(): the error log of the last failure.
+func getLog(this js.Value, args []js.Value) (interface{}) {
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+	return logStatus
+}
@@ -0,0 +1,205 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The watchdog of the Go runtime embedded in the host (see "SetWatchdog()"): it samples the number of goroutines
+	and the heap, and when one of them exceeds its threshold it logs a warning with the leak suspects
+	(the most frequent goroutine stacks), an early warning for long-running hosts. The numbers are also part
+	of the statistics (see "GetStats()").
+*/
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C" // Needed to feature cgo compatibility
+
+import (
+	"bufio"
+	"bytes"
+	"runtime/metrics"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The watchdog samples the runtime in this interval
+const watchdogInterval = 10 * time.Second
+
+// The number of goroutine stacks listed as leak suspects
+const leakSuspects = 3
+
+// The samples of the runtime (cheap to read, without stopping the world)
+const goroutinesMetric = "/sched/goroutines:goroutines"
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// The thresholds of the watchdog (guarded by the watchdogMutex, 0 disables a threshold), watchdogStop stops it
+// (nil if it isn't running), a threshold is reported again once the number fell below it
+var watchdogMutex = &sync.Mutex{}
+var maxGoroutines int64
+var maxHeapBytes int64
+var watchdogStop chan struct{}
+var goroutinesExceeded = false
+var heapExceeded = false
+
+
+// sampleRuntime returns the number of goroutines and the bytes of the heap's objects.
+func sampleRuntime() (int64, int64) {
+	samples := []metrics.Sample{{Name: goroutinesMetric}, {Name: heapMetric}}
+	metrics.Read(samples)
+
+	var goroutines, heap int64
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		goroutines = int64(samples[0].Value.Uint64())
+	}
+	if samples[1].Value.Kind() == metrics.KindUint64 {
+		heap = int64(samples[1].Value.Uint64())
+	}
+	return goroutines, heap
+}
+
+
+// goroutineLeakSuspects summarizes the most frequent goroutine stacks, each by its count and its first function
+// outside of the runtime (e.g. "412 x main.receivePump").
+func goroutineLeakSuspects() (string) {
+	var dump bytes.Buffer
+	if pprof.Lookup("goroutine").WriteTo(&dump, 1) != nil {
+		return ""
+	}
+
+	// The profile groups identical stacks: "<count> @ <addresses>" followed by a "#" line per frame.
+	type stack struct {
+		count int
+		function string
+	}
+	var stacks []stack
+	scanner := bufio.NewScanner(&dump)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if count, _, found := strings.Cut(line, " @ "); found {
+			if parsed, err := strconv.Atoi(count); err == nil {
+				stacks = append(stacks, stack{count: parsed})
+			}
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(stacks) == 0 || stacks[len(stacks) - 1].function != "" || len(fields) < 3 || fields[0] != "#" {
+			continue
+		}
+		// e.g. "main.receivePump+0x45"
+		function, _, _ := strings.Cut(fields[2], "+")
+		if strings.HasPrefix(function, "runtime.") || strings.HasPrefix(function, "runtime/") || strings.HasPrefix(function, "internal/") || strings.HasPrefix(function, "sync.") {
+			continue
+		}
+		stacks[len(stacks) - 1].function = function
+	}
+
+	sort.SliceStable(stacks, func(i, j int) (bool) {
+		return stacks[i].count > stacks[j].count
+	})
+	suspects := []string{}
+	for i := 0; i < len(stacks) && i < leakSuspects; i++ {
+		function := stacks[i].function
+		if function == "" {
+			function = "runtime"
+		}
+		suspects = append(suspects, strconv.Itoa(stacks[i].count) + " x " + function)
+	}
+	return strings.Join(suspects, ", ")
+}
+
+
+// checkRuntime samples the runtime and logs a warning for every threshold it newly exceeds.
+func checkRuntime() {
+	goroutines, heap := sampleRuntime()
+
+	watchdogMutex.Lock()
+		reportGoroutines := maxGoroutines > 0 && goroutines > maxGoroutines && goroutinesExceeded == false
+		goroutinesExceeded = maxGoroutines > 0 && goroutines > maxGoroutines
+		reportHeap := maxHeapBytes > 0 && heap > maxHeapBytes && heapExceeded == false
+		heapExceeded = maxHeapBytes > 0 && heap > maxHeapBytes
+		goroutineLimit, heapLimit := maxGoroutines, maxHeapBytes
+	watchdogMutex.Unlock()
+
+	if reportGoroutines {
+		logWarning("Watchdog: " + strconv.FormatInt(goroutines, 10) + " goroutines exceed the threshold of " + strconv.FormatInt(goroutineLimit, 10) +
+			", leak suspects: " + goroutineLeakSuspects())
+	}
+	if reportHeap {
+		logWarning("Watchdog: the heap of " + strconv.FormatInt(heap / (1024 * 1024), 10) + " MB exceeds the threshold of " + strconv.FormatInt(heapLimit / (1024 * 1024), 10) +
+			" MB (" + strconv.FormatInt(goroutines, 10) + " goroutines, leak suspects: " + goroutineLeakSuspects() + ")")
+	}
+}
+
+
+// runWatchdog checks the runtime in the interval until it's stopped.
+func runWatchdog(stop chan struct{}) {
+	ticker := time.NewTicker(watchdogInterval)
+	defer ticker.Stop()
+
+	checkRuntime()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			noteWakeup()
+			checkRuntime()
+		}
+	}
+}
+
+
+// stopWatchdog stops the watchdog (if running).
+// The caller has to hold the watchdogMutex.
+func stopWatchdog() {
+	if watchdogStop != nil {
+		close(watchdogStop)
+		watchdogStop = nil
+	}
+}
+
+
+/*
+	SetWatchdog(cMaxGoroutines C.int, cMaxHeapMB C.int) (C.int):
+	watches the Go runtime embedded in the host (checked every 10 seconds): when the number of goroutines or the heap
+	exceeds its threshold, a warning with the leak suspects (the most frequent goroutine stacks, e.g. "412 x main.receivePump")
+	is logged (again once the number fell below the threshold and exceeds it anew), the current numbers are part of the
+	statistics regardless (see "GetStats()")
+
+	Parameters:
+		cMaxGoroutines C.int
+			(the threshold of the goroutines, 0 disables it (default))
+		cMaxHeapMB C.int
+			(the threshold of the heap in megabytes, 0 disables it (default))
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetWatchdog
+func SetWatchdog(cMaxGoroutines C.int, cMaxHeapMB C.int) (C.int) {
+	if cMaxGoroutines < 0 || cMaxHeapMB < 0 {
+		logError("Invalid watchdog threshold (must not be negative)", nil)
+		return result(resultInvalidArgument)
+	}
+
+	watchdogMutex.Lock()
+	defer watchdogMutex.Unlock()
+
+	maxGoroutines = int64(cMaxGoroutines)
+	maxHeapBytes = int64(cMaxHeapMB) * 1024 * 1024
+	goroutinesExceeded, heapExceeded = false, false
+
+	stopWatchdog()
+	if maxGoroutines > 0 || maxHeapBytes > 0 {
+		watchdogStop = make(chan struct{})
+		go runWatchdog(watchdogStop)
+	}
+	return result(resultOK)
+}
@@ -0,0 +1,36 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The protocol of the experimental WebSocket bridge (see go-speech-recognition-bridge.go), which opens the streaming session
+	of google on behalf of the wasm build running in a browser (see go-speech-recognition-wasm.go), one session per connection:
+		browser -> bridge:
+			a text message with the configuration (bridgeConfig),
+			then binary messages with the audio (16 bit PCM, little endian, mono),
+			a text message {"finalize":true} ends the audio (google returns the remaining results and ends the stream)
+		bridge -> browser:
+			text messages with the results (bridgeMessage, the results in the structured form of "ReceiveTranscriptJSON()")
+			or the failure of the session, the bridge closes the connection when the stream ended
+	This file doesn't need cgo, it's shared by the builds without it.
+*/
+
+package main
+
+// The configuration of a session (the parameters of "InitializeStream()")
+type bridgeConfig struct {
+	Language string `json:"language"`
+	SampleRate int32 `json:"sampleRate"`
+	Model string `json:"model"`
+	MaxAlternatives int32 `json:"maxAlternatives"`
+	InterimResults bool `json:"interimResults"`
+}
+
+// A text message of the browser after the configuration
+type bridgeControl struct {
+	Finalize bool `json:"finalize"`
+}
+
+// A message of the bridge: the results of a response or the error ending the session
+type bridgeMessage struct {
+	Results []transcriptResult `json:"results,omitempty"`
+	Error string `json:"error,omitempty"`
+}
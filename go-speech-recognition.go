@@ -4,17 +4,26 @@
 	This C++ library written in Go provides functions needed to transcribe 
 	speech to text using Google's "Cloud Speech-To-Text" API.
 	It needs to be compiled with cgo:
-	"go build -o go-speech-recognition.dll -buildmode=c-shared go-speech-recognition.go"
+	"go build -o go-speech-recognition.dll -buildmode=c-shared ."
 	
 	See the README.md for instructions on how to use this library.
 */
 
 package main // Needs to remain main package for cgo compiling.
 
+/*
+#include <stdlib.h>
+#include <stdint.h>
+
+// A recognition hypothesis (see "ReceiveAlternatives()"), the same layout as GO_SPEECH_RECOGNITION_ALTERNATIVE in the header
+typedef struct {
+	char* transcript;
+	float confidence;
+} goSpeechRecognitionAlternative;
+*/
+import "C" // Needed to feature cgo compatibility
+
 import (
-	
-	"C" // Needed to feature cgo compatibility
-	
 	// Standard packages:
 	"io"
 	"reflect"
@@ -23,10 +32,53 @@ import (
 	"encoding/binary"
 	"context"
 	"sync"
+	"sync/atomic"
+	"encoding/json"
+	"time"
+	"math"
+	"strconv"
+	"strings"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"regexp"
+	"bufio"
+	"unicode"
+	"encoding/csv"
+	"math/cmplx"
+	"errors"
+	"sort"
+
+	// Protocol buffer helpers (needed to copy configuration messages and to convert durations):
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/duration"
+
+	// gRPC packages (needed to classify errors):
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	// Protocol buffer JSON encoding (needed to encode google's error details), the error detail types get registered by the import:
+	"github.com/golang/protobuf/jsonpb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	rpccode "google.golang.org/genproto/googleapis/rpc/code"
+
+	// OpenTelemetry packages (needed for the optional tracing and the OTLP export):
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 
 	// External (Google) packages (download with "go get -u cloud.google.com/go/speech/apiv1"):
 	speech "cloud.google.com/go/speech/apiv1"
 	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+	"google.golang.org/api/option"
 )
 
 // Global variables needed to maintain the session (and to feature an one time initialization).
@@ -36,15 +88,474 @@ var cancel context.CancelFunc
 var client* speech.Client
 var stream speechpb.Speech_StreamingRecognizeClient
 
+// Return codes of the exports (see "SetLegacyReturnCodes()"): 0 on success, negative on failure
+const (
+	resultOK C.int = 0
+	resultError C.int = -1
+	resultNotInitialized C.int = -2
+	resultInvalidArgument C.int = -3
+	resultFinalized C.int = -4
+	resultQueueFull C.int = -5
+	resultStreamEnded C.int = -6
+	resultBudgetExceeded C.int = -7
+	resultCanceled C.int = -8
+	resultRateLimited C.int = -9
+	resultQuotaExceeded C.int = -10
+	resultWrongThread C.int = -11
+	resultBusy C.int = -12
+	resultModelUnavailable C.int = -13
+)
+
+// Blocking calls of "SendAudio()" and "ReceiveTranscript()", each with its own context derived from the session's context,
+// so they can be canceled without closing the session (see "CancelPendingSend()" and "CancelPendingReceive()")
+type pendingCalls struct {
+	mutex sync.Mutex
+	nextID uint64
+	cancels map[uint64]context.CancelFunc
+}
+var pendingSends = &pendingCalls{cancels: map[uint64]context.CancelFunc{}}
+var pendingReceives = &pendingCalls{cancels: map[uint64]context.CancelFunc{}}
+
+// Set for hosts built against the previous convention (1 on success, 0 on failure)
+var legacyReturnCodes int32
+
 // Used to save error logs
 var logStatus string;
 
+// The last error in a structured form (see "GetLastErrorJSON()")
+type structuredError struct {
+	Code int32 `json:"code"`
+	Status string `json:"status"`
+	Message string `json:"message"`
+	Retryable bool `json:"retryable"`
+	Details []json.RawMessage `json:"details"`
+	Timestamp string `json:"timestamp"`
+	SessionID string `json:"sessionId"`
+	RequestID string `json:"requestId"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+var lastError structuredError
+
+// Correlation IDs: every session gets a random ID, every stream (request to google) of the session
+// a sequence number, both are included in all log entries (see "GetSessionID()")
+var sessionID string
+var requestCount uint64
+var sessionActive = false
+
+// Labels of the sessions (e.g. the customer ID), included in the log entries, the stats, the spans and the transcript files
+// for downstream correlation (see "SetSessionLabel()"), guarded by the logMutex
+var sessionLabels = map[string]string{}
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Ring buffers of log entries: one per session (the last ones are kept) and a global one for errors outside of a session
+// (see "GetSessionLog()" and "GetGlobalLog()")
+type logRing struct {
+	entries []string
+	next int
+}
+const logRingSize = 64
+const maxSessionLogs = 16
+var sessionLogs = map[string]*logRing{}
+var sessionLogOrder []string
+var globalLog = &logRing{}
+
+// Set to emit OpenTelemetry spans (see "EnableTracing()")
+var tracingEnabled int32
+
+// The providers of the OTLP export (see "EnableOTLPExport()")
+var exportMutex = &sync.Mutex{}
+var tracerProvider *sdktrace.TracerProvider
+var meterProvider *sdkmetric.MeterProvider
+
+// Used to safely log from the background goroutines
+var logMutex = &sync.Mutex{}
+
 // Used to safely close the stream
 var sendMutex = &sync.Mutex{}
 var receiveMutex = &sync.Mutex{}
 
+// Used to safely swap the stream while reconfiguring the session
+var streamMutex = &sync.Mutex{}
+
+// Used to send on a stream one request at a time (never held together with the sendMutex, see "sendToStream()")
+var streamSendMutex = &sync.Mutex{}
+
+// A response received by the receive pump or the error that ended the receiving
+type receiveResult struct {
+	resp *speechpb.StreamingRecognizeResponse
+	clock *streamClock	// the clock of the stream, which received the response
+	sequence int64	// the sequence number of a final result (see "SetResultAcknowledgment()")
+	err error
+}
+
+// Bounded queue of received results (filled by the receive pump, emptied by "ReceiveTranscript()")
+const resultQueueSize = 64
+var resultQueue chan receiveResult
+
+// Closed when the receive pump has stopped
+var receivePumpDone chan struct{}
+
+// Bounded queue of audio chunks (filled by "SendAudio()", emptied by the send pump)
+const audioQueueSize = 256
+var audioQueue chan audioChunk
+
+// An audio chunk and the host's timestamp of its first sample in microseconds, -1 if it just continues the previous chunk
+// (see "SendAudioWithTimestamp()")
+type audioChunk struct {
+	data []byte
+	samples int64	// 0 if unknown (compressed audio, see "SendAudioBytes()")
+	timestampUs int64
+}
+
+// The clocks of the open streams (guarded by the streamMutex)
+var streamClocks = map[speechpb.Speech_StreamingRecognizeClient]*streamClock{}
+
+// Closed when the send pump has stopped
+var sendPumpDone chan struct{}
+
+// Number of audio bytes waiting in the audio queue (see "GetQueueDepths()")
+var queuedAudioBytes int64
+
+// Pause after which the send pump sends a silence frame, 0 if disabled (see "SetKeepAlive()")
+var keepAliveThresholdMs int32
+const keepAliveFrameMs = 100
+
+// The error that stopped the send pump (reported by the next "SendAudio()" call)
+var sendFailure error
+
+// Behavior of the queues when they are full (see "SetOverflowPolicy()")
+const (
+	overflowBlock int32 = 0
+	overflowDropOldest int32 = 1
+	overflowDropNewest int32 = 2
+	overflowError int32 = 3
+)
+var overflowPolicy = overflowBlock
+
+// Set when the result queue rejected results (overflow policy "error")
+var resultsRejected int32
+
+// Set when the stream has been finalized by the library (e.g. after a silence timeout),
+// the remaining results can still be received, but no more audio is sent
+var finalized = false
+
+// Closed by the finalization: the send pump sends the audio queued before and then tells google, that no more audio follows
+var finishing chan struct{}
+
+// Events reported by the library (see "PollEvent()")
+const (
+	eventSilenceTimeout int32 = 1
+	eventMaxDuration int32 = 2
+	eventBudgetExceeded int32 = 3
+	eventClipping int32 = 4
+	eventDCOffset int32 = 5
+	eventNearSilence int32 = 6
+	eventQuotaExceeded int32 = 7
+	eventOffline int32 = 8
+	eventBackfilled int32 = 9
+	eventFormatMismatch int32 = 10
+	eventWakeWord int32 = 11
+	eventWakeWordRearmed int32 = 12
+)
+const eventQueueSize = 64
+var eventQueue = make(chan int32, eventQueueSize)
+
+// Voice activity detection: audio with a lower RMS level counts as silence
+const vadThreshold = 500.0
+
+// Level of the audio passed to the last "SendAudio()" call in dBFS, saved as the bits of a float64
+// (so it can be accessed atomically), and the level callback, nil if not set (see "GetInputLevel()" and "SetLevelCallback()")
+var inputRMSBits = math.Float64bits(minLevelDB)
+var inputPeakBits = math.Float64bits(minLevelDB)
+var levelCallback unsafe.Pointer
+var levelUserData unsafe.Pointer
+
+// Lowest level in dBFS (the dynamic range of 16 bit samples), silence is reported with it
+const minLevelDB = -96.0
+
+// Diagnostics of the sent audio (the most common causes of a bad recognition), every problem is reported once per session:
+// clipping (more than 0.1 % of the samples at full scale), DC offset (mean above 3 % of full scale)
+// and near silence (RMS level below -60 dBFS for 5 seconds)
+const clippingLevel = 32767
+const clippingRatio = 0.001
+const dcOffsetLevel = 1000.0
+const nearSilenceLevel = 33.0
+const nearSilenceSeconds = 5
+var reportedAudioProblems = map[int32]bool{}
+var nearSilentSamples int64
+
+// Set to detect a WAV header at the start of the session's audio (see "SetWavHeaderDetection()"),
+// awaitingFirstAudio is set until the first audio of the session has been passed (both guarded by the sendMutex)
+var wavHeaderDetection = false
+var awaitingFirstAudio = false
+
+// The format of a WAV file (only the fields needed to validate it) and the offset of its samples
+type wavHeader struct {
+	format uint16
+	channels uint16
+	sampleRate uint32
+	bitsPerSample uint16
+	dataOffset int
+}
+const wavFormatPCM = 1
+
+// Preprocessing of the audio before it's sent (see "SetAutomaticGainControl()")
+var preprocessMutex = &sync.Mutex{}
+
+// Automatic gain control: the gain adapts (smoothly) to bring the audio to the target RMS level,
+// audio below the noise floor doesn't change the gain (so silence isn't amplified)
+const agcMinGain = 0.1
+const agcMaxGain = 10.0
+const agcAdaptation = 0.2
+const agcNoiseFloor = nearSilenceLevel
+var agcEnabled = false
+var agcTargetRMS float64
+var agcGain = 1.0
+
+// High-pass filter (second order butterworth), removes rumble and DC offset, the settings apply to the next session,
+// which gets its own filter (see "SetHighPassFilter()")
+var highPassEnabled = false
+var highPassCutoffHz float64
+var highPass *biquadFilter
+
+type biquadFilter struct {
+	b0, b1, b2, a1, a2 float64	// coefficients (normalized)
+	x1, x2, y1, y2 float64		// the last inputs and outputs
+}
+
+// Noise suppression (spectral subtraction): the noise spectrum is tracked continuously (it follows quiet frames quickly
+// and loud frames slowly) and subtracted from every frame, the frames overlap by half, which delays the audio by a frame,
+// the settings apply to the next session, which gets its own suppressor (see "SetNoiseSuppression()")
+const noiseFrameSize = 512
+const noiseHopSize = noiseFrameSize / 2
+// The delay of the output in samples
+const noiseLatency = noiseFrameSize
+const noiseFloorGain = 0.1
+// The estimate follows the quiet frames, so it's lower than the average noise, which the over-subtraction compensates
+const noiseOverSubtraction = 16.0
+var noiseSuppressionEnabled = false
+var noiseSuppressionStrength float64
+var suppressor *noiseSuppressor
+
+type noiseSuppressor struct {
+	strength float64
+	window []float64	// the last frame of input samples
+	pending []float64	// input samples of the next hop
+	tail []float64		// the second half of the last processed frame (overlaps the next one)
+	ready []float64		// processed samples, which haven't been returned yet
+	noise []float64		// the estimated noise magnitude of every frequency bin (nil until the first frame)
+}
+
+// Duration of silence after which the stream is finalized, 0 if disabled (see "SetNoSpeechTimeout()")
+var noSpeechTimeoutSeconds int32
+var silentSamples int64
+
+// Maximum duration of a session, 0 if unlimited (see "SetMaxSessionDuration()")
+var maxSessionSeconds int32
+var sessionStart time.Time
+var maxDurationTimer *time.Timer
+
+// Billed time accounting: seconds of audio sent in the session and on the current day (across sessions)
+// and the daily budget, 0 if unlimited (see "GetBilledSecondsEstimate()" and "SetBilledSecondsBudget()")
+var billingMutex = &sync.Mutex{}
+var sessionBilledSeconds float64
+var dailyBilledSeconds float64
+var billingDay string
+var dailyBudgetSeconds float64
+
+// Billed time reported by google (see "GetBilledTime()"): the total of every stream of the session
+// (google reports it cumulated per stream, the streams which aren't received anymore are summed up) and of the batch files
+// since the library has been loaded
+var reportedStreamSeconds = map[*streamClock]float64{}
+var retiredStreamSeconds float64
+var reportedBatchSeconds float64
+
+// Rate limits across the process (see "SetRateLimits()"): concurrent recognitions (the streaming session and the files
+// of the batches being transcribed) and requests per minute (every stream and batch request), 0 means unlimited,
+// new recognitions wait up to the queue timeout for a free slot and are rejected afterwards
+var limitMutex = &sync.Mutex{}
+var maxConcurrentRecognitions int
+var maxRequestsPerMinute int
+var rateLimitQueueTimeout time.Duration
+var activeRecognitions int
+var recentRequests []time.Time
+var limitChanged = make(chan struct{})	// closed (and replaced) when a slot may have become free
+
+// Classification of the gRPC status codes: errors with retryable codes lead to a new stream,
+// all others end the receiving (see "SetRetryableCode()")
+var retryMutex = &sync.Mutex{}
+var retryableCodes = map[codes.Code]bool{
+	codes.Unavailable:			true,
+	codes.ResourceExhausted:	true,
+	codes.DeadlineExceeded:		true,
+	codes.Aborted:				true,
+	codes.Internal:				true,
+	codes.OutOfRange:			true,	// google ends streams after the maximum stream duration
+}
+const maxRetries = 3
+
+// When google reports the quota as exceeded (RESOURCE_EXHAUSTED), the sending pauses for the advised delay
+// (google's retry info, otherwise the default, at most the maximum) before a new stream is opened,
+// meanwhile the audio is queued, quotaResume is closed when the sending continues (guarded by the sendMutex)
+const defaultQuotaPause = 5 * time.Second
+const maxQuotaPause = time.Minute
+var quotaResume chan struct{}
+
+// Maximum time "Shutdown()" waits for the library to release everything
+const shutdownTimeout = 2 * time.Second
+
+// Statistics of the session (see "GetStats()")
+type sessionStats struct {
+	AudioOverflows uint64 `json:"audioOverflows"`
+	ResultOverflows uint64 `json:"resultOverflows"`
+	KeepAliveFrames uint64 `json:"keepAliveFrames"`
+	StreamRetries uint64 `json:"streamRetries"`
+	ChunkMs int32 `json:"chunkMs"`
+	SendLatencyMs float64 `json:"sendLatencyMs"`
+	BilledSeconds float64 `json:"billedSeconds"`
+	Goroutines int64 `json:"goroutines"`
+	HeapBytes int64 `json:"heapBytes"`
+	Wakeups uint64 `json:"wakeups"`
+	IdleMs int64 `json:"idleMs"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+var audioOverflows uint64
+var resultOverflows uint64
+var keepAliveFrames uint64
+var streamRetries uint64
+
+// Adaptive chunk size: the chunks hold between 20 and 200 ms of audio, so the time a chunk takes to send
+// (the smoothed latency of the stream's Send calls) stays a fraction of its duration:
+// a fast network gets small chunks (low latency), a slow one big chunks (less overhead)
+const minChunkMs = 20
+const maxChunkMs = 200
+const initialChunkMs = 32			// 1024 bytes at 16kHz (the former fixed size)
+const chunkLatencyFactor = 4		// the chunk duration in multiples of the send latency
+const sendLatencySmoothing = 0.2
+const compressedChunkBytes = 1024	// compressed audio (unknown duration) keeps the fixed size
+var chunkMs int32 = initialChunkMs
+var sendLatencyUs int64
+
+// The configuration of the session (needed to restart the stream, e.g. after a single utterance)
+var sessionConfig *speechpb.StreamingRecognitionConfig
+var singleUtterance = false
+
+// Phrase hints, grouped by their boost (see "LoadPhraseHintsFromFile()")
+var phraseHints []*speechpb.SpeechContext
+
+// Set to request the confidence of every word (see "SetWordConfidence()")
+var wordConfidence = false
+
+// Set to request the start and end time of every word (see "SetWordTimeOffsets()")
+var wordTimeOffsets = false
+
+// The encoding of the sent audio (see "SetAudioEncoding()"), "SendAudio()" requires LINEAR16
+var audioEncoding = speechpb.RecognitionConfig_LINEAR16
+
+// Speaker diarization, nil if disabled (see "SetSpeakerDiarization()")
+var diarizationConfig *speechpb.SpeakerDiarizationConfig
+
+// Words of the session labeled with their speaker (see "GetSpeakerTranscript()"): the words of the finished
+// streams and the words of the current stream (google repeats all words of a stream in its latest final result)
+type speakerTurn struct {
+	Speaker int32 `json:"speaker"`
+	Label string `json:"label"`
+	Transcript string `json:"transcript"`
+}
+var speakerMutex = &sync.Mutex{}
+var speakerWords []*speechpb.WordInfo
+var streamSpeakerWords []*speechpb.WordInfo
+
+// Rolling transcript files, one per speaker or per channel, no files if the directory is empty (see "SetTranscriptFiles()")
+var transcriptFilesDirectory string
+var transcriptFilesPerChannel = false
+
+// The callback for final results and its user data, nil if not set (see "SetFinalResultCallback()")
+var callbackMutex = &sync.Mutex{}
+var finalResultCallback unsafe.Pointer
+var finalResultUserData unsafe.Pointer
+
+// Interim results with a lower stability are suppressed, 0 if disabled (see "SetMinStability()"),
+// saved as the bits of a float32 (so it can be accessed atomically)
+var minStabilityBits uint32
+
+// The N-best re-ranking callback and its user data, nil if not set (see "SetRerankCallback()")
+var rerankCallback unsafe.Pointer
+var rerankUserData unsafe.Pointer
+
+// Utterances: the interim results and the final result of the same utterance share an ID (see "PollUtteranceEvent()")
+const (
+	utteranceStarted int32 = 1
+	utteranceUpdated int32 = 2
+	utteranceFinalized int32 = 3
+	utteranceAborted int32 = 4
+)
+type utteranceEvent struct {
+	id int32
+	event int32
+	transcript string
+}
+const utteranceQueueSize = 64
+var utteranceQueue = make(chan utteranceEvent, utteranceQueueSize)
+
+// The ID of the last utterance (counted across sessions) and whether it's still open (only used by the receive pump)
+var utteranceID int32
+var utteranceOpen = false
+var utteranceTranscript string
+
+// End of the last final result of the current stream, i.e. the start of the next utterance (only used by the receive pump)
+var lastFinalEnd time.Duration
+
+// Replacement rules applied to the transcripts before delivery (see "AddReplacement()" and "LoadReplacementsFromFile()")
+type replacement struct {
+	search string
+	pattern *regexp.Regexp	// nil for exact rules
+	replace string
+}
+var postProcessMutex = &sync.Mutex{}
+var replacements []replacement
+
+// Formatting options applied to the transcripts before the replacement rules (see "SetOutputFormatting()")
+const (
+	formatLowercase int32 = 1
+	formatSentenceCase int32 = 2
+	formatStripFillers int32 = 4
+	formatCollapseRepeats int32 = 8
+)
+var outputFormatting int32
+
+// Filler words removed by formatStripFillers
+var fillerWords = map[string]bool{"uh": true, "uhm": true, "um": true, "umm": true, "er": true, "erm": true, "ah": true, "hmm": true}
+
+// Formatting of numbers (see "SetNumberFormatting()"): google's V1 API has no switch, it formats most numbers as digits,
+// so both directions are done locally (for english transcripts)
+const (
+	numbersAsDelivered int32 = 0
+	numbersDigits int32 = 1
+	numbersVerbatim int32 = 2
+)
+var numberFormatting = numbersAsDelivered
+
+// Number words of the local inverse text normalization
+var numberUnits = map[string]int64{
+	"zero": 0, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9,
+	"ten": 10, "eleven": 11, "twelve": 12, "thirteen": 13, "fourteen": 14, "fifteen": 15, "sixteen": 16,
+	"seventeen": 17, "eighteen": 18, "nineteen": 19,
+	"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50, "sixty": 60, "seventy": 70, "eighty": 80, "ninety": 90,
+}
+var numberScales = map[string]int64{"thousand": 1000, "million": 1000000, "billion": 1000000000}
+
 var initialized = false
 
+// Used to initialize and close sessions one after another
+var lifecycleMutex = &sync.Mutex{}
+
+// Counts the sessions, so background work can tell whether it belongs to the current one
+var sessionGeneration uint64
+
+// The calls of "SendAudio()" and "ReceiveTranscript()" in progress ("CloseStream()" waits for them)
+var inFlight sync.WaitGroup
+
 
 /*
 	InitializeStream(cLanguage *_Ctype_char, cSampleRate C.int):
@@ -59,13 +570,70 @@ var initialized = false
 			use at least 16kHz)
 		
 	Return:
-		1 if successful
-		0 if failed (error log can be retrieved with "GetLog()")
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
 */
 
 // Next comment is needed by cgo to know which function to export.
 //export InitializeStream
 func InitializeStream(cTranscriptLanguage *_Ctype_char, cSampleRate C.int, cTranscriptionModel *_Ctype_char, cMaxAlternatives C.int, cInterimResults C.int ) (C.int) {
+	span := startSpan("InitializeStream")
+	code := initializeStream(cTranscriptLanguage, cSampleRate, cTranscriptionModel, cMaxAlternatives, cInterimResults, nil)
+	endSpan(span, code)
+	return result(code)
+}
+
+
+/*
+	CreateSessionWithCredentials(cCredentials *C.char, cQuotaProject *C.char, cTranscriptLanguage *C.char, cSampleRate C.int, cTranscriptionModel *C.char, cMaxAlternatives C.int, cInterimResults C.int) (C.int):
+	initializes the streaming session like "InitializeStream()", but with the credentials and the project of a customer
+	(instead of the default credentials of the environment), so hosts transcribing on behalf of multiple customers
+	can route every session to the customer's project (the library runs one streaming session at a time,
+	batches get their own credentials, see "SetBatchCredentials()")
+	
+	Parameters:
+		cCredentials *C.char
+			(the path of a service account key file or the JSON key itself, empty for the default credentials)
+		cQuotaProject *C.char
+			(the project the usage is billed to, empty for the project of the credentials)
+		the others:
+			the same as "InitializeStream()"
+		
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export CreateSessionWithCredentials
+func CreateSessionWithCredentials(cCredentials *C.char, cQuotaProject *C.char, cTranscriptLanguage *C.char, cSampleRate C.int, cTranscriptionModel *C.char, cMaxAlternatives C.int, cInterimResults C.int) (C.int) {
+	span := startSpan("InitializeStream")
+	options := clientOptions(C.GoString(cCredentials), C.GoString(cQuotaProject))
+	code := initializeStream(cTranscriptLanguage, cSampleRate, cTranscriptionModel, cMaxAlternatives, cInterimResults, options)
+	endSpan(span, code)
+	return result(code)
+}
+
+
+// clientOptions returns the options of a client using the credentials (a key file or the JSON key itself)
+// and the quota project, without both the client uses the default credentials of the environment.
+func clientOptions(credentials string, quotaProject string) ([]option.ClientOption) {
+	var options []option.ClientOption
+	switch {
+	case strings.HasPrefix(strings.TrimSpace(credentials), "{"):
+		options = append(options, option.WithCredentialsJSON([]byte(credentials)))
+	case credentials != "":
+		options = append(options, option.WithCredentialsFile(credentials))
+	}
+	if quotaProject != "" {
+		options = append(options, option.WithQuotaProject(quotaProject))
+	}
+	return options
+}
+
+// initializeStream implements "InitializeStream()" and "CreateSessionWithCredentials()" (the exports only add the tracing),
+// the client options select the credentials (nil for the default credentials).
+func initializeStream(cTranscriptLanguage *C.char, cSampleRate C.int, cTranscriptionModel *C.char, cMaxAlternatives C.int, cInterimResults C.int, options []option.ClientOption) (C.int) {
 	
 
 	// converts the input C string to a go string (needed to send the initialization message)
@@ -83,266 +651,4788 @@ func InitializeStream(cTranscriptLanguage *_Ctype_char, cSampleRate C.int, cTran
 	// "converts" the input C integer to a bool
 	goInterimResults := int32(cInterimResults) == int32(1)
 
+	config := newStreamingConfig(goTranscriptLanguage, goSampleRate, goTranscriptionModel, goMaxAlternatives, goInterimResults)
+	return startSession(config, options, nil)
+}
+
+// startSession sets the streaming session up with the configuration, a snapshot (see "RestoreSession()") continues
+// its logical session, nil starts a new one.
+func startSession(config *speechpb.StreamingRecognitionConfig, options []option.ClientOption, restored *sessionSnapshot) (C.int) {
+
+	// The session can't be replaced while a callback holds up the library (see go-speech-recognition-threads.go).
+	if code := checkCallbackThread("InitializeStream"); code != resultOK {
+		return code
+	}
+
+	// A model google doesn't offer for the language would only fail once the stream is running.
+	if reason := modelUnavailable(config.Config.LanguageCode, config.Config.Model); reason != "" {
+		logError(reason, nil)
+		return resultModelUnavailable
+	}
+
+	// Don't start streaming, when the budget is already used up.
+	if budgetUsedUp() {
+		logError("Billed seconds budget is exceeded", nil)
+		return resultBudgetExceeded
+	}
+
+	// No other session may be initialized or closed meanwhile.
+	lifecycleMutex.Lock()
+	defer lifecycleMutex.Unlock()
+
+	// A running session is closed first (instead of leaking its goroutines and connection).
+	closeStream()
+
+	// Files exceeding the retention policy are deleted before new ones are written (see "SetDataRetention()").
+	enforceRetention()
+
+	// The session needs a free slot of the rate limits (released by "CloseStream()").
+	if code := acquireRecognition(nil); code != resultOK {
+		return code
+	}
+
+	// Every session gets a new ID (included in all log entries), a restored session keeps its ID.
+	if restored == nil {
+		newSessionID()
+	} else {
+		restoreSessionID(restored)
+	}
 
 	// Set the context for the stream.
 	ctx, cancel = context.WithCancel(context.Background())
 
-	// Create a new Client.
-	client, err := speech.NewClient(ctx)
+	// Create a new Client (saved globally, so the stream can be reopened by "Reconfigure()").
+	var err error
+	client, err = speech.NewClient(ctx, options...)
 	if err != nil {
-		logStatus = err.Error()
-		return C.int(0);
+		cancel()
+		cancel = nil
+		releaseRecognition()
+		logError("", err)
+		endSessionLog()
+		return resultError
 	}
 	
-	// Create a new Stream.
-	stream, err = client.StreamingRecognize(ctx)
-	if err != nil {
-		logStatus = err.Error()
-		return C.int(0);
+	// Create a new Stream and send the initial configuration message.
+	sessionConfig = config
+	newStream, err := openStream(sessionConfig)
+	// Without connection the session starts offline, if the backfill is enabled (see "SetBackfill()").
+	sendMutex.Lock()
+		startOffline := err != nil && isConnectivityError(err) && backfillDirectory != ""
+	sendMutex.Unlock()
+	if err != nil && startOffline == false {
+		client.Close()
+		cancel()
+		cancel = nil
+		releaseRecognition()
+		logError("", err)
+		endSessionLog()
+		return resultError
 	}
 
-	// Send the initial configuration message.
-	if err := stream.Send(&speechpb.StreamingRecognizeRequest{
-				StreamingRequest: &speechpb.StreamingRecognizeRequest_StreamingConfig{
-					StreamingConfig: &speechpb.StreamingRecognitionConfig{
-						Config: &speechpb.RecognitionConfig{
-							Encoding:			speechpb.RecognitionConfig_LINEAR16,
-							SampleRateHertz:	goSampleRate,				// Remember to use a recording with 16KHz sample rate.
-							LanguageCode:		goTranscriptLanguage,		// Can be adjusted to language to be transcribed. (BCP-47)
-							Model:				goTranscriptionModel,		// Can be either "video", "phone_call", "command_and_search", "default" (see https://cloud.google.com/speech-to-text/docs/basics)
-							MaxAlternatives:	goMaxAlternatives,			// Maximum number of recognition hypotheses: Valid values are 0-30, 0 or 1 return only one							
-							},
-						InterimResults:	goInterimResults,	// boolean
-						},
-					},
-				}); 
-	err != nil {
-		logStatus = err.Error()
-		return C.int(0);
+	streamMutex.Lock()
+		stream = newStream
+	streamMutex.Unlock()
+
+	// Reset the statistics of the previous session.
+	atomic.StoreUint64(&audioOverflows, 0)
+	atomic.StoreUint64(&resultOverflows, 0)
+	atomic.StoreInt32(&resultsRejected, 0)
+	atomic.StoreInt32(&deferredReceiveCode, 0)
+	atomic.StoreInt64(&queuedAudioBytes, 0)
+	atomic.StoreUint64(&keepAliveFrames, 0)
+	atomic.StoreUint64(&streamRetries, 0)
+	atomic.StoreInt32(&chunkMs, initialChunkMs)
+	atomic.StoreInt64(&sendLatencyUs, 0)
+	resetWakeups()
+	resetDrift()
+	resetFormatCheck()
+	resetWakeWord()
+	silentSamples = 0
+	nearSilentSamples = 0
+	reportedAudioProblems = map[int32]bool{}
+	awaitingFirstAudio = true
+	preprocessMutex.Lock()
+		agcGain = 1.0
+		highPass = nil
+		if highPassEnabled {
+			highPass = newHighPassFilter(highPassCutoffHz, float64(config.Config.SampleRateHertz))
+		}
+		suppressor = nil
+		if noiseSuppressionEnabled {
+			suppressor = newNoiseSuppressor(noiseSuppressionStrength)
+		}
+	preprocessMutex.Unlock()
+	finalized = false
+	speakerMutex.Lock()
+		speakerWords = nil
+		streamSpeakerWords = nil
+	speakerMutex.Unlock()
+	lastFinalEnd = 0
+	billingMutex.Lock()
+		sessionBilledSeconds = 0
+		reportedStreamSeconds = map[*streamClock]float64{}
+		retiredStreamSeconds = 0
+	billingMutex.Unlock()
+	// A restored session continues the audio time and the timestamps of the snapshot.
+	resetSnapshotState(restored, config.Config.SampleRateHertz)
+
+	if startOffline {
+		sendMutex.Lock()
+			goOffline(ctx)
+		sendMutex.Unlock()
 	}
 
+	// Start receiving in the background.
+	receiveMutex.Lock()
+		resultQueue = make(chan receiveResult, resultQueueSize)
+		receivePumpDone = make(chan struct{})
+	receiveMutex.Unlock()
+	go receivePump(ctx, resultQueue, receivePumpDone)
+
+	// Start sending in the background.
+	sendMutex.Lock()
+		audioQueue = make(chan audioChunk, audioQueueSize)
+		finishing = make(chan struct{})
+		sendPumpDone = make(chan struct{})
+		sendFailure = nil
+	sendMutex.Unlock()
+	go sendPump(ctx, audioQueue, finishing, sendPumpDone)
+
+	// Accept calls from now on.
+	sendMutex.Lock()
+	receiveMutex.Lock()
+		sessionGeneration++
+		sessionStart = time.Now()
+		armMaxDurationTimer()
+		initialized = true
+		// The real-time ring accepts audio from now on (see "SetRealtimeAudio()").
+		startRealtimeAudio(ctx, config.Config.SampleRateHertz)
+	receiveMutex.Unlock()
+	sendMutex.Unlock()
 
-	initialized = true
-	return C.int(1);
+	// Checkpoint the session periodically (see "GetSessionSnapshot()").
+	go checkpointPump(ctx)
+	return resultOK
 }
 
-	
+
 /*
-	SendAudio(recording, recordingLength C.int):
-	prepares the inputted audio data to be sent to google,
-	handles the sending process
+	Reconfigure(cTranscriptLanguage *C.char, cSampleRate C.int, cTranscriptionModel *C.char, cMaxAlternatives C.int, cInterimResults C.int) (C.int):
+	switches the configuration of a running session,
+	the current stream gets finished (its remaining results can still be received)
+	and a new stream with the updated configuration takes over,
+	the client and the session state are kept, so the host doesn't need to reinitialize
 	
-	Parameters:
-		recording:
-			has to be a Pointer to short values committed by C++ function call
-			(16KHz Audio Stream)
+	Parameter:
+		the same as "InitializeStream()"
 		
-		recordingLength:
-			just the length of the recording (needed as we can't use C++ vectors in golang)	
-
 	Return:
-		1 if successful
-		0 if failed (error log can be retrieved with "GetLog()")
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()"), the current stream keeps running
 */
-	
+
 // Next comment is needed by cgo to know which function to export.
-//export SendAudio
-func SendAudio(recording *C.short, recordingLength C.int) (C.int){
+//export Reconfigure
+func Reconfigure(cTranscriptLanguage *C.char, cSampleRate C.int, cTranscriptionModel *C.char, cMaxAlternatives C.int, cInterimResults C.int) (C.int) {
+	if code := checkCallbackThread("Reconfigure"); code != resultOK {
+		return result(code)
+	}
 
-	// Create a slice of C.short values.
-	var length = int(recordingLength) 	// Convert recordingLength from C.int to an int value (needed to define the sliceHeader in the following).
-	var list []C.short			// Define a new slice of C.shorts.
-	
-	// Pass the reference to the input C.short values to the slice's data.
-	sliceHeader := (*reflect.SliceHeader)((unsafe.Pointer(&list)))
-	sliceHeader.Len = length
-	sliceHeader.Cap = length
-	sliceHeader.Data = uintptr(unsafe.Pointer(recording))
-	
-	// As we need to send byte values instead of C.Shorts, the list gets copied in a temporary bytes.Buffer.
-	// (maybe changed in future for reduction of copy operations)
-	temporaryByteBuffer := new(bytes.Buffer)
-	err := binary.Write(temporaryByteBuffer, binary.LittleEndian, list)
-	
-	if err != nil {
-		logStatus = ("binary.Write failed:" + err.Error())
-		return C.int(0)
-	}	
+	if reason := modelUnavailable(C.GoString(cTranscriptLanguage), C.GoString(cTranscriptionModel)); reason != "" {
+		logError(reason, nil)
+		return result(resultModelUnavailable)
+	}
 
+	config := newStreamingConfig(C.GoString(cTranscriptLanguage), int32(cSampleRate), C.GoString(cTranscriptionModel), int32(cMaxAlternatives), int32(cInterimResults) == int32(1))
 
-// [SENDING]
-	
-	// For sending to google we declare a slice of bytes, that acts as a pipeline.
-	// When it's too big, the streaming is too fast for google, so we cap it at 1024 byte.
-	pipeline := make([]byte, 1024)
+	// No audio may be sent while the streams are swapped (audio sent meanwhile waits and goes to the new stream).
+	sendMutex.Lock()
+	defer sendMutex.Unlock()
 
-	for {
-		// Each loop run: Fill pipeline with the next 1024 values of the byte buffer.
-		// n is needed to keep track of the reading progress
-		n, err := temporaryByteBuffer.Read(pipeline)		
-		
-		// Stop streaming when reaching the end of the input stream.
-		if err == io.EOF {
-			return C.int(1)
-		}
+	if initialized == false {
+		logError("Stream is not initialized", nil)
+		return result(resultNotInitialized)
+	}
 
-		if n > 0 {
-			
-			// Ensure that the stream is initialized
-			sendMutex.Lock()			
-				// Check if the stream is initialized
-				if initialized == false {
-
-					sendMutex.Unlock()
-
-					logStatus = ("Stream is not initialized")
-					return C.int(1)
-				}	
-				// Send the pipeline upto the n-th byte (except the last loop run n==1024) as a message to google
-				err := stream.Send(&speechpb.StreamingRecognizeRequest{
-						StreamingRequest: &speechpb.StreamingRecognizeRequest_AudioContent{
-							AudioContent: pipeline[:n],		
-							},
-						});
+	if finalized == true {
+		logError("Stream has been finalized", nil)
+		return result(resultFinalized)
+	}
 
-			sendMutex.Unlock()
-			
-			if err == context.Canceled {
-				return C.int(1)
-			}
-			if err != nil {
-				logStatus = ("Could not send audio:" + err.Error())
-				return C.int(0)
-			}
-		}
+	if err := restartStream(config); err != nil {
+		logError("Could not reconfigure: ", err)
+		return result(resultError)
 	}
+
+	sessionConfig = config
+	return result(resultOK)
 }
 
 
 /*
-	ReceiveTranscript (output **C.char) (C.int):	
-	retrieves and saves the current final transcripts from Google
+	SetSingleUtterance(cSingleUtterance C.int):
+	enables the single utterance mode (e.g. for voice commands),
+	google stops recognizing after the first utterance, the library then automatically
+	starts a new stream for the next utterance,
+	has to be called before "InitializeStream()" or "Reconfigure()" to take effect
 	
-	After the call output contains the current final transcript 
+	Parameter:
+		cSingleUtterance C.int
+			(1 to enable, 0 to disable the single utterance mode)
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetSingleUtterance
+func SetSingleUtterance(cSingleUtterance C.int) () {
+	sendMutex.Lock()
+		singleUtterance = int32(cSingleUtterance) == int32(1)
+	sendMutex.Unlock()
+}
+
+
+/*
+	SetNextUtteranceLanguage(cTranscriptLanguage *C.char) (C.int):
+	transcribes only the next utterance in the given language (e.g. for a bilingual prompt),
+	afterwards the session's language is used again,
+	only available in single utterance mode
 	
-	Parameters:
-		output:
-			The pointer which is used to store the current final transcript
-				
+	Parameter:
+		cTranscriptLanguage *C.char
+			(transcription language of the next utterance as a C string (use BCP-47 language tag))
+		
 	Return:
-		1 if successful
-		0 if failed (error log can be retrieved with "GetLog()")
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
 */
 
 // Next comment is needed by cgo to know which function to export.
-//export ReceiveTranscript
-func ReceiveTranscript (output **C.char) (C.int) {
+//export SetNextUtteranceLanguage
+func SetNextUtteranceLanguage(cTranscriptLanguage *C.char) (C.int) {
 
-	// Ensure that the stream is initialized
-	receiveMutex.Lock()
-		// Check if the stream is initialized
-		if initialized == false {
-			receiveMutex.Unlock()
-			logStatus = ("Stream is not initialized")
-			return C.int(0)
-		}
-		// Check if there are results or errors yet.
-		resp, err := stream.Recv()
-	receiveMutex.Unlock()
+	goTranscriptLanguage := C.GoString(cTranscriptLanguage)
 
-	// Error handling.
-	if err == context.Canceled {
-		return C.int(1)
+	sendMutex.Lock()
+	defer sendMutex.Unlock()
+
+	if initialized == false {
+		logError("Stream is not initialized", nil)
+		return result(resultNotInitialized)
 	}
 
+	if finalized == true {
+		logError("Stream has been finalized", nil)
+		return result(resultFinalized)
+	}
 
-	if err != nil {
-		logStatus = ("Cannot stream results: " + err.Error())
-		return C.int(0)
+	if sessionConfig.SingleUtterance == false {
+		logError("Language override is only available in single utterance mode", nil)
+		return result(resultError)
 	}
 
-	if err := resp.Error; err != nil {
-		logStatus = ("Could not recognize: " + err.GetMessage())
-		return C.int(0)
+	// Copy the session's configuration, so the session default stays untouched.
+	config := proto.Clone(sessionConfig).(*speechpb.StreamingRecognitionConfig)
+	config.Config.LanguageCode = goTranscriptLanguage
+
+	// The utterance is recognized by a new stream using the overridden language.
+	if err := restartStream(config); err != nil {
+		logError("Could not override language: ", err)
+		return result(resultError)
 	}
 
-	var helperString = "";
+	return result(resultOK)
+}
 
-	// Check received message for results and store it in helperString.
-	for _, result := range resp.Results {	
-		// Needed to get only the transcription without additional informations i.e. "confidence".
-		for _, alternative := range result.Alternatives { 
-			// If the alternative string starts with a space - remove it
+
+// restartAfterUtterance starts a new stream with the session's configuration when google
+// finished a single utterance (reverting a language override of the finished utterance).
+func restartAfterUtterance() {
+	sendMutex.Lock()
+	defer sendMutex.Unlock()
+
+	if initialized == false || finalized == true {
+		return
+	}
+
+	if err := restartStream(sessionConfig); err != nil {
+		logError("Could not restart after utterance: ", err)
+	}
+}
+
+
+// newStreamingConfig builds the initial configuration message of a stream.
+func newStreamingConfig(language string, sampleRate int32, model string, maxAlternatives int32, interimResults bool) (*speechpb.StreamingRecognitionConfig) {
+	// The library picks the model (see "SetModelHints()").
+	if strings.EqualFold(model, "auto") {
+		model = autoModel(language, sampleRate)
+	}
+
+	return &speechpb.StreamingRecognitionConfig{
+		Config: &speechpb.RecognitionConfig{
+			Encoding:			audioEncoding,		// LINEAR16 unless set (see "SetAudioEncoding()")
+			SampleRateHertz:	sampleRate,			// Remember to use a recording with 16KHz sample rate.
+			LanguageCode:		language,			// Can be adjusted to language to be transcribed. (BCP-47)
+			Model:				model,				// Can be either "video", "phone_call", "command_and_search", "default" (see https://cloud.google.com/speech-to-text/docs/basics)
+			MaxAlternatives:	maxAlternatives,	// Maximum number of recognition hypotheses: Valid values are 0-30, 0 or 1 return only one
+			DiarizationConfig:	diarizationConfig,	// nil if disabled (see "SetSpeakerDiarization()")
+			EnableWordConfidence:	wordConfidence,	// boolean (see "SetWordConfidence()")
+			EnableWordTimeOffsets:	wordTimeOffsets,	// boolean (see "SetWordTimeOffsets()")
+			SpeechContexts:		phraseHints,		// see "LoadPhraseHintsFromFile()"
+			},
+		InterimResults:	interimResults,	// boolean
+		SingleUtterance:	singleUtterance,	// boolean (see "SetSingleUtterance()")
+		EnableVoiceActivityEvents:	voiceActivityEvents,	// boolean (see "SetVoiceActivityEvents()")
+		}
+}
+
+
+// openStream creates a new stream on the session's client and sends the initial configuration message.
+func openStream(config *speechpb.StreamingRecognitionConfig) (speechpb.Speech_StreamingRecognizeClient, error) {
+
+	newStream, err := client.StreamingRecognize(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every stream is a new request to google.
+	logMutex.Lock()
+		requestCount++
+		requestID := currentRequestID()
+	logMutex.Unlock()
+	recordStream(newStream, requestID, config.Config.Model)
+
+	if err := newStream.Send(&speechpb.StreamingRecognizeRequest{
+				StreamingRequest: &speechpb.StreamingRecognizeRequest_StreamingConfig{
+					StreamingConfig: config,
+					},
+				}); 
+	err != nil {
+		return nil, err
+	}
+
+	// Every stream has its own clock (the result times start at zero).
+	streamMutex.Lock()
+		streamClocks[newStream] = &streamClock{sampleRate: int64(config.Config.SampleRateHertz)}
+	streamMutex.Unlock()
+
+	return newStream, nil
+}
+
+
+// sendToStream sends the request on a stream of the session, one request at a time (the send pump sends without
+// holding the sendMutex, but gRPC doesn't allow concurrent calls of Send and CloseSend on a stream).
+func sendToStream(target speechpb.Speech_StreamingRecognizeClient, request *speechpb.StreamingRecognizeRequest) (error) {
+	streamSendMutex.Lock()
+	defer streamSendMutex.Unlock()
+
+	return target.Send(request)
+}
+
+
+// closeStreamSend tells google that no more audio follows on a stream of the session (see "sendToStream()").
+func closeStreamSend(target speechpb.Speech_StreamingRecognizeClient) {
+	streamSendMutex.Lock()
+	defer streamSendMutex.Unlock()
+
+	target.CloseSend()
+}
+
+
+// retireStream forgets a stream of the session, which isn't received anymore (its billed time is kept).
+func retireStream(oldStream speechpb.Speech_StreamingRecognizeClient) {
+	streamMutex.Lock()
+		clock := streamClocks[oldStream]
+		delete(streamClocks, oldStream)
+	streamMutex.Unlock()
+	if clock == nil {
+		return
+	}
+
+	billingMutex.Lock()
+		retiredStreamSeconds += reportedStreamSeconds[clock]
+		delete(reportedStreamSeconds, clock)
+	billingMutex.Unlock()
+}
+
+
+// restartStream finishes the current stream and replaces it by a new one using the given configuration.
+// The caller has to hold the sendMutex.
+func restartStream(config *speechpb.StreamingRecognitionConfig) (error) {
+
+	// While offline there is no stream to replace (see "SetBackfill()").
+	if isOffline() {
+		return errors.New("google can't be reached (the audio is spooled until the connection is back)")
+	}
+
+	// Every new stream is a request (it isn't delayed by the rate limits, the session already holds its slot).
+	recordRequest()
+
+	// Open the new stream first, so the old one keeps running if this fails.
+	newStream, err := openStream(config)
+	if err != nil {
+		return err
+	}
+
+	streamMutex.Lock()
+		oldStream := stream
+		stream = newStream
+	streamMutex.Unlock()
+
+	// Tell google that no more audio follows, the final results of the old stream are still received.
+	closeStreamSend(oldStream)
+
+	// A send failure of the old stream doesn't affect the new one.
+	sendFailure = nil
+
+	return nil
+}
+
+	
+/*
+	SendAudio(recording, recordingLength C.int):
+	prepares the inputted audio data to be sent to google,
+	handles the sending process
+	
+	Parameters:
+		recording:
+			has to be a Pointer to short values committed by C++ function call
+			(16KHz Audio Stream)
+		
+		recordingLength:
+			just the length of the recording (needed as we can't use C++ vectors in golang)	
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+	
+// Next comment is needed by cgo to know which function to export.
+//export SendAudio
+func SendAudio(recording *C.short, recordingLength C.int) (C.int){
+	span := startSpan("SendAudio")
+	// The library assigns the timestamp (the time of the call, a restored session continues its timeline).
+	code := sendAudio(recording, recordingLength, libraryTimestamp(int64(recordingLength)))
+	endSpan(span, code)
+	return result(code)
+}
+
+
+/*
+	SendAudioWithTimestamp(recording *C.short, recordingLength C.int, timestampUs C.longlong) (C.int):
+	sends the audio like "SendAudio()" together with the host's capture timestamp of its first sample,
+	the timestamps of the results and words (see "ReceiveTranscriptJSON()") are then given in the host's media clock
+	(e.g. for A/V sync of captions), "SendAudio()" uses the time of the call instead
+	
+	Parameters:
+		recording:
+			the same as "SendAudio()"
+		recordingLength:
+			the same as "SendAudio()"
+		timestampUs:
+			the host's timestamp of the first sample in microseconds (any clock, e.g. the presentation timestamp of the media)
+	
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SendAudioWithTimestamp
+func SendAudioWithTimestamp(recording *C.short, recordingLength C.int, timestampUs C.longlong) (C.int){
+	span := startSpan("SendAudio")
+	code := sendAudio(recording, recordingLength, int64(timestampUs))
+	endSpan(span, code)
+	return result(code)
+}
+
+// sendAudio implements "SendAudio()" and "SendAudioWithTimestamp()" (the exports only add the tracing).
+func sendAudio(recording *C.short, recordingLength C.int, timestampUs int64) (C.int){
+	if code := checkCallbackThread("SendAudio"); code != resultOK {
+		return code
+	}
+
+	// Create a slice of C.short values.
+	var length = int(recordingLength) 	// Convert recordingLength from C.int to an int value (needed to define the sliceHeader in the following).
+	var list []C.short			// Define a new slice of C.shorts.
+	
+	// Pass the reference to the input C.short values to the slice's data.
+	sliceHeader := (*reflect.SliceHeader)((unsafe.Pointer(&list)))
+	sliceHeader.Len = length
+	sliceHeader.Cap = length
+	sliceHeader.Data = uintptr(unsafe.Pointer(recording))
+
+	// Skip a WAV header at the start of the session's audio (see "SetWavHeaderDetection()").
+	list = skipWavHeader(list)
+	
+	// Meter the input level (also without a session, e.g. to check the microphone).
+	meterInputLevel(list)
+
+	// While the recognition is muted (e.g. during the host's prompts), silence is sent instead (see "MuteRecognition()").
+	muted := isRecognitionMuted()
+	if muted {
+		list = make([]C.short, len(list))
+	} else {
+		// Warn about audio problems (clipping, DC offset, near silence).
+		diagnoseAudio(list)
+
+		// Warn about audio in another format than the session's (see go-speech-recognition-format.go).
+		detectFormatMismatch(list, timestampUs)
+	}
+
+	// Only the audio following the wake word is streamed (see "SetWakeWord()").
+	list, awake := gateWakeWord(list)
+
+	if muted == false && awake {
+		// Finalize the stream, when no speech has been detected for too long (see "SetNoSpeechTimeout()").
+		if detectNoSpeechTimeout(list) {
+			finalizeStream(eventSilenceTimeout)
+			return resultOK
+		}
+	}
+
+	// Process the audio before sending (the host's samples stay untouched), see "SetAutomaticGainControl()".
+	processed := preprocessAudio(list)
+
+	// As we need to send byte values instead of C.Shorts, the list gets copied in a temporary bytes.Buffer.
+	// (maybe changed in future for reduction of copy operations)
+	temporaryByteBuffer := new(bytes.Buffer)
+	err := binary.Write(temporaryByteBuffer, binary.LittleEndian, processed)
+	
+	if err != nil {
+		logError("binary.Write failed:", err)
+		return resultError
+	}	
+
+	return queueAudio(temporaryByteBuffer, timestampUs, true)
+}
+
+
+/*
+	SendAudioBytes(data *C.uint8_t, dataLength C.int) (C.int):
+	sends already encoded audio (e.g. FLAC, MULAW or OGG_OPUS frames of the host's capture stack) unchanged to google,
+	the encoding has to be set before (see "SetAudioEncoding()"), the processing of "SendAudio()"
+	(level metering, diagnostics, preprocessing and the no speech timeout) doesn't apply to it
+	
+	Parameters:
+		data:
+			the encoded audio
+		dataLength:
+			the number of bytes
+	
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SendAudioBytes
+func SendAudioBytes(data *C.uint8_t, dataLength C.int) (C.int){
+	if code := checkCallbackThread("SendAudioBytes"); code != resultOK {
+		return result(code)
+	}
+	if dataLength < 0 || (data == nil && dataLength > 0) {
+		logError("Invalid audio data", nil)
+		return result(resultInvalidArgument)
+	}
+
+	span := startSpan("SendAudio")
+	// The bytes are copied, the host may reuse its buffer after the call.
+	code := queueAudio(bytes.NewBuffer(C.GoBytes(unsafe.Pointer(data), dataLength)), time.Now().UnixNano() / 1000, false)
+	endSpan(span, code)
+	return result(code)
+}
+
+
+// queueAudio queues the audio in chunks for the send pump, pcm is set for the samples of "SendAudio()"
+// (which require the LINEAR16 encoding).
+func queueAudio(audio *bytes.Buffer, timestampUs int64, pcm bool) (C.int) {
+	// The preprocessing delays the samples of "SendAudio()", so they were captured earlier (see "SetNoiseSuppression()").
+	var delaySamples int64
+	if pcm {
+		delaySamples = preprocessingDelay()
+	}
+
+	// Ensure that the stream is initialized
+	sendMutex.Lock()
+		// Check if the stream is initialized
+		if initialized == false {
+
+			sendMutex.Unlock()
+
+			logError("Stream is not initialized", nil)
+			return resultNotInitialized
+		}
+		// Check if the stream has been finalized (no more audio is accepted).
+		if finalized == true {
+
+			sendMutex.Unlock()
+
+			logError("Stream has been finalized", nil)
+			return resultFinalized
+		}
+		// Check if the send pump failed to send earlier audio.
+		if sendFailure != nil {
+			err := sendFailure
+			sendMutex.Unlock()
+
+			logError("Could not send audio:", err)
+			return resultError
+		}
+		// The samples of "SendAudio()" can't be sent in another encoding.
+		if pcm && sessionConfig.Config.Encoding != speechpb.RecognitionConfig_LINEAR16 {
+
+			sendMutex.Unlock()
+
+			logError("SendAudio requires the LINEAR16 encoding (use SendAudioBytes for encoded audio)", nil)
+			return resultInvalidArgument
+		}
+		// Encoded audio can't be analyzed for the wake word (see "SetWakeWord()").
+		if pcm == false && wakeWordEnabled() {
+			sendMutex.Unlock()
+
+			logError("SendAudioBytes can't be used with a wake word (use SendAudio)", nil)
+			return resultInvalidArgument
+		}
+		// Encoded audio can't be replaced by silence, so it's dropped while the recognition is muted (see "MuteRecognition()").
+		if pcm == false && isRecognitionMuted() {
+			sendMutex.Unlock()
+			return resultOK
+		}
+		queue := audioQueue
+		queueCtx := ctx
+		sampleRate := int64(sessionConfig.Config.SampleRateHertz)
+		if timestampUs >= 0 && delaySamples > 0 {
+			timestampUs = max(timestampUs - delaySamples * 1000000 / sampleRate, 0)
+		}
+		bytesPerSample := encodingBytesPerSample(sessionConfig.Config.Encoding)
+		// The drift between the capture clock and the timestamps (see "GetClockDrift()")
+		if bytesPerSample > 0 {
+			measureDrift(int64(audio.Len()) / bytesPerSample, timestampUs, sampleRate)
+		}
+		// "CloseStream()" waits until this call returned.
+		inFlight.Add(1)
+	sendMutex.Unlock()
+	defer inFlight.Done()
+
+	// A blocking call can be canceled by "CancelPendingSend()".
+	callCtx, callDone := pendingSends.begin(queueCtx)
+	defer callDone()
+
+	// Bytes of the recording queued so far (needed for the timestamps of the chunks).
+	var queuedBytes int64
+
+	// For sending to google we split the audio into chunks, that are queued for the send pump.
+	// Their size adapts to the network (see "adaptChunkSize()").
+	chunkBytes := compressedChunkBytes
+	if bytesPerSample > 0 {
+		chunkBytes = int(int64(atomic.LoadInt32(&chunkMs)) * sampleRate / 1000 * bytesPerSample)
+	}
+	
+	for {
+		chunk := make([]byte, chunkBytes)
+
+		// Each loop run: Fill the chunk with the next values of the byte buffer.
+		// n is needed to keep track of the reading progress
+		n, err := audio.Read(chunk)		
+		
+		// Stop streaming when reaching the end of the input stream.
+		if err == io.EOF {
+			return resultOK
+		}
+
+		if n > 0 {
+			// Queue the chunk upto the n-th byte (only the last loop run may not fill it), if the queue is full the overflow policy applies.
+			atomic.AddInt64(&queuedAudioBytes, int64(n))
+			// The duration of compressed audio is unknown, so its chunks have no samples and no timestamp.
+			queued := audioChunk{data: chunk[:n], timestampUs: -1}
+			if bytesPerSample > 0 {
+				queued.samples = int64(n) / bytesPerSample
+				queued.timestampUs = timestampUs + queuedBytes / bytesPerSample * 1000000 / sampleRate
+			}
+			queuedBytes += int64(n)
+			if enqueue(queue, queued, callCtx.Done(), &audioOverflows, dropAudioChunk) == false {
+				atomic.AddInt64(&queuedAudioBytes, -int64(n))
+				if queueCtx.Err() != nil {
+					return resultOK
+				}
+				if callCtx.Err() != nil {
+					logError("Sending has been canceled", nil)
+					return resultCanceled
+				}
+				logError("Audio queue is full", nil)
+				return resultQueueFull
+			}
+		}
+	}
+}
+
+
+// sendPump runs in its own goroutine (started by "InitializeStream()") and sends the queued audio chunks to google.
+// A failure is saved and reported by the next "SendAudio()" call, a new stream (see "Reconfigure()") resets it.
+// When the stream gets finalized (finish is closed), the queued audio and the audio delayed by the preprocessing are sent,
+// before google is told that no more audio follows.
+func sendPump(pumpCtx context.Context, queue chan audioChunk, finish chan struct{}, done chan struct{}) {
+	defer close(done)
+
+	for {
+		// When the keep-alive is enabled, a silence frame is sent if no audio arrives in time.
+		var keepAlive <-chan time.Time
+		var keepAliveTimer *time.Timer
+		if thresholdMs := atomic.LoadInt32(&keepAliveThresholdMs); thresholdMs > 0 {
+			keepAliveTimer = time.NewTimer(time.Duration(thresholdMs) * time.Millisecond)
+			keepAlive = keepAliveTimer.C
+		}
+
+		var chunk audioChunk
+		keepAliveFrame := false
+		select {
+		case chunk = <-queue:
+			atomic.AddInt64(&queuedAudioBytes, -int64(len(chunk.data)))
+		case <-keepAlive:
+			chunk = silenceFrame()
+			// No silence can be generated for compressed audio.
+			if len(chunk.data) == 0 {
+				noteWakeup()
+				continue
+			}
+			keepAliveFrame = true
+			atomic.AddUint64(&keepAliveFrames, 1)
+		case <-finish:
+			if keepAliveTimer != nil {
+				keepAliveTimer.Stop()
+			}
+			finishSending(pumpCtx, queue)
+			// Afterwards the chunks are discarded (so "SendAudio()" doesn't block on a full queue).
+			finish = nil
+			continue
+		case <-pumpCtx.Done():
+			return
+		}
+		if keepAliveTimer != nil {
+			keepAliveTimer.Stop()
+		}
+		noteWakeup()
+
+		// Stop streaming when the budget is used up (the queued audio isn't sent anymore).
+		if sendChunk(pumpCtx, chunk, keepAliveFrame, finish == nil) {
+			finalizeStream(eventBudgetExceeded)
+			if finish != nil {
+				closeSending()
+				finish = nil
+			}
+		}
+	}
+}
+
+
+// finishSending sends the audio queued before the finalization and the audio delayed by the preprocessing
+// (see "flushPreprocessing()"), then it tells google that no more audio follows.
+func finishSending(pumpCtx context.Context, queue chan audioChunk) {
+	for drained := false; drained == false; {
+		select {
+		case chunk := <-queue:
+			atomic.AddInt64(&queuedAudioBytes, -int64(len(chunk.data)))
+			sendChunk(pumpCtx, chunk, false, false)
+		default:
+			drained = true
+		}
+	}
+
+	if delayed := flushPreprocessing(); len(delayed) > 0 {
+		data := new(bytes.Buffer)
+		binary.Write(data, binary.LittleEndian, delayed)
+		sendChunk(pumpCtx, audioChunk{data: data.Bytes(), samples: int64(len(delayed)), timestampUs: -1}, false, false)
+	}
+
+	closeSending()
+}
+
+
+// closeSending tells google that no more audio follows (there is no stream while offline).
+func closeSending() {
+	sendMutex.Lock()
+		streamMutex.Lock()
+			currentStream := stream
+		streamMutex.Unlock()
+	sendMutex.Unlock()
+	if currentStream != nil {
+		closeStreamSend(currentStream)
+	}
+}
+
+
+// sendChunk sends the chunk on the current stream (resp. spools it while offline) and returns whether the budget is used up.
+// After a failure or once the sending has been finished (see "finishSending()") the chunk is discarded.
+func sendChunk(pumpCtx context.Context, chunk audioChunk, keepAliveFrame bool, finished bool) (bool) {
+	// While the quota is exceeded, the audio waits (see "pauseForQuota()").
+	sendMutex.Lock()
+		resume := quotaResume
+	sendMutex.Unlock()
+	if resume != nil {
+		select {
+		case <-resume:
+		case <-pumpCtx.Done():
+			return false
+		}
+	}
+
+	// The sendMutex isn't held while sending, so the exports don't wait for the network.
+	// A chunk refused by a stream replaced meanwhile (see "Reconfigure()") is sent on the new stream.
+	budgetExceeded := false
+	for sent := false; sent == false; {
+		var current speechpb.Speech_StreamingRecognizeClient
+		sendMutex.Lock()
+			if sendFailure == nil && finished == false && spool != nil {
+				// While offline the audio is spooled (see "SetBackfill()"), the keep-alive isn't needed.
+				if keepAliveFrame == false {
+					spool.write(chunk)
+				}
+			} else if sendFailure == nil && finished == false {
+				streamMutex.Lock()
+					current = stream
+				streamMutex.Unlock()
+			}
+		sendMutex.Unlock()
+		if current == nil {
+			break
+		}
+
+		sendStart := time.Now()
+		err := sendToStream(current, &speechpb.StreamingRecognizeRequest{
+				StreamingRequest: &speechpb.StreamingRecognizeRequest_AudioContent{
+					AudioContent: chunk.data,
+					},
+				})
+
+		sendMutex.Lock()
+			streamMutex.Lock()
+				replaced := stream != current
+				clock := streamClocks[current]
+			streamMutex.Unlock()
+			sent = err == nil || replaced == false || finalized || pumpCtx.Err() != nil
+			if err != nil && sent && pumpCtx.Err() == nil && finalized == false {
+				sendFailure = err
+			}
+			if err == nil {
+				adaptChunkSize(time.Since(sendStart))
+
+				// Only audio with a known duration is billed (not compressed audio).
+				budgetExceeded = accountBilledAudio(float64(chunk.samples) / float64(sessionConfig.Config.SampleRateHertz))
+
+				if clock != nil {
+					clock.compensate(compensatedSampleRate())
+					clock.advance(chunk.samples, chunk.timestampUs)
+				}
+			}
+		sendMutex.Unlock()
+	}
+	return budgetExceeded
+}
+
+
+// enqueue pushes the item into the queue, if the queue is full the overflow policy applies
+// (the overflow counter gets incremented and onDrop (if set) gets called for every dropped item).
+// Returns false if the item has been rejected or done has been closed while blocking.
+func enqueue[T any](queue chan T, item T, done <-chan struct{}, overflows *uint64, onDrop func(T)) (bool) {
+
+	// Fast path: there is space left in the queue.
+	select {
+	case queue <- item:
+		return true
+	default:
+	}
+
+	switch atomic.LoadInt32(&overflowPolicy) {
+
+	case overflowDropOldest:
+		atomic.AddUint64(overflows, 1)
+		for {
+			// Make space by dropping the oldest item (the consumer may have made space meanwhile).
+			select {
+			case dropped := <-queue:
+				if onDrop != nil {
+					onDrop(dropped)
+				}
+			default:
+			}
+			select {
+			case queue <- item:
+				return true
+			default:
+			}
+		}
+
+	case overflowDropNewest:
+		atomic.AddUint64(overflows, 1)
+		if onDrop != nil {
+			onDrop(item)
+		}
+		return true
+
+	case overflowError:
+		atomic.AddUint64(overflows, 1)
+		return false
+	}
+
+	// overflowBlock: wait until there is space left.
+	select {
+	case queue <- item:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+
+// adaptChunkSize smoothes the send latency and derives the chunk size of the next "SendAudio()" calls from it.
+func adaptChunkSize(latency time.Duration) {
+	latencyUs := int64(latency / time.Microsecond)
+	smoothed := atomic.LoadInt64(&sendLatencyUs)
+	if smoothed == 0 {
+		smoothed = latencyUs
+	} else {
+		smoothed += int64(sendLatencySmoothing * float64(latencyUs - smoothed))
+	}
+	atomic.StoreInt64(&sendLatencyUs, smoothed)
+
+	ms := int32(smoothed * chunkLatencyFactor / 1000)
+	if ms < minChunkMs {
+		ms = minChunkMs
+	}
+	// The profile may limit the chunk size for a lower latency (see "ApplyProfile()").
+	if ceiling := atomic.LoadInt32(&chunkCeilingMs); ms > ceiling {
+		ms = ceiling
+	}
+	atomic.StoreInt32(&chunkMs, ms)
+}
+
+
+// silenceFrame creates a short frame of silence (used to keep the stream alive during pauses),
+// the frame is empty if the session's encoding is compressed.
+func silenceFrame() (audioChunk) {
+	sendMutex.Lock()
+		samples := int(sessionConfig.Config.SampleRateHertz) * keepAliveFrameMs / 1000
+		encoding := sessionConfig.Config.Encoding
+	sendMutex.Unlock()
+
+	switch encoding {
+	case speechpb.RecognitionConfig_LINEAR16:
+		// 16 bit samples: 2 bytes per sample
+		return audioChunk{data: make([]byte, samples * 2), samples: int64(samples), timestampUs: -1}
+	case speechpb.RecognitionConfig_MULAW:
+		// 0xFF is the zero level of mu-law.
+		return audioChunk{data: bytes.Repeat([]byte{0xFF}, samples), samples: int64(samples), timestampUs: -1}
+	}
+	return audioChunk{timestampUs: -1}
+}
+
+
+// encodingBytesPerSample returns the bytes per sample of an encoding, 0 for compressed audio.
+func encodingBytesPerSample(encoding speechpb.RecognitionConfig_AudioEncoding) (int64) {
+	switch encoding {
+	case speechpb.RecognitionConfig_LINEAR16:
+		return 2
+	case speechpb.RecognitionConfig_MULAW:
+		return 1
+	}
+	return 0
+}
+
+
+// dropAudioChunk keeps the queued audio bytes up to date, when a chunk is dropped by the overflow policy.
+func dropAudioChunk(chunk audioChunk) {
+	atomic.AddInt64(&queuedAudioBytes, -int64(len(chunk.data)))
+}
+
+
+/*
+	SetOverflowPolicy(cPolicy C.int) (C.int):
+	sets how the internal audio and result queues behave when they are full
+	(e.g. because the network is too slow or the host doesn't receive fast enough)
+	
+	Parameter:
+		cPolicy C.int
+			0 (block): wait until there is space left (default, no data is lost)
+			1 (drop oldest): drop the oldest queued item (lowest latency)
+			2 (drop newest): drop the new item
+			3 (error): reject the new item, "SendAudio()" resp. "ReceiveTranscript()" fail
+		
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetOverflowPolicy
+func SetOverflowPolicy(cPolicy C.int) (C.int) {
+	policy := int32(cPolicy)
+	if policy < overflowBlock || policy > overflowError {
+		logError("Unknown overflow policy", nil)
+		return result(resultInvalidArgument)
+	}
+	atomic.StoreInt32(&overflowPolicy, policy)
+	return result(resultOK)
+}
+
+
+// isFinalized returns whether the stream has been finalized (safe to call without holding the sendMutex).
+func isFinalized() (bool) {
+	sendMutex.Lock()
+	defer sendMutex.Unlock()
+	return finalized
+}
+
+
+// finalizeStream gracefully ends the stream: no more audio is sent, google delivers the remaining results
+// (which can still be received) and the event gets reported to the host.
+func finalizeStream(event int32) {
+	finalizeSession(0, event)
+}
+
+
+// finalizeSession finalizes the stream like "finalizeStream()", but only if it belongs to the given
+// session generation (0 matches every session), so late timers can't finalize a newer session.
+func finalizeSession(generation uint64, event int32) {
+	sendMutex.Lock()
+		if initialized == false || finalized == true || (generation != 0 && generation != sessionGeneration) {
+			sendMutex.Unlock()
+			return
+		}
+		finalized = true
+		finish := finishing
+	sendMutex.Unlock()
+
+	// The send pump finishes the sending (see "sendPump()").
+	close(finish)
+
+	reportEvent(event)
+}
+
+
+// reportEvent queues an event for "PollEvent()" (if the host doesn't poll the events, new ones get dropped).
+func reportEvent(event int32) {
+	select {
+	case eventQueue <- event:
+	default:
+	}
+}
+
+
+// detectNoSpeechTimeout runs the voice activity detection on the samples and returns true,
+// if the silence lasts longer than the no speech timeout.
+func detectNoSpeechTimeout(samples []C.short) (bool) {
+	timeoutSeconds := atomic.LoadInt32(&noSpeechTimeoutSeconds)
+
+	sendMutex.Lock()
+	defer sendMutex.Unlock()
+
+	if initialized == false || timeoutSeconds <= 0 {
+		return false
+	}
+
+	if rms(samples) >= vadThreshold {
+		silentSamples = 0
+		return false
+	}
+
+	silentSamples += int64(len(samples))
+	return silentSamples >= int64(timeoutSeconds) * int64(sessionConfig.Config.SampleRateHertz)
+}
+
+
+// rms calculates the root mean square level of the samples.
+func rms[T ~int16 | ~float64](samples []T) (float64) {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, sample := range samples {
+		sum += float64(sample) * float64(sample)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+
+/*
+	SetWavHeaderDetection(cEnabled C.int):
+	detects a WAV header at the start of the first audio of a session passed to "SendAudio()" (e.g. when a WAV file is
+	streamed as is), the header is skipped and its format is validated against the session's settings
+	(a mismatch is logged as a warning, see "GetLog()"), has to be called before the first "SendAudio()" of a session
+
+	Parameter:
+		cEnabled C.int
+			(1 to enable, 0 to disable the detection (default))
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetWavHeaderDetection
+func SetWavHeaderDetection(cEnabled C.int) () {
+	sendMutex.Lock()
+		wavHeaderDetection = int32(cEnabled) == int32(1)
+	sendMutex.Unlock()
+}
+
+
+// skipWavHeader returns the samples after a WAV header at the start of the session's audio (if enabled)
+// and warns about a format, which doesn't match the session's settings.
+func skipWavHeader(samples []C.short) ([]C.short) {
+	sendMutex.Lock()
+		if initialized == false || awaitingFirstAudio == false || len(samples) == 0 {
+			sendMutex.Unlock()
+			return samples
+		}
+		awaitingFirstAudio = false
+		enabled := wavHeaderDetection
+		sampleRate := sessionConfig.Config.SampleRateHertz
+	sendMutex.Unlock()
+
+	if enabled == false {
+		return samples
+	}
+
+	// The samples as the bytes they were read from
+	data := (*[1 << 30]byte)(unsafe.Pointer(&samples[0]))[:len(samples) * 2:len(samples) * 2]
+	header, err := parseWavHeader(data)
+	if err != nil {
+		logWarning("Could not parse the WAV header, the audio is sent unchanged: " + err.Error())
+		return samples
+	}
+	if header == nil {
+		return samples
+	}
+
+	if header.format != wavFormatPCM || header.bitsPerSample != 16 {
+		logWarning("The WAV audio isn't 16 bit PCM (format " + strconv.Itoa(int(header.format)) +
+			", " + strconv.Itoa(int(header.bitsPerSample)) + " bit)")
+	}
+	if header.channels != 1 {
+		logWarning("The WAV audio has " + strconv.Itoa(int(header.channels)) + " channels instead of 1")
+	}
+	if int32(header.sampleRate) != sampleRate {
+		logWarning("The sample rate of the WAV audio (" + strconv.Itoa(int(header.sampleRate)) +
+			" Hz) doesn't match the session's sample rate (" + strconv.Itoa(int(sampleRate)) + " Hz)")
+	}
+	return samples[header.dataOffset / 2:]
+}
+
+
+// parseWavHeader parses the RIFF header at the start of the data, the header is nil if the data doesn't start with one.
+func parseWavHeader(data []byte) (*wavHeader, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, nil
+	}
+
+	var header wavHeader
+	foundFormat := false
+	// The chunks follow the RIFF header, every chunk starts with its ID and size (padded to an even size).
+	for offset := 12; offset + 8 <= len(data); {
+		id := string(data[offset:offset + 4])
+		size := int(binary.LittleEndian.Uint32(data[offset + 4:offset + 8]))
+		body := offset + 8
+
+		switch id {
+		case "fmt ":
+			if size < 16 || body + 16 > len(data) {
+				return nil, errors.New("incomplete format chunk")
+			}
+			header.format = binary.LittleEndian.Uint16(data[body:])
+			header.channels = binary.LittleEndian.Uint16(data[body + 2:])
+			header.sampleRate = binary.LittleEndian.Uint32(data[body + 4:])
+			header.bitsPerSample = binary.LittleEndian.Uint16(data[body + 14:])
+			foundFormat = true
+		case "data":
+			if foundFormat == false {
+				return nil, errors.New("no format chunk before the data chunk")
+			}
+			header.dataOffset = body
+			return &header, nil
+		}
+		offset = body + size + size % 2
+	}
+	return nil, errors.New("no data chunk in the first audio")
+}
+
+
+// meterInputLevel saves the RMS and peak level of the samples and passes them to the level callback.
+func meterInputLevel(samples []C.short) {
+	rmsDB := levelDB(rms(samples))
+	peakDB := levelDB(peak(samples))
+	atomic.StoreUint64(&inputRMSBits, math.Float64bits(rmsDB))
+	atomic.StoreUint64(&inputPeakBits, math.Float64bits(peakDB))
+
+	callbackMutex.Lock()
+		callback, userData := levelCallback, levelUserData
+	callbackMutex.Unlock()
+
+	if callback != nil {
+		callLevelCallback(callback, userData, rmsDB, peakDB)
+	}
+}
+
+
+// diagnoseAudio checks the samples for clipping, DC offset and near silence,
+// every problem is logged as a warning and reported as an event once per session.
+func diagnoseAudio(samples []C.short) {
+	if len(samples) == 0 {
+		return
+	}
+
+	var clipped int
+	var sum float64
+	for _, sample := range samples {
+		if sample >= clippingLevel || sample <= -clippingLevel {
+			clipped++
+		}
+		sum += float64(sample)
+	}
+
+	sendMutex.Lock()
+		if initialized == false {
+			sendMutex.Unlock()
+			return
+		}
+
+		var problems []int32
+		if float64(clipped) / float64(len(samples)) > clippingRatio {
+			problems = append(problems, eventClipping)
+		}
+		if math.Abs(sum / float64(len(samples))) > dcOffsetLevel {
+			problems = append(problems, eventDCOffset)
+		}
+		if rms(samples) < nearSilenceLevel {
+			nearSilentSamples += int64(len(samples))
+			if nearSilentSamples >= nearSilenceSeconds * int64(sessionConfig.Config.SampleRateHertz) {
+				problems = append(problems, eventNearSilence)
+			}
+		} else {
+			nearSilentSamples = 0
+		}
+
+		var newProblems []int32
+		for _, problem := range problems {
+			if reportedAudioProblems[problem] == false {
+				reportedAudioProblems[problem] = true
+				newProblems = append(newProblems, problem)
+			}
+		}
+	sendMutex.Unlock()
+
+	for _, problem := range newProblems {
+		switch problem {
+		case eventClipping:
+			logWarning("Audio is clipping, reduce the input gain")
+		case eventDCOffset:
+			logWarning("Audio has a DC offset, check the microphone")
+		case eventNearSilence:
+			logWarning("Audio is nearly silent, check the microphone and the input gain")
+		}
+		reportEvent(problem)
+	}
+}
+
+
+// preprocessAudio applies the enabled preprocessing stages to a copy of the samples
+// (returns the samples themselves if no stage is enabled).
+func preprocessAudio(samples []C.short) ([]C.short) {
+	preprocessMutex.Lock()
+	defer preprocessMutex.Unlock()
+
+	if agcEnabled == false && suppressor == nil && highPass == nil {
+		return samples
+	}
+
+	processed := make([]float64, len(samples))
+	for i, sample := range samples {
+		processed[i] = float64(sample)
+	}
+
+	if highPass != nil {
+		highPass.process(processed)
+	}
+	if suppressor != nil {
+		processed = suppressor.process(processed)
+	}
+	if agcEnabled {
+		applyGainControl(processed)
+	}
+
+	return quantizeSamples(processed)
+}
+
+
+// preprocessingDelay returns the number of samples the preprocessed audio is delayed by.
+func preprocessingDelay() (int64) {
+	preprocessMutex.Lock()
+	defer preprocessMutex.Unlock()
+
+	if suppressor == nil {
+		return 0
+	}
+	return noiseLatency
+}
+
+
+// flushPreprocessing returns the samples still delayed by the noise suppression (nil without it),
+// so the end of the audio isn't lost when the stream is finalized.
+func flushPreprocessing() ([]C.short) {
+	preprocessMutex.Lock()
+	defer preprocessMutex.Unlock()
+
+	if suppressor == nil {
+		return nil
+	}
+	processed := suppressor.flush()
+	if agcEnabled {
+		applyGainControl(processed)
+	}
+	return quantizeSamples(processed)
+}
+
+
+// quantizeSamples converts the processed samples back to 16 bit samples (limited to the range of 16 bit).
+func quantizeSamples(processed []float64) ([]C.short) {
+	converted := make([]C.short, len(processed))
+	for i, sample := range processed {
+		converted[i] = C.short(math.Max(math.Min(math.Round(sample), math.MaxInt16), math.MinInt16))
+	}
+	return converted
+}
+
+
+// applyGainControl adapts the gain to the samples' level and amplifies them (without clipping).
+// The caller has to hold the preprocessMutex.
+func applyGainControl(samples []float64) {
+	if level := rms(samples); level > agcNoiseFloor {
+		desired := math.Max(math.Min(agcTargetRMS / level, agcMaxGain), agcMinGain)
+		agcGain += (desired - agcGain) * agcAdaptation
+	}
+
+	gain := agcGain
+	if highest := peak(samples); highest * gain > math.MaxInt16 {
+		gain = math.MaxInt16 / highest
+	}
+
+	for i := range samples {
+		samples[i] *= gain
+	}
+}
+
+
+// newHighPassFilter creates a second order butterworth high-pass filter (coefficients of the "Audio EQ Cookbook").
+func newHighPassFilter(cutoffHz float64, sampleRate float64) (*biquadFilter) {
+	omega := 2 * math.Pi * cutoffHz / sampleRate
+	alpha := math.Sin(omega) / math.Sqrt2	// sin(omega) / (2 * Q) with Q = 1/sqrt(2)
+	a0 := 1 + alpha
+
+	return &biquadFilter{
+		b0:	(1 + math.Cos(omega)) / 2 / a0,
+		b1:	-(1 + math.Cos(omega)) / a0,
+		b2:	(1 + math.Cos(omega)) / 2 / a0,
+		a1:	-2 * math.Cos(omega) / a0,
+		a2:	(1 - alpha) / a0,
+	}
+}
+
+
+// process filters the samples in place (the filter state carries over to the next call).
+func (filter *biquadFilter) process(samples []float64) {
+	for i, x := range samples {
+		y := filter.b0 * x + filter.b1 * filter.x1 + filter.b2 * filter.x2 - filter.a1 * filter.y1 - filter.a2 * filter.y2
+		filter.x2, filter.x1 = filter.x1, x
+		filter.y2, filter.y1 = filter.y1, y
+		samples[i] = y
+	}
+}
+
+
+/*
+	SetHighPassFilter(cEnabled C.int, cCutoffHz C.double) (C.int):
+	enables the high-pass filter, which removes rumble (e.g. handling noise) and the DC offset of cheap microphones
+	from the audio before it's sent, speech is hardly affected below 120 Hz,
+	has to be called before "InitializeStream()" to take effect
+	
+	Parameter:
+		cEnabled C.int
+			(1 to enable, 0 to disable the high-pass filter (default))
+		cCutoffHz C.double
+			(the cutoff frequency between 20 and 300 Hz, e.g. 100.0)
+		
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetHighPassFilter
+func SetHighPassFilter(cEnabled C.int, cCutoffHz C.double) (C.int) {
+	cutoffHz := float64(cCutoffHz)
+	if int32(cEnabled) == int32(1) && !(cutoffHz >= 20 && cutoffHz <= 300) {
+		logError("Cutoff frequency has to be between 20 and 300 Hz", nil)
+		return result(resultInvalidArgument)
+	}
+
+	preprocessMutex.Lock()
+		highPassEnabled = int32(cEnabled) == int32(1)
+		highPassCutoffHz = cutoffHz
+	preprocessMutex.Unlock()
+	return result(resultOK)
+}
+
+
+// newNoiseSuppressor creates the noise suppressor of a session.
+func newNoiseSuppressor(strength float64) (*noiseSuppressor) {
+	return &noiseSuppressor{
+		strength:	strength,
+		window:		make([]float64, noiseFrameSize),
+		tail:		make([]float64, noiseHopSize),
+		// The output is a hop behind, so every call can return as many samples as it got.
+		ready:		make([]float64, noiseHopSize),
+	}
+}
+
+
+// process denoises the samples and returns the same number of (delayed) samples.
+func (ns *noiseSuppressor) process(samples []float64) ([]float64) {
+	ns.pending = append(ns.pending, samples...)
+	for len(ns.pending) >= noiseHopSize {
+		copy(ns.window, ns.window[noiseHopSize:])
+		copy(ns.window[noiseHopSize:], ns.pending[:noiseHopSize])
+		ns.pending = ns.pending[noiseHopSize:]
+		ns.ready = append(ns.ready, ns.processFrame()...)
+	}
+
+	output := ns.ready[:len(samples)]
+	ns.ready = append([]float64{}, ns.ready[len(samples):]...)
+	return output
+}
+
+
+// flush returns the delayed samples (the frames are completed by silence).
+func (ns *noiseSuppressor) flush() ([]float64) {
+	return ns.process(make([]float64, noiseLatency))
+}
+
+
+// processFrame denoises the current frame and returns the next hop of output samples (overlap-add).
+func (ns *noiseSuppressor) processFrame() ([]float64) {
+	// Square root of the hann window for analysis and synthesis, overlapping by half they add up to 1.
+	spectrum := make([]complex128, noiseFrameSize)
+	for i, sample := range ns.window {
+		spectrum[i] = complex(sample * math.Sqrt(0.5 - 0.5 * math.Cos(2 * math.Pi * float64(i) / noiseFrameSize)), 0)
+	}
+	fft(spectrum, false)
+
+	if ns.noise == nil {
+		ns.noise = make([]float64, noiseFrameSize)
+		for i, bin := range spectrum {
+			ns.noise[i] = cmplx.Abs(bin)
+		}
+	}
+
+	for i, bin := range spectrum {
+		magnitude := cmplx.Abs(bin)
+
+		// Quiet frames are most likely noise, so the estimate follows them quickly and loud frames slowly.
+		if magnitude < ns.noise[i] {
+			ns.noise[i] += (magnitude - ns.noise[i]) * 0.1
+		} else {
+			ns.noise[i] += (magnitude - ns.noise[i]) * 0.001
+		}
+
+		gain := 1.0
+		if magnitude > 0 {
+			gain = math.Sqrt(math.Max(1 - noiseOverSubtraction * ns.strength * (ns.noise[i] * ns.noise[i]) / (magnitude * magnitude), noiseFloorGain * noiseFloorGain))
+		}
+		spectrum[i] = bin * complex(gain, 0)
+	}
+
+	fft(spectrum, true)
+	output := make([]float64, noiseHopSize)
+	for i := range spectrum {
+		sample := real(spectrum[i]) * math.Sqrt(0.5 - 0.5 * math.Cos(2 * math.Pi * float64(i) / noiseFrameSize))
+		if i < noiseHopSize {
+			output[i] = ns.tail[i] + sample
+		} else {
+			ns.tail[i - noiseHopSize] = sample
+		}
+	}
+	return output
+}
+
+
+// fft transforms the values in place (radix-2, the length has to be a power of two), inverse includes the scaling.
+func fft(values []complex128, inverse bool) {
+	n := len(values)
+
+	// Bit reversal permutation.
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j & bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			values[i], values[j] = values[j], values[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, sign * 2 * math.Pi / float64(size)))
+		for start := 0; start < n; start += size {
+			twiddle := complex(1, 0)
+			for k := 0; k < size / 2; k++ {
+				even, odd := values[start + k], values[start + k + size / 2] * twiddle
+				values[start + k] = even + odd
+				values[start + k + size / 2] = even - odd
+				twiddle *= step
+			}
+		}
+	}
+
+	if inverse {
+		for i := range values {
+			values[i] /= complex(float64(n), 0)
+		}
+	}
+}
+
+
+/*
+	SetNoiseSuppression(cEnabled C.int, cStrength C.double) (C.int):
+	enables the noise suppression, which removes steady background noise (e.g. fans or street noise)
+	from the audio before it's sent, the audio gets delayed by 32 ms (at 16kHz, its end is sent when the stream is finalized),
+	has to be called before "InitializeStream()" to take effect (every session has its own noise estimate)
+	
+	Parameter:
+		cEnabled C.int
+			(1 to enable, 0 to disable the noise suppression (default))
+		cStrength C.double
+			(how much noise is removed between 0.0 and 1.0, e.g. 0.5, higher values can distort the speech)
+		
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetNoiseSuppression
+func SetNoiseSuppression(cEnabled C.int, cStrength C.double) (C.int) {
+	strength := float64(cStrength)
+	if int32(cEnabled) == int32(1) && !(strength >= 0 && strength <= 1) {
+		logError("Noise suppression strength has to be between 0.0 and 1.0", nil)
+		return result(resultInvalidArgument)
+	}
+
+	preprocessMutex.Lock()
+		noiseSuppressionEnabled = int32(cEnabled) == int32(1)
+		noiseSuppressionStrength = strength
+	preprocessMutex.Unlock()
+	return result(resultOK)
+}
+
+
+/*
+	SetAutomaticGainControl(cEnabled C.int, cTargetDB C.double) (C.int):
+	enables the automatic gain control, which amplifies or attenuates the audio before it's sent
+	to bring it to the target level (e.g. for hosts with uncontrolled microphone gain),
+	the gain adapts smoothly (between -20 dB and +20 dB) and silence isn't amplified,
+	the level metering and the diagnostics (see "GetInputLevel()") still measure the unprocessed audio
+	
+	Parameter:
+		cEnabled C.int
+			(1 to enable, 0 to disable the automatic gain control (default))
+		cTargetDB C.double
+			(the target RMS level in dBFS, e.g. -20.0)
+		
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetAutomaticGainControl
+func SetAutomaticGainControl(cEnabled C.int, cTargetDB C.double) (C.int) {
+	if int32(cEnabled) != int32(1) {
+		preprocessMutex.Lock()
+			agcEnabled = false
+		preprocessMutex.Unlock()
+		return result(resultOK)
+	}
+
+	targetDB := float64(cTargetDB)
+	if !(targetDB > minLevelDB && targetDB <= 0) {
+		logError("Target level has to be between -96.0 and 0.0 dBFS", nil)
+		return result(resultInvalidArgument)
+	}
+
+	preprocessMutex.Lock()
+		agcEnabled = true
+		agcTargetRMS = 32768 * math.Pow(10, targetDB / 20)
+	preprocessMutex.Unlock()
+	return result(resultOK)
+}
+
+
+// peak returns the highest absolute sample value.
+func peak[T ~int16 | ~float64](samples []T) (float64) {
+	var highest float64
+	for _, sample := range samples {
+		highest = math.Max(highest, math.Abs(float64(sample)))
+	}
+	return highest
+}
+
+
+// levelDB converts a sample level into dBFS (decibels relative to full scale).
+func levelDB(level float64) (float64) {
+	if level <= 0 {
+		return minLevelDB
+	}
+	return math.Max(20 * math.Log10(level / 32768), minLevelDB)
+}
+
+
+/*
+	GetInputLevel(rmsDB *C.double, peakDB *C.double) (C.int):
+	retrieves the level of the audio passed to the last "SendAudio()" call (also without a session),
+	e.g. to render a VU meter or to confirm the microphone is live
+	
+	Parameters:
+		rmsDB:
+			The pointer which is used to store the RMS level in dBFS (between -96.0 (silence) and 0.0 (full scale))
+		peakDB:
+			The pointer which is used to store the peak level in dBFS (between -96.0 (silence) and 0.0 (full scale))
+		
+	Return:
+		0 (1 with legacy return codes)
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetInputLevel
+func GetInputLevel(rmsDB *C.double, peakDB *C.double) (C.int) {
+	*rmsDB = C.double(math.Float64frombits(atomic.LoadUint64(&inputRMSBits)))
+	*peakDB = C.double(math.Float64frombits(atomic.LoadUint64(&inputPeakBits)))
+	return result(resultOK)
+}
+
+
+/*
+	SetLevelCallback(cCallback unsafe.Pointer, cUserData unsafe.Pointer):
+	registers a callback, which gets the level of the audio passed to every "SendAudio()" call
+	(invoked by "SendAudio()" on the host's thread before the audio is queued, unless the callbacks are queued, see "SetCallbackDispatch()")
+	
+	Parameter:
+		cCallback unsafe.Pointer
+			(the callback as a C function pointer "void callback(double rmsDB, double peakDB, void* userData)",
+			NULL removes the callback)
+		cUserData unsafe.Pointer
+			(passed to the callback unchanged, e.g. a pointer to the host's object)
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetLevelCallback
+func SetLevelCallback(cCallback unsafe.Pointer, cUserData unsafe.Pointer) () {
+	callbackMutex.Lock()
+		levelCallback = cCallback
+		levelUserData = cUserData
+	callbackMutex.Unlock()
+}
+
+
+/*
+	SetNoSpeechTimeout(cSeconds C.int) (C.int):
+	finalizes the stream, when no speech has been detected in the sent audio for the given duration
+	(e.g. for kiosk interactions), the remaining results can still be received,
+	the event GO_SPEECH_RECOGNITION_EVENT_SILENCE_TIMEOUT is reported (see "PollEvent()")
+	
+	Parameter:
+		cSeconds C.int
+			(the duration of silence in seconds, 0 disables the timeout (default))
+		
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetNoSpeechTimeout
+func SetNoSpeechTimeout(cSeconds C.int) (C.int) {
+	if int32(cSeconds) < 0 {
+		logError("No speech timeout must not be negative", nil)
+		return result(resultInvalidArgument)
+	}
+	atomic.StoreInt32(&noSpeechTimeoutSeconds, int32(cSeconds))
+	return result(resultOK)
+}
+
+
+/*
+	SetMaxSessionDuration(cSeconds C.int) (C.int):
+	finalizes the stream, when the session lasts longer than the given duration
+	(guards against forgetting to stop streaming), the remaining results can still be received,
+	the event GO_SPEECH_RECOGNITION_EVENT_MAX_DURATION is reported (see "PollEvent()"),
+	if called during a session the duration is measured from the session's initialization
+	
+	Parameter:
+		cSeconds C.int
+			(the maximum duration in seconds, 0 means unlimited (default))
+		
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetMaxSessionDuration
+func SetMaxSessionDuration(cSeconds C.int) (C.int) {
+	if int32(cSeconds) < 0 {
+		logError("Maximum session duration must not be negative", nil)
+		return result(resultInvalidArgument)
+	}
+
+	sendMutex.Lock()
+		maxSessionSeconds = int32(cSeconds)
+		if initialized == true {
+			armMaxDurationTimer()
+		}
+	sendMutex.Unlock()
+	return result(resultOK)
+}
+
+
+// armMaxDurationTimer (re)starts the timer which finalizes the session after the maximum duration.
+// The caller has to hold the sendMutex.
+func armMaxDurationTimer() {
+	if maxDurationTimer != nil {
+		maxDurationTimer.Stop()
+		maxDurationTimer = nil
+	}
+
+	if maxSessionSeconds <= 0 {
+		return
+	}
+
+	remaining := time.Until(sessionStart.Add(time.Duration(maxSessionSeconds) * time.Second))
+	generation := sessionGeneration
+	maxDurationTimer = time.AfterFunc(remaining, func() {
+		finalizeSession(generation, eventMaxDuration)
+	})
+}
+
+
+// accountBilledAudio adds the sent audio to the billed time and returns true, if the daily budget is exceeded.
+func accountBilledAudio(seconds float64) (bool) {
+	billingMutex.Lock()
+	defer billingMutex.Unlock()
+
+	rollBillingDay()
+	sessionBilledSeconds += seconds
+	dailyBilledSeconds += seconds
+
+	return dailyBudgetSeconds > 0 && dailyBilledSeconds >= dailyBudgetSeconds
+}
+
+
+// accountDailyBilledAudio adds audio sent besides the session (see "StartConversation()") to the daily billed time
+// and returns true, if the daily budget is exceeded.
+func accountDailyBilledAudio(seconds float64) (bool) {
+	billingMutex.Lock()
+	defer billingMutex.Unlock()
+
+	rollBillingDay()
+	dailyBilledSeconds += seconds
+
+	return dailyBudgetSeconds > 0 && dailyBilledSeconds >= dailyBudgetSeconds
+}
+
+
+// accountStreamBilledTime keeps the billed time google reported for a stream of the session (identified by its clock).
+func accountStreamBilledTime(clock *streamClock, billed *duration.Duration) {
+	billedDuration, err := ptypes.Duration(billed)
+	if billed == nil || err != nil {
+		return
+	}
+
+	billingMutex.Lock()
+		reportedStreamSeconds[clock] = billedDuration.Seconds()
+	billingMutex.Unlock()
+}
+
+
+// accountBatchBilledTime adds the billed time google reported for a file of a batch.
+func accountBatchBilledTime(billed *duration.Duration) {
+	billedDuration, err := ptypes.Duration(billed)
+	if billed == nil || err != nil {
+		return
+	}
+
+	billingMutex.Lock()
+		reportedBatchSeconds += billedDuration.Seconds()
+	billingMutex.Unlock()
+}
+
+
+// reportedSessionSeconds returns the billed time google reported for the session.
+// The caller has to hold the billingMutex.
+func reportedSessionSeconds() (float64) {
+	seconds := retiredStreamSeconds
+	for _, streamSeconds := range reportedStreamSeconds {
+		seconds += streamSeconds
+	}
+	return seconds
+}
+
+
+// budgetUsedUp returns true, if the daily budget is exceeded.
+func budgetUsedUp() (bool) {
+	billingMutex.Lock()
+	defer billingMutex.Unlock()
+
+	rollBillingDay()
+	return dailyBudgetSeconds > 0 && dailyBilledSeconds >= dailyBudgetSeconds
+}
+
+
+// rollBillingDay resets the daily billed time when a new day started.
+// The caller has to hold the billingMutex.
+func rollBillingDay() {
+	today := time.Now().Format("2006-01-02")
+	if today != billingDay {
+		billingDay = today
+		dailyBilledSeconds = 0
+	}
+}
+
+
+/*
+	GetBilledSecondsEstimate(session *C.double, today *C.double) (C.int):
+	retrieves an estimate of the billed time, i.e. the seconds of audio actually sent to google
+	(including keep-alive frames), google may round the billed time up
+	
+	Parameters:
+		session:
+			The pointer which is used to store the seconds sent in the current (or last) session
+		today:
+			The pointer which is used to store the seconds sent today (across all sessions of the process)
+		
+	Return:
+		0 (1 with legacy return codes)
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetBilledSecondsEstimate
+func GetBilledSecondsEstimate(session *C.double, today *C.double) (C.int) {
+	billingMutex.Lock()
+	defer billingMutex.Unlock()
+
+	rollBillingDay()
+	*session = C.double(sessionBilledSeconds)
+	*today = C.double(dailyBilledSeconds)
+	return result(resultOK)
+}
+
+
+/*
+	GetBilledTime(session *C.double, batches *C.double) (C.int):
+	retrieves the billed time reported by google (its total billed time, so the invoices can be reconciled),
+	google reports it with the final results of a stream, so it trails the estimate (see "GetBilledSecondsEstimate()")
+	
+	Parameters:
+		session:
+			The pointer which is used to store the seconds billed for the current (or last) session (all its streams)
+		batches:
+			The pointer which is used to store the seconds billed for the files of the batches (see "TranscribeFiles()")
+			since the library has been loaded
+		
+	Return:
+		0 (1 with legacy return codes)
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetBilledTime
+func GetBilledTime(session *C.double, batches *C.double) (C.int) {
+	billingMutex.Lock()
+	defer billingMutex.Unlock()
+
+	*session = C.double(reportedSessionSeconds())
+	*batches = C.double(reportedBatchSeconds)
+	return result(resultOK)
+}
+
+
+/*
+	SetBilledSecondsBudget(cSeconds C.int) (C.int):
+	sets a hard cap for the audio sent per day (across all sessions of the process),
+	when it is exceeded the stream is finalized, the event GO_SPEECH_RECOGNITION_EVENT_BUDGET_EXCEEDED
+	is reported (see "PollEvent()") and no new stream can be initialized until the next day
+	
+	Parameter:
+		cSeconds C.int
+			(the budget in seconds, 0 means unlimited (default))
+		
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetBilledSecondsBudget
+func SetBilledSecondsBudget(cSeconds C.int) (C.int) {
+	if int32(cSeconds) < 0 {
+		logError("Billed seconds budget must not be negative", nil)
+		return result(resultInvalidArgument)
+	}
+
+	billingMutex.Lock()
+		dailyBudgetSeconds = float64(cSeconds)
+	billingMutex.Unlock()
+	return result(resultOK)
+}
+
+
+/*
+	SetRateLimits(cMaxConcurrent C.int, cRequestsPerMinute C.int, cQueueTimeoutMs C.int) (C.int):
+	caps the recognitions across the process, so a buggy host can't use up the project's quota:
+	the concurrent recognitions (the streaming session and the files of the batches being transcribed)
+	and the requests per minute (every stream and batch request), a new session or file waits up to the queue timeout
+	for a free slot and fails with GO_SPEECH_RECOGNITION_ERROR_RATE_LIMITED afterwards
+	
+	Parameters:
+		cMaxConcurrent C.int
+			(the maximum number of concurrent recognitions, 0 means unlimited (default))
+		cRequestsPerMinute C.int
+			(the maximum number of requests per minute, 0 means unlimited (default))
+		cQueueTimeoutMs C.int
+			(the maximum time to wait for a free slot in milliseconds, 0 rejects immediately (default))
+		
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetRateLimits
+func SetRateLimits(cMaxConcurrent C.int, cRequestsPerMinute C.int, cQueueTimeoutMs C.int) (C.int) {
+	if int32(cMaxConcurrent) < 0 || int32(cRequestsPerMinute) < 0 || int32(cQueueTimeoutMs) < 0 {
+		logError("Rate limits must not be negative", nil)
+		return result(resultInvalidArgument)
+	}
+
+	limitMutex.Lock()
+		maxConcurrentRecognitions = int(cMaxConcurrent)
+		maxRequestsPerMinute = int(cRequestsPerMinute)
+		rateLimitQueueTimeout = time.Duration(cQueueTimeoutMs) * time.Millisecond
+		// Waiting recognitions may fit the new limits.
+		close(limitChanged)
+		limitChanged = make(chan struct{})
+	limitMutex.Unlock()
+	return result(resultOK)
+}
+
+
+// acquireRecognition admits a new recognition and accounts its request, if the rate limits are reached
+// it waits up to the queue timeout (or until done is closed) for a free slot.
+func acquireRecognition(done <-chan struct{}) (C.int) {
+	limitMutex.Lock()
+	deadline := time.Now().Add(rateLimitQueueTimeout)
+	for {
+		now := time.Now()
+		pruneRequests(now)
+		concurrencyFree := maxConcurrentRecognitions == 0 || activeRecognitions < maxConcurrentRecognitions
+		rateFree := maxRequestsPerMinute == 0 || len(recentRequests) < maxRequestsPerMinute
+		if concurrencyFree && rateFree {
+			activeRecognitions++
+			recentRequests = append(recentRequests, now)
+			limitMutex.Unlock()
+			return resultOK
+		}
+
+		wait := deadline.Sub(now)
+		if wait <= 0 {
+			limitMutex.Unlock()
+			logError("Rate limit reached (concurrent recognitions or requests per minute)", nil)
+			return resultRateLimited
+		}
+		// The oldest request leaves the window of a minute.
+		if rateFree == false {
+			if untilFree := recentRequests[0].Add(time.Minute).Sub(now); untilFree < wait {
+				wait = untilFree
+			}
+		}
+		changed := limitChanged
+		limitMutex.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-changed:
+		case <-timer.C:
+		case <-done:
+			timer.Stop()
+			return resultCanceled
+		}
+		timer.Stop()
+		limitMutex.Lock()
+	}
+}
+
+
+// releaseRecognition frees the slot of a finished recognition.
+func releaseRecognition() {
+	limitMutex.Lock()
+		activeRecognitions--
+		close(limitChanged)
+		limitChanged = make(chan struct{})
+	limitMutex.Unlock()
+}
+
+
+// recordRequest accounts a request of a running recognition (e.g. a new stream of the session).
+func recordRequest() {
+	limitMutex.Lock()
+		now := time.Now()
+		pruneRequests(now)
+		recentRequests = append(recentRequests, now)
+	limitMutex.Unlock()
+}
+
+
+// pruneRequests removes the requests older than a minute (the limitMutex has to be locked).
+func pruneRequests(now time.Time) {
+	expired := 0
+	for expired < len(recentRequests) && now.Sub(recentRequests[expired]) >= time.Minute {
+		expired++
+	}
+	recentRequests = recentRequests[expired:]
+}
+
+
+/*
+	PollEvent(event *C.int) (C.int):
+	retrieves the next event reported by the library (doesn't block)
+	
+	Parameters:
+		event:
+			The pointer which is used to store the event (see GO_SPEECH_RECOGNITION_EVENT in the header)
+		
+	Return:
+		1 if an event has been retrieved
+		0 if no event is pending
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export PollEvent
+func PollEvent(event *C.int) (C.int) {
+	select {
+	case next := <-eventQueue:
+		*event = C.int(next)
+		return C.int(1)
+	default:
+		return C.int(0)
+	}
+}
+
+
+/*
+	SetKeepAlive(cEnabled C.int, cThresholdMs C.int) (C.int):
+	keeps the stream open while the host pauses sending audio,
+	google terminates a stream which doesn't receive audio for a while,
+	so a short frame of silence is sent whenever no audio has been sent for the threshold
+	
+	Parameter:
+		cEnabled C.int
+			(1 to enable, 0 to disable the keep-alive (default))
+		cThresholdMs C.int
+			(the pause in milliseconds after which a silence frame is sent, e.g. 1000)
+		
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetKeepAlive
+func SetKeepAlive(cEnabled C.int, cThresholdMs C.int) (C.int) {
+	if int32(cEnabled) != int32(1) {
+		atomic.StoreInt32(&keepAliveThresholdMs, 0)
+		return result(resultOK)
+	}
+
+	if int32(cThresholdMs) <= 0 {
+		logError("Keep-alive threshold has to be positive", nil)
+		return result(resultInvalidArgument)
+	}
+
+	atomic.StoreInt32(&keepAliveThresholdMs, int32(cThresholdMs))
+	return result(resultOK)
+}
+
+
+/*
+	GetQueueDepths (audioMs *C.int, results *C.int) (C.int):
+	retrieves how much data is currently buffered in the internal queues
+	(e.g. to display the buffering state or to implement a backpressure UI)
+	
+	Parameters:
+		audioMs:
+			The pointer which is used to store the duration of the queued audio (in milliseconds) that isn't sent yet,
+			-1 for compressed audio (its duration is unknown)
+		results:
+			The pointer which is used to store the number of received results that aren't retrieved yet
+				
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetQueueDepths
+func GetQueueDepths (audioMs *C.int, results *C.int) (C.int) {
+
+	sendMutex.Lock()
+		// Check if the stream is initialized
+		if initialized == false {
+			sendMutex.Unlock()
+			logError("Stream is not initialized", nil)
+			return result(resultNotInitialized)
+		}
+		// The duration of compressed audio is unknown (0 bytes per sample).
+		bytesPerSecond := int64(sessionConfig.Config.SampleRateHertz) * encodingBytesPerSample(sessionConfig.Config.Encoding)
+	sendMutex.Unlock()
+
+	receiveMutex.Lock()
+		queuedResults := len(resultQueue)
+	receiveMutex.Unlock()
+
+	*audioMs = C.int(-1)
+	if bytesPerSecond > 0 {
+		*audioMs = C.int(atomic.LoadInt64(&queuedAudioBytes) * 1000 / bytesPerSecond)
+	}
+	*results = C.int(queuedResults)
+	return result(resultOK)
+}
+
+
+// currentStats returns the statistics of the current session (see "GetStats()").
+func currentStats() (sessionStats) {
+	stats := sessionStats{
+		AudioOverflows:		atomic.LoadUint64(&audioOverflows),
+		ResultOverflows:	atomic.LoadUint64(&resultOverflows),
+		KeepAliveFrames:	atomic.LoadUint64(&keepAliveFrames),
+		StreamRetries:		atomic.LoadUint64(&streamRetries),
+		ChunkMs:			atomic.LoadInt32(&chunkMs),
+		SendLatencyMs:		float64(atomic.LoadInt64(&sendLatencyUs)) / 1000,
+	}
+	// The embedded runtime (see "SetWatchdog()")
+	stats.Goroutines, stats.HeapBytes = sampleRuntime()
+	// The library's goroutines (see go-speech-recognition-idle.go)
+	stats.Wakeups, stats.IdleMs = wakeupStats()
+	billingMutex.Lock()
+		stats.BilledSeconds = reportedSessionSeconds()
+	billingMutex.Unlock()
+	logMutex.Lock()
+		stats.Labels = copyLabels()
+	logMutex.Unlock()
+	return stats
+}
+
+
+/*
+	GetStats (output **C.char) (C.int):
+	retrieves the statistics of the current session as a JSON object, e.g.:
+	{"audioOverflows":0,"resultOverflows":2,"keepAliveFrames":0,"streamRetries":1,"chunkMs":20,"sendLatencyMs":0.4,"billedSeconds":15,
+	"goroutines":14,"heapBytes":5242880,"wakeups":1250,"idleMs":30000}
+	(chunkMs is the chosen chunk size in milliseconds of audio, sendLatencyMs the smoothed latency of sending a chunk,
+	billedSeconds the billed time reported by google, see "GetBilledTime()", goroutines and heapBytes the number of goroutines
+	and the heap of the Go runtime embedded in the host, see "SetWatchdog()", wakeups the number of times the library's
+	goroutines woke up to do work in the session and idleMs the time since the last wakeup, see go-speech-recognition-idle.go)
+	
+	Parameters:
+		output:
+			The pointer which is used to store the statistics
+				
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetStats
+func GetStats (output **C.char) (C.int) {
+	encoded, err := json.Marshal(currentStats())
+	if err != nil {
+		logError("Could not encode stats: ", err)
+		return result(resultError)
+	}
+
+	*output = cString(string(encoded))
+	return result(resultOK)
+}
+
+
+/*
+	ReceiveTranscript (output **C.char) (C.int):	
+	retrieves and saves the current final transcripts from Google
+	(the responses are received in the background and buffered in a bounded queue,
+	so the host doesn't have to poll fast enough to keep google's stream flowing)
+	
+	After the call output contains the current final transcript 
+	
+	Parameters:
+		output:
+			The pointer which is used to store the current final transcript
+				
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export ReceiveTranscript
+func ReceiveTranscript (output **C.char) (C.int) {
+	span := startSpan("ReceiveTranscript")
+	code := receiveTranscript(output)
+	endSpan(span, code)
+	return result(code)
+}
+
+// receiveTranscript implements "ReceiveTranscript()" (the export only adds the tracing).
+func receiveTranscript (output **C.char) (C.int) {
+
+	resp, _, sequence, code := receiveResponse()
+	if code != resultOK {
+		return code
+	}
+	atomic.StoreInt64(&lastDeliveredSequence, sequence)
+
+	// The session has been closed.
+	if resp == nil {
+		*output = cString("")
+		return resultOK
+	}
+
+	var helperString = "";
+
+	// Check received message for results and store it in helperString.
+	for _, result := range resp.Results {	
+		// Needed to get only the transcription without additional informations i.e. "confidence".
+		for _, alternative := range result.Alternatives { 
+			// If the alternative string starts with a space - remove it
 			if(len(alternative.Transcript) > 0 && alternative.Transcript[0] == " "[0]) {
 				
 				// Concatenate the alternatives, splitted by ';'
 				helperString += alternative.Transcript[1:] + (string(';'))
 
-			} else {
+			} else {
+
+				// Concatenate the alternatives, splitted by ';'
+				helperString += alternative.Transcript + (string(';'))
+			}
+		}		
+	}
+	
+	// Responses without results (e.g. speech events) deliver an empty transcript.
+	if len(helperString) == 0 {
+		*output = cString(helperString)
+		return resultOK
+	}
+
+	// Fill output and remove semicolons in front/end
+
+	// ";word;"" -> "word"
+	if((helperString[0] == ";"[0]) && (helperString[len(helperString)-1] == ";"[0])){
+		*output = cString(helperString[1:len(helperString)-1])
+		return resultOK
+
+	// "word;"" -> "word"
+	}else if ((helperString[0] != ";"[0]) && (helperString[len(helperString)-1] == ";"[0])){
+		*output = cString(helperString[:len(helperString)-1])
+		return resultOK
+
+	// ";word"" -> "word"
+	}else if ((helperString[0] == ";"[0]) && (helperString[len(helperString)-1] != ";"[0])){
+		*output = cString(helperString[1:])
+		return resultOK
+	}
+
+	// "word"
+	*output = cString(helperString)
+	return resultOK
+}
+
+
+/*
+	ReceiveTranscriptJSON (output **C.char) (C.int):
+	retrieves the next results like "ReceiveTranscript()", but in a structured form as a JSON array
+	(one object per result, the alternatives aren't concatenated), e.g.:
+	[{"transcript":"turn on the light","isFinal":true,"stability":0,"confidence":0.92,"endTimestamp":1500000,
+	"words":[{"word":"turn","confidence":0.95,"speaker":0,"startTimestamp":200000,"endTimestamp":400000},...]}]
+	the words are only included in final results, their confidence only if enabled (see "SetWordConfidence()"),
+	their timestamps only if enabled (see "SetWordTimeOffsets()"),
+	the timestamps are given in microseconds of the host's clock (see "SendAudioWithTimestamp()"), -1 if unknown
+
+	Parameters:
+		output:
+			The pointer which is used to store the results
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export ReceiveTranscriptJSON
+func ReceiveTranscriptJSON (output **C.char) (C.int) {
+
+	resp, clock, sequence, code := receiveResponse()
+	if code != resultOK {
+		return result(code)
+	}
+	atomic.StoreInt64(&lastDeliveredSequence, sequence)
+
+	results := []transcriptResult{}
+	if resp != nil {
+		results = transcriptResults(resp, clock)
+	}
+	for i := range results {
+		results[i].Sequence = sequence
+	}
+
+	encoded, err := json.Marshal(results)
+	if err != nil {
+		logError("Could not encode results: ", err)
+		return result(resultError)
+	}
+
+	*output = cString(string(encoded))
+	return result(resultOK)
+}
+
+
+/*
+	ReceiveAlternatives (list **C.goSpeechRecognitionAlternative, count *C.int) (C.int):
+	retrieves the next result like "ReceiveTranscript()", but as a list of alternatives ranked by google
+	(most likely first) with their confidences (e.g. for N-best re-scoring, see the maximum number of alternatives
+	of "InitializeStream()"), the confidence is only set for final results
+	
+	Parameters:
+		list:
+			The pointer which is used to store the alternatives (has to be released with "FreeAlternatives()")
+		count:
+			The pointer which is used to store the number of alternatives (0 if the response contains no result)
+	
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export ReceiveAlternatives
+func ReceiveAlternatives (list **C.goSpeechRecognitionAlternative, count *C.int) (C.int) {
+	*list = nil
+	*count = 0
+
+	resp, _, sequence, code := receiveResponse()
+	if code != resultOK || resp == nil {
+		return result(code)
+	}
+	atomic.StoreInt64(&lastDeliveredSequence, sequence)
+
+	alternatives := rankedAlternatives(resp)
+	if len(alternatives) == 0 {
+		return result(resultOK)
+	}
+
+	// The list is allocated by C, so the host can keep it after the call.
+	size := C.size_t(unsafe.Sizeof(C.goSpeechRecognitionAlternative{}))
+	*list = (*C.goSpeechRecognitionAlternative)(C.malloc(size * C.size_t(len(alternatives))))
+	entries := (*[1 << 20]C.goSpeechRecognitionAlternative)(unsafe.Pointer(*list))[:len(alternatives):len(alternatives)]
+	for i, alternative := range alternatives {
+		entries[i].transcript = cString(strings.TrimSpace(alternative.Transcript))
+		entries[i].confidence = C.float(alternative.Confidence)
+	}
+	*count = C.int(len(alternatives))
+	return result(resultOK)
+}
+
+
+// rankedAlternatives returns the alternatives of the response's first final result (or its first result, if none is final).
+func rankedAlternatives(resp *speechpb.StreamingRecognizeResponse) ([]*speechpb.SpeechRecognitionAlternative) {
+	var alternatives []*speechpb.SpeechRecognitionAlternative
+	for _, result := range resp.Results {
+		if len(result.Alternatives) == 0 {
+			continue
+		}
+		if result.IsFinal {
+			return result.Alternatives
+		}
+		if alternatives == nil {
+			alternatives = result.Alternatives
+		}
+	}
+	return alternatives
+}
+
+
+/*
+	FreeAlternatives (list *C.goSpeechRecognitionAlternative, count C.int):
+	releases the alternatives retrieved by "ReceiveAlternatives()"
+	
+	Parameters:
+		list:
+			The alternatives
+		count:
+			The number of alternatives
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export FreeAlternatives
+func FreeAlternatives (list *C.goSpeechRecognitionAlternative, count C.int) () {
+	if list == nil {
+		return
+	}
+
+	entries := (*[1 << 20]C.goSpeechRecognitionAlternative)(unsafe.Pointer(list))[:int(count):int(count)]
+	for _, entry := range entries {
+		C.free(unsafe.Pointer(entry.transcript))
+	}
+	C.free(unsafe.Pointer(list))
+}
+
+
+/*
+	SetWordConfidence(cEnabled C.int):
+	requests the confidence of every word of the final results (included in "ReceiveTranscriptJSON()"),
+	so uncertain words can be weighted differently,
+	has to be called before "InitializeStream()" or "Reconfigure()" to take effect
+
+	Parameter:
+		cEnabled C.int
+			(1 to enable, 0 to disable the word confidence (default))
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetWordConfidence
+func SetWordConfidence(cEnabled C.int) () {
+	sendMutex.Lock()
+		wordConfidence = int32(cEnabled) == int32(1)
+	sendMutex.Unlock()
+}
+
+
+/*
+	SetWordTimeOffsets(cEnabled C.int):
+	requests the start and end time of every word of the final results (included in "ReceiveTranscriptJSON()"
+	as timestamps of the host's clock, see "SendAudioWithTimestamp()"),
+	has to be called before "InitializeStream()" or "Reconfigure()" to take effect
+
+	Parameter:
+		cEnabled C.int
+			(1 to enable, 0 to disable the word time offsets (default))
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetWordTimeOffsets
+func SetWordTimeOffsets(cEnabled C.int) () {
+	sendMutex.Lock()
+		wordTimeOffsets = int32(cEnabled) == int32(1)
+	sendMutex.Unlock()
+}
+
+
+/*
+	SetAudioEncoding(cEncoding C.int) (C.int):
+	sets the encoding of the sent audio (the values of google's AudioEncoding, see GO_SPEECH_RECOGNITION_ENCODING),
+	every encoding except LINEAR16 (default) has to be sent with "SendAudioBytes()",
+	has to be called before "InitializeStream()" or "Reconfigure()" to take effect
+
+	Parameter:
+		cEncoding C.int
+			(1: LINEAR16, 2: FLAC, 3: MULAW, 4: AMR, 5: AMR_WB, 6: OGG_OPUS, 7: SPEEX_WITH_HEADER_BYTE)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetAudioEncoding
+func SetAudioEncoding(cEncoding C.int) (C.int) {
+	encoding := speechpb.RecognitionConfig_AudioEncoding(cEncoding)
+	if encoding < speechpb.RecognitionConfig_LINEAR16 || encoding > speechpb.RecognitionConfig_SPEEX_WITH_HEADER_BYTE {
+		logError("Unknown audio encoding", nil)
+		return result(resultInvalidArgument)
+	}
+
+	sendMutex.Lock()
+		audioEncoding = encoding
+	sendMutex.Unlock()
+	return result(resultOK)
+}
+
+
+// receiveResponse waits for the next response received by the receive pump (and returns the clock of its stream
+// and its sequence number, see "SetResultAcknowledgment()"), the response is nil if the session has been closed meanwhile.
+func receiveResponse() (*speechpb.StreamingRecognizeResponse, *streamClock, int64, C.int) {
+
+	// One receive call at a time, never by a callback (see go-speech-recognition-threads.go).
+	if code := checkCallbackThread("ReceiveTranscript"); code != resultOK {
+		return nil, nil, 0, code
+	}
+	if code := beginReceive(); code != resultOK {
+		return nil, nil, 0, code
+	}
+	defer endReceive()
+
+	// Ensure that the stream is initialized
+	receiveMutex.Lock()
+		// Check if the stream is initialized
+		if initialized == false {
+			receiveMutex.Unlock()
+			logError("Stream is not initialized", nil)
+			return nil, nil, 0, resultNotInitialized
+		}
+		queue := resultQueue
+		queueCtx := ctx
+		// "CloseStream()" waits until this call returned.
+		inFlight.Add(1)
+	receiveMutex.Unlock()
+	defer inFlight.Done()
+	defer rearmResultEvent(queue)
+
+	// A blocking call can be canceled by "CancelPendingReceive()".
+	callCtx, callDone := pendingReceives.begin(queueCtx)
+	defer callDone()
+
+	// An error found by "ReceiveAllPending()" after the results it delivered.
+	if code := C.int(atomic.SwapInt32(&deferredReceiveCode, 0)); code != resultOK {
+		return nil, nil, 0, code
+	}
+
+	// Wait for the next result received by the receive pump,
+	// unacknowledged results are delivered again meanwhile (see "SetResultAcknowledgment()").
+	var received receiveResult
+	var open bool
+	for waiting := true; waiting; {
+		if pending := dueRedelivery(); pending != nil {
+			return pending.resp, pending.clock, pending.sequence, resultOK
+		}
+		var due <-chan time.Time
+		timer := redeliveryTimer()
+		if timer != nil {
+			due = timer.C
+		}
+
+		select {
+		case received, open = <-queue:
+			waiting = false
+		case <-due:
+		case <-callCtx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			// The session has been closed.
+			if queueCtx.Err() != nil {
+				return nil, nil, 0, resultOK
+			}
+			logError("Receiving has been canceled", nil)
+			return nil, nil, 0, resultCanceled
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+	return checkReceived(received, open)
+}
+
+
+// checkReceived handles a result taken from the result queue (open is false if the queue has been closed)
+// and returns it like "receiveResponse()".
+func checkReceived(received receiveResult, open bool) (*speechpb.StreamingRecognizeResponse, *streamClock, int64, C.int) {
+
+	// Results have been rejected because the queue was full (overflow policy "error").
+	if atomic.SwapInt32(&resultsRejected, 0) == 1 {
+		logError("Result queue is full, results were dropped", nil)
+		return nil, nil, 0, resultQueueFull
+	}
+
+	// The receive pump stopped after delivering its last error.
+	if open == false {
+		logError("Stream has ended", nil)
+		return nil, nil, 0, resultStreamEnded
+	}
+
+	resp, err := received.resp, received.err
+
+	// Error handling.
+	if err == context.Canceled {
+		return nil, nil, 0, resultOK
+	}
+
+
+	if status.Code(err) == codes.ResourceExhausted {
+		logError("Quota exceeded: ", err)
+		return nil, nil, 0, resultQuotaExceeded
+	}
+
+	if err != nil {
+		logError("Cannot stream results: ", err)
+		return nil, nil, 0, resultError
+	}
+
+	if err := resp.Error; err != nil {
+		logError("Could not recognize: ", status.ErrorProto(err))
+		return nil, nil, 0, resultError
+	}
+
+	markDelivered(received.sequence)
+	return resp, received.clock, received.sequence, resultOK
+}
+
+
+// receivePump runs in its own goroutine (started by "InitializeStream()") and receives the responses from google,
+// so google can keep sending while the host is busy. The results are pushed into the bounded result queue,
+// which is closed after the error that ended the receiving has been pushed.
+func receivePump(pumpCtx context.Context, queue chan receiveResult, done chan struct{}) {
+	defer close(done)
+	// The end of the receiving is signaled as well (see "GetResultEventHandle()").
+	defer signalResultEvent()
+	defer close(queue)
+	// An utterance without final result is aborted, when the receiving ends.
+	defer abortUtterance()
+
+	// Consecutive retries without receiving a response
+	retries := 0
+
+	for {
+		resp, clock, err := receiveFromCurrentStream(pumpCtx)
+		noteWakeup()
+		if err == nil {
+			recordRawResponse(resp)
+			accountStreamBilledTime(clock, resp.TotalBilledTime)
+			reportSpeechEvent(resp, clock)
+		}
+
+		// A finalized stream ends regularly after its remaining results have been received.
+		if err == io.EOF && isFinalized() {
+			return
+		}
+
+		// An exceeded quota needs a pause before the next stream (the audio is queued meanwhile).
+		quotaExceeded := err != nil && pumpCtx.Err() == nil && status.Code(err) == codes.ResourceExhausted
+		if quotaExceeded {
+			pauseForQuota(pumpCtx, err)
+		}
+
+		// Transient errors (see "SetRetryableCode()") are handled by transparently opening a new stream.
+		if err != nil && pumpCtx.Err() == nil && isRetryable(err) && retries < maxRetries {
+			retries++
+			retried := retryStream()
+			if quotaExceeded {
+				resumeSending()
+			}
+			if retried {
+				atomic.AddUint64(&streamRetries, 1)
+				finishStream()
+				continue
+			}
+		} else if quotaExceeded {
+			resumeSending()
+		}
+		// A lost connection is bridged by spooling the audio (see "SetBackfill()").
+		if err != nil && pumpCtx.Err() == nil && isConnectivityError(err) {
+			sendMutex.Lock()
+				offline := goOffline(pumpCtx)
+			sendMutex.Unlock()
+			if offline {
+				finishStream()
+				continue
+			}
+		}
+		// The sequence number is assigned before the result is queued, so a result dropped by the overflow policy
+		// is delivered again (see "SetResultAcknowledgment()").
+		var sequence int64
+		if err == nil {
+			retries = 0
+			// Responses whose results are all suppressed aren't delivered at all.
+			if filterUnstableResults(resp) == false {
+				continue
+			}
+			rerankAlternatives(resp)
+			postProcessResponse(resp)
+			collectSpeakerWords(resp)
+			appendChannelFiles(resp)
+			dispatchFinalResults(resp)
+			advanceTurn(resp)
+			noteWakeWordResult(resp)
+			trackUtterance(resp)
+			checkpointFinalResults(resp, clock)
+			sequence = trackDelivery(resp, clock)
+		}
+
+		// In single utterance mode google stops recognizing after the utterance, so a new stream is needed.
+		if err == nil && resp.SpeechEventType == speechpb.StreamingRecognizeResponse_END_OF_SINGLE_UTTERANCE {
+			restartAfterUtterance()
+		}
+
+		// The error that ends the receiving is never dropped.
+		if err != nil {
+			select {
+			case queue <- receiveResult{resp: resp, clock: clock, err: err}:
+			case <-pumpCtx.Done():
+			}
+			return
+		}
+
+		if enqueue(queue, receiveResult{resp: resp, clock: clock, sequence: sequence}, pumpCtx.Done(), &resultOverflows, nil) == false {
+			if pumpCtx.Err() != nil {
+				return
+			}
+			// Overflow policy "error": the host gets informed by the next "ReceiveTranscript()" call.
+			atomic.StoreInt32(&resultsRejected, 1)
+			continue
+		}
+		signalResultEvent()
+	}
+}
+
+
+// retryStream replaces a failed stream by a new one with the session's configuration.
+func retryStream() (bool) {
+	sendMutex.Lock()
+	defer sendMutex.Unlock()
+
+	if initialized == false || finalized == true {
+		return false
+	}
+
+	streamMutex.Lock()
+		failedStream := stream
+	streamMutex.Unlock()
+
+	if err := restartStream(sessionConfig); err != nil {
+		logError("Could not retry: ", err)
+		return false
+	}
+	// The receiving continues on the new stream.
+	retireStream(failedStream)
+	return true
+}
+
+
+// pauseForQuota reports the exceeded quota and pauses the sending for the delay advised by google
+// (until "resumeSending()" is called), it returns when the delay elapsed or the session has been closed.
+func pauseForQuota(pumpCtx context.Context, err error) {
+	delay := quotaRetryDelay(err)
+	logWarning("Quota exceeded, pausing for " + delay.String())
+	reportEvent(eventQuotaExceeded)
+
+	sendMutex.Lock()
+		if quotaResume == nil {
+			quotaResume = make(chan struct{})
+		}
+	sendMutex.Unlock()
+
+	select {
+	case <-time.After(delay):
+	case <-pumpCtx.Done():
+	}
+}
+
+
+// resumeSending lets the send pump continue after a pause for the quota.
+func resumeSending() {
+	sendMutex.Lock()
+		if quotaResume != nil {
+			close(quotaResume)
+			quotaResume = nil
+		}
+	sendMutex.Unlock()
+}
+
+
+// quotaRetryDelay returns the delay advised by google's retry info (a detail of the error).
+func quotaRetryDelay(err error) (time.Duration) {
+	for _, detail := range status.Convert(err).Details() {
+		info, ok := detail.(*errdetails.RetryInfo)
+		if ok == false || info.RetryDelay == nil {
+			continue
+		}
+		delay, err := ptypes.Duration(info.RetryDelay)
+		if err != nil || delay <= 0 {
+			continue
+		}
+		if delay > maxQuotaPause {
+			return maxQuotaPause
+		}
+		return delay
+	}
+	return defaultQuotaPause
+}
+
+
+// isRetryable classifies the error by its gRPC status code.
+func isRetryable(err error) (bool) {
+	retryMutex.Lock()
+	defer retryMutex.Unlock()
+	return retryableCodes[status.Code(err)]
+}
+
+
+/*
+	IsRetryableCode(cCode C.int) (C.int):
+	returns how the library classifies the gRPC status code
+	(see https://grpc.github.io/grpc/core/md_doc_statuscodes.html),
+	on retryable errors a new stream is opened transparently (up to 3 times in a row),
+	all other errors end the receiving
+	
+	Parameter:
+		cCode C.int
+			(the gRPC status code, e.g. 8 for RESOURCE_EXHAUSTED)
+		
+	Return:
+		1 if the code is treated as retryable
+		0 if the code is treated as fatal
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export IsRetryableCode
+func IsRetryableCode(cCode C.int) (C.int) {
+	retryMutex.Lock()
+	defer retryMutex.Unlock()
+
+	if retryableCodes[codes.Code(cCode)] {
+		return C.int(1)
+	}
+	return C.int(0)
+}
+
+
+/*
+	SetRetryableCode(cCode C.int, cRetryable C.int) (C.int):
+	overrides how the library classifies the gRPC status code
+	(e.g. treat RESOURCE_EXHAUSTED as fatal for interactive use cases)
+	
+	Parameter:
+		cCode C.int
+			(the gRPC status code, e.g. 8 for RESOURCE_EXHAUSTED)
+		cRetryable C.int
+			(1 to treat the code as retryable, 0 to treat it as fatal)
+		
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetRetryableCode
+func SetRetryableCode(cCode C.int, cRetryable C.int) (C.int) {
+	code := codes.Code(cCode)
+	// OK (0) is no error and UNAUTHENTICATED (16) is the highest status code.
+	if code == codes.OK || code > codes.Unauthenticated {
+		logError("Unknown gRPC status code", nil)
+		return result(resultInvalidArgument)
+	}
+
+	retryMutex.Lock()
+		retryableCodes[code] = int32(cRetryable) == int32(1)
+	retryMutex.Unlock()
+	return result(resultOK)
+}
+
+
+// receiveFromCurrentStream receives the next response, when a stream has been replaced by "Reconfigure()"
+// its remaining results are received first, afterwards the receiving continues on the new stream.
+// While the session is offline it waits for the connection and delivers the backfill first (see "SetBackfill()").
+// Only the receive pump may call it.
+func receiveFromCurrentStream(pumpCtx context.Context) (*speechpb.StreamingRecognizeResponse, *streamClock, error) {
+	for {
+		// The results of the spooled audio precede the live results.
+		if len(backfillResponses) > 0 {
+			resp := backfillResponses[0]
+			backfillResponses = backfillResponses[1:]
+			return resp, backfillClock, nil
+		}
+
+		streamMutex.Lock()
+			currentStream := stream
+			clock := streamClocks[currentStream]
+			resume := offlineResume
+			spooled := spoolToBackfill
+			spoolToBackfill = nil
+		streamMutex.Unlock()
+
+		if spooled != nil {
+			backfillResponses = transcribeSpool(pumpCtx, spooled)
+			backfillClock = spooled.clock
+			continue
+		}
+
+		if currentStream == nil {
+			// Wait until the connection is back.
+			if resume != nil {
+				select {
+				case <-resume:
+					continue
+				case <-pumpCtx.Done():
+					return nil, nil, context.Canceled
+				}
+			}
+			// A session finalized while offline ends after its backfill.
+			if isFinalized() && pumpCtx.Err() == nil {
+				return nil, nil, io.EOF
+			}
+			// The stream has been closed by "CloseStream()".
+			return nil, nil, context.Canceled
+		}
+
+		resp, err := currentStream.Recv()
+		captureStreamMetadata(currentStream, resp, err)
+
+		streamMutex.Lock()
+			replaced := currentStream != stream
+			offline := offlineResume != nil
+		streamMutex.Unlock()
+
+		// An old stream which ended isn't received anymore.
+		if err != nil && replaced {
+			retireStream(currentStream)
+		}
+		// The old stream is finished (or dropped by going offline), continue with the new one.
+		if err != nil && replaced && (err == io.EOF || offline) {
+			finishStream()
+			continue
+		}
+		return resp, clock, err
+	}
+}
+
+
+/*
+	LoadPhraseHintsFromFile(cPath *C.char) (C.int):
+	loads phrase hints (e.g. product names or commands), which google recognizes more likely,
+	so large hint lists can be managed without recompiling the host,
+	replaces the previously loaded hints and has to be called before "InitializeStream()" or "Reconfigure()" to take effect
+	
+	Parameter:
+		cPath *C.char
+			(the path of the file as a C string, a ".csv" file contains one phrase and optionally its boost per line
+			(e.g. "Acme Turbo,15"), any other file one phrase per line, lines starting with "#" are ignored,
+			an empty path removes the hints)
+		
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export LoadPhraseHintsFromFile
+func LoadPhraseHintsFromFile(cPath *C.char) (C.int) {
+	path := C.GoString(cPath)
+
+	var hints []*speechpb.SpeechContext
+	if path != "" {
+		var err error
+		hints, err = readPhraseHints(path)
+		if err != nil {
+			logError("Could not load phrase hints: ", err)
+			return result(resultError)
+		}
+	}
+
+	sendMutex.Lock()
+		phraseHints = hints
+	sendMutex.Unlock()
+	return result(resultOK)
+}
+
+
+// readPhraseHints reads the phrases of a plain text or CSV file, phrases with the same boost share a speech context.
+func readPhraseHints(path string) ([]*speechpb.SpeechContext, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records [][]string
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		reader.Comment = '#'
+		records, err = reader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			records = append(records, []string{scanner.Text()})
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	var hints []*speechpb.SpeechContext
+	contexts := map[float32]*speechpb.SpeechContext{}
+	for i, record := range records {
+		phrase := strings.TrimSpace(record[0])
+		if phrase == "" || strings.HasPrefix(phrase, "#") {
+			continue
+		}
+
+		boost := float64(0)
+		if len(record) > 1 && strings.TrimSpace(record[1]) != "" {
+			boost, err = strconv.ParseFloat(strings.TrimSpace(record[1]), 32)
+			if err != nil {
+				return nil, errors.New("invalid boost in line " + strconv.Itoa(i + 1) + ": " + err.Error())
+			}
+		}
+
+		speechContext, ok := contexts[float32(boost)]
+		if ok == false {
+			speechContext = &speechpb.SpeechContext{Boost: float32(boost)}
+			contexts[float32(boost)] = speechContext
+			hints = append(hints, speechContext)
+		}
+		speechContext.Phrases = append(speechContext.Phrases, phrase)
+	}
+	return hints, nil
+}
+
+
+// filterUnstableResults removes the interim results below the minimum stability from the response,
+// returns false if the response had results and none of them is left.
+func filterUnstableResults(resp *speechpb.StreamingRecognizeResponse) (bool) {
+	minStability := math.Float32frombits(atomic.LoadUint32(&minStabilityBits))
+	if minStability <= 0 || len(resp.Results) == 0 {
+		return true
+	}
+
+	var kept []*speechpb.StreamingRecognitionResult
+	for _, result := range resp.Results {
+		if result.IsFinal || result.Stability >= minStability {
+			kept = append(kept, result)
+		}
+	}
+	resp.Results = kept
+	return len(kept) > 0
+}
+
+
+/*
+	SetMinStability(cStability C.float) (C.int):
+	suppresses interim results with a lower stability (google's estimate of how likely an interim result
+	changes, see "ReceiveTranscriptJSON()"), so jittery interim results don't disturb live captions,
+	final results are always delivered
+	
+	Parameter:
+		cStability C.float
+			(the minimum stability between 0.0 and 1.0, e.g. 0.8, 0.0 delivers all interim results (default))
+		
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetMinStability
+func SetMinStability(cStability C.float) (C.int) {
+	stability := float32(cStability)
+	if !(stability >= 0 && stability <= 1) {
+		logError("Minimum stability has to be between 0.0 and 1.0", nil)
+		return result(resultInvalidArgument)
+	}
+	atomic.StoreUint32(&minStabilityBits, math.Float32bits(stability))
+	return result(resultOK)
+}
+
+
+// rerankAlternatives lets the host's re-ranking callback choose the alternative of every result with several alternatives,
+// the chosen alternative is moved to the front, so it's delivered like google's most likely one.
+// Only the receive pump may call it.
+func rerankAlternatives(resp *speechpb.StreamingRecognizeResponse) {
+	callbackMutex.Lock()
+		callback, userData := rerankCallback, rerankUserData
+	callbackMutex.Unlock()
+
+	if callback == nil {
+		return
+	}
+
+	for _, result := range resp.Results {
+		if len(result.Alternatives) < 2 {
+			continue
+		}
+
+		transcripts := make([]string, len(result.Alternatives))
+		confidences := make([]float32, len(result.Alternatives))
+		for i, alternative := range result.Alternatives {
+			transcripts[i] = strings.TrimSpace(alternative.Transcript)
+			confidences[i] = alternative.Confidence
+		}
+
+		chosen := callRerankCallback(callback, userData, transcripts, confidences)
+		if chosen <= 0 || chosen >= len(result.Alternatives) {
+			// Google's ranking is kept (an invalid index counts as 0).
+			continue
+		}
+
+		alternative := result.Alternatives[chosen]
+		copy(result.Alternatives[1:chosen + 1], result.Alternatives[:chosen])
+		result.Alternatives[0] = alternative
+	}
+}
+
+
+/*
+	SetRerankCallback(cCallback unsafe.Pointer, cUserData unsafe.Pointer):
+	registers a callback, which re-ranks the alternatives of every result with several alternatives
+	(e.g. based on the host's command grammar), it gets the alternatives ranked by google and returns the index
+	of the chosen one, which is then delivered like google's most likely alternative (e.g. by "ReceiveTranscript()"),
+	the callback is invoked on a thread of the library and should return quickly (receiving waits meanwhile),
+	the list is only valid during the call
+	
+	Parameter:
+		cCallback unsafe.Pointer
+			(the callback as a C function pointer
+			"int callback(GO_SPEECH_RECOGNITION_ALTERNATIVE* list, int count, void* userData)",
+			NULL removes the callback)
+		cUserData unsafe.Pointer
+			(passed to the callback unchanged, e.g. a pointer to the host's object)
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetRerankCallback
+func SetRerankCallback(cCallback unsafe.Pointer, cUserData unsafe.Pointer) () {
+	callbackMutex.Lock()
+		rerankCallback = cCallback
+		rerankUserData = cUserData
+	callbackMutex.Unlock()
+}
+
+
+// finishStream resets the per stream state, when the receiving continues on a new stream.
+// Only the receive pump may call it.
+func finishStream() {
+	finishSpeakerStream()
+	// The result times of the new stream start at zero.
+	lastFinalEnd = 0
+	// The new stream doesn't continue the utterance of the old one.
+	abortUtterance()
+}
+
+
+// trackUtterance assigns the response's results to the current utterance and reports its lifecycle events.
+// Only the receive pump may call it.
+func trackUtterance(resp *speechpb.StreamingRecognizeResponse) {
+	transcript := ""
+	final := false
+	for _, result := range resp.Results {
+		if len(result.Alternatives) == 0 {
+			continue
+		}
+		// The interim results of a response are consecutive parts of the utterance.
+		transcript += result.Alternatives[0].Transcript
+		final = final || result.IsFinal
+	}
+	transcript = strings.TrimSpace(transcript)
+
+	// Responses without results (e.g. speech events) don't belong to an utterance.
+	if transcript == "" && final == false {
+		return
+	}
+
+	if utteranceOpen == false {
+		utteranceID++
+		utteranceOpen = true
+		reportUtteranceEvent(utteranceStarted, transcript)
+	} else if final == false && transcript != utteranceTranscript {
+		reportUtteranceEvent(utteranceUpdated, transcript)
+	}
+	utteranceTranscript = transcript
+
+	if final {
+		reportUtteranceEvent(utteranceFinalized, transcript)
+		utteranceOpen = false
+	}
+}
+
+
+// abortUtterance reports the current utterance as aborted, if it has no final result yet.
+// Only the receive pump may call it.
+func abortUtterance() {
+	if utteranceOpen {
+		reportUtteranceEvent(utteranceAborted, utteranceTranscript)
+		utteranceOpen = false
+	}
+}
+
+
+// reportUtteranceEvent queues an event of the current utterance for "PollUtteranceEvent()"
+// (if the host doesn't poll the events, new ones get dropped).
+func reportUtteranceEvent(event int32, transcript string) {
+	select {
+	case utteranceQueue <- utteranceEvent{id: utteranceID, event: event, transcript: transcript}:
+	default:
+	}
+}
+
+
+/*
+	PollUtteranceEvent(id *C.int, event *C.int, transcript **C.char) (C.int):
+	retrieves the next lifecycle event of an utterance (doesn't block), the interim results and the final result
+	of the same utterance share an ID, so the host can update e.g. a caption in place:
+	an utterance is started by its first result, updated by the following interim results and either finalized
+	by its final result or aborted (e.g. when the stream ends before the final result)
+	
+	Parameters:
+		id:
+			The pointer which is used to store the ID of the utterance (counted across sessions, starting with 1)
+		event:
+			The pointer which is used to store the event (see GO_SPEECH_RECOGNITION_UTTERANCE_EVENT in the header)
+		transcript:
+			The pointer which is used to store the utterance's current transcript
+		
+	Return:
+		1 if an event has been retrieved
+		0 if no event is pending
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export PollUtteranceEvent
+func PollUtteranceEvent(id *C.int, event *C.int, transcript **C.char) (C.int) {
+	select {
+	case next := <-utteranceQueue:
+		*id = C.int(next.id)
+		*event = C.int(next.event)
+		*transcript = cString(next.transcript)
+		return C.int(1)
+	default:
+		return C.int(0)
+	}
+}
+
+
+// dispatchFinalResults invokes the final result callback for every final result of the response.
+// Only the receive pump may call it.
+func dispatchFinalResults(resp *speechpb.StreamingRecognizeResponse) {
+	callbackMutex.Lock()
+		callback, userData := finalResultCallback, finalResultUserData
+	callbackMutex.Unlock()
+
+	for _, result := range resp.Results {
+		if result.IsFinal == false || len(result.Alternatives) == 0 {
+			continue
+		}
+
+		// The utterance lasts from the end of the previous final result to the end of this one.
+		end, err := ptypes.Duration(result.ResultEndTime)
+		if err != nil {
+			end = lastFinalEnd
+		}
+		duration := end - lastFinalEnd
+		lastFinalEnd = end
+
+		if callback != nil {
+			alternative := result.Alternatives[0]
+			callFinalResultCallback(callback, userData, strings.TrimSpace(alternative.Transcript), duration.Seconds(), alternative.Confidence)
+		}
+	}
+}
+
+
+/*
+	SetFinalResultCallback(cCallback unsafe.Pointer, cUserData unsafe.Pointer):
+	registers a callback, which is invoked whenever a result becomes final (interim results don't invoke it)
+	with the utterance's full text, its duration and its confidence,
+	the callback is invoked on a thread of the library and should return quickly (receiving waits meanwhile),
+	unless the callbacks are queued (see "SetCallbackDispatch()"), the transcript is only valid during the call
+	
+	Parameter:
+		cCallback unsafe.Pointer
+			(the callback as a C function pointer
+			"void callback(char* transcript, double durationSeconds, float confidence, void* userData)",
+			NULL removes the callback)
+		cUserData unsafe.Pointer
+			(passed to the callback unchanged, e.g. a pointer to the host's object)
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetFinalResultCallback
+func SetFinalResultCallback(cCallback unsafe.Pointer, cUserData unsafe.Pointer) () {
+	callbackMutex.Lock()
+		finalResultCallback = cCallback
+		finalResultUserData = cUserData
+	callbackMutex.Unlock()
+}
+
+
+/*
+	SetSpeakerDiarization(cEnabled C.int, cMinSpeakers C.int, cMaxSpeakers C.int) (C.int):
+	enables the speaker diarization, google then tags every word of the final results with its speaker,
+	the words are assembled into speaker turns (see "GetSpeakerTranscript()"),
+	has to be called before "InitializeStream()" or "Reconfigure()" to take effect
+
+	Parameter:
+		cEnabled C.int
+			(1 to enable, 0 to disable the speaker diarization (default))
+		cMinSpeakers C.int
+			(the minimum number of speakers in the conversation, e.g. 2)
+		cMaxSpeakers C.int
+			(the maximum number of speakers in the conversation, e.g. 6)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetSpeakerDiarization
+func SetSpeakerDiarization(cEnabled C.int, cMinSpeakers C.int, cMaxSpeakers C.int) (C.int) {
+	if int32(cEnabled) != int32(1) {
+		sendMutex.Lock()
+			diarizationConfig = nil
+		sendMutex.Unlock()
+		return result(resultOK)
+	}
+
+	if int32(cMinSpeakers) < 1 || int32(cMaxSpeakers) < int32(cMinSpeakers) {
+		logError("Speaker counts have to be positive and the maximum must not be lower than the minimum", nil)
+		return result(resultInvalidArgument)
+	}
+
+	sendMutex.Lock()
+		diarizationConfig = &speechpb.SpeakerDiarizationConfig{
+			EnableSpeakerDiarization:	true,
+			MinSpeakerCount:			int32(cMinSpeakers),
+			MaxSpeakerCount:			int32(cMaxSpeakers),
+			}
+	sendMutex.Unlock()
+	return result(resultOK)
+}
+
+
+// collectSpeakerWords keeps the speaker tagged words of the response's final results.
+// Google repeats all words of the stream in its latest final result, so they replace the ones collected before.
+func collectSpeakerWords(resp *speechpb.StreamingRecognizeResponse) {
+	for _, result := range resp.Results {
+		if result.IsFinal == false || len(result.Alternatives) == 0 {
+			continue
+		}
+
+		var words []*speechpb.WordInfo
+		for _, word := range result.Alternatives[0].Words {
+			if word.SpeakerTag > 0 {
+				words = append(words, word)
+			}
+		}
+		if len(words) == 0 {
+			continue
+		}
+
+		speakerMutex.Lock()
+			// Only the words which weren't part of the previous final result are new.
+			var newWords []*speechpb.WordInfo
+			if len(words) > len(streamSpeakerWords) {
+				newWords = words[len(streamSpeakerWords):]
+			}
+			streamSpeakerWords = words
+
+			if transcriptFilesDirectory != "" && transcriptFilesPerChannel == false {
+				appendSpeakerFiles(newWords)
+			}
+		speakerMutex.Unlock()
+	}
+}
+
+
+// appendSpeakerFiles appends the words to the transcript files of their speakers, one line per turn.
+// The caller has to hold the speakerMutex.
+func appendSpeakerFiles(words []*speechpb.WordInfo) {
+	for start := 0; start < len(words); {
+		end := start
+		var line []string
+		for end < len(words) && words[end].SpeakerTag == words[start].SpeakerTag {
+			line = append(line, words[end].Word)
+			end++
+		}
+		appendTranscriptFile("speaker-" + strconv.Itoa(int(words[start].SpeakerTag)), strings.Join(line, " "))
+		start = end
+	}
+}
+
+
+// appendChannelFiles appends the final transcripts of the response to the transcript files of their channels.
+func appendChannelFiles(resp *speechpb.StreamingRecognizeResponse) {
+	speakerMutex.Lock()
+	defer speakerMutex.Unlock()
+
+	if transcriptFilesDirectory == "" || transcriptFilesPerChannel == false {
+		return
+	}
+
+	for _, result := range resp.Results {
+		if result.IsFinal == false || len(result.Alternatives) == 0 {
+			continue
+		}
+		appendTranscriptFile("channel-" + strconv.Itoa(int(result.ChannelTag)), strings.TrimSpace(result.Alternatives[0].Transcript))
+	}
+}
+
+
+// appendTranscriptFile appends the line to the session's transcript file with the given name.
+// The caller has to hold the speakerMutex.
+func appendTranscriptFile(name string, line string) {
+	line = redactSensitive(line)
+
+	logMutex.Lock()
+		path := filepath.Join(transcriptFilesDirectory, sessionID + "-" + name + ".txt")
+		labels := formatLabels(", ")
+	logMutex.Unlock()
+
+	// Encrypted files get their own name, so they're never mixed with plain text (see "SetEncryptionKey()").
+	aead := currentCipher()
+	if aead != nil {
+		path += encryptedFileSuffix
+	}
+
+	_, err := os.Stat(path)
+	created := os.IsNotExist(err)
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		logError("Could not open transcript file: ", err)
+		return
+	}
+	defer file.Close()
+
+	// A new file starts with the labels of the session (see "SetSessionLabel()").
+	if created && labels != "" {
+		line = "# " + labels[2:] + "\n" + line
+	}
+
+	if err := appendRecord(file, aead, created, []byte(line + "\n")); err != nil {
+		logError("Could not write transcript file: ", err)
+	}
+}
+
+
+/*
+	SetTranscriptFiles(cDirectory *C.char, cPerChannel C.int) (C.int):
+	writes the final transcripts into rolling files, one per speaker (see "SetSpeakerDiarization()")
+	or one per audio channel (e.g. for call-center QA tooling), the files are named
+	"<session ID>-speaker-<speaker>.txt" resp. "<session ID>-channel-<channel>.txt"
+	and every turn is appended as a line
+
+	Parameter:
+		cDirectory *C.char
+			(the existing directory of the files as a C string, an empty string disables the files (default))
+		cPerChannel C.int
+			(1 to write one file per channel, 0 to write one file per speaker)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetTranscriptFiles
+func SetTranscriptFiles(cDirectory *C.char, cPerChannel C.int) (C.int) {
+	directory := C.GoString(cDirectory)
+
+	if directory != "" {
+		if forbidsDiskWrites("The transcript files") {
+			return result(resultInvalidArgument)
+		}
+		if info, err := os.Stat(directory); err != nil || info.IsDir() == false {
+			logError("Transcript files directory doesn't exist: " + directory, nil)
+			return result(resultInvalidArgument)
+		}
+	}
+
+	speakerMutex.Lock()
+		transcriptFilesDirectory = directory
+		transcriptFilesPerChannel = int32(cPerChannel) == int32(1)
+	speakerMutex.Unlock()
+	return result(resultOK)
+}
+
+
+// finishSpeakerStream adds the words of the finished stream to the session's words (the next stream starts anew).
+func finishSpeakerStream() {
+	speakerMutex.Lock()
+	defer speakerMutex.Unlock()
+
+	speakerWords = append(speakerWords, streamSpeakerWords...)
+	streamSpeakerWords = nil
+}
+
+
+// speakerTurns merges the session's words into turns, a new turn starts whenever the speaker changes.
+func speakerTurns() ([]speakerTurn) {
+	speakerMutex.Lock()
+		words := append(append([]*speechpb.WordInfo{}, speakerWords...), streamSpeakerWords...)
+	speakerMutex.Unlock()
+
+	turns := []speakerTurn{}
+	for _, word := range words {
+		last := len(turns) - 1
+		if last >= 0 && turns[last].Speaker == word.SpeakerTag {
+			turns[last].Transcript += " " + word.Word
+			continue
+		}
+		turns = append(turns, speakerTurn{
+			Speaker:	word.SpeakerTag,
+			Label:		"Speaker " + strconv.Itoa(int(word.SpeakerTag)),
+			Transcript:	word.Word,
+		})
+	}
+	return turns
+}
+
+
+/*
+	GetSpeakerTranscript (output **C.char) (C.int):
+	retrieves the transcript of the current (or last) session assembled into speaker turns
+	(see "SetSpeakerDiarization()") as a JSON array, e.g.:
+	[{"speaker":1,"label":"Speaker 1","transcript":"hello how can I help"},{"speaker":2,"label":"Speaker 2","transcript":"I have a question"}]
+	(the speaker tags of the current stream may still change with google's next final result)
+
+	Parameters:
+		output:
+			The pointer which is used to store the speaker turns
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetSpeakerTranscript
+func GetSpeakerTranscript (output **C.char) (C.int) {
+	encoded, err := json.Marshal(speakerTurns())
+	if err != nil {
+		logError("Could not encode speaker turns: ", err)
+		return result(resultError)
+	}
+
+	*output = cString(string(encoded))
+	return result(resultOK)
+}
+
+
+// postProcessResponse applies the post-processing to the transcripts of the response (before any delivery).
+func postProcessResponse(resp *speechpb.StreamingRecognizeResponse) {
+	for _, result := range resp.Results {
+		for _, alternative := range result.Alternatives {
+			alternative.Transcript = postProcess(alternative.Transcript)
+			for _, word := range alternative.Words {
+				word.Word = normalizeTranscript(word.Word)
+			}
+		}
+	}
+}
+
+
+// postProcess applies the formatting options, the replacement rules (in the order they have been added)
+// and the Unicode normalization (see "SetUnicodeNormalization()") to the transcript.
+func postProcess(transcript string) (string) {
+	transcript = formatTranscript(transcript, atomic.LoadInt32(&outputFormatting))
+
+	switch atomic.LoadInt32(&numberFormatting) {
+	case numbersDigits:
+		transcript = numberWordsToDigits(transcript)
+	case numbersVerbatim:
+		transcript = digitsToNumberWords(transcript)
+	}
+
+	postProcessMutex.Lock()
+	defer postProcessMutex.Unlock()
+
+	for _, rule := range replacements {
+		if rule.pattern != nil {
+			transcript = rule.pattern.ReplaceAllString(transcript, rule.replace)
+		} else {
+			transcript = strings.ReplaceAll(transcript, rule.search, rule.replace)
+		}
+	}
+	return normalizeTranscript(transcript)
+}
+
+
+// formatTranscript applies the formatting options to the transcript.
+func formatTranscript(transcript string, formatting int32) (string) {
+	if formatting == 0 {
+		return transcript
+	}
+
+	if formatting & (formatStripFillers | formatCollapseRepeats) != 0 {
+		var kept []string
+		for _, word := range strings.Fields(transcript) {
+			// Words are compared without case and punctuation, e.g. "Um," is a filler word.
+			bare := strings.ToLower(strings.TrimFunc(word, unicode.IsPunct))
+			if formatting & formatStripFillers != 0 && fillerWords[bare] {
+				continue
+			}
+			if formatting & formatCollapseRepeats != 0 && len(kept) > 0 && bare != "" &&
+				strings.ToLower(strings.TrimFunc(kept[len(kept)-1], unicode.IsPunct)) == bare {
+				// The last one of the repeated words is kept (it carries the punctuation).
+				kept[len(kept)-1] = word
+				continue
+			}
+			kept = append(kept, word)
+		}
+		transcript = strings.Join(kept, " ")
+	}
+
+	if formatting & (formatLowercase | formatSentenceCase) != 0 {
+		transcript = strings.ToLower(transcript)
+	}
+
+	if formatting & formatSentenceCase != 0 {
+		// The first letter of the transcript and of every sentence gets capitalized.
+		runes := []rune(transcript)
+		startOfSentence := true
+		for i, r := range runes {
+			if startOfSentence && unicode.IsLetter(r) {
+				runes[i] = unicode.ToUpper(r)
+				startOfSentence = false
+			} else if r == '.' || r == '?' || r == '!' {
+				startOfSentence = true
+			}
+		}
+		transcript = string(runes)
+	}
+
+	return transcript
+}
+
+
+/*
+	SetOutputFormatting(cFormatting C.int) (C.int):
+	sets how the transcripts are formatted before delivery (interim and final results alike),
+	the formatting is applied before the replacement dictionary (see "AddReplacement()")
+	
+	Parameter:
+		cFormatting C.int
+			(a combination of the following flags, 0 disables the formatting (default)
+			1 (lowercase): all letters are converted to lowercase
+			2 (sentence case): all letters are converted to lowercase, except the first letter of every sentence
+			4 (strip fillers): filler words like "uh" and "um" are removed
+			8 (collapse repeats): repeated words like "the the" are reduced to one)
+		
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetOutputFormatting
+func SetOutputFormatting(cFormatting C.int) (C.int) {
+	formatting := int32(cFormatting)
+	if formatting < 0 || formatting > (formatLowercase | formatSentenceCase | formatStripFillers | formatCollapseRepeats) {
+		logError("Unknown output formatting", nil)
+		return result(resultInvalidArgument)
+	}
+	atomic.StoreInt32(&outputFormatting, formatting)
+	return result(resultOK)
+}
+
+
+// numberWordsToDigits replaces spelled out numbers by digits, e.g. "twenty three" by "23" (inverse text normalization).
+func numberWordsToDigits(transcript string) (string) {
+	words := strings.Fields(transcript)
+	var converted []string
+
+	for i := 0; i < len(words); {
+		// Find the longest sequence of number words starting here ("and" only inside of a number, e.g. "one hundred and five").
+		total, current := int64(0), int64(0)
+		end, numberEnd := i, i
+		for end < len(words) {
+			bare := strings.ToLower(strings.TrimFunc(words[end], unicode.IsPunct))
+			if value, ok := numberUnits[bare]; ok {
+				// Numbers only follow hundreds, scales or tens (e.g. "twenty three"), "nine one one" are three numbers
+				// and nothing follows a zero.
+				if end > i && (value == 0 || total + current == 0 || (current % 100 != 0 && (current % 100 < 20 || current % 10 != 0 || value >= 10))) {
+					break
+				}
+				current += value
+			} else if bare == "hundred" && end > i {
+				current *= 100
+			} else if scale, ok := numberScales[bare]; ok && end > i {
+				total += current * scale
+				current = 0
+			} else if bare != "and" || end == i {
+				break
+			}
+			end++
+			if bare != "and" {
+				numberEnd = end
+			}
+			// Punctuation ends the number, e.g. "three, four".
+			if strings.TrimRightFunc(words[end-1], unicode.IsPunct) != words[end-1] {
+				break
+			}
+		}
+
+		// A lone "one" or "zero" is rather a word (e.g. "no one came"), unless other numbers are next to it (e.g. "nine one one").
+		first := strings.ToLower(strings.TrimFunc(words[i], unicode.IsPunct))
+		lone := numberEnd == i + 1 && (first == "one" || first == "zero") &&
+			(i == 0 || isNumberWord(words[i-1]) == false) && (numberEnd == len(words) || isNumberWord(words[numberEnd]) == false)
+
+		if numberEnd == i || lone {
+			converted = append(converted, words[i])
+			i++
+			continue
+		}
+
+		// The trailing punctuation of the last number word is kept.
+		last := words[numberEnd-1]
+		converted = append(converted, strconv.FormatInt(total + current, 10) + last[len(strings.TrimRightFunc(last, unicode.IsPunct)):])
+		i = numberEnd
+	}
+	return strings.Join(converted, " ")
+}
+
+
+// isNumberWord reports whether the word is (a part of) a number, spelled out or in digits.
+func isNumberWord(word string) (bool) {
+	bare := strings.ToLower(strings.TrimFunc(word, unicode.IsPunct))
+	_, unit := numberUnits[bare]
+	_, scale := numberScales[bare]
+	digits := bare != "" && strings.TrimFunc(bare, unicode.IsDigit) == ""
+	return unit || scale || bare == "hundred" || digits
+}
+
+
+// digitsToNumberWords replaces whole numbers by spelled out numbers, e.g. "23" by "twenty three".
+func digitsToNumberWords(transcript string) (string) {
+	words := strings.Fields(transcript)
+	for i, word := range words {
+		bare := strings.TrimRightFunc(word, unicode.IsPunct)
+		number, err := strconv.ParseInt(bare, 10, 64)
+		if err != nil || number < 0 || number >= 1000000000000 {
+			continue
+		}
+		words[i] = spellNumber(number) + word[len(bare):]
+	}
+	return strings.Join(words, " ")
+}
+
+
+// spellNumber spells out the number (below one trillion).
+func spellNumber(number int64) (string) {
+	if number < 20 || (number < 100 && number % 10 == 0) {
+		for word, value := range numberUnits {
+			if value == number {
+				return word
+			}
+		}
+	}
+	if number < 100 {
+		return spellNumber(number - number % 10) + " " + spellNumber(number % 10)
+	}
+	if number < 1000 {
+		spelled := spellNumber(number / 100) + " hundred"
+		if number % 100 != 0 {
+			spelled += " " + spellNumber(number % 100)
+		}
+		return spelled
+	}
+
+	for _, scale := range []string{"billion", "million", "thousand"} {
+		if number >= numberScales[scale] {
+			spelled := spellNumber(number / numberScales[scale]) + " " + scale
+			if number % numberScales[scale] != 0 {
+				spelled += " " + spellNumber(number % numberScales[scale])
+			}
+			return spelled
+		}
+	}
+	return ""
+}
+
+
+/*
+	SetNumberFormatting(cMode C.int) (C.int):
+	sets how numbers are formatted in the transcripts before delivery (e.g. command parsers need digits),
+	google's V1 API has no switch (it formats most numbers as digits), so the library converts them locally
+	(for english transcripts), the conversion is applied after the formatting options (see "SetOutputFormatting()")
+	
+	Parameter:
+		cMode C.int
+			0 (as delivered): numbers are delivered as recognized by google (default)
+			1 (digits): spelled out numbers are converted to digits, e.g. "twenty three" to "23"
+			2 (verbatim): whole numbers are spelled out, e.g. "23" to "twenty three"
+		
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetNumberFormatting
+func SetNumberFormatting(cMode C.int) (C.int) {
+	mode := int32(cMode)
+	if mode < numbersAsDelivered || mode > numbersVerbatim {
+		logError("Unknown number formatting", nil)
+		return result(resultInvalidArgument)
+	}
+	atomic.StoreInt32(&numberFormatting, mode)
+	return result(resultOK)
+}
+
+
+// newReplacement creates a replacement rule, regular expressions get compiled.
+func newReplacement(search string, replace string, isRegex bool) (replacement, error) {
+	rule := replacement{search: search, replace: replace}
+	if isRegex {
+		pattern, err := regexp.Compile(search)
+		if err != nil {
+			return rule, err
+		}
+		rule.pattern = pattern
+	}
+	return rule, nil
+}
+
+
+/*
+	AddReplacement(cSearch *C.char, cReplace *C.char, cRegex C.int) (C.int):
+	adds a rule to the replacement dictionary, which is applied to all transcripts before delivery
+	(e.g. to correct recurring mis-transcriptions of brand names), the rules are applied in the order they have been added
+	
+	Parameter:
+		cSearch *C.char
+			(the text to replace as a C string (case sensitive) or a regular expression, e.g. "(?i)go ogle")
+		cReplace *C.char
+			(the replacement as a C string, regular expressions can refer to their groups, e.g. "$1")
+		cRegex C.int
+			(1 if cSearch is a regular expression, 0 if it's an exact text)
+		
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export AddReplacement
+func AddReplacement(cSearch *C.char, cReplace *C.char, cRegex C.int) (C.int) {
+	search := C.GoString(cSearch)
+	if search == "" {
+		logError("Replacement search must not be empty", nil)
+		return result(resultInvalidArgument)
+	}
+
+	rule, err := newReplacement(search, C.GoString(cReplace), int32(cRegex) == int32(1))
+	if err != nil {
+		logError("Invalid regular expression: ", err)
+		return result(resultInvalidArgument)
+	}
+
+	postProcessMutex.Lock()
+		replacements = append(replacements, rule)
+	postProcessMutex.Unlock()
+	return result(resultOK)
+}
+
+
+/*
+	LoadReplacementsFromFile(cPath *C.char) (C.int):
+	adds the rules of a file to the replacement dictionary (see "AddReplacement()"),
+	one rule per line in the form "search -> replace", a search enclosed in slashes is a regular expression
+	(e.g. "/(?i)go ogle/ -> Google"), empty lines and lines starting with "#" are ignored,
+	if a line is invalid no rule of the file is added
+	
+	Parameter:
+		cPath *C.char
+			(the path of the file as a C string)
+		
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export LoadReplacementsFromFile
+func LoadReplacementsFromFile(cPath *C.char) (C.int) {
+	file, err := os.Open(C.GoString(cPath))
+	if err != nil {
+		logError("Could not open replacements file: ", err)
+		return result(resultError)
+	}
+	defer file.Close()
+
+	var rules []replacement
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "->", 2)
+		search := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || search == "" {
+			logError("Invalid replacement in line " + strconv.Itoa(lineNumber), nil)
+			return result(resultInvalidArgument)
+		}
+
+		isRegex := len(search) > 2 && strings.HasPrefix(search, "/") && strings.HasSuffix(search, "/")
+		if isRegex {
+			search = search[1:len(search)-1]
+		}
+
+		rule, err := newReplacement(search, strings.TrimSpace(parts[1]), isRegex)
+		if err != nil {
+			logError("Invalid regular expression in line " + strconv.Itoa(lineNumber) + ": ", err)
+			return result(resultInvalidArgument)
+		}
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		logError("Could not read replacements file: ", err)
+		return result(resultError)
+	}
+
+	postProcessMutex.Lock()
+		replacements = append(replacements, rules...)
+	postProcessMutex.Unlock()
+	return result(resultOK)
+}
+
+
+/*
+	ClearReplacements():
+	removes all rules from the replacement dictionary
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export ClearReplacements
+func ClearReplacements() () {
+	postProcessMutex.Lock()
+		replacements = nil
+	postProcessMutex.Unlock()
+}
+
+
+// result converts a return code into the convention chosen by the host (see "SetLegacyReturnCodes()").
+func result(code C.int) (C.int) {
+	if atomic.LoadInt32(&legacyReturnCodes) == 0 {
+		return code
+	}
+	if code == resultOK {
+		return C.int(1)
+	}
+	return C.int(0)
+}
+
+
+// begin registers a call and returns its context, the returned function has to be called when the call returns.
+func (calls *pendingCalls) begin(parent context.Context) (context.Context, func()) {
+	callCtx, callCancel := context.WithCancel(parent)
+
+	calls.mutex.Lock()
+		id := calls.nextID
+		calls.nextID++
+		calls.cancels[id] = callCancel
+	calls.mutex.Unlock()
+
+	return callCtx, func() {
+		calls.mutex.Lock()
+			delete(calls.cancels, id)
+		calls.mutex.Unlock()
+		callCancel()
+	}
+}
+
+
+// cancelAll cancels all registered calls.
+func (calls *pendingCalls) cancelAll() {
+	calls.mutex.Lock()
+	defer calls.mutex.Unlock()
+
+	for _, callCancel := range calls.cancels {
+		callCancel()
+	}
+}
+
+
+/*
+	CancelPendingReceive():
+	cancels all "ReceiveTranscript()" calls which are waiting for a result,
+	they return GO_SPEECH_RECOGNITION_ERROR_CANCELED (e.g. so a host shutting down doesn't have to wait for them),
+	the session keeps running
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export CancelPendingReceive
+func CancelPendingReceive() () {
+	pendingReceives.cancelAll()
+}
+
+
+/*
+	CancelPendingSend():
+	cancels all "SendAudio()" calls which are waiting for space in the audio queue (overflow policy "block"),
+	they return GO_SPEECH_RECOGNITION_ERROR_CANCELED, the session keeps running
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export CancelPendingSend
+func CancelPendingSend() () {
+	pendingSends.cancelAll()
+}
+
+
+/*
+	SetLegacyReturnCodes(cLegacy C.int):
+	switches the return codes of the exports to the previous convention
+	(1 on success, 0 on failure) for hosts built against it,
+	by default the exports return 0 on success and a negative error code on failure,
+	the exports answering a question (e.g. "IsInitialized()") always return 1 (yes) or 0 (no)
+	
+	Parameter:
+		cLegacy C.int
+			(1 to use the previous convention, 0 to use the current one (default))
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetLegacyReturnCodes
+func SetLegacyReturnCodes(cLegacy C.int) () {
+	if int32(cLegacy) == int32(1) {
+		atomic.StoreInt32(&legacyReturnCodes, 1)
+	} else {
+		atomic.StoreInt32(&legacyReturnCodes, 0)
+	}
+}
+
+
+/*
+	GetLog () (*_Ctype_char)
+	returns the last logged event as a String
+
+	Return:
+		logStatus as a CString (usable by C)
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetLog
+func GetLog () (*_Ctype_char) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	return cString(logStatus);
+}
+
+
+/*
+	FreeString(cString *C.char) ():
+	frees a string returned by the library (e.g. by "ReceiveTranscript()" or "GetLog()"),
+	so hosts and bindings don't depend on sharing the C runtime of the library (e.g. with another compiler or language)
+
+	Parameter:
+		cString *C.char
+			(the string, nil is ignored)
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export FreeString
+func FreeString(cString *C.char) () {
+	if cString != nil {
+		C.free(unsafe.Pointer(cString))
+	}
+}
+
 
-				// Concatenate the alternatives, splitted by ';'
-				helperString += alternative.Transcript + (string(';'))
+// logError saves the message (followed by the error, if there is one) as the last logged event
+// and the structured form of the error (errors without gRPC status count as UNKNOWN).
+func logError(message string, err error) {
+	code := codes.Unknown
+	details := []json.RawMessage{}
+
+	if err != nil {
+		message += err.Error()
+		if st, ok := status.FromError(err); ok {
+			code = st.Code()
+			// Google's error details (e.g. quota violations) as JSON objects.
+			for _, detail := range st.Proto().GetDetails() {
+				encoded, encodeErr := (&jsonpb.Marshaler{}).MarshalToString(detail)
+				if encodeErr != nil {
+					// The type of the detail is unknown, so only its type is included.
+					encoded = `{"@type":` + strconv.Quote(detail.GetTypeUrl()) + `}`
+				}
+				details = append(details, json.RawMessage(encoded))
 			}
-		}		
+		}
+	}
+
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	activeSessionID, requestID := "", ""
+	if sessionActive {
+		activeSessionID, requestID = sessionID, currentRequestID()
+	}
+
+	timestamp := time.Now()
+	message = addLogEntry(logLevelError, message, timestamp)
+
+	logStatus = message
+	lastError = structuredError{
+		Code:		int32(code),
+		Status:		rpccode.Code_name[int32(code)],
+		Message:	message,
+		Retryable:	err != nil && isRetryable(err),
+		Details:	details,
+		Timestamp:	timestamp.Format(time.RFC3339Nano),
+		SessionID:	activeSessionID,
+		RequestID:	requestID,
+		Labels:		copyLabels(),
+	}
+}
+
+
+// logWarning saves the message in the log like "logError()", but as a warning
+// (the last logged event and the last error stay untouched).
+func logWarning(message string) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	addLogEntry(logLevelWarning, message, time.Now())
+}
+
+
+// addLogEntry adds the message to the session's log (prefixed with the correlation IDs) or, outside of a session,
+// to the global log, records it in the structured history (see "GetLogEntries()") and returns the (prefixed) message.
+// The caller has to hold the logMutex.
+func addLogEntry(level string, message string, timestamp time.Time) (string) {
+	// The sensitive terms never reach the log (see "AddSensitiveTerm()").
+	message = redactSensitive(message)
+	recordLogEntry(level, message, timestamp)
+
+	if level == logLevelWarning {
+		message = "Warning: " + message
+	}
+	if sessionActive {
+		message = "[session " + sessionID + ", request " + currentRequestID() + formatLabels(", ") + "] " + message
+	}
+
+	entry := timestamp.Format(time.RFC3339Nano) + " " + message
+	if sessionActive {
+		sessionLogs[sessionID].add(entry)
+	} else {
+		globalLog.add(entry)
+	}
+	return message
+}
+
+
+// add appends the entry, the oldest entry gets overwritten if the ring is full.
+func (ring *logRing) add(entry string) {
+	if len(ring.entries) < logRingSize {
+		ring.entries = append(ring.entries, entry)
+		return
 	}
+	ring.entries[ring.next] = entry
+	ring.next = (ring.next + 1) % logRingSize
+}
+
+
+// list returns the entries, oldest first.
+func (ring *logRing) list() ([]string) {
+	return append(append([]string{}, ring.entries[ring.next:]...), ring.entries[:ring.next]...)
+}
+
+
+// endSessionLog marks the session as inactive, following errors go to the global log.
+func endSessionLog() {
+	logMutex.Lock()
+		sessionActive = false
+	logMutex.Unlock()
+}
+
+
+/*
+	GetSessionLog (cSessionID *C.char) (*C.char):
+	returns the log of the given session (see "GetSessionID()"), one entry per line (oldest first),
+	each entry starts with its timestamp, the logs of the last 16 sessions are kept (up to 64 entries each)
 	
-	// Fill output and remove semicolons in front/end
+	Parameter:
+		cSessionID *C.char
+			(the ID of the session as a C string)
 
-	// ";word;"" -> "word"
-	if((helperString[0] == ";"[0]) && (helperString[len(helperString)-1] == ";"[0])){
-		*output = C.CString(helperString[1:len(helperString)-1])
-		return C.int(1)
+	Return:
+		the log as a CString (usable by C), empty if the session is unknown
+*/
 
-	// "word;"" -> "word"
-	}else if ((helperString[0] != ";"[0]) && (helperString[len(helperString)-1] == ";"[0])){
-		*output = C.CString(helperString[:len(helperString)-1])
-		return C.int(1)
+// Next comment is needed by cgo to know which function to export.
+//export GetSessionLog
+func GetSessionLog (cSessionID *C.char) (*C.char) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
 
-	// ";word"" -> "word"
-	}else if ((helperString[0] == ";"[0]) && (helperString[len(helperString)-1] != ";"[0])){
-		*output = C.CString(helperString[1:])
-		return C.int(1)
+	ring, ok := sessionLogs[C.GoString(cSessionID)]
+	if ok == false {
+		return cString("")
 	}
+	return cString(strings.Join(ring.list(), "\n"))
+}
 
-	// "word"
-	*output = C.CString(helperString)
-	return C.int(1)
+
+/*
+	GetGlobalLog () (*C.char):
+	returns the log of the errors outside of a session (e.g. calls before "InitializeStream()"),
+	one entry per line (oldest first), each entry starts with its timestamp (up to 64 entries are kept)
+
+	Return:
+		the log as a CString (usable by C)
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetGlobalLog
+func GetGlobalLog () (*C.char) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	return cString(strings.Join(globalLog.list(), "\n"))
+}
+
+
+// newSessionID creates the ID of a new session and resets the request counter.
+func newSessionID() {
+	random := make([]byte, 8)
+	rand.Read(random)
+	useSessionID(hex.EncodeToString(random), 0)
+}
+
+
+// useSessionID starts the session with the ID, its requests are counted from the given number on.
+func useSessionID(id string, requests uint64) {
+	logMutex.Lock()
+		sessionID = id
+		requestCount = requests
+		sessionActive = true
+
+		// Every session logs into its own ring (a restored session keeps its ring), the oldest session's ring gets dropped.
+		if sessionLogs[sessionID] == nil {
+			sessionLogs[sessionID] = &logRing{}
+			sessionLogOrder = append(sessionLogOrder, sessionID)
+			if len(sessionLogOrder) > maxSessionLogs {
+				delete(sessionLogs, sessionLogOrder[0])
+				sessionLogOrder = sessionLogOrder[1:]
+			}
+		}
+	logMutex.Unlock()
+
+	// The requests of the previous session are forgotten (see "GetSessionInfo()").
+	resetStreamInfos()
+}
+
+
+// currentRequestID returns the ID of the current request, i.e. "<session ID>-<stream sequence number>".
+// The caller has to hold the logMutex.
+func currentRequestID() (string) {
+	if sessionID == "" {
+		return ""
+	}
+	return sessionID + "-" + strconv.FormatUint(requestCount, 10)
+}
+
+
+// startSpan starts an OpenTelemetry span for an export, tagged with the correlation IDs
+// (a no-op span if tracing is disabled or no exporter is installed).
+func startSpan(name string) (trace.Span) {
+	if atomic.LoadInt32(&tracingEnabled) == 0 {
+		return trace.SpanFromContext(context.Background())
+	}
+
+	logMutex.Lock()
+		attributes := []attribute.KeyValue{
+			attribute.String("session.id", sessionID),
+			attribute.String("request.id", currentRequestID()),
+		}
+		for key, value := range sessionLabels {
+			attributes = append(attributes, attribute.String("label." + key, value))
+		}
+	logMutex.Unlock()
+
+	_, span := otel.Tracer("go-speech-recognition").Start(context.Background(), name, trace.WithAttributes(attributes...))
+	return span
+}
+
+
+// endSpan ends the span, a failed export marks it as failed (with the last log entry as description).
+func endSpan(span trace.Span, code C.int) {
+	if code < resultOK && span.IsRecording() {
+		logMutex.Lock()
+			span.SetStatus(otelcodes.Error, logStatus)
+		logMutex.Unlock()
+	}
+	span.End()
 }
 
 
 /*
-	GetLog () (*_Ctype_char)
-	returns the last logged event as a String
+	EnableOTLPExport(cEndpoint *C.char) (C.int):
+	exports the library's spans and metrics (the statistics of "GetStats()" and the billed seconds)
+	via OTLP/HTTP to the given endpoint (e.g. an OpenTelemetry collector) and enables the tracing
+	
+	Parameter:
+		cEndpoint *C.char
+			(the URL of the OTLP/HTTP endpoint as a C string, e.g. "http://localhost:4318")
+		
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export EnableOTLPExport
+func EnableOTLPExport(cEndpoint *C.char) (C.int) {
+	endpoint := C.GoString(cEndpoint)
+
+	exportMutex.Lock()
+	defer exportMutex.Unlock()
+
+	// Replace a previous export.
+	shutdownOTLPExport(context.Background())
+
+	traceExporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		logError("Could not create trace exporter: ", err)
+		return result(resultError)
+	}
+
+	metricExporter, err := otlpmetrichttp.New(context.Background(), otlpmetrichttp.WithEndpointURL(endpoint))
+	if err != nil {
+		traceExporter.Shutdown(context.Background())
+		logError("Could not create metric exporter: ", err)
+		return result(resultError)
+	}
+
+	tracerProvider = sdktrace.NewTracerProvider(sdktrace.WithBatcher(traceExporter))
+	meterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)))
+
+	if err := registerMetrics(meterProvider.Meter("go-speech-recognition")); err != nil {
+		shutdownOTLPExport(context.Background())
+		logError("Could not register metrics: ", err)
+		return result(resultError)
+	}
+
+	otel.SetTracerProvider(tracerProvider)
+	atomic.StoreInt32(&tracingEnabled, 1)
+	return result(resultOK)
+}
+
+
+/*
+	DisableOTLPExport():
+	flushes the pending spans and metrics and stops the export of "EnableOTLPExport()"
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export DisableOTLPExport
+func DisableOTLPExport() () {
+	exportMutex.Lock()
+	defer exportMutex.Unlock()
+
+	shutdownOTLPExport(context.Background())
+}
+
+
+// shutdownOTLPExport flushes (until the context is done) and stops the providers of the OTLP export (if there are any).
+// The caller has to hold the exportMutex.
+func shutdownOTLPExport(flushCtx context.Context) {
+	if tracerProvider != nil {
+		tracerProvider.Shutdown(flushCtx)
+		tracerProvider = nil
+	}
+	if meterProvider != nil {
+		meterProvider.Shutdown(flushCtx)
+		meterProvider = nil
+	}
+}
+
+
+// registerMetrics publishes the session statistics as observable OpenTelemetry instruments.
+func registerMetrics(meter metric.Meter) (error) {
+	counters := map[string]*uint64{
+		"speech.audio.overflows":	&audioOverflows,
+		"speech.result.overflows":	&resultOverflows,
+		"speech.keepalive.frames":	&keepAliveFrames,
+		"speech.stream.retries":	&streamRetries,
+	}
+
+	var observables []metric.Observable
+	observed := map[metric.Int64ObservableCounter]*uint64{}
+	for name, counter := range counters {
+		instrument, err := meter.Int64ObservableCounter(name)
+		if err != nil {
+			return err
+		}
+		observables = append(observables, instrument)
+		observed[instrument] = counter
+	}
+
+	billedSeconds, err := meter.Float64ObservableCounter("speech.billed.seconds", metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+	observables = append(observables, billedSeconds)
+
+	_, err = meter.RegisterCallback(func(_ context.Context, observer metric.Observer) (error) {
+		for instrument, counter := range observed {
+			observer.ObserveInt64(instrument, int64(atomic.LoadUint64(counter)))
+		}
+		billingMutex.Lock()
+			observer.ObserveFloat64(billedSeconds, sessionBilledSeconds)
+		billingMutex.Unlock()
+		return nil
+	}, observables...)
+	return err
+}
+
+
+/*
+	GetSessionID () (*C.char):
+	returns the ID of the current (or last) session, which is included in all log entries
+	(followed by the ID of the request to google, e.g. "[session 1f2e3d4c5b6a7988, request 1f2e3d4c5b6a7988-2] ...")
 
 	Return:
-		logStatus as a CString (usable by C)
+		the session ID as a CString (usable by C), empty if no session has been initialized yet
 */
 
 // Next comment is needed by cgo to know which function to export.
-//export GetLog
-func GetLog () (*_Ctype_char) {
-	return C.CString(logStatus);
+//export GetSessionID
+func GetSessionID () (*C.char) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	return cString(sessionID)
+}
+
+
+/*
+	SetSessionLabel(cKey *C.char, cValue *C.char) (C.int):
+	attaches a label (e.g. the customer ID or the room name) to the session and the following ones,
+	the labels are included in the log entries (e.g. "[session 1f2e3d4c5b6a7988, request 1f2e3d4c5b6a7988-2, customer=42] ..."),
+	the last error (see "GetLastErrorJSON()"), the stats (see "GetStats()"), the spans (see "EnableTracing()")
+	and the transcript files (see "SetTranscriptFiles()") for downstream correlation
+
+	Parameters:
+		cKey *C.char
+			(the key, letters, digits, "_", "-" and "." only)
+		cValue *C.char
+			(the value, an empty string removes the label)
+
+	Return:
+		0 if successful (1 with legacy return codes)
+		a negative error code if failed (0 with legacy return codes, error log can be retrieved with "GetLog()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export SetSessionLabel
+func SetSessionLabel(cKey *C.char, cValue *C.char) (C.int) {
+	key := C.GoString(cKey)
+	value := C.GoString(cValue)
+	if labelKeyPattern.MatchString(key) == false {
+		logError("Invalid label key", nil)
+		return result(resultInvalidArgument)
+	}
+
+	logMutex.Lock()
+		if value == "" {
+			delete(sessionLabels, key)
+		} else {
+			sessionLabels[key] = value
+		}
+	logMutex.Unlock()
+	return result(resultOK)
+}
+
+
+/*
+	ClearSessionLabels():
+	removes all labels (see "SetSessionLabel()")
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export ClearSessionLabels
+func ClearSessionLabels() () {
+	logMutex.Lock()
+		sessionLabels = map[string]string{}
+	logMutex.Unlock()
+}
+
+
+// formatLabels formats the labels as "<separator>key=value" sorted by key (the logMutex has to be locked).
+func formatLabels(separator string) (string) {
+	keys := make([]string, 0, len(sessionLabels))
+	for key := range sessionLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	formatted := ""
+	for _, key := range keys {
+		formatted += separator + key + "=" + sessionLabels[key]
+	}
+	return formatted
+}
+
+
+// copyLabels returns a copy of the labels, nil if there are none (the logMutex has to be locked).
+func copyLabels() (map[string]string) {
+	if len(sessionLabels) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(sessionLabels))
+	for key, value := range sessionLabels {
+		labels[key] = value
+	}
+	return labels
+}
+
+
+/*
+	EnableTracing(cEnabled C.int):
+	emits OpenTelemetry spans for "InitializeStream()", "SendAudio()" and "ReceiveTranscript()",
+	tagged with the session ID and the request ID
+	
+	Parameter:
+		cEnabled C.int
+			(1 to enable, 0 to disable the tracing (default))
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export EnableTracing
+func EnableTracing(cEnabled C.int) () {
+	if int32(cEnabled) == int32(1) {
+		atomic.StoreInt32(&tracingEnabled, 1)
+	} else {
+		atomic.StoreInt32(&tracingEnabled, 0)
+	}
+}
+
+
+/*
+	GetLastErrorJSON () (*C.char):
+	returns the last logged error in a machine-readable form as a JSON object, e.g.:
+	{"code":8,"status":"RESOURCE_EXHAUSTED","message":"...","retryable":true,
+	"details":[{"@type":"type.googleapis.com/google.rpc.QuotaFailure",...}],"timestamp":"2019-06-01T12:00:00.000+02:00"}
+	(errors of the library itself have the code 2 (UNKNOWN))
+
+	Return:
+		the last error as a CString (usable by C), "{}" if no error has been logged yet
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export GetLastErrorJSON
+func GetLastErrorJSON () (*C.char) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	if lastError.Timestamp == "" {
+		return cString("{}")
+	}
+
+	encoded, err := json.Marshal(lastError)
+	if err != nil {
+		return cString("{}")
+	}
+	return cString(string(encoded))
 }
 
 
 /*
-	CloseStream () (C.int):
-	closes the streaming session
+	CloseStream ():
+	closes the streaming session,
+	in-flight "SendAudio()" and "ReceiveTranscript()" calls are aborted and waited for,
+	so the stream is never released while it's still in use
 */
 
 // Next comment is needed by cgo to know which function to export.
 //export CloseStream
 func CloseStream () () {
+	// A callback can't wait for the session to close (the session waits for the callback).
+	if insideCallback() {
+		closeStreamLater()
+		return
+	}
+
+	lifecycleMutex.Lock()
+	defer lifecycleMutex.Unlock()
+
+	closeStream()
+
+	// The files of the session are subject to the retention policy (see "SetDataRetention()").
+	enforceRetention()
+}
+
+
+// closeStream tears the session down in a fixed order, so in-flight calls complete or abort safely:
+// 1. no new calls are accepted, 2. blocking work gets aborted, 3. the pumps and the in-flight calls
+// are waited for, 4. the stream and the client are released.
+// The caller has to hold the lifecycleMutex.
+func closeStream() {
+	// Nothing to close (never initialized or already closed).
+	if cancel == nil {
+		return
+	}
+
+	// 1. The cancellation aborts all blocking calls (first, a send hanging in the network mustn't delay the closing).
 	cancel()
-	// Ensure that no sending or receiving is done while closing the stream.
+
+	// 2. From now on the exports (and the background goroutines) see the session as closed.
+	sendMutex.Lock()
+	receiveMutex.Lock()
+		initialized = false
+		realtimeRing.Store(nil)
+		if maxDurationTimer != nil {
+			maxDurationTimer.Stop()
+			maxDurationTimer = nil
+		}
+	receiveMutex.Unlock()
+	sendMutex.Unlock()
+
+	// 3. Wait until the pumps stopped and the in-flight calls returned.
+	if receivePumpDone != nil {
+		<-receivePumpDone
+	}
+	if sendPumpDone != nil {
+		<-sendPumpDone
+	}
+	if realtimePumpDone != nil {
+		<-realtimePumpDone
+	}
+	inFlight.Wait()
+
+	// Following errors aren't part of the session anymore.
+	endSessionLog()
+
+	// The passthrough stream ends with the session (see "SendRawRequest()").
+	closeRawStream()
+
+	// 4. Nobody uses the stream anymore.
 	sendMutex.Lock()
 	receiveMutex.Lock()
+	streamMutex.Lock()
 		stream = nil
+		streamClocks = map[speechpb.Speech_StreamingRecognizeClient]*streamClock{}
+		quotaResume = nil
+		offlineResume = nil
+		spoolToBackfill = nil
+		backfillResponses = nil
+		// Close the gRPC connection of the client.
+		if client != nil {
+			client.Close()
+		}
 		client = nil
 		ctx = nil
-		initialized = false
+		cancel = nil
+	streamMutex.Unlock()
 	receiveMutex.Unlock()
 	sendMutex.Unlock()
+
+	// 5. Free the slot of the rate limits.
+	releaseRecognition()
+}
+
+
+/*
+	Shutdown ():
+	releases everything the library holds: closes the streaming session (if the host didn't call "CloseStream()"),
+	stops the background goroutines, closes the gRPC connection and flushes the OTLP export,
+	it gives up after 2 seconds (a hanging connection mustn't block the host's teardown),
+	it's called automatically when the library is unloaded or the process exits (without flushing the OTLP export),
+	but hosts should call it explicitly before unloading the library
+*/
+
+// Next comment is needed by cgo to know which function to export.
+//export Shutdown
+func Shutdown () () {
+	if checkCallbackThread("Shutdown") != resultOK {
+		return
+	}
+
+	shutdown(true)
+}
+
+
+// Next comment is needed by cgo to know which function to export.
+//export unloadLibrary
+func unloadLibrary() {
+	// Called by the destructor (see go-speech-recognition-unload.go): on Windows under the loader lock and at the exit
+	// of a Go process when the runtime can't schedule anymore, so the library must not wait unless it holds something.
+	if libraryIdle() {
+		return
+	}
+
+	// The network isn't waited for either.
+	shutdown(false)
+}
+
+
+// shutdown releases everything the library holds within the shutdownTimeout, flush sends the pending OTLP export.
+func shutdown(flush bool) {
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+
+	released := make(chan struct{})
+	go func() {
+		defer close(released)
+
+		CloseStream()
+
+		// The workers of the batches must not call back into an unloaded host.
+		cancelBatches(shutdownCtx.Done())
+
+		// The streams of a conversation are canceled right away.
+		conversationLifecycleMutex.Lock()
+			endConversation(false)
+		conversationLifecycleMutex.Unlock()
+
+		// The queued callbacks must not be pumped into an unloaded host either.
+		clearCallbacks()
+
+		exportCtx := shutdownCtx
+		if flush == false {
+			// The canceled context drops the pending spans and metrics.
+			var dropPending context.CancelFunc
+			exportCtx, dropPending = context.WithCancel(shutdownCtx)
+			dropPending()
+		}
+		exportMutex.Lock()
+			shutdownOTLPExport(exportCtx)
+		exportMutex.Unlock()
+
+		debugServerMutex.Lock()
+			stopDebugServer()
+		debugServerMutex.Unlock()
+		watchdogMutex.Lock()
+			stopWatchdog()
+		watchdogMutex.Unlock()
+
+		resultEventMutex.Lock()
+			closeResultEvent()
+		resultEventMutex.Unlock()
+
+		logMutex.Lock()
+			closeLogFile()
+			closeSystemLog()
+		logMutex.Unlock()
+	}()
+
+	select {
+	case <-released:
+	case <-shutdownCtx.Done():
+	}
 }
 
 
@@ -1,5 +1,7 @@
 #pragma once
 
+#include <stdint.h>
+
 /*
 Author: Christopher Dreide(https://github.com/Drizzy3D)
 
@@ -9,8 +11,8 @@ See the README.md for instructions on how to use this header.
 */
 
 /*
-Create enum, which is needed to handle the return values of some functions as cgo
-doesn't support bool transfer between C and Go.
+Create enum, which is needed to handle the boolean parameters and the return values of the functions
+answering a question as cgo doesn't support bool transfer between C and Go.
 */
 enum GO_SPEECH_RECOGNITION_BOOL {
 	GO_SPEECH_RECOGNITION_TRUE = 1,
@@ -18,7 +20,37 @@ enum GO_SPEECH_RECOGNITION_BOOL {
 };
 
 /*
-GO_SPEECH_RECOGNITION_BOOL InitializeStream(char* cTranscriptLanguage, int cSampleRate):
+Create enum, which is needed to handle the return codes of the functions reporting success or failure:
+GO_SPEECH_RECOGNITION_OK on success, a negative error code on failure
+(hosts built against the previous convention can switch back to it with SetLegacyReturnCodes).
+*/
+enum GO_SPEECH_RECOGNITION_RESULT {
+	GO_SPEECH_RECOGNITION_OK = 0,
+	GO_SPEECH_RECOGNITION_ERROR = -1,
+	GO_SPEECH_RECOGNITION_ERROR_NOT_INITIALIZED = -2,
+	GO_SPEECH_RECOGNITION_ERROR_INVALID_ARGUMENT = -3,
+	GO_SPEECH_RECOGNITION_ERROR_FINALIZED = -4,
+	GO_SPEECH_RECOGNITION_ERROR_QUEUE_FULL = -5,
+	GO_SPEECH_RECOGNITION_ERROR_STREAM_ENDED = -6,
+	GO_SPEECH_RECOGNITION_ERROR_BUDGET_EXCEEDED = -7,
+	GO_SPEECH_RECOGNITION_ERROR_CANCELED = -8,
+	GO_SPEECH_RECOGNITION_ERROR_RATE_LIMITED = -9,
+	GO_SPEECH_RECOGNITION_ERROR_QUOTA_EXCEEDED = -10,
+	GO_SPEECH_RECOGNITION_ERROR_WRONG_THREAD = -11,
+	GO_SPEECH_RECOGNITION_ERROR_BUSY = -12,
+	GO_SPEECH_RECOGNITION_ERROR_MODEL_UNAVAILABLE = -13
+};
+
+/*
+void SetLegacyReturnCodes(GO_SPEECH_RECOGNITION_BOOL cLegacy):
+switches the return codes to the previous convention (GO_SPEECH_RECOGNITION_TRUE on success,
+GO_SPEECH_RECOGNITION_FALSE on failure) for hosts built against it,
+the functions answering a question (e.g. IsInitialized) always return a GO_SPEECH_RECOGNITION_BOOL
+*/
+typedef void(*GO_SPEECH_RECOGNITION_SET_LEGACY_RETURN_CODES)(GO_SPEECH_RECOGNITION_BOOL cLegacy);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT InitializeStream(char* cTranscriptLanguage, int cSampleRate):
 one time initialization,
 sets the streaming session up (saved in global variables),
 sends the initial configuration message
@@ -37,13 +69,49 @@ cInterimResults
 (set GO_SPEECH_RECOGNITION_TRUE if you want to get interim results)
 
 Return:
-GO_SPEECH_RECOGNITION_TRUE if successful
-GO_SPEECH_RECOGNITION_FALSE if failed (error log can be retrieved with "GetLog()")
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_INITIALIZE_STREAM)(char* cTranscriptLanguage, int cSampleRate, char* cTranscriptionModel, int cMaxAlternatives, GO_SPEECH_RECOGNITION_BOOL cInterimResults);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT Reconfigure(char* cTranscriptLanguage, int cSampleRate, char* cTranscriptionModel, int cMaxAlternatives, GO_SPEECH_RECOGNITION_BOOL cInterimResults):
+switches the configuration of a running session (e.g. the language),
+the current stream gets finished (its remaining results can still be received)
+and a new stream with the updated configuration takes over
+
+Parameter:
+the same as InitializeStream
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()"), the current stream keeps running
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_RECONFIGURE)(char* cTranscriptLanguage, int cSampleRate, char* cTranscriptionModel, int cMaxAlternatives, GO_SPEECH_RECOGNITION_BOOL cInterimResults);
+
+/*
+void SetSingleUtterance(GO_SPEECH_RECOGNITION_BOOL cSingleUtterance):
+enables the single utterance mode (e.g. for voice commands),
+google stops recognizing after the first utterance, the library then automatically
+starts a new stream for the next utterance,
+has to be called before InitializeStream or Reconfigure to take effect
+*/
+typedef void(*GO_SPEECH_RECOGNITION_SET_SINGLE_UTTERANCE)(GO_SPEECH_RECOGNITION_BOOL cSingleUtterance);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetNextUtteranceLanguage(char* cTranscriptLanguage):
+transcribes only the next utterance in the given language (BCP-47 language tag, e.g. for a bilingual prompt),
+afterwards the session's language is used again,
+only available in single utterance mode
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
 */
-typedef GO_SPEECH_RECOGNITION_BOOL(*GO_SPEECH_RECOGNITION_INITIALIZE_STREAM)(char* cTranscriptLanguage, int cSampleRate, char* cTranscriptionModel, int cMaxAlternatives, GO_SPEECH_RECOGNITION_BOOL cInterimResults);
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_NEXT_UTTERANCE_LANGUAGE)(char* cTranscriptLanguage);
 
 /*
-GO_SPEECH_RECOGNITION_BOOL SendAudio(const short* recording, int recording_size):
+GO_SPEECH_RECOGNITION_RESULT SendAudio(const short* recording, int recording_size):
 prepares the inputted audio data to be sent to google,
 handles the sending process
 
@@ -55,10 +123,10 @@ recording_size:
 the size of the transferred recording
 
 Return:
-GO_SPEECH_RECOGNITION_TRUE if successful
-GO_SPEECH_RECOGNITION_FALSE if failed (error log can be retrieved with "GetLog()")
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
 */
-typedef GO_SPEECH_RECOGNITION_BOOL(*GO_SPEECH_RECOGNITION_SEND_AUDIO)(const short* recording, int recording_size);
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SEND_AUDIO)(const short* recording, int recording_size);
 
 /*
 ReceiveTranscript (char**):
@@ -66,10 +134,10 @@ retrieves and saves the current final transcripts from Google
 
 Return:
 (per reference [char* (current transcript)] alternatives will be splitted using ';' characters)
-GO_SPEECH_RECOGNITION_TRUE if successful
-GO_SPEECH_RECOGNITION_FALSE if failed (error log can be retrieved with "GetLog()")
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
 */
-typedef GO_SPEECH_RECOGNITION_BOOL(*GO_SPEECH_RECOGNITION_RECEIVE_TRANSCRIPT)(char**);
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_RECEIVE_TRANSCRIPT)(char**);
 
 /*
 char* GetLog ():
@@ -80,13 +148,20 @@ char* (last logged event)
 */
 typedef char*(*GO_SPEECH_RECOGNITION_GET_LOG)();
 
+/*
+void FreeString(char* cString):
+frees a string returned by the library (the output of ReceiveTranscript, GetLog, ...),
+so hosts don't depend on sharing the C runtime of the library (e.g. with another compiler or language)
+*/
+typedef void(*GO_SPEECH_RECOGNITION_FREE_STRING)(char* cString);
+
 
 /*
 void CloseStream ():
 closes the streaming session, all accesses to the streaming object
 in the go-speech-recognition.dll are secured by mutex
 */
-typedef GO_SPEECH_RECOGNITION_BOOL(*GO_SPEECH_RECOGNITION_CLOSE_STREAM)();
+typedef void(*GO_SPEECH_RECOGNITION_CLOSE_STREAM)();
 
 /*
 GO_SPEECH_RECOGNITION_BOOL IsInitialized ():
@@ -97,3 +172,1638 @@ GO_SPEECH_RECOGNITION_TRUE if the stream is initialized
 GO_SPEECH_RECOGNITION_FALSE if the stream is not initialized
 */
 typedef GO_SPEECH_RECOGNITION_BOOL(*GO_SPEECH_RECOGNITION_IS_INITIALIZED)();
+
+/*
+Create enum, which is needed to choose how the internal audio and result queues behave when they are full.
+*/
+enum GO_SPEECH_RECOGNITION_OVERFLOW_POLICY {
+	GO_SPEECH_RECOGNITION_OVERFLOW_BLOCK = 0,
+	GO_SPEECH_RECOGNITION_OVERFLOW_DROP_OLDEST = 1,
+	GO_SPEECH_RECOGNITION_OVERFLOW_DROP_NEWEST = 2,
+	GO_SPEECH_RECOGNITION_OVERFLOW_ERROR = 3
+};
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetOverflowPolicy(GO_SPEECH_RECOGNITION_OVERFLOW_POLICY cPolicy):
+sets how the internal audio and result queues behave when they are full
+(e.g. because the network is too slow or the host doesn't receive fast enough)
+
+Parameter:
+cPolicy
+GO_SPEECH_RECOGNITION_OVERFLOW_BLOCK: wait until there is space left (default, no data is lost)
+GO_SPEECH_RECOGNITION_OVERFLOW_DROP_OLDEST: drop the oldest queued item (lowest latency)
+GO_SPEECH_RECOGNITION_OVERFLOW_DROP_NEWEST: drop the new item
+GO_SPEECH_RECOGNITION_OVERFLOW_ERROR: reject the new item, SendAudio resp. ReceiveTranscript fail
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_OVERFLOW_POLICY)(GO_SPEECH_RECOGNITION_OVERFLOW_POLICY cPolicy);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT GetStats(char**):
+retrieves the statistics of the current session as a JSON object, e.g.:
+{"audioOverflows":0,"resultOverflows":2,"keepAliveFrames":0,"streamRetries":1,"chunkMs":20,"sendLatencyMs":0.4,"billedSeconds":15,
+"goroutines":14,"heapBytes":5242880,"wakeups":1250,"idleMs":30000}
+(chunkMs is the size of the sent chunks in milliseconds of audio, which adapts to the send latency between 20 and 200 ms,
+billedSeconds the billed time reported by google (see GetBilledTime), goroutines and heapBytes the number of goroutines
+and the heap of the Go runtime embedded in the host (see SetWatchdog), wakeups the number of times the library's goroutines
+woke up to do work in the session and idleMs the time since the last wakeup (it grows while the idle session uses no CPU))
+
+Return:
+(per reference [char* (statistics as JSON)])
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_GET_STATS)(char**);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT GetQueueDepths(int* audioMs, int* results):
+retrieves how much data is currently buffered in the internal queues
+(e.g. to display the buffering state or to implement a backpressure UI)
+
+Return:
+(per reference [int (duration of the queued audio in milliseconds that isn't sent yet, -1 for compressed audio)],
+[int (number of received results that aren't retrieved yet)])
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_GET_QUEUE_DEPTHS)(int* audioMs, int* results);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetKeepAlive(GO_SPEECH_RECOGNITION_BOOL cEnabled, int cThresholdMs):
+keeps the stream open while the host pauses sending audio,
+google terminates a stream which doesn't receive audio for a while,
+so a short frame of silence is sent whenever no audio has been sent for cThresholdMs milliseconds
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_KEEP_ALIVE)(GO_SPEECH_RECOGNITION_BOOL cEnabled, int cThresholdMs);
+
+/*
+Create enum, which is needed to identify the events reported by the library (see PollEvent).
+*/
+enum GO_SPEECH_RECOGNITION_EVENT {
+	GO_SPEECH_RECOGNITION_EVENT_SILENCE_TIMEOUT = 1,
+	GO_SPEECH_RECOGNITION_EVENT_MAX_DURATION = 2,
+	GO_SPEECH_RECOGNITION_EVENT_BUDGET_EXCEEDED = 3,
+	GO_SPEECH_RECOGNITION_EVENT_CLIPPING = 4,
+	GO_SPEECH_RECOGNITION_EVENT_DC_OFFSET = 5,
+	GO_SPEECH_RECOGNITION_EVENT_NEAR_SILENCE = 6,
+	GO_SPEECH_RECOGNITION_EVENT_QUOTA_EXCEEDED = 7,
+	GO_SPEECH_RECOGNITION_EVENT_OFFLINE = 8,
+	GO_SPEECH_RECOGNITION_EVENT_BACKFILLED = 9,
+	GO_SPEECH_RECOGNITION_EVENT_FORMAT_MISMATCH = 10,
+	GO_SPEECH_RECOGNITION_EVENT_WAKE_WORD = 11,
+	GO_SPEECH_RECOGNITION_EVENT_WAKE_WORD_REARMED = 12
+};
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetNoSpeechTimeout(int cSeconds):
+finalizes the stream, when no speech has been detected in the sent audio for cSeconds seconds
+(0 disables the timeout (default)), the remaining results can still be received,
+the event GO_SPEECH_RECOGNITION_EVENT_SILENCE_TIMEOUT is reported
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_NO_SPEECH_TIMEOUT)(int cSeconds);
+
+/*
+GO_SPEECH_RECOGNITION_BOOL PollEvent(int* event):
+retrieves the next event reported by the library (doesn't block)
+
+Return:
+(per reference [int (GO_SPEECH_RECOGNITION_EVENT)])
+GO_SPEECH_RECOGNITION_TRUE if an event has been retrieved
+GO_SPEECH_RECOGNITION_FALSE if no event is pending
+*/
+typedef GO_SPEECH_RECOGNITION_BOOL(*GO_SPEECH_RECOGNITION_POLL_EVENT)(int* event);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetMaxSessionDuration(int cSeconds):
+finalizes the stream, when the session lasts longer than cSeconds seconds
+(0 means unlimited (default)), the remaining results can still be received,
+the event GO_SPEECH_RECOGNITION_EVENT_MAX_DURATION is reported,
+if called during a session the duration is measured from the session's initialization
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_MAX_SESSION_DURATION)(int cSeconds);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT GetBilledSecondsEstimate(double* session, double* today):
+retrieves an estimate of the billed time, i.e. the seconds of audio actually sent to google
+(including keep-alive frames), google may round the billed time up
+
+Return:
+(per reference [double (seconds sent in the current (or last) session)],
+[double (seconds sent today across all sessions of the process)])
+GO_SPEECH_RECOGNITION_OK
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_GET_BILLED_SECONDS_ESTIMATE)(double* session, double* today);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT GetBilledTime(double* session, double* batches):
+retrieves the billed time reported by google (its total billed time, so the invoices can be reconciled),
+google reports it with the final results of a stream, so it trails the estimate of GetBilledSecondsEstimate
+
+Return:
+(per reference [double (seconds billed for the current (or last) session, all its streams)],
+[double (seconds billed for the files of the batches since the library has been loaded)])
+GO_SPEECH_RECOGNITION_OK
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_GET_BILLED_TIME)(double* session, double* batches);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetBilledSecondsBudget(int cSeconds):
+sets a hard cap for the audio sent per day (across all sessions of the process, 0 means unlimited (default)),
+when it is exceeded the stream is finalized, GO_SPEECH_RECOGNITION_EVENT_BUDGET_EXCEEDED
+is reported and no new stream can be initialized until the next day
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_BILLED_SECONDS_BUDGET)(int cSeconds);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetRateLimits(int cMaxConcurrent, int cRequestsPerMinute, int cQueueTimeoutMs):
+caps the recognitions across the process: the concurrent recognitions (the streaming session and the files of the batches
+being transcribed) and the requests per minute (every stream and batch request), 0 means unlimited (default),
+a new session or file waits up to cQueueTimeoutMs for a free slot (0 rejects immediately)
+and fails with GO_SPEECH_RECOGNITION_ERROR_RATE_LIMITED afterwards
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_RATE_LIMITS)(int cMaxConcurrent, int cRequestsPerMinute, int cQueueTimeoutMs);
+
+/*
+GO_SPEECH_RECOGNITION_BOOL IsRetryableCode(int cCode):
+returns how the library classifies the gRPC status code (e.g. 8 for RESOURCE_EXHAUSTED,
+see https://grpc.github.io/grpc/core/md_doc_statuscodes.html),
+on retryable errors a new stream is opened transparently (up to 3 times in a row),
+all other errors end the receiving
+
+Return:
+GO_SPEECH_RECOGNITION_TRUE if the code is treated as retryable
+GO_SPEECH_RECOGNITION_FALSE if the code is treated as fatal
+*/
+typedef GO_SPEECH_RECOGNITION_BOOL(*GO_SPEECH_RECOGNITION_IS_RETRYABLE_CODE)(int cCode);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetRetryableCode(int cCode, GO_SPEECH_RECOGNITION_BOOL cRetryable):
+overrides how the library classifies the gRPC status code
+(e.g. treat RESOURCE_EXHAUSTED as fatal for interactive use cases)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_RETRYABLE_CODE)(int cCode, GO_SPEECH_RECOGNITION_BOOL cRetryable);
+
+/*
+char* GetLastErrorJSON ():
+returns the last logged error in a machine-readable form as a JSON object, e.g.:
+{"code":8,"status":"RESOURCE_EXHAUSTED","message":"...","retryable":true,
+"details":[{"@type":"type.googleapis.com/google.rpc.QuotaFailure",...}],"timestamp":"2019-06-01T12:00:00.000+02:00"}
+(code and status are the gRPC status, errors of the library itself have the code 2 (UNKNOWN))
+
+Return:
+char* (last error as JSON, "{}" if no error has been logged yet)
+*/
+typedef char*(*GO_SPEECH_RECOGNITION_GET_LAST_ERROR_JSON)();
+
+/*
+Create enum, which is needed to choose the format of GetLastResponseRaw.
+*/
+enum GO_SPEECH_RECOGNITION_RAW_FORMAT {
+	GO_SPEECH_RECOGNITION_RAW_JSON = 0,
+	GO_SPEECH_RECOGNITION_RAW_PROTOBUF = 1
+};
+
+/*
+GO_SPEECH_RECOGNITION_RESULT GetLastResponseRaw(GO_SPEECH_RECOGNITION_RAW_FORMAT cFormat, char** output, int* outputLength):
+retrieves the last StreamingRecognizeResponse received from google, unmodified by the library (for debugging),
+as JSON (the JSON mapping of protobuf) or as the serialized protobuf message (binary, not null-terminated),
+"{}" or no bytes if no response has been received yet, free the output with FreeString
+(outputLength may be NULL for JSON)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_GET_LAST_RESPONSE_RAW)(GO_SPEECH_RECOGNITION_RAW_FORMAT cFormat, char** output, int* outputLength);
+
+/*
+char* GetSessionID ():
+returns the ID of the current (or last) session, which is included in all log entries
+(followed by the ID of the request to google, e.g. "[session 1f2e3d4c5b6a7988, request 1f2e3d4c5b6a7988-2] ...")
+
+Return:
+char* (session ID, empty if no session has been initialized yet)
+*/
+typedef char*(*GO_SPEECH_RECOGNITION_GET_SESSION_ID)();
+
+/*
+GO_SPEECH_RECOGNITION_RESULT GetSessionInfo(char** output):
+retrieves the identifiers of the current (or last) session and its requests to google (every stream) as a JSON object, e.g.:
+{"sessionId":"3f2a...","active":true,"labels":{"customer":"42"},"streams":[{"requestId":"3f2a...-1",
+"opened":"2019-06-01T12:00:00.000+02:00","model":"latest_long","googleRequestId":"4711","headers":{"x-goog-...":["..."]},"trailers":{...}}]}
+(requestId is the library's ID of the request, model the model of the stream (e.g. picked for "auto"), googleRequestId the ID google returned in its responses,
+headers and trailers the gRPC metadata of google's response, the last 100 streams of the session are kept)
+
+Return:
+(per reference [char* (session info as JSON, free it with FreeString)])
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_GET_SESSION_INFO)(char** output);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetSnapshotInterval(int cSeconds):
+sets the interval of the session's checkpoints in seconds (default 5, 0 to checkpoint only after the final results),
+the session is also checkpointed after every final result,
+has to be called before InitializeStream to take effect
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_SNAPSHOT_INTERVAL)(int cSeconds);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT GetSessionSnapshot(char** output):
+retrieves the latest checkpoint of the current (or last) session as a JSON string the host can persist, e.g.:
+{"version":1,"sessionId":"3f2a...","labels":{"customer":"42"},"requests":3,"config":{"config":{"encoding":"LINEAR16",...}},
+"audioSeconds":83.2,"audioEndTimestampUs":1559383283200000,"lastFinalTimestampUs":1559383281900000,"taken":"2019-06-01T12:01:23.4+02:00"}
+(audioSeconds is the audio sent in the session, audioEndTimestampUs the timestamp of the end of the sent audio,
+lastFinalTimestampUs the end of the last final result (-1 if unknown), the snapshot contains no credentials and no transcripts)
+
+Return:
+(per reference [char* (snapshot as JSON, free it with FreeString)])
+GO_SPEECH_RECOGNITION_OK if successful
+GO_SPEECH_RECOGNITION_NOT_INITIALIZED if no snapshot has been taken yet
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_GET_SESSION_SNAPSHOT)(char** output);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT RestoreSession(const char* cSnapshot):
+initializes the streaming session like InitializeStream, but continues the logical session of a snapshot
+(e.g. after a crash or restart of the host): the session keeps its ID, its labels and its configuration,
+its requests are numbered on, the timestamps SendAudio assigns continue at the end of the last final result,
+the host should send the audio after lastFinalTimestampUs again (its results got lost with the crash),
+the default credentials are used (the snapshot contains none)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_RESTORE_SESSION)(const char* cSnapshot);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetSessionLabel(const char* cKey, const char* cValue):
+attaches a label (e.g. the customer ID or the room name) to the session and the following ones, the labels are included
+in the log entries (e.g. "[session 1f2e3d4c5b6a7988, request 1f2e3d4c5b6a7988-2, customer=42] ..."), the last error,
+the stats, the spans and the transcript files, the key may contain letters, digits, "_", "-" and ".", an empty value removes the label
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_SESSION_LABEL)(const char* cKey, const char* cValue);
+
+/*
+void ClearSessionLabels():
+removes all labels (see SetSessionLabel)
+*/
+typedef void(*GO_SPEECH_RECOGNITION_CLEAR_SESSION_LABELS)();
+
+/*
+void EnableTracing(GO_SPEECH_RECOGNITION_BOOL cEnabled):
+emits OpenTelemetry spans for InitializeStream, SendAudio and ReceiveTranscript,
+tagged with the session ID and the request ID
+*/
+typedef void(*GO_SPEECH_RECOGNITION_ENABLE_TRACING)(GO_SPEECH_RECOGNITION_BOOL cEnabled);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT EnableOTLPExport(char* cEndpoint):
+exports the library's spans and metrics (the statistics of GetStats and the billed seconds)
+via OTLP/HTTP to the given endpoint (e.g. an OpenTelemetry collector at "http://localhost:4318")
+and enables the tracing
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_ENABLE_OTLP_EXPORT)(char* cEndpoint);
+
+/*
+void DisableOTLPExport():
+flushes the pending spans and metrics and stops the export
+*/
+typedef void(*GO_SPEECH_RECOGNITION_DISABLE_OTLP_EXPORT)();
+
+/*
+void Shutdown ():
+releases everything the library holds: closes the streaming session (if CloseStream hasn't been called),
+stops the background goroutines, closes the gRPC connection and flushes the OTLP export,
+it gives up after 2 seconds (a hanging connection mustn't block the host's teardown),
+it's called automatically when the library is unloaded or the process exits (without flushing the OTLP export),
+but hosts should call it explicitly before unloading the library
+*/
+typedef void(*GO_SPEECH_RECOGNITION_SHUTDOWN)();
+
+/*
+void InitializeLibrary ():
+prepares the library, needed when it's linked statically (built with -buildmode=c-archive, e.g. for iOS):
+returns once the Go runtime has started and links the teardown calling Shutdown when the process exits
+(the host should still call Shutdown itself when it terminates), the shared library doesn't need it
+*/
+typedef void(*GO_SPEECH_RECOGNITION_INITIALIZE_LIBRARY)();
+
+/*
+void CancelPendingReceive():
+cancels all ReceiveTranscript calls which are waiting for a result,
+they return GO_SPEECH_RECOGNITION_ERROR_CANCELED (e.g. so a host shutting down doesn't have to wait for them),
+the session keeps running
+*/
+typedef void(*GO_SPEECH_RECOGNITION_CANCEL_PENDING_RECEIVE)();
+
+/*
+void CancelPendingSend():
+cancels all SendAudio calls which are waiting for space in the audio queue (GO_SPEECH_RECOGNITION_OVERFLOW_BLOCK),
+they return GO_SPEECH_RECOGNITION_ERROR_CANCELED, the session keeps running
+*/
+typedef void(*GO_SPEECH_RECOGNITION_CANCEL_PENDING_SEND)();
+
+/*
+char* GetSessionLog (char* cSessionID):
+returns the log of the given session (see GetSessionID), one entry per line (oldest first),
+each entry starts with its timestamp, the logs of the last 16 sessions are kept (up to 64 entries each)
+
+Return:
+char* (the session's log, empty if the session is unknown)
+*/
+typedef char*(*GO_SPEECH_RECOGNITION_GET_SESSION_LOG)(char* cSessionID);
+
+/*
+char* GetGlobalLog ():
+returns the log of the errors outside of a session (e.g. calls before InitializeStream),
+one entry per line (oldest first), each entry starts with its timestamp (up to 64 entries are kept)
+
+Return:
+char* (the global log)
+*/
+typedef char*(*GO_SPEECH_RECOGNITION_GET_GLOBAL_LOG)();
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetSpeakerDiarization(GO_SPEECH_RECOGNITION_BOOL cEnabled, int cMinSpeakers, int cMaxSpeakers):
+enables the speaker diarization, google then tags every word of the final results with its speaker
+(the words are assembled into speaker turns, see GetSpeakerTranscript),
+has to be called before InitializeStream or Reconfigure to take effect
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_SPEAKER_DIARIZATION)(GO_SPEECH_RECOGNITION_BOOL cEnabled, int cMinSpeakers, int cMaxSpeakers);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT GetSpeakerTranscript (char** output):
+retrieves the transcript of the current (or last) session assembled into speaker turns as a JSON array, e.g.:
+[{"speaker":1,"label":"Speaker 1","transcript":"hello how can I help"},{"speaker":2,"label":"Speaker 2","transcript":"I have a question"}]
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_GET_SPEAKER_TRANSCRIPT)(char**);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetTranscriptFiles(char* cDirectory, GO_SPEECH_RECOGNITION_BOOL cPerChannel):
+writes the final transcripts into rolling files in the given (existing) directory, one per speaker (see SetSpeakerDiarization)
+or one per audio channel (cPerChannel), named "<session ID>-speaker-<speaker>.txt" resp. "<session ID>-channel-<channel>.txt",
+every turn is appended as a line, an empty directory disables the files
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_TRANSCRIPT_FILES)(char* cDirectory, GO_SPEECH_RECOGNITION_BOOL cPerChannel);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT ReceiveTranscriptJSON (char** output):
+retrieves the next results like ReceiveTranscript, but in a structured form as a JSON array
+(one object per result, the alternatives aren't concatenated), e.g.:
+[{"transcript":"turn on the light","isFinal":true,"stability":0,"confidence":0.92,"words":[{"word":"turn","confidence":0.95,"speaker":0},...]}]
+the words are only included in final results, their confidence only if enabled (see SetWordConfidence)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_RECEIVE_TRANSCRIPT_JSON)(char**);
+
+/*
+void SetWordConfidence(GO_SPEECH_RECOGNITION_BOOL cEnabled):
+requests the confidence of every word of the final results (included in ReceiveTranscriptJSON),
+has to be called before InitializeStream or Reconfigure to take effect
+*/
+typedef void(*GO_SPEECH_RECOGNITION_SET_WORD_CONFIDENCE)(GO_SPEECH_RECOGNITION_BOOL cEnabled);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT AddReplacement(char* cSearch, char* cReplace, GO_SPEECH_RECOGNITION_BOOL cRegex):
+adds a rule to the replacement dictionary, which is applied to all transcripts before delivery
+(cSearch is an exact, case sensitive text or a regular expression (cRegex), whose groups can be referred to in cReplace, e.g. "$1"),
+the rules are applied in the order they have been added
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_ADD_REPLACEMENT)(char* cSearch, char* cReplace, GO_SPEECH_RECOGNITION_BOOL cRegex);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT LoadReplacementsFromFile(char* cPath):
+adds the rules of a file to the replacement dictionary, one rule per line in the form "search -> replace",
+a search enclosed in slashes is a regular expression, empty lines and lines starting with "#" are ignored
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_LOAD_REPLACEMENTS_FROM_FILE)(char* cPath);
+
+/*
+void ClearReplacements():
+removes all rules from the replacement dictionary
+*/
+typedef void(*GO_SPEECH_RECOGNITION_CLEAR_REPLACEMENTS)();
+
+/*
+Create enum, which is needed to combine the formatting options of SetOutputFormatting.
+*/
+enum GO_SPEECH_RECOGNITION_FORMATTING {
+	GO_SPEECH_RECOGNITION_FORMAT_LOWERCASE = 1,
+	GO_SPEECH_RECOGNITION_FORMAT_SENTENCE_CASE = 2,
+	GO_SPEECH_RECOGNITION_FORMAT_STRIP_FILLERS = 4,
+	GO_SPEECH_RECOGNITION_FORMAT_COLLAPSE_REPEATS = 8
+};
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetOutputFormatting(int cFormatting):
+sets how the transcripts are formatted before delivery (interim and final results alike),
+cFormatting is a combination of GO_SPEECH_RECOGNITION_FORMATTING flags (0 disables the formatting (default)),
+the formatting is applied before the replacement dictionary (see AddReplacement)
+
+GO_SPEECH_RECOGNITION_FORMAT_LOWERCASE: all letters are converted to lowercase
+GO_SPEECH_RECOGNITION_FORMAT_SENTENCE_CASE: all letters are converted to lowercase, except the first letter of every sentence
+GO_SPEECH_RECOGNITION_FORMAT_STRIP_FILLERS: filler words like "uh" and "um" are removed
+GO_SPEECH_RECOGNITION_FORMAT_COLLAPSE_REPEATS: repeated words like "the the" are reduced to one
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_OUTPUT_FORMATTING)(int cFormatting);
+
+/*
+Create enum, which is needed to select the number formatting of SetNumberFormatting.
+*/
+enum GO_SPEECH_RECOGNITION_NUMBER_FORMATTING {
+	GO_SPEECH_RECOGNITION_NUMBERS_AS_DELIVERED = 0,
+	GO_SPEECH_RECOGNITION_NUMBERS_DIGITS = 1,
+	GO_SPEECH_RECOGNITION_NUMBERS_VERBATIM = 2
+};
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetNumberFormatting(GO_SPEECH_RECOGNITION_NUMBER_FORMATTING cMode):
+sets how numbers are formatted in the transcripts before delivery,
+google's V1 API has no switch (it formats most numbers as digits), so the library converts them locally (for english transcripts)
+
+GO_SPEECH_RECOGNITION_NUMBERS_AS_DELIVERED: numbers are delivered as recognized by google (default)
+GO_SPEECH_RECOGNITION_NUMBERS_DIGITS: spelled out numbers are converted to digits, e.g. "twenty three" to "23"
+GO_SPEECH_RECOGNITION_NUMBERS_VERBATIM: whole numbers are spelled out, e.g. "23" to "twenty three"
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_NUMBER_FORMATTING)(GO_SPEECH_RECOGNITION_NUMBER_FORMATTING cMode);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT LoadPhraseHintsFromFile(char* cPath):
+loads phrase hints (e.g. product names or commands), which google recognizes more likely,
+a ".csv" file contains one phrase and optionally its boost per line (e.g. "Acme Turbo,15"), any other file one phrase per line,
+replaces the previously loaded hints (an empty path removes them) and has to be called before InitializeStream or Reconfigure to take effect
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_LOAD_PHRASE_HINTS_FROM_FILE)(char* cPath);
+
+/*
+Callback invoked whenever a result becomes final (see SetFinalResultCallback),
+transcript is only valid during the call.
+*/
+typedef void(*GO_SPEECH_RECOGNITION_FINAL_RESULT_CALLBACK)(char* transcript, double durationSeconds, float confidence, void* userData);
+
+/*
+void SetFinalResultCallback(GO_SPEECH_RECOGNITION_FINAL_RESULT_CALLBACK cCallback, void* cUserData):
+registers a callback, which is invoked whenever a result becomes final (interim results don't invoke it)
+with the utterance's full text, its duration and its confidence (cUserData is passed unchanged, NULL removes the callback),
+the callback is invoked on a thread of the library and should return quickly (receiving waits meanwhile),
+unless the callbacks are queued (see SetCallbackDispatch)
+*/
+typedef void(*GO_SPEECH_RECOGNITION_SET_FINAL_RESULT_CALLBACK)(GO_SPEECH_RECOGNITION_FINAL_RESULT_CALLBACK cCallback, void* cUserData);
+
+/*
+Create enum, which is needed to identify the lifecycle events of an utterance (see PollUtteranceEvent).
+*/
+enum GO_SPEECH_RECOGNITION_UTTERANCE_EVENT {
+	GO_SPEECH_RECOGNITION_UTTERANCE_STARTED = 1,
+	GO_SPEECH_RECOGNITION_UTTERANCE_UPDATED = 2,
+	GO_SPEECH_RECOGNITION_UTTERANCE_FINALIZED = 3,
+	GO_SPEECH_RECOGNITION_UTTERANCE_ABORTED = 4
+};
+
+/*
+GO_SPEECH_RECOGNITION_BOOL PollUtteranceEvent(int* id, int* event, char** transcript):
+retrieves the next lifecycle event of an utterance (doesn't block), the interim results and the final result
+of the same utterance share an ID (counted across sessions, starting with 1):
+an utterance is started by its first result, updated by the following interim results and either finalized
+by its final result or aborted (e.g. when the stream ends before the final result)
+
+Return:
+GO_SPEECH_RECOGNITION_TRUE if an event has been retrieved
+GO_SPEECH_RECOGNITION_FALSE if no event is pending
+*/
+typedef GO_SPEECH_RECOGNITION_BOOL(*GO_SPEECH_RECOGNITION_POLL_UTTERANCE_EVENT)(int* id, int* event, char** transcript);
+
+/*
+Create enum, which is needed to identify the speech events reported by google (see PollSpeechEvent).
+*/
+enum GO_SPEECH_RECOGNITION_SPEECH_EVENT {
+	GO_SPEECH_RECOGNITION_SPEECH_END_OF_SINGLE_UTTERANCE = 1,
+	GO_SPEECH_RECOGNITION_SPEECH_ACTIVITY_BEGIN = 2,
+	GO_SPEECH_RECOGNITION_SPEECH_ACTIVITY_END = 3,
+	GO_SPEECH_RECOGNITION_SPEECH_ACTIVITY_TIMEOUT = 4
+};
+
+/*
+void SetVoiceActivityEvents(GO_SPEECH_RECOGNITION_BOOL cEnabled):
+lets google report the begin and the end of speech (see PollSpeechEvent),
+has to be called before InitializeStream or Reconfigure to take effect
+*/
+typedef void(*GO_SPEECH_RECOGNITION_SET_VOICE_ACTIVITY_EVENTS)(GO_SPEECH_RECOGNITION_BOOL cEnabled);
+
+/*
+GO_SPEECH_RECOGNITION_BOOL PollSpeechEvent(int* event, long long* timestampUs):
+retrieves the next speech event reported by google (doesn't block): the end of an utterance in single utterance mode
+and, if enabled (see SetVoiceActivityEvents), the begin and the end of speech,
+the time of the event is given in microseconds of the host's clock (see SendAudioWithTimestamp), -1 if unknown
+
+Return:
+GO_SPEECH_RECOGNITION_TRUE if an event has been retrieved
+GO_SPEECH_RECOGNITION_FALSE if no event is pending
+*/
+typedef GO_SPEECH_RECOGNITION_BOOL(*GO_SPEECH_RECOGNITION_POLL_SPEECH_EVENT)(int* event, long long* timestampUs);
+
+/*
+A recognition hypothesis retrieved by ReceiveAlternatives.
+*/
+typedef struct {
+	char* transcript;
+	float confidence;
+} GO_SPEECH_RECOGNITION_ALTERNATIVE;
+
+/*
+GO_SPEECH_RECOGNITION_RESULT ReceiveAlternatives (GO_SPEECH_RECOGNITION_ALTERNATIVE** list, int* count):
+retrieves the next result like ReceiveTranscript, but as a list of alternatives ranked by google (most likely first)
+with their confidences (the confidence is only set for final results),
+the list has to be released with FreeAlternatives
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_RECEIVE_ALTERNATIVES)(GO_SPEECH_RECOGNITION_ALTERNATIVE** list, int* count);
+
+/*
+void FreeAlternatives (GO_SPEECH_RECOGNITION_ALTERNATIVE* list, int count):
+releases the alternatives retrieved by ReceiveAlternatives
+*/
+typedef void(*GO_SPEECH_RECOGNITION_FREE_ALTERNATIVES)(GO_SPEECH_RECOGNITION_ALTERNATIVE* list, int count);
+
+/*
+Callback re-ranking the alternatives of a result (see SetRerankCallback),
+returns the index of the chosen alternative, list is only valid during the call.
+*/
+typedef int(*GO_SPEECH_RECOGNITION_RERANK_CALLBACK)(GO_SPEECH_RECOGNITION_ALTERNATIVE* list, int count, void* userData);
+
+/*
+void SetRerankCallback(GO_SPEECH_RECOGNITION_RERANK_CALLBACK cCallback, void* cUserData):
+registers a callback, which re-ranks the alternatives of every result with several alternatives (e.g. based on the host's command grammar),
+the chosen alternative is then delivered like google's most likely alternative (cUserData is passed unchanged, NULL removes the callback),
+the callback is invoked on a thread of the library and should return quickly (receiving waits meanwhile)
+*/
+typedef void(*GO_SPEECH_RECOGNITION_SET_RERANK_CALLBACK)(GO_SPEECH_RECOGNITION_RERANK_CALLBACK cCallback, void* cUserData);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetMinStability(float cStability):
+suppresses interim results with a lower stability (google's estimate of how likely an interim result changes,
+see ReceiveTranscriptJSON) between 0.0 and 1.0, 0.0 delivers all interim results (default), final results are always delivered
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_MIN_STABILITY)(float cStability);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT GetInputLevel(double* rmsDB, double* peakDB):
+retrieves the RMS and peak level (in dBFS, between -96.0 (silence) and 0.0 (full scale)) of the audio passed to the last SendAudio call
+(also without a session), e.g. to render a VU meter or to confirm the microphone is live
+
+Return:
+GO_SPEECH_RECOGNITION_OK
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_GET_INPUT_LEVEL)(double* rmsDB, double* peakDB);
+
+/*
+Callback getting the level of the audio passed to SendAudio (see SetLevelCallback).
+*/
+typedef void(*GO_SPEECH_RECOGNITION_LEVEL_CALLBACK)(double rmsDB, double peakDB, void* userData);
+
+/*
+void SetLevelCallback(GO_SPEECH_RECOGNITION_LEVEL_CALLBACK cCallback, void* cUserData):
+registers a callback, which gets the level of the audio passed to every SendAudio call
+(invoked by SendAudio on the host's thread unless the callbacks are queued (see SetCallbackDispatch),
+cUserData is passed unchanged, NULL removes the callback)
+*/
+typedef void(*GO_SPEECH_RECOGNITION_SET_LEVEL_CALLBACK)(GO_SPEECH_RECOGNITION_LEVEL_CALLBACK cCallback, void* cUserData);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetAutomaticGainControl(GO_SPEECH_RECOGNITION_BOOL cEnabled, double cTargetDB):
+enables the automatic gain control, which brings the audio to the target RMS level (in dBFS, e.g. -20.0) before it's sent,
+the gain adapts smoothly (between -20 dB and +20 dB) and silence isn't amplified
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_AUTOMATIC_GAIN_CONTROL)(GO_SPEECH_RECOGNITION_BOOL cEnabled, double cTargetDB);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetNoiseSuppression(GO_SPEECH_RECOGNITION_BOOL cEnabled, double cStrength):
+enables the noise suppression, which removes steady background noise (e.g. fans or street noise) from the audio before it's sent,
+cStrength (between 0.0 and 1.0) sets how much noise is removed (higher values can distort the speech),
+the audio gets delayed by 32 ms (at 16kHz, its end is sent when the stream is finalized), has to be called before InitializeStream to take effect
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_NOISE_SUPPRESSION)(GO_SPEECH_RECOGNITION_BOOL cEnabled, double cStrength);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetHighPassFilter(GO_SPEECH_RECOGNITION_BOOL cEnabled, double cCutoffHz):
+enables the high-pass filter, which removes rumble and the DC offset of cheap microphones from the audio before it's sent,
+cCutoffHz is the cutoff frequency between 20 and 300 Hz (e.g. 100.0), has to be called before InitializeStream to take effect
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_HIGH_PASS_FILTER)(GO_SPEECH_RECOGNITION_BOOL cEnabled, double cCutoffHz);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SendAudioWithTimestamp(const short* recording, int recording_size, long long timestampUs):
+sends the audio like SendAudio together with the host's capture timestamp of its first sample (in microseconds, any clock),
+the timestamps of the results and words (see ReceiveTranscriptJSON) are then given in the host's media clock (e.g. for A/V sync),
+SendAudio uses the time of the call instead
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SEND_AUDIO_WITH_TIMESTAMP)(const short* recording, int recording_size, long long timestampUs);
+
+/*
+void SetWordTimeOffsets(GO_SPEECH_RECOGNITION_BOOL cEnabled):
+requests the start and end time of every word of the final results (included in ReceiveTranscriptJSON
+as timestamps of the host's clock), has to be called before InitializeStream to take effect
+*/
+typedef void(*GO_SPEECH_RECOGNITION_SET_WORD_TIME_OFFSETS)(GO_SPEECH_RECOGNITION_BOOL cEnabled);
+
+/*
+void SetWavHeaderDetection(GO_SPEECH_RECOGNITION_BOOL cEnabled):
+detects a WAV header at the start of the first audio of a session passed to SendAudio (e.g. when a WAV file is streamed as is),
+the header is skipped and its format is validated against the session's settings (a mismatch is logged as a warning),
+has to be called before the first SendAudio of a session
+*/
+typedef void(*GO_SPEECH_RECOGNITION_SET_WAV_HEADER_DETECTION)(GO_SPEECH_RECOGNITION_BOOL cEnabled);
+
+/*
+Create enum, which is needed to set the encoding of the sent audio (the values of google's AudioEncoding).
+*/
+enum GO_SPEECH_RECOGNITION_ENCODING {
+	GO_SPEECH_RECOGNITION_ENCODING_LINEAR16 = 1,
+	GO_SPEECH_RECOGNITION_ENCODING_FLAC = 2,
+	GO_SPEECH_RECOGNITION_ENCODING_MULAW = 3,
+	GO_SPEECH_RECOGNITION_ENCODING_AMR = 4,
+	GO_SPEECH_RECOGNITION_ENCODING_AMR_WB = 5,
+	GO_SPEECH_RECOGNITION_ENCODING_OGG_OPUS = 6,
+	GO_SPEECH_RECOGNITION_ENCODING_SPEEX_WITH_HEADER_BYTE = 7
+};
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetAudioEncoding(GO_SPEECH_RECOGNITION_ENCODING cEncoding):
+sets the encoding of the sent audio, every encoding except GO_SPEECH_RECOGNITION_ENCODING_LINEAR16 (default)
+has to be sent with SendAudioBytes, has to be called before InitializeStream to take effect
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_AUDIO_ENCODING)(GO_SPEECH_RECOGNITION_ENCODING cEncoding);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SendAudioBytes(const uint8_t* data, int data_size):
+sends already encoded audio (e.g. FLAC, MULAW or OGG_OPUS frames) unchanged to google,
+the level metering, diagnostics, preprocessing and no speech timeout of SendAudio don't apply to it
+(the keep-alive and the billed time budget only work for LINEAR16 and MULAW)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SEND_AUDIO_BYTES)(const uint8_t* data, int data_size);
+
+/*
+void SetBatchOptions(const char* cLanguage, const char* cModel):
+sets the language (BCP-47, "en-US" by default) and the model ("default" by default) of the next batches (see TranscribeFiles),
+the phrase hints and the post-processing of the transcripts apply as well
+*/
+typedef void(*GO_SPEECH_RECOGNITION_SET_BATCH_OPTIONS)(const char* cLanguage, const char* cModel);
+
+/*
+Callback getting the result of a file of a batch (see SetBatchCallback):
+result is GO_SPEECH_RECOGNITION_OK if the file has been transcribed, a negative GO_SPEECH_RECOGNITION_RESULT if failed,
+completed and total are the number of finished files and all files of the batch.
+*/
+typedef void(*GO_SPEECH_RECOGNITION_BATCH_CALLBACK)(int handle, const char* path, GO_SPEECH_RECOGNITION_RESULT result, const char* transcript, int completed, int total, void* userData);
+
+/*
+void SetBatchCallback(GO_SPEECH_RECOGNITION_BATCH_CALLBACK cCallback, void* cUserData):
+registers a callback, which gets the result of every file of a batch
+(invoked concurrently on threads of the library unless the callbacks are queued (see SetCallbackDispatch),
+path and transcript are only valid during the call,
+cUserData is passed unchanged, NULL removes the callback)
+*/
+typedef void(*GO_SPEECH_RECOGNITION_SET_BATCH_CALLBACK)(GO_SPEECH_RECOGNITION_BATCH_CALLBACK cCallback, void* cUserData);
+
+/*
+int TranscribeFiles(const char** cPaths, int cCount, int cConcurrency):
+starts transcribing the audio files (WAV or FLAC files, or gs:// URIs of files in Cloud Storage) in the background,
+up to cConcurrency files at a time (independent of the streaming session), the results are reported by the batch callback,
+files up to a minute are recognized synchronously, longer files by long-running operations (files above 10 MB have to be uploaded to Cloud Storage)
+
+Return:
+the handle of the batch (positive, see GetOperationProgress) if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef int(*GO_SPEECH_RECOGNITION_TRANSCRIBE_FILES)(const char** cPaths, int cCount, int cConcurrency);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT GetOperationProgress(int cHandle, double* percent, double* etaSeconds):
+retrieves the progress of a batch (see TranscribeFiles) for progress bars: the percentage between 0.0 and 100.0
+(the average of its files, long-running operations report their progress while they're running)
+and the estimated remaining time in seconds (-1.0 if unknown yet), finished batches report 100.0
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_GET_OPERATION_PROGRESS)(int cHandle, double* percent, double* etaSeconds);
+
+/*
+void SetOperationStateFile(const char* cPath):
+sets the state file, which keeps the names of the running long-running operations of the batches,
+so a restarted host can resume them (see ResumeOperations) instead of sending the audio again, an empty path disables it (default)
+*/
+typedef void(*GO_SPEECH_RECOGNITION_SET_OPERATION_STATE_FILE)(const char* cPath);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT ResumeOperations(int* handle):
+resumes the long-running operations saved in the state file by a previous run of the host as a new batch
+(their results are reported by the batch callback), has to be called once after the start,
+stores the handle of the batch (positive, see GetOperationProgress), 0 if there are no operations to resume
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_RESUME_OPERATIONS)(int* handle);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetResultCache(const char* cLocation):
+enables the result cache of the batches (see TranscribeFiles): the transcripts are cached by the hash of the audio
+and the recognition settings, so identical files are returned instantly without transcribing (and billing) them again,
+cLocation is a local directory, a memcached server ("memcached://localhost:11211"), a redis server ("redis://:password@localhost:6379")
+or an empty string to disable the cache (default)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_RESULT_CACHE)(const char* cLocation);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT CreateSessionWithCredentials(const char* cCredentials, const char* cQuotaProject, const char* cTranscriptLanguage, int cSampleRate, const char* cTranscriptionModel, int cMaxAlternatives, GO_SPEECH_RECOGNITION_BOOL cInterimResults):
+initializes the streaming session like InitializeStream, but with the credentials (the path of a service account key file or the JSON key itself)
+and the quota project of a customer (empty strings use the default credentials and the project of the credentials),
+the library runs one streaming session at a time
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_CREATE_SESSION_WITH_CREDENTIALS)(const char* cCredentials, const char* cQuotaProject, const char* cTranscriptLanguage, int cSampleRate, const char* cTranscriptionModel, int cMaxAlternatives, GO_SPEECH_RECOGNITION_BOOL cInterimResults);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT ValidateConfig(const char* cCredentials, const char* cQuotaProject, const char* cTranscriptLanguage, int cSampleRate, const char* cTranscriptionModel, int cMaxAlternatives, GO_SPEECH_RECOGNITION_BOOL cInterimResults, char** output):
+checks a configuration without streaming (a dry run of CreateSessionWithCredentials including the settings made before, e.g. the audio encoding):
+the parameters are checked locally, then google checks the credentials, the language and the model with a stream without audio (no audio is billed),
+all problems are reported at once as a JSON array, e.g.:
+[{"parameter":"sampleRate","message":"AMR requires 8000 Hz"},
+{"parameter":"request","message":"The requested model is currently not supported for language : de-DE.","status":"INVALID_ARGUMENT"}]
+
+Return:
+(per reference [char* (the problems as JSON, "[]" if the configuration is valid)])
+GO_SPEECH_RECOGNITION_OK if the configuration is valid
+GO_SPEECH_RECOGNITION_ERROR_INVALID_ARGUMENT if the configuration has problems
+GO_SPEECH_RECOGNITION_ERROR if google couldn't check it (e.g. invalid credentials or no connection)
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_VALIDATE_CONFIG)(const char* cCredentials, const char* cQuotaProject, const char* cTranscriptLanguage, int cSampleRate, const char* cTranscriptionModel, int cMaxAlternatives, GO_SPEECH_RECOGNITION_BOOL cInterimResults, char** output);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT IsModelAvailable(const char* cTranscriptLanguage, const char* cTranscriptionModel):
+checks whether google offers the model for the language according to its documentation (e.g. "video" and "phone_call" only for some languages,
+the medical models only for "en-US"), models without documented constraints are available,
+InitializeStream and Reconfigure fail the same way for a model that isn't available
+
+Return:
+GO_SPEECH_RECOGNITION_OK if the model is available
+GO_SPEECH_RECOGNITION_ERROR_MODEL_UNAVAILABLE if not (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_IS_MODEL_AVAILABLE)(const char* cTranscriptLanguage, const char* cTranscriptionModel);
+
+/*
+Create enum, which is needed to declare the source of the audio (see SetModelHints).
+*/
+enum GO_SPEECH_RECOGNITION_AUDIO_SOURCE {
+	GO_SPEECH_RECOGNITION_AUDIO_SOURCE_UNKNOWN = 0,
+	GO_SPEECH_RECOGNITION_AUDIO_SOURCE_MICROPHONE = 1,
+	GO_SPEECH_RECOGNITION_AUDIO_SOURCE_TELEPHONE = 2,
+	GO_SPEECH_RECOGNITION_AUDIO_SOURCE_MEDIA = 3
+};
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetModelHints(GO_SPEECH_RECOGNITION_AUDIO_SOURCE cAudioSource, int cExpectedUtteranceSeconds):
+declares the audio of the next sessions (the expected length of the utterances in seconds, 0 if unknown), so the library can pick the model
+if the model "auto" is passed to InitializeStream or Reconfigure: telephone audio (or audio sampled at 8 kHz) gets "phone_call", media "video",
+short utterances (up to 10 seconds or in single utterance mode) "latest_short", everything else "latest_long"
+(a model google doesn't offer for the language is replaced by "latest_short" or "latest_long"),
+has to be called before InitializeStream or Reconfigure to take effect
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_MODEL_HINTS)(GO_SPEECH_RECOGNITION_AUDIO_SOURCE cAudioSource, int cExpectedUtteranceSeconds);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT ApplyProfile(const char* cProfile):
+applies a preset of settings for a use case (the settings can still be adjusted afterwards),
+the model is picked by passing "auto" to InitializeStream (see SetModelHints):
+"interactive": voice commands, "latest_short", chunks up to 50 ms, single utterance mode, voice activity events,
+	the stream is finalized after 8 seconds without speech (interim results recommended)
+"dictation": "latest_long", chunks up to 100 ms, interim results with a stability of at least 0.5, keep-alive (interim results recommended)
+"captioning": "video", chunks up to 100 ms, interim results with a stability of at least 0.8, keep-alive (interim results recommended)
+"telephony": "phone_call", chunks up to 200 ms, voice activity events, keep-alive (final results only)
+has to be called before InitializeStream or Reconfigure to take effect
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_APPLY_PROFILE)(const char* cProfile);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT InitializeStreamWithProfile(const char* cProfile, const char* cTranscriptLanguage, int cSampleRate):
+applies the profile (see ApplyProfile) and initializes the streaming session like InitializeStream
+with the model picked for the profile, one alternative and the profile's interim results
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_INITIALIZE_STREAM_WITH_PROFILE)(const char* cProfile, const char* cTranscriptLanguage, int cSampleRate);
+
+/*
+void SetBatchCredentials(const char* cCredentials, const char* cQuotaProject):
+sets the credentials and the quota project of the next batches (see TranscribeFiles), batches of different customers can run at the same time
+(every batch keeps the credentials it has been started with, empty strings use the default credentials (default))
+*/
+typedef void(*GO_SPEECH_RECOGNITION_SET_BATCH_CREDENTIALS)(const char* cCredentials, const char* cQuotaProject);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetResultAcknowledgment(GO_SPEECH_RECOGNITION_BOOL cEnabled, int cRedeliveryMs, const char* cJournalFile):
+enables acknowledged delivery of the final results: every final result gets a sequence number ("sequence" of ReceiveTranscriptJSON,
+GetLastResultSequence after ReceiveTranscript or ReceiveAlternatives) and is returned again by the receive functions after cRedeliveryMs
+(0 for 30 seconds) until it is acknowledged with AckResult, the unacknowledged results are journaled to cJournalFile
+(loaded again by the next process using the same file, an empty string keeps them in memory only)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_RESULT_ACKNOWLEDGMENT)(GO_SPEECH_RECOGNITION_BOOL cEnabled, int cRedeliveryMs, const char* cJournalFile);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT AckResult(long long cSequence):
+acknowledges a final result (see SetResultAcknowledgment), so it isn't delivered again
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_ACK_RESULT)(long long cSequence);
+
+/*
+long long GetLastResultSequence():
+returns the sequence number of the result returned by the last receive call (see SetResultAcknowledgment),
+0 if it has none (an interim result or the acknowledgment is disabled)
+*/
+typedef long long(*GO_SPEECH_RECOGNITION_GET_LAST_RESULT_SEQUENCE)();
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetBackfill(const char* cDirectory, int cMaxMegabytes):
+enables the backfill mode (has to be called before InitializeStream): if google can't be reached at the initialization or the connection
+gets lost, the session keeps running and the audio is spooled to cDirectory (up to cMaxMegabytes, between 1 and 10, 0 for 10),
+once the connection is back the spooled audio is recognized by a batch request and its final results are delivered (with the timestamps
+of their audio) before the live results continue (GO_SPEECH_RECOGNITION_EVENT_OFFLINE and GO_SPEECH_RECOGNITION_EVENT_BACKFILLED are reported),
+an empty cDirectory disables the backfill (default)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_BACKFILL)(const char* cDirectory, int cMaxMegabytes);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetEncryptionKey(const uint8_t* cKey, int cKeyLength):
+enables the AES-GCM encryption of the files the library writes (the backfill's spool, the transcript files, the journal of the result acknowledgment
+and the result cache) with the host's key of 16, 24 or 32 bytes (copied by the library), encrypted transcript files get the suffix ".enc",
+a cKeyLength of 0 disables the encryption (default)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_ENCRYPTION_KEY)(const uint8_t* cKey, int cKeyLength);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT DecryptFile(const char* cPath, const char* cOutputPath):
+decrypts a file written by the library with the key of SetEncryptionKey into cOutputPath (a file which isn't encrypted is copied as is)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_DECRYPT_FILE)(const char* cPath, const char* cOutputPath);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetDataRetention(int cMaxAgeSeconds, int cMaxMegabytes, GO_SPEECH_RECOGNITION_BOOL cSecureDelete):
+sets the retention policy of the files the library writes (the transcript files, the backfill's spool files and the result cache directory):
+files older than cMaxAgeSeconds and the oldest files exceeding cMaxMegabytes in total are deleted (0 for unlimited (default)),
+the policy is applied immediately, whenever a session is initialized or closed and whenever the result cache grows,
+with cSecureDelete the files are overwritten with zeros before they're deleted (also by PurgeLocalData)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_DATA_RETENTION)(int cMaxAgeSeconds, int cMaxMegabytes, GO_SPEECH_RECOGNITION_BOOL cSecureDelete);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT PurgeLocalData():
+deletes all data the library keeps on disk: the transcript files, the backfill's spool files (except the spool of an offline session),
+the result cache directory and the journal of the result acknowledgment (the unacknowledged results are dropped as well)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if a file couldn't be deleted (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_PURGE_LOCAL_DATA)();
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetInMemoryOnly(GO_SPEECH_RECOGNITION_BOOL cEnabled):
+enables the in-memory only mode for hosts under "no recording" policies: the library never writes audio or transcripts to disk,
+every feature that would (SetTranscriptFiles, SetBackfill, a directory for SetResultCache, a journal for SetResultAcknowledgment,
+SetOperationStateFile, EnableFileLogging, WriteDiagnosticsBundle and DecryptFile) fails with an error instead, enabling fails while such a feature is configured
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_IN_MEMORY_ONLY)(GO_SPEECH_RECOGNITION_BOOL cEnabled);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT CreateSessionWithAccessToken(const char* cAccessToken, int cExpiresInSeconds, const char* cQuotaProject, const char* cTranscriptLanguage, int cSampleRate, const char* cTranscriptionModel, int cMaxAlternatives, GO_SPEECH_RECOGNITION_BOOL cInterimResults):
+initializes the streaming session like InitializeStream, but authenticated with an OAuth access token of the host
+(e.g. of the user's Google sign-in, with the scope "https://www.googleapis.com/auth/cloud-platform"),
+cExpiresInSeconds is the lifetime of the token (0 if unknown), cQuotaProject the project the usage is billed to (empty for the project of the token),
+the library doesn't refresh the token, the host replaces it with SetAccessToken before it expires
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_CREATE_SESSION_WITH_ACCESS_TOKEN)(const char* cAccessToken, int cExpiresInSeconds, const char* cQuotaProject, const char* cTranscriptLanguage, int cSampleRate, const char* cTranscriptionModel, int cMaxAlternatives, GO_SPEECH_RECOGNITION_BOOL cInterimResults);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetAccessToken(const char* cAccessToken, int cExpiresInSeconds):
+replaces the access token of a session created with CreateSessionWithAccessToken (e.g. after the host refreshed the sign-in),
+the next request to google uses the new token
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_ACCESS_TOKEN)(const char* cAccessToken, int cExpiresInSeconds);
+
+/*
+Create enum, which is needed to select the thread the callbacks are invoked on (see SetCallbackDispatch).
+*/
+enum GO_SPEECH_RECOGNITION_CALLBACK_DISPATCH {
+	GO_SPEECH_RECOGNITION_DISPATCH_IMMEDIATE = 0,	// on the library's threads (default)
+	GO_SPEECH_RECOGNITION_DISPATCH_QUEUED = 1		// on the thread calling PumpCallbacks
+};
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetCallbackDispatch(GO_SPEECH_RECOGNITION_CALLBACK_DISPATCH cMode):
+sets the thread the callbacks (of SetFinalResultCallback, SetLevelCallback and SetBatchCallback) are invoked on:
+immediately on the library's threads (default) or queued until the host calls PumpCallbacks on the thread it chose (e.g. its main thread),
+the callbacks queued before switching back stay queued until they're pumped, the re-ranking callback (SetRerankCallback)
+is always invoked immediately
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_CALLBACK_DISPATCH)(GO_SPEECH_RECOGNITION_CALLBACK_DISPATCH cMode);
+
+/*
+int PumpCallbacks(int cMaxCallbacks):
+invokes the queued callbacks on the calling thread in the order they were queued (at most cMaxCallbacks, 0 for all),
+the queue holds 1024 callbacks, the oldest are dropped (with a warning in the log) when they aren't pumped in time
+
+Return:
+the number of callbacks invoked
+GO_SPEECH_RECOGNITION_ERROR_BUSY if another PumpCallbacks is in progress (a callback can't pump the callbacks itself)
+*/
+typedef int(*GO_SPEECH_RECOGNITION_PUMP_CALLBACKS)(int cMaxCallbacks);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SendRawRequest(const uint8_t* data, int dataLength):
+sends a serialized google.cloud.speech.v1.StreamingRecognizeRequest to google as it is (protobuf passthrough),
+a request with a streaming configuration opens a new passthrough stream on the client of the session (a running one is closed),
+the following requests are sent on it, an empty request (dataLength 0) ends its audio,
+the session has to be initialized, its own stream keeps running (the passthrough stream is closed with the session)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SEND_RAW_REQUEST)(const uint8_t* data, int dataLength);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT ReceiveRawResponse(char** output, int* outputLength):
+waits for the next response of the passthrough stream and retrieves the serialized google.cloud.speech.v1.StreamingRecognizeResponse
+as it is (binary, not null-terminated, free it with FreeString), no response (outputLength 0) if the session has been closed
+or a new passthrough stream has been opened meanwhile, only one thread at a time may receive (GO_SPEECH_RECOGNITION_ERROR_BUSY)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+GO_SPEECH_RECOGNITION_ERROR_STREAM_ENDED once google ended the stream regularly
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_RECEIVE_RAW_RESPONSE)(char** output, int* outputLength);
+
+/*
+Create enum, which is needed to select the party of a conversation (see StartConversation).
+*/
+enum GO_SPEECH_RECOGNITION_PARTY {
+	GO_SPEECH_RECOGNITION_PARTY_LOCAL = 0,	// e.g. the local microphone
+	GO_SPEECH_RECOGNITION_PARTY_REMOTE = 1	// e.g. the remote audio of a VoIP call
+};
+
+/*
+GO_SPEECH_RECOGNITION_RESULT StartConversation(const char* cTranscriptLanguage, int cSampleRate, const char* cTranscriptionModel, const char* cLocalSpeaker, const char* cRemoteSpeaker):
+starts a conversation of two parties (e.g. an interview or a call), each party's audio is recognized by its own stream
+and the final results of both are merged into one interleaved timeline attributed to the speakers,
+the conversation runs besides the streaming session with its own connection (default credentials),
+the settings made before apply like in InitializeStream (the audio has to be LINEAR16), a running conversation is ended
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_START_CONVERSATION)(const char* cTranscriptLanguage, int cSampleRate, const char* cTranscriptionModel, const char* cLocalSpeaker, const char* cRemoteSpeaker);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SendConversationAudio(GO_SPEECH_RECOGNITION_PARTY cParty, short* recording, int recordingLength, long long timestampUs):
+sends audio of a party of the conversation, the timestamp of the first sample in microseconds (-1 for the time of the call)
+has to be on the same clock for both parties, so their results interleave correctly
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SEND_CONVERSATION_AUDIO)(GO_SPEECH_RECOGNITION_PARTY cParty, short* recording, int recordingLength, long long timestampUs);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT GetConversationTranscript(char** output):
+retrieves the merged transcript of the current (or last) conversation as a JSON array of the final results in the order of their start, e.g.:
+[{"party":0,"speaker":"Interviewer","transcript":"How did you start?","confidence":0.93,"startTimestamp":1559383200000000,"endTimestamp":1559383201800000},
+{"party":1,"speaker":"Guest","transcript":"By accident.","confidence":0.88,"startTimestamp":1559383202100000,"endTimestamp":1559383203000000}]
+(the start of a result is its first word with the word time offsets enabled, otherwise the end of the party's previous result)
+
+Return:
+(per reference [char* (transcript as JSON, free it with FreeString)])
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_GET_CONVERSATION_TRANSCRIPT)(char** output);
+
+/*
+void EndConversation():
+ends the conversation, the parties' remaining final results are added to the transcript (waiting at most 10 seconds),
+the transcript can still be retrieved until the next conversation starts
+*/
+typedef void(*GO_SPEECH_RECOGNITION_END_CONVERSATION)();
+
+/*
+GO_SPEECH_RECOGNITION_RESULT AddMixerSource(double cGainDB, int* sourceID):
+registers a source of the mixer (e.g. the microphone or the loopback of a desktop call) with its gain in dB (-96.0 - 20.0, 0.0 keeps its level),
+the audio of all sources (see SendMixerAudio) is mixed into the audio of the streaming session
+
+Return:
+(per reference [int (the ID of the source)])
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_ADD_MIXER_SOURCE)(double cGainDB, int* sourceID);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetMixerSourceGain(int cSourceID, double cGainDB):
+changes the gain of a source of the mixer in dB (-96.0 - 20.0), takes effect with its next audio
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_MIXER_SOURCE_GAIN)(int cSourceID, double cGainDB);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT RemoveMixerSource(int cSourceID):
+removes a source of the mixer, its audio not yet mixed is dropped
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_REMOVE_MIXER_SOURCE)(int cSourceID);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SendMixerAudio(int cSourceID, short* recording, int recordingLength):
+sends audio of a source of the mixer (at the session's sample rate), the sources' audio is mixed with their gains
+and sent like SendAudio as soon as every source delivered it, a source lagging behind more than 200 ms is filled up with silence
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SEND_MIXER_AUDIO)(int cSourceID, short* recording, int recordingLength);
+
+/*
+void SetDriftCompensation(int cEnabled):
+maps google's result times to the timestamps with the measured sample rate of the capture clock (see GetClockDrift)
+instead of the nominal one (1 to enable, 0 to disable (default)), so the timestamps of long sessions stay aligned
+with the timeline (e.g. of a video), takes effect once 10 seconds of audio have been measured
+*/
+typedef void(*GO_SPEECH_RECOGNITION_SET_DRIFT_COMPENSATION)(int cEnabled);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT GetClockDrift(double* driftMs, double* ppm):
+retrieves the cumulative drift in milliseconds between the capture clock (the samples sent at the nominal sample rate)
+and the clock of the timestamps (the wall clock for SendAudio, the host's clock for SendAudioWithTimestamp) in the current session
+and the deviation of the sample rate in parts per million (0 until 10 seconds of audio have been measured),
+both are positive if the capture clock is slower, gaps in the audio (e.g. a paused capture) aren't counted as drift
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_GET_CLOCK_DRIFT)(double* driftMs, double* ppm);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT RunBenchmark(const char* cWavPath, const char* cTranscriptLanguage, const char* cTranscriptionModel, int cRealtime, char** output):
+streams a reference WAV file (16 bit PCM) to google like a live session (in real time with cRealtime 1, as fast as possible with 0)
+and returns a report as a JSON object, e.g.:
+{"file":"reference.wav","model":"latest_long","realtime":true,"audioSeconds":30.2,"wallSeconds":31.1,"realtimeFactor":0.97,
+"throughputKBps":31.6,"timeToFirstInterimMs":412,"timeToFirstFinalMs":3380,"timeToFinalMs":640,"finalResults":6,"reconnects":0,"transcript":"..."}
+(the times are measured from the start of the streaming, timeToFinalMs from the end of the audio to the last final result, -1 if they couldn't be measured),
+the settings made before apply like in InitializeStream, the benchmark has its own connection (default credentials)
+and doesn't affect the streaming session, the call blocks until the benchmark is done
+
+Return:
+(per reference [char* (report as JSON, free it with FreeString)])
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_RUN_BENCHMARK)(const char* cWavPath, const char* cTranscriptLanguage, const char* cTranscriptionModel, int cRealtime, char** output);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT EvaluateTranscript(const char* cHypothesis, const char* cReference, char** output):
+compares a transcript (cHypothesis) with its reference transcript and returns the word error rate (WER), the character error rate (CER)
+and the alignment of the words as a JSON object, e.g.:
+{"wer":0.25,"cer":0.1,"referenceWords":4,"substitutions":1,"deletions":0,"insertions":0,"hypothesis":"the cat sad down",
+"reference":"the cat sat down","diff":"the cat [-sat+sad] down","alignment":[{"op":"equal","reference":"the","hypothesis":"the"},...]}
+(both texts are normalized first: lowercase, without punctuation, op is "equal", "substitute", "delete" or "insert")
+
+Return:
+(per reference [char* (evaluation as JSON, free it with FreeString)])
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_EVALUATE_TRANSCRIPT)(const char* cHypothesis, const char* cReference, char** output);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT EvaluateFile(const char* cPath, const char* cReference, char** output):
+transcribes an audio file (WAV or FLAC, or a gs:// URI) like a batch (with the settings of SetBatchOptions and SetBatchCredentials)
+and compares the transcript with the reference transcript like EvaluateTranscript, the evaluation additionally contains the "file",
+the call blocks until the file is transcribed
+
+Return:
+(per reference [char* (evaluation as JSON, free it with FreeString)])
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_EVALUATE_FILE)(const char* cPath, const char* cReference, char** output);
+
+/*
+Create enum, which is needed to select a synthetic test signal (see GenerateTestSignal and StreamTestSignal).
+*/
+enum GO_SPEECH_RECOGNITION_TEST_SIGNAL {
+	GO_SPEECH_RECOGNITION_TEST_SIGNAL_TONE = 0,	// a sine tone of 1 kHz
+	GO_SPEECH_RECOGNITION_TEST_SIGNAL_DIGITS = 1	// a sequence of DTMF digits ("1234567890", 200 ms each with 100 ms pauses)
+};
+
+/*
+GO_SPEECH_RECOGNITION_RESULT GenerateTestSignal(int cSignal, int cSampleRate, short* buffer, int bufferLength):
+fills the buffer with bufferLength samples of a synthetic test signal (16 bit PCM, mono, 8000 - 48000 Hz),
+e.g. to feed it through your own audio path into SendAudio
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_GENERATE_TEST_SIGNAL)(int cSignal, int cSampleRate, short* buffer, int bufferLength);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT StreamTestSignal(int cSignal, int cDurationMs):
+streams a synthetic test signal (1 - 60000 ms) at the session's sample rate through the full pipeline like SendAudio
+(in real time like a live capture), so the audio path and the credentials can be verified without a microphone or a file,
+tones aren't transcribed (no results are expected), the call blocks until the signal has been sent
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_STREAM_TEST_SIGNAL)(int cSignal, int cDurationMs);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT GetLogEntries(long long cSinceSequence, char** output):
+retrieves the logged errors and warnings after the given sequence number (0 for the whole history, oldest first) as a JSON object, e.g.:
+{"entries":[{"sequence":41,"timestamp":"2019-06-01T12:00:00.000+02:00","level":"error","message":"...",
+"sessionId":"1f2e3d4c5b6a7988","requestId":"1f2e3d4c5b6a7988-2","labels":{"customer":"42"}}],"lastSequence":41,"dropped":0}
+(poll with the lastSequence of the previous call to get the new entries only, the last 256 entries are kept,
+dropped counts the entries after the given sequence number which have been overwritten since)
+
+Return:
+(per reference [char* (entries as JSON, free it with FreeString)])
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_GET_LOG_ENTRIES)(long long cSinceSequence, char** output);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT EnableFileLogging(const char* cPath, int cMaxMB, int cMaxFiles):
+writes the logged errors and warnings to a file (an empty path disables it), one JSON object per line like the entries of GetLogEntries,
+when the file exceeds cMaxMB megabytes (1 - 1024) it's renamed to "<path>.1" (the older files to "<path>.2" and so on)
+and a new file is started, beyond cMaxFiles files including the current one (1 - 100) the oldest file is deleted,
+an existing file is continued
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_ENABLE_FILE_LOGGING)(const char* cPath, int cMaxMB, int cMaxFiles);
+
+/*
+Create enum, which is needed to select the log of the operating system (see EnableSystemLog).
+*/
+enum GO_SPEECH_RECOGNITION_SYSTEM_LOG {
+	GO_SPEECH_RECOGNITION_SYSTEM_LOG_NONE = 0,	// no forwarding (default)
+	GO_SPEECH_RECOGNITION_SYSTEM_LOG_EVENT_LOG = 1,	// the Windows Event Log (Windows only)
+	GO_SPEECH_RECOGNITION_SYSTEM_LOG_SYSLOG = 2	// syslog, collected by journald on systemd systems (Linux and macOS only)
+};
+
+/*
+GO_SPEECH_RECOGNITION_RESULT EnableSystemLog(GO_SPEECH_RECOGNITION_SYSTEM_LOG cSystemLog, const char* cSource):
+forwards the logged errors and warnings (with the correlation IDs of their session) to the log of the operating system,
+the Windows Event Log (the "Application" log, the source is registered on the first use, which needs administrator rights once)
+or syslog, cSource is the source in the Event Log resp. the tag in syslog (empty for "go-speech-recognition")
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_ENABLE_SYSTEM_LOG)(GO_SPEECH_RECOGNITION_SYSTEM_LOG cSystemLog, const char* cSource);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT WriteDiagnosticsBundle(const char* cPath):
+writes a zip file for a bug report with the configuration of the current (or last) session (config.json),
+the recent log (log.json like GetLogEntries, last-error.json like GetLastErrorJSON), the statistics (stats.json like GetStats),
+the requests to google (session.json like GetSessionInfo), the stacks of the goroutines (goroutines.txt)
+and the environment (environment.json: the platform, the versions of the modules, the memory and the environment variables
+of google, gRPC, Go and the proxies), secrets aren't included (the credentials and keys are never written,
+the values of environment variables whose name contains e.g. KEY, SECRET or TOKEN and the passwords of URLs are replaced by "REDACTED")
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_WRITE_DIAGNOSTICS_BUNDLE)(const char* cPath);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT EnableDebugServer(int cPort):
+serves pprof ("/debug/pprof/") and expvar ("/debug/vars", including the statistics of GetStats as "speechStats")
+of the Go runtime embedded in the host on the loopback interface (127.0.0.1) with the port (1 - 65535, 0 stops the server),
+e.g. to profile memory growth or goroutine leaks with "go tool pprof http://localhost:6060/debug/pprof/heap",
+a running server is replaced
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_ENABLE_DEBUG_SERVER)(int cPort);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetWatchdog(int cMaxGoroutines, int cMaxHeapMB):
+watches the Go runtime embedded in the host (checked every 10 seconds): when the number of goroutines or the heap (in megabytes)
+exceeds its threshold (0 disables a threshold (default)), a warning with the leak suspects (the most frequent goroutine stacks,
+e.g. "412 x main.receivePump") is logged (again once the number fell below the threshold and exceeds it anew),
+the current numbers are part of the statistics regardless (see GetStats)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_WATCHDOG)(int cMaxGoroutines, int cMaxHeapMB);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetGCPercent(int cPercent):
+sets the garbage collection target percentage of the Go runtime embedded in the host (like GOGC, default 100):
+a collection is triggered when the heap has grown by this percentage since the last one, higher values mean fewer collections
+(and pauses) at the cost of memory, -1 disables the collection (then only the memory limit triggers it, see SetMemoryLimit)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_GC_PERCENT)(int cPercent);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetMemoryLimit(int cLimitMB):
+sets the soft memory limit of the Go runtime embedded in the host in megabytes (like GOMEMLIMIT, 0 removes the limit (default)):
+the collector runs more often when the memory approaches the limit, so with a high (or disabled) GC percentage the memory stays bounded
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_MEMORY_LIMIT)(int cLimitMB);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetRealtimeAudio(int cCapacityMs):
+enables the real-time audio path of the following sessions (see SendAudioRealtime), the ring buffering the audio
+between the host's audio callback and the library is allocated with the given capacity (100 - 10000 ms) when InitializeStream
+starts the session, 0 disables the real-time path (default)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_REALTIME_AUDIO)(int cCapacityMs);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SendAudioRealtime(const short* recording, int recording_size):
+sends the audio like SendAudio, but suited for real-time threads (e.g. the host's audio callback): the samples are copied
+into a lock-free ring (the copying doesn't lock, allocate or wait), which the library drains every 10 milliseconds
+(the draining parks after a second without audio, the first call after the pause wakes it),
+only one thread may call it at a time and it doesn't log,
+the first call from a thread not created by Go binds the thread to the Go runtime (which takes locks and may allocate),
+so pre-warm the real-time thread with one call (e.g. with recording_size 0) before its real-time processing starts
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+GO_SPEECH_RECOGNITION_ERROR_NOT_INITIALIZED without a session (or with the real-time path disabled)
+GO_SPEECH_RECOGNITION_ERROR_QUEUE_FULL if the ring is full (the audio is dropped)
+otherwise the error of the last drained audio (the audio of this call has been accepted regardless, see "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SEND_AUDIO_REALTIME)(const short* recording, int recording_size);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT ReceiveAllPending(char** output):
+retrieves all results received since the last call like ReceiveTranscriptJSON as one JSON array (in the order of their arrival,
+"[]" if there is none), but without waiting, so a host polling at its frame rate needs one call per frame,
+if an error ends the results, they are retrieved anyway and the error is returned by the next receive call
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_RECEIVE_ALL_PENDING)(char** output);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT GetResultEventHandle(long long* handle):
+retrieves a waitable handle, which is signaled while results are queued (and when the receiving ended), so the host can wait for it
+in its own event loop and then retrieve the results without blocking (e.g. with ReceiveAllPending):
+on Windows an event object (a HANDLE for WaitForMultipleObjects), on Linux an eventfd and on the other platforms the read end of a pipe
+(file descriptors, readable while signaled, for select, poll or epoll),
+the library resets it when the receive calls emptied the queue, so the host must neither read nor reset nor close it,
+the handle stays the same for all sessions until Shutdown closes it
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_GET_RESULT_EVENT_HANDLE)(long long* handle);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT MuteRecognition(GO_SPEECH_RECOGNITION_BOOL cMuted, int cEchoWindowMs):
+mutes the recognition while the host plays a prompt (e.g. text-to-speech), so the assistant doesn't hear itself:
+the audio passed to SendAudio (and its variants) is replaced by silence, so the stream stays alive and the timestamps stay aligned,
+the diagnostics and the no speech timeout skip it (which starts anew after the prompt), encoded audio (see SendAudioBytes) is dropped instead,
+the recognition stays muted (also for the following sessions) until it's unmuted,
+when unmuting, the audio stays muted for cEchoWindowMs (0 - 5000) milliseconds to drop the tail of the prompt (e.g. 300)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_MUTE_RECOGNITION)(GO_SPEECH_RECOGNITION_BOOL cMuted, int cEchoWindowMs);
+
+/*
+Create enum, which is needed to identify the states of the turns (see EnableTurnTaking).
+*/
+enum GO_SPEECH_RECOGNITION_TURN_STATE {
+	GO_SPEECH_RECOGNITION_TURN_IDLE = 0,
+	GO_SPEECH_RECOGNITION_TURN_LISTENING = 1,
+	GO_SPEECH_RECOGNITION_TURN_THINKING = 2,
+	GO_SPEECH_RECOGNITION_TURN_SPEAKING = 3
+};
+
+/*
+GO_SPEECH_RECOGNITION_RESULT EnableTurnTaking(GO_SPEECH_RECOGNITION_BOOL cEnabled, int cEchoWindowMs):
+enables the turn-taking of a voice assistant: the library starts listening to the user (LISTENING), the first final result
+ends the user's turn and mutes the recognition (THINKING, the turn callback gets the user's transcript), the host then speaks
+its answer (SPEAKING) and listens again (LISTENING) by SetTurnState, the recognition is unmuted after cEchoWindowMs (0 - 5000) milliseconds
+(see MuteRecognition), enabling also enables the single utterance mode (see SetSingleUtterance, takes effect with the next InitializeStream or Reconfigure),
+disabling unmutes the recognition
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_ENABLE_TURN_TAKING)(GO_SPEECH_RECOGNITION_BOOL cEnabled, int cEchoWindowMs);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetTurnState(int cState):
+changes the state of the turns (a GO_SPEECH_RECOGNITION_TURN_STATE except IDLE) when the host's turn progresses:
+SPEAKING when it starts to speak its answer, LISTENING when it finished or doesn't answer at all,
+THINKING to mute the recognition without answering, the turn callback is invoked with the new state
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_TURN_STATE)(int cState);
+
+/*
+int GetTurnState():
+returns the current state of the turns (a GO_SPEECH_RECOGNITION_TURN_STATE), IDLE if the turn-taking is disabled
+*/
+typedef int(*GO_SPEECH_RECOGNITION_GET_TURN_STATE)();
+
+/*
+void TurnCallback(int state, char* transcript, void* userData):
+invoked whenever the state of the turns changes (a GO_SPEECH_RECOGNITION_TURN_STATE), the transcript is the user's utterance
+when the state changes to THINKING, otherwise empty, it is only valid during the call
+*/
+typedef void(*GO_SPEECH_RECOGNITION_TURN_CALLBACK)(int state, char* transcript, void* userData);
+
+/*
+void SetTurnCallback(GO_SPEECH_RECOGNITION_TURN_CALLBACK cCallback, void* cUserData):
+registers the turn callback (NULL removes it), which is invoked by the library's threads (the end of the user's turn)
+or by the calls changing the state on the host's thread, unless the callbacks are queued (see SetCallbackDispatch),
+cUserData is passed to the callback unchanged
+*/
+typedef void(*GO_SPEECH_RECOGNITION_SET_TURN_CALLBACK)(GO_SPEECH_RECOGNITION_TURN_CALLBACK cCallback, void* cUserData);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetWakeWord(const char* cPath, double cSensitivity):
+enables the wake word gate: the audio of SendAudio (and its variants) is analyzed locally and only streamed to google after the wake word
+has been spoken (GO_SPEECH_RECOGNITION_EVENT_WAKE_WORD is reported), until the first final result or until no result arrived
+for 8 seconds of audio, then the gate closes again (GO_SPEECH_RECOGNITION_EVENT_WAKE_WORD_REARMED),
+the detector is a simple keyword model matching the audio against a recording of the wake word (speaker dependent),
+cPath is the WAV file (16 bit PCM, mono) of the wake word (0.2 - 3 seconds of speech) recorded with the microphone used later,
+NULL or "" disables the gate (default), cSensitivity (0.0 - 1.0, e.g. 0.5) trades missed wake words for false ones,
+encoded audio (see SendAudioBytes) is rejected while the gate is enabled, the time offsets of the results only count the streamed audio,
+the stream should be kept alive meanwhile (see SetKeepAlive)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_WAKE_WORD)(const char* cPath, double cSensitivity);
+
+/*
+GO_SPEECH_RECOGNITION_RESULT AddSensitiveTerm(char* cTerm, GO_SPEECH_RECOGNITION_BOOL cRegex):
+adds a term which must never be persisted by the library (privacy mode): it's masked as "***" in the log (including the log file,
+the system log and the diagnostics bundle), in the transcript files and in the journal of the result acknowledgment,
+transcripts containing it aren't cached, the results delivered to the host stay untouched,
+cTerm is matched case-insensitively and with any whitespace between its words or is a regular expression (cRegex),
+the terms should be added before the session starts
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_ADD_SENSITIVE_TERM)(char* cTerm, GO_SPEECH_RECOGNITION_BOOL cRegex);
+
+/*
+void ClearSensitiveTerms():
+removes all sensitive terms
+*/
+typedef void(*GO_SPEECH_RECOGNITION_CLEAR_SENSITIVE_TERMS)();
+
+/*
+Create enum, which is needed to select the Unicode normalization form of SetUnicodeNormalization.
+*/
+enum GO_SPEECH_RECOGNITION_NORMALIZATION {
+	GO_SPEECH_RECOGNITION_NORMALIZATION_NONE = 0,
+	GO_SPEECH_RECOGNITION_NORMALIZATION_NFC = 1,
+	GO_SPEECH_RECOGNITION_NORMALIZATION_NFD = 2
+};
+
+/*
+GO_SPEECH_RECOGNITION_RESULT SetUnicodeNormalization(int cForm):
+normalizes the transcripts (and their words) to a Unicode normalization form (a GO_SPEECH_RECOGNITION_NORMALIZATION) before delivery,
+e.g. "é" is one code point (U+00E9) in NFC and two (U+0065 U+0301) in NFD, applied after the formatting and the replacement dictionary,
+all strings passed to the host are valid UTF-8 anyway (invalid bytes are replaced by U+FFFD)
+
+GO_SPEECH_RECOGNITION_NORMALIZATION_NONE: the transcripts are delivered as recognized by google (default)
+GO_SPEECH_RECOGNITION_NORMALIZATION_NFC: composed characters (e.g. for Windows and Linux)
+GO_SPEECH_RECOGNITION_NORMALIZATION_NFD: decomposed characters (e.g. like the file names of macOS's HFS+)
+
+Return:
+GO_SPEECH_RECOGNITION_OK if successful
+a negative GO_SPEECH_RECOGNITION_RESULT if failed (error log can be retrieved with "GetLog()")
+*/
+typedef GO_SPEECH_RECOGNITION_RESULT(*GO_SPEECH_RECOGNITION_SET_UNICODE_NORMALIZATION)(int cForm);
@@ -0,0 +1,636 @@
+//go:build cgo
+
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Stress tests of the session's concurrency: the exports sending, receiving and closing are called concurrently
+	on a fake stream (no connection to google), run them with the race detector:
+
+		go test -race .
+
+	Test files can't use cgo, so the C types are named by cgo's Go names (e.g. "_Ctype_short" for C.short).
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/cmplx"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unsafe"
+
+	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// The samples of one call of "SendAudio()" (200 ms at 16 kHz)
+const testChunkSamples = 3200
+
+// A deadlock is reported, when the exports haven't returned after this time
+const testDeadline = 10 * time.Second
+
+
+// fakeStream replaces google's stream: every audio request is answered by a final result,
+// with blockSend set every Send of audio blocks until the stream's context is canceled.
+type fakeStream struct {
+	ctx context.Context
+	blockSend bool
+	responses chan *speechpb.StreamingRecognizeResponse
+	closed chan struct{}
+	closeOnce sync.Once
+
+	sending int32		// the Send calls in progress
+	blockedSends int32	// the Send calls blocked (blockSend)
+	overlaps int32		// the calls of Send resp. CloseSend overlapping another one (gRPC doesn't allow it)
+	audioBytes int64	// the audio sent before "CloseSend()"
+}
+
+func (fake *fakeStream) Send(request *speechpb.StreamingRecognizeRequest) (error) {
+	if atomic.AddInt32(&fake.sending, 1) > 1 {
+		atomic.AddInt32(&fake.overlaps, 1)
+	}
+	defer atomic.AddInt32(&fake.sending, -1)
+
+	select {
+	case <-fake.closed:
+		return io.EOF
+	default:
+	}
+	if request.GetAudioContent() == nil {
+		return nil
+	}
+	atomic.AddInt64(&fake.audioBytes, int64(len(request.GetAudioContent())))
+
+	if fake.blockSend {
+		atomic.AddInt32(&fake.blockedSends, 1)
+		<-fake.ctx.Done()
+		return status.FromContextError(fake.ctx.Err()).Err()
+	}
+
+	select {
+	case fake.responses <- &speechpb.StreamingRecognizeResponse{
+			Results: []*speechpb.StreamingRecognitionResult{{
+				Alternatives: []*speechpb.SpeechRecognitionAlternative{{Transcript: "hello", Confidence: 0.9}},
+				IsFinal: true,
+				}},
+			}:
+	default:
+	}
+	return nil
+}
+
+func (fake *fakeStream) Recv() (*speechpb.StreamingRecognizeResponse, error) {
+	// The remaining responses are received after "CloseSend()".
+	select {
+	case resp := <-fake.responses:
+		return resp, nil
+	default:
+	}
+	select {
+	case resp := <-fake.responses:
+		return resp, nil
+	case <-fake.closed:
+		return nil, io.EOF
+	case <-fake.ctx.Done():
+		return nil, status.FromContextError(fake.ctx.Err()).Err()
+	}
+}
+
+func (fake *fakeStream) CloseSend() (error) {
+	if atomic.LoadInt32(&fake.sending) > 0 {
+		atomic.AddInt32(&fake.overlaps, 1)
+	}
+	fake.closeOnce.Do(func() { close(fake.closed) })
+	return nil
+}
+
+func (fake *fakeStream) Header() (metadata.MD, error) { return metadata.MD{}, nil }
+func (fake *fakeStream) Trailer() (metadata.MD) { return metadata.MD{} }
+func (fake *fakeStream) Context() (context.Context) { return fake.ctx }
+
+func (fake *fakeStream) SendMsg(m any) (error) {
+	return fake.Send(m.(*speechpb.StreamingRecognizeRequest))
+}
+
+func (fake *fakeStream) RecvMsg(m any) (error) {
+	resp, err := fake.Recv()
+	if err != nil {
+		return err
+	}
+	proto.Merge(m.(*speechpb.StreamingRecognizeResponse), resp)
+	return nil
+}
+
+// The fake is used as google's stream (through the client's connection) and implements its interface.
+var _ speechpb.Speech_StreamingRecognizeClient = (*fakeStream)(nil)
+
+
+// fakeGoogle hands out the fake streams of a session.
+type fakeGoogle struct {
+	mutex sync.Mutex
+	streams []*fakeStream
+	blockSend bool
+}
+
+func (google *fakeGoogle) all() ([]*fakeStream) {
+	google.mutex.Lock()
+	defer google.mutex.Unlock()
+
+	return append([]*fakeStream{}, google.streams...)
+}
+
+// intercept opens a fake stream instead of connecting to google.
+func (google *fakeGoogle) intercept(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	fake := &fakeStream{
+		ctx: ctx,
+		blockSend: google.blockSend,
+		responses: make(chan *speechpb.StreamingRecognizeResponse, 64),
+		closed: make(chan struct{}),
+	}
+
+	google.mutex.Lock()
+		google.streams = append(google.streams, fake)
+	google.mutex.Unlock()
+	return fake, nil
+}
+
+
+// startFakeSession starts a session on fake streams, the session is closed at the end of the test.
+func startFakeSession(t *testing.T, blockSend bool) (*fakeGoogle) {
+	t.Helper()
+
+	google := &fakeGoogle{blockSend: blockSend}
+	conn, err := grpc.NewClient("passthrough:///fake-google",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStreamInterceptor(google.intercept))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	SetLegacyReturnCodes(0)
+	config := newStreamingConfig("en-US", 16000, "", 1, true)
+	if code := startSession(config, []option.ClientOption{option.WithGRPCConn(conn)}, nil); code != resultOK {
+		t.Fatalf("Could not start the session: %d (%s)", code, logStatus)
+	}
+	// A deadlocked session mustn't hang the remaining tests.
+	t.Cleanup(func() { returnsWithin(testDeadline, CloseStream) })
+	return google
+}
+
+
+// sendTestAudio sends 200 ms of a quiet tone.
+func sendTestAudio() (_Ctype_int) {
+	samples := make([]int16, testChunkSamples)
+	for i := range samples {
+		samples[i] = int16(i % 64 - 32)
+	}
+	return SendAudio((*_Ctype_short)(unsafe.Pointer(&samples[0])), testChunkSamples)
+}
+
+
+// receiveEveryWay calls each receive export once.
+func receiveEveryWay() {
+	var transcript *_Ctype_char
+	if ReceiveTranscript(&transcript) == resultOK {
+		FreeString(transcript)
+	}
+
+	var json *_Ctype_char
+	if ReceiveTranscriptJSON(&json) == resultOK {
+		FreeString(json)
+	}
+
+	var alternatives *_Ctype_goSpeechRecognitionAlternative
+	var count _Ctype_int
+	if ReceiveAlternatives(&alternatives, &count) == resultOK {
+		FreeAlternatives(alternatives, count)
+	}
+}
+
+
+// returnsWithin runs the call and reports whether it returned before the deadline.
+func returnsWithin(deadline time.Duration, call func()) (bool) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		call()
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(deadline):
+		return false
+	}
+}
+
+
+// checkOverlaps fails the test, if Send and CloseSend have been called concurrently on a stream.
+func checkOverlaps(t *testing.T, google *fakeGoogle) {
+	t.Helper()
+
+	for i, fake := range google.all() {
+		if overlaps := atomic.LoadInt32(&fake.overlaps); overlaps > 0 {
+			t.Errorf("Stream %d: %d concurrent calls of Send/CloseSend", i, overlaps)
+		}
+	}
+}
+
+
+// TestStressSendReceiveClose closes sessions while other goroutines send and receive.
+func TestStressSendReceiveClose(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		google := startFakeSession(t, false)
+
+		stopped := make(chan struct{})
+		var workers sync.WaitGroup
+		work := func(call func()) {
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				for {
+					select {
+					case <-stopped:
+						return
+					default:
+					}
+					call()
+				}
+			}()
+		}
+
+		work(func() { sendTestAudio() })
+		work(func() { sendTestAudio() })
+		work(receiveEveryWay)
+		work(receiveEveryWay)
+		work(func() {
+			var audioMs, results _Ctype_int
+			GetQueueDepths(&audioMs, &results)
+		})
+
+		time.Sleep(time.Duration(10 + round * 5) * time.Millisecond)
+		if returnsWithin(testDeadline, CloseStream) == false {
+			t.Fatalf("Round %d: CloseStream didn't return (deadlock)", round)
+		}
+		close(stopped)
+		if returnsWithin(testDeadline, workers.Wait) == false {
+			t.Fatalf("Round %d: the sending and receiving didn't stop after CloseStream (deadlock)", round)
+		}
+		checkOverlaps(t, google)
+	}
+}
+
+
+// TestBlockedSendDoesntBlockExports stalls the sending (e.g. by gRPC's flow control): the exports mustn't wait
+// for the network and "CloseStream()" has to abort the send.
+func TestBlockedSendDoesntBlockExports(t *testing.T) {
+	google := startFakeSession(t, true)
+
+	for i := 0; i < 4; i++ {
+		if code := sendTestAudio(); code != resultOK {
+			t.Fatalf("SendAudio failed: %d (%s)", code, logStatus)
+		}
+	}
+
+	// Wait until the send pump hangs in the network.
+	deadline := time.Now().Add(testDeadline)
+	for blocked := false; blocked == false; {
+		if time.Now().After(deadline) {
+			t.Fatal("The send pump didn't send")
+		}
+		for _, fake := range google.all() {
+			blocked = blocked || atomic.LoadInt32(&fake.blockedSends) > 0
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	receiving := make(chan struct{})
+	go func() {
+		defer close(receiving)
+		receiveEveryWay()
+	}()
+
+	if returnsWithin(time.Second, func() {
+		var audioMs, results _Ctype_int
+		GetQueueDepths(&audioMs, &results)
+	}) == false {
+		t.Fatal("GetQueueDepths waits for the blocked send")
+	}
+	if returnsWithin(time.Second, func() { sendTestAudio() }) == false {
+		t.Fatal("SendAudio waits for the blocked send")
+	}
+
+	if returnsWithin(testDeadline, CloseStream) == false {
+		t.Fatal("CloseStream didn't abort the blocked send (deadlock)")
+	}
+	if returnsWithin(testDeadline, func() { <-receiving }) == false {
+		t.Fatal("The receiving didn't end after CloseStream")
+	}
+	checkOverlaps(t, google)
+}
+
+
+// TestFormatTranscript applies the flags of "SetOutputFormatting()" alone and combined.
+func TestFormatTranscript(t *testing.T) {
+	tests := []struct {
+		transcript string
+		formatting int32
+		want string
+	}{
+		{"Hello World. How are you?", 0, "Hello World. How are you?"},
+		{"Hello World. How are you?", formatLowercase, "hello world. how are you?"},
+		{"HELLO world. how ARE you? fine! thanks", formatSentenceCase, "Hello world. How are you? Fine! Thanks"},
+		{"um I think uh, we should Erm go", formatStripFillers, "I think we should go"},
+		{"the the cat sat sat. on the mat", formatCollapseRepeats, "the cat sat. on the mat"},
+		{"The the", formatCollapseRepeats, "the"},
+		{"uh so so um we go", formatStripFillers | formatCollapseRepeats, "so we go"},
+		{"um HELLO hello. the END", formatStripFillers | formatCollapseRepeats | formatSentenceCase, "Hello. The end"},
+		{"", formatStripFillers | formatSentenceCase, ""},
+	}
+
+	for _, test := range tests {
+		if got := formatTranscript(test.transcript, test.formatting); got != test.want {
+			t.Errorf("formatTranscript(%q, %d) = %q, want %q", test.transcript, test.formatting, got, test.want)
+		}
+	}
+}
+
+
+// TestNumberWordsToDigits converts spelled out numbers, but keeps the words, which only look like numbers.
+func TestNumberWordsToDigits(t *testing.T) {
+	tests := []struct {
+		transcript string
+		want string
+	}{
+		{"twenty three", "23"},
+		{"one hundred and five", "105"},
+		{"two thousand and twenty four", "2024"},
+		{"three million four hundred thousand", "3400000"},
+		{"nine one one", "9 1 1"},
+		{"one, two, three.", "1, 2, 3."},
+		{"call one 800", "call 1 800"},
+		{"zero zero seven", "0 0 7"},
+		{"no one came", "no one came"},
+		{"one of them", "one of them"},
+		{"the one.", "the one."},
+		{"zero tolerance", "zero tolerance"},
+		{"rock and roll", "rock and roll"},
+		{"five and", "5 and"},
+		{"I have twenty, and you?", "I have 20, and you?"},
+	}
+
+	for _, test := range tests {
+		if got := numberWordsToDigits(test.transcript); got != test.want {
+			t.Errorf("numberWordsToDigits(%q) = %q, want %q", test.transcript, got, test.want)
+		}
+	}
+}
+
+
+// TestSpellNumber spells out numbers and converts them back.
+func TestSpellNumber(t *testing.T) {
+	tests := []struct {
+		number int64
+		want string
+	}{
+		{0, "zero"},
+		{7, "seven"},
+		{13, "thirteen"},
+		{40, "forty"},
+		{42, "forty two"},
+		{100, "one hundred"},
+		{105, "one hundred five"},
+		{2024, "two thousand twenty four"},
+		{1000000, "one million"},
+		{3400017, "three million four hundred thousand seventeen"},
+	}
+
+	for _, test := range tests {
+		if got := spellNumber(test.number); got != test.want {
+			t.Errorf("spellNumber(%d) = %q, want %q", test.number, got, test.want)
+		}
+		// A lone "zero" stays a word.
+		if back := numberWordsToDigits(test.want); test.number != 0 && back != strconv.FormatInt(test.number, 10) {
+			t.Errorf("numberWordsToDigits(%q) = %q, want %d", test.want, back, test.number)
+		}
+	}
+
+	if got := digitsToNumberWords("call 911, it's 42."); got != "call nine hundred eleven, it's forty two." {
+		t.Errorf("digitsToNumberWords = %q", got)
+	}
+}
+
+
+// TestNoiseSuppressorDelay checks the delay of the suppressor: without suppression the output is the input delayed
+// by noiseLatency samples and the flush returns the delayed end.
+func TestNoiseSuppressorDelay(t *testing.T) {
+	input := make([]float64, 3000)
+	for i := range input {
+		input[i] = 1000 * math.Sin(float64(i) * 0.05) + float64(i % 7)
+	}
+
+	ns := newNoiseSuppressor(0)
+	var output []float64
+	// Calls of odd sizes
+	for start := 0; start < len(input); start += 333 {
+		chunk := input[start:min(start + 333, len(input))]
+		processed := ns.process(append([]float64{}, chunk...))
+		if len(processed) != len(chunk) {
+			t.Fatalf("process returned %d samples for %d", len(processed), len(chunk))
+		}
+		output = append(output, processed...)
+	}
+	output = append(output, ns.flush()...)
+
+	if len(output) != len(input) + noiseLatency {
+		t.Fatalf("%d samples in total, want %d", len(output), len(input) + noiseLatency)
+	}
+	for i, sample := range output {
+		want := 0.0
+		if i >= noiseLatency {
+			want = input[i - noiseLatency]
+		}
+		if math.Abs(sample - want) > 1e-6 {
+			t.Fatalf("Sample %d is %f, want %f", i, sample, want)
+		}
+	}
+}
+
+
+// TestFinalizeSendsDelayedAudio finalizes a stream with the noise suppression: the audio queued before
+// and the audio delayed by the suppressor are sent before google is told that no more audio follows.
+func TestFinalizeSendsDelayedAudio(t *testing.T) {
+	SetLegacyReturnCodes(0)
+	if code := SetNoiseSuppression(1, 0.5); code != resultOK {
+		t.Fatalf("SetNoiseSuppression failed: %d", code)
+	}
+	defer SetNoiseSuppression(0, 0)
+	google := startFakeSession(t, false)
+
+	for i := 0; i < 4; i++ {
+		if code := sendTestAudio(); code != resultOK {
+			t.Fatalf("SendAudio failed: %d (%s)", code, logStatus)
+		}
+	}
+	finalizeStream(eventSilenceTimeout)
+
+	fake := google.all()[0]
+	select {
+	case <-fake.closed:
+	case <-time.After(testDeadline):
+		t.Fatal("The stream wasn't closed after the finalization")
+	}
+	if sent, want := atomic.LoadInt64(&fake.audioBytes), int64(4 * testChunkSamples + noiseLatency) * 2; sent != want {
+		t.Errorf("%d bytes of audio sent, want %d", sent, want)
+	}
+}
+
+
+// TestNoiseSuppressorRemovesNoise compares the level of steady noise before and after the suppression.
+func TestNoiseSuppressorRemovesNoise(t *testing.T) {
+	// A deterministic noise (linear congruential generator)
+	seed := uint32(1)
+	noise := make([]float64, 16000)
+	for i := range noise {
+		seed = seed * 1664525 + 1013904223
+		noise[i] = float64(int32(seed) >> 20)
+	}
+
+	ns := newNoiseSuppressor(1)
+	output := ns.process(append([]float64{}, noise...))
+
+	// The estimate needs some frames, the second half is compared.
+	before, after := rms(noise[8000:]), rms(output[8000:])
+	if after > before / 2 {
+		t.Errorf("The noise level fell from %.1f to %.1f only", before, after)
+	}
+}
+
+
+// TestFFT compares the transform with the discrete fourier transform and transforms it back.
+func TestFFT(t *testing.T) {
+	for _, n := range []int{1, 2, 8, 64} {
+		values := make([]complex128, n)
+		for i := range values {
+			values[i] = complex(math.Sin(float64(i)) * 3, float64(i % 3))
+		}
+
+		transformed := append([]complex128{}, values...)
+		fft(transformed, false)
+		for k := range values {
+			var want complex128
+			for i, value := range values {
+				want += value * cmplx.Exp(complex(0, -2 * math.Pi * float64(i * k) / float64(n)))
+			}
+			if cmplx.Abs(transformed[k] - want) > 1e-9 {
+				t.Errorf("n=%d: bin %d is %v, want %v", n, k, transformed[k], want)
+			}
+		}
+
+		fft(transformed, true)
+		for i := range values {
+			if cmplx.Abs(transformed[i] - values[i]) > 1e-9 {
+				t.Errorf("n=%d: sample %d is %v after the inverse transform, want %v", n, i, transformed[i], values[i])
+			}
+		}
+	}
+}
+
+
+// TestHighPassFilter measures the gain of the filter for a DC offset and sine waves.
+func TestHighPassFilter(t *testing.T) {
+	const sampleRate = 16000.0
+	tests := []struct {
+		frequency float64
+		minGain float64
+		maxGain float64
+	}{
+		{0, 0, 0.001},				// DC offset
+		{20, 0, 0.1},				// rumble
+		{100, 0.69, 0.72},			// cutoff: -3 dB
+		{1000, 0.99, 1.01},
+		{4000, 0.99, 1.01},
+	}
+
+	for _, test := range tests {
+		filter := newHighPassFilter(100, sampleRate)
+		samples := make([]float64, 32000)
+		for i := range samples {
+			samples[i] = 1000 * math.Cos(2 * math.Pi * test.frequency * float64(i) / sampleRate)
+		}
+		input := rms(samples[16000:])
+		filter.process(samples)
+
+		// The filter has settled after the first second.
+		if gain := rms(samples[16000:]) / input; gain < test.minGain || gain > test.maxGain {
+			t.Errorf("%.0f Hz: gain %.3f, want between %.3f and %.3f", test.frequency, gain, test.minGain, test.maxGain)
+		}
+	}
+}
+
+
+// TestParseWavHeader parses valid headers and rejects broken ones.
+func TestParseWavHeader(t *testing.T) {
+	chunk := func(id string, body []byte) ([]byte) {
+		size := make([]byte, 4)
+		binary.LittleEndian.PutUint32(size, uint32(len(body)))
+		data := append([]byte(id), size...)
+		data = append(data, body...)
+		if len(body) % 2 == 1 {
+			data = append(data, 0)
+		}
+		return data
+	}
+	format := make([]byte, 16)
+	binary.LittleEndian.PutUint16(format[0:], wavFormatPCM)
+	binary.LittleEndian.PutUint16(format[2:], 1)
+	binary.LittleEndian.PutUint32(format[4:], 16000)
+	binary.LittleEndian.PutUint16(format[14:], 16)
+	riff := func(chunks ...[]byte) ([]byte) {
+		data := append([]byte("RIFF"), 0, 0, 0, 0)
+		data = append(data, "WAVE"...)
+		for _, c := range chunks {
+			data = append(data, c...)
+		}
+		return data
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want *wavHeader
+		fails bool
+	}{
+		{"pcm", riff(chunk("fmt ", format), chunk("data", []byte{1, 2})), &wavHeader{wavFormatPCM, 1, 16000, 16, 44}, false},
+		{"odd chunk before", riff(chunk("LIST", []byte{1, 2, 3}), chunk("fmt ", format), chunk("data", nil)), &wavHeader{wavFormatPCM, 1, 16000, 16, 56}, false},
+		{"no header", []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, nil, false},
+		{"too short", []byte("RIFF"), nil, false},
+		{"data before format", riff(chunk("data", nil), chunk("fmt ", format)), nil, true},
+		{"short format", riff(chunk("fmt ", format[:8]), chunk("data", nil)), nil, true},
+		{"no data", riff(chunk("fmt ", format)), nil, true},
+	}
+
+	for _, test := range tests {
+		header, err := parseWavHeader(test.data)
+		if (err != nil) != test.fails {
+			t.Errorf("%s: error %v", test.name, err)
+			continue
+		}
+		if (header == nil) != (test.want == nil) || (header != nil && *header != *test.want) {
+			t.Errorf("%s: header %+v, want %+v", test.name, header, test.want)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+#pragma once
+
+/*
+Author: Christopher Dreide(https://github.com/Drizzy3D)
+
+Modern C++ wrapper of the go-speech-recognition library (C++17, std::span overloads with C++20):
+gspeech::Library loads the library at runtime and resolves its functions,
+gspeech::Session owns the streaming session (closed by its destructor).
+
+Errors are thrown as gspeech::Error by default,
+define GSPEECH_USE_EXPECTED (C++23) to get std::expected<T, gspeech::Error> results instead.
+
+See the README.md for instructions on how to use this header.
+*/
+
+#include "go-speech-recognition.h"
+
+#include <cstdint>
+#include <memory>
+#include <stdexcept>
+#include <string>
+#include <utility>
+
+#if __cplusplus >= 202002L || (defined(_MSVC_LANG) && _MSVC_LANG >= 202002L)
+#include <span>
+#define GSPEECH_HAS_SPAN 1
+#endif
+
+#if defined(GSPEECH_USE_EXPECTED)
+#include <expected>
+#endif
+
+#if defined(_WIN32)
+#include <windows.h>
+#else
+#include <dlfcn.h>
+#endif
+
+namespace gspeech {
+
+/*
+An error reported by the library: its GO_SPEECH_RECOGNITION_RESULT and the error log ("GetLog()").
+*/
+class Error : public std::runtime_error {
+public:
+	Error(int code, const std::string& message) : std::runtime_error(message), code_(code) {}
+
+	// The GO_SPEECH_RECOGNITION_RESULT of the failed call (GO_SPEECH_RECOGNITION_ERROR for failures of the wrapper)
+	int code() const noexcept { return code_; }
+
+private:
+	int code_;
+};
+
+#if defined(GSPEECH_USE_EXPECTED)
+template <class T>
+using Result = std::expected<T, Error>;
+#define GSPEECH_FAIL(error) return std::unexpected(error)
+#else
+template <class T>
+using Result = T;
+#define GSPEECH_FAIL(error) throw error
+#endif
+
+/*
+The loaded library and its functions, the library stays loaded as long as a Library (or a Session using it) exists.
+*/
+class Library {
+public:
+	// Loads the library (e.g. "go-speech-recognition.dll" or "./go-speech-recognition.so"), throws std::runtime_error if it can't be loaded.
+	explicit Library(const std::string& path) : handle_(load(path), &unload) {
+		if (!handle_) {
+			throw std::runtime_error("Could not load " + path);
+		}
+		resolve(setLegacyReturnCodes, "SetLegacyReturnCodes");
+		resolve(initializeStream, "InitializeStream");
+		resolve(createSessionWithCredentials, "CreateSessionWithCredentials");
+		resolve(reconfigure, "Reconfigure");
+		resolve(sendAudio, "SendAudio");
+		resolve(sendAudioWithTimestamp, "SendAudioWithTimestamp");
+		resolve(sendAudioBytes, "SendAudioBytes");
+		resolve(receiveTranscript, "ReceiveTranscript");
+		resolve(receiveTranscriptJSON, "ReceiveTranscriptJSON");
+		resolve(cancelPendingReceive, "CancelPendingReceive");
+		resolve(pollEvent, "PollEvent");
+		resolve(getStats, "GetStats");
+		resolve(getSessionID, "GetSessionID");
+		resolve(setSessionLabel, "SetSessionLabel");
+		resolve(ackResult, "AckResult");
+		resolve(getLastResultSequence, "GetLastResultSequence");
+		resolve(getLog, "GetLog");
+		resolve(getLastErrorJSON, "GetLastErrorJSON");
+		resolve(freeString, "FreeString");
+		resolve(closeStream, "CloseStream");
+		resolve(shutdown, "Shutdown");
+
+		// The wrapper relies on the negative error codes.
+		setLegacyReturnCodes(GO_SPEECH_RECOGNITION_FALSE);
+	}
+
+	// The error log of the last failure
+	std::string log() const { return take(getLog()); }
+
+	// The last error as JSON (see "GetLastErrorJSON()")
+	std::string lastErrorJSON() const { return take(getLastErrorJSON()); }
+
+	// Takes the ownership of a string returned by the library (freed by the library).
+	std::string take(char* value) const {
+		if (value == nullptr) {
+			return std::string();
+		}
+		std::string copy(value);
+		freeString(value);
+		return copy;
+	}
+
+	// Releases everything the library holds (see "Shutdown()"), has to be called before the library gets unloaded.
+	void release() const { shutdown(); }
+
+	GO_SPEECH_RECOGNITION_SET_LEGACY_RETURN_CODES setLegacyReturnCodes = nullptr;
+	GO_SPEECH_RECOGNITION_INITIALIZE_STREAM initializeStream = nullptr;
+	GO_SPEECH_RECOGNITION_CREATE_SESSION_WITH_CREDENTIALS createSessionWithCredentials = nullptr;
+	GO_SPEECH_RECOGNITION_RECONFIGURE reconfigure = nullptr;
+	GO_SPEECH_RECOGNITION_SEND_AUDIO sendAudio = nullptr;
+	GO_SPEECH_RECOGNITION_SEND_AUDIO_WITH_TIMESTAMP sendAudioWithTimestamp = nullptr;
+	GO_SPEECH_RECOGNITION_SEND_AUDIO_BYTES sendAudioBytes = nullptr;
+	GO_SPEECH_RECOGNITION_RECEIVE_TRANSCRIPT receiveTranscript = nullptr;
+	GO_SPEECH_RECOGNITION_RECEIVE_TRANSCRIPT_JSON receiveTranscriptJSON = nullptr;
+	GO_SPEECH_RECOGNITION_CANCEL_PENDING_RECEIVE cancelPendingReceive = nullptr;
+	GO_SPEECH_RECOGNITION_POLL_EVENT pollEvent = nullptr;
+	GO_SPEECH_RECOGNITION_GET_STATS getStats = nullptr;
+	GO_SPEECH_RECOGNITION_GET_SESSION_ID getSessionID = nullptr;
+	GO_SPEECH_RECOGNITION_SET_SESSION_LABEL setSessionLabel = nullptr;
+	GO_SPEECH_RECOGNITION_ACK_RESULT ackResult = nullptr;
+	GO_SPEECH_RECOGNITION_GET_LAST_RESULT_SEQUENCE getLastResultSequence = nullptr;
+	GO_SPEECH_RECOGNITION_GET_LOG getLog = nullptr;
+	GO_SPEECH_RECOGNITION_GET_LAST_ERROR_JSON getLastErrorJSON = nullptr;
+	GO_SPEECH_RECOGNITION_FREE_STRING freeString = nullptr;
+	GO_SPEECH_RECOGNITION_CLOSE_STREAM closeStream = nullptr;
+	GO_SPEECH_RECOGNITION_SHUTDOWN shutdown = nullptr;
+
+private:
+#if defined(_WIN32)
+	using Handle = HMODULE;
+	static void* load(const std::string& path) { return LoadLibraryA(path.c_str()); }
+	static void unload(void* handle) { if (handle != nullptr) FreeLibrary(static_cast<Handle>(handle)); }
+	void* symbol(const char* name) const { return reinterpret_cast<void*>(GetProcAddress(static_cast<Handle>(handle_.get()), name)); }
+#else
+	static void* load(const std::string& path) { return dlopen(path.c_str(), RTLD_NOW | RTLD_LOCAL); }
+	static void unload(void* handle) { if (handle != nullptr) dlclose(handle); }
+	void* symbol(const char* name) const { return dlsym(handle_.get(), name); }
+#endif
+
+	// Resolves a function, throws std::runtime_error if the library doesn't export it (e.g. an older version).
+	template <class Function>
+	void resolve(Function& function, const char* name) {
+		function = reinterpret_cast<Function>(symbol(name));
+		if (function == nullptr) {
+			throw std::runtime_error(std::string("The library doesn't export ") + name);
+		}
+	}
+
+	std::unique_ptr<void, void(*)(void*)> handle_;
+};
+
+/*
+The options of a session (see "InitializeStream()").
+*/
+struct SessionOptions {
+	std::string language = "en-US";
+	int sampleRate = 16000;
+	std::string model = "default";
+	int maxAlternatives = 1;
+	bool interimResults = false;
+	// The credentials (a key file or the JSON key) and the quota project of a customer, empty for the defaults (see "CreateSessionWithCredentials()")
+	std::string credentials;
+	std::string quotaProject;
+};
+
+/*
+The streaming session, closed by the destructor.
+The library runs one session at a time: opening a session closes the running one.
+*/
+class Session {
+public:
+	// Opens the session (the library has to outlive it).
+	static Result<Session> open(const Library& library, const SessionOptions& options = SessionOptions()) {
+		int code;
+		if (options.credentials.empty() && options.quotaProject.empty()) {
+			code = library.initializeStream(const_cast<char*>(options.language.c_str()), options.sampleRate,
+				const_cast<char*>(options.model.c_str()), options.maxAlternatives, toBool(options.interimResults));
+		} else {
+			code = library.createSessionWithCredentials(options.credentials.c_str(), options.quotaProject.c_str(),
+				options.language.c_str(), options.sampleRate, options.model.c_str(), options.maxAlternatives, toBool(options.interimResults));
+		}
+		if (code != GO_SPEECH_RECOGNITION_OK) {
+			GSPEECH_FAIL(Error(code, library.log()));
+		}
+		return Session(library);
+	}
+
+	Session(const Session&) = delete;
+	Session& operator=(const Session&) = delete;
+
+	Session(Session&& other) noexcept : library_(std::exchange(other.library_, nullptr)) {}
+
+	Session& operator=(Session&& other) noexcept {
+		if (this != &other) {
+			close();
+			library_ = std::exchange(other.library_, nullptr);
+		}
+		return *this;
+	}
+
+	~Session() { close(); }
+
+	// Closes the session (also done by the destructor).
+	void close() noexcept {
+		if (library_ != nullptr) {
+			library_->closeStream();
+			library_ = nullptr;
+		}
+	}
+
+	// Sends 16 bit PCM samples (see "SendAudio()").
+	Result<void> sendAudio(const int16_t* samples, size_t count) {
+		return check(library_->sendAudio(samples, static_cast<int>(count)));
+	}
+
+	// Sends 16 bit PCM samples with the capture timestamp of the first sample (see "SendAudioWithTimestamp()").
+	Result<void> sendAudio(const int16_t* samples, size_t count, long long timestampUs) {
+		return check(library_->sendAudioWithTimestamp(samples, static_cast<int>(count), timestampUs));
+	}
+
+	// Sends encoded audio in the encoding of "SetAudioEncoding()" (see "SendAudioBytes()").
+	Result<void> sendAudioBytes(const uint8_t* data, size_t size) {
+		return check(library_->sendAudioBytes(data, static_cast<int>(size)));
+	}
+
+#if defined(GSPEECH_HAS_SPAN)
+	Result<void> sendAudio(std::span<const int16_t> samples) { return sendAudio(samples.data(), samples.size()); }
+	Result<void> sendAudio(std::span<const int16_t> samples, long long timestampUs) { return sendAudio(samples.data(), samples.size(), timestampUs); }
+	Result<void> sendAudioBytes(std::span<const uint8_t> data) { return sendAudioBytes(data.data(), data.size()); }
+#endif
+
+	// Waits for the next transcript (see "ReceiveTranscript()"), the alternatives are separated by ';'.
+	Result<std::string> receiveTranscript() {
+		return receive(library_->receiveTranscript);
+	}
+
+	// Waits for the next results as JSON (see "ReceiveTranscriptJSON()").
+	Result<std::string> receiveTranscriptJSON() {
+		return receive(library_->receiveTranscriptJSON);
+	}
+
+	// Aborts a blocking receive call of another thread (see "CancelPendingReceive()").
+	void cancelReceive() { library_->cancelPendingReceive(); }
+
+	// Switches the configuration of the running session (see "Reconfigure()").
+	Result<void> reconfigure(const std::string& language, int sampleRate, const std::string& model, int maxAlternatives, bool interimResults) {
+		return check(library_->reconfigure(const_cast<char*>(language.c_str()), sampleRate,
+			const_cast<char*>(model.c_str()), maxAlternatives, toBool(interimResults)));
+	}
+
+	// Acknowledges a final result (see "AckResult()").
+	Result<void> ack(long long sequence) { return check(library_->ackResult(sequence)); }
+
+	// The sequence number of the last received result (see "GetLastResultSequence()").
+	long long lastResultSequence() const { return library_->getLastResultSequence(); }
+
+	// Attaches a label to the session (see "SetSessionLabel()").
+	Result<void> setLabel(const std::string& key, const std::string& value) {
+		return check(library_->setSessionLabel(key.c_str(), value.c_str()));
+	}
+
+	// Retrieves the next event (a GO_SPEECH_RECOGNITION_EVENT), false if none is pending (see "PollEvent()").
+	bool pollEvent(int& event) { return library_->pollEvent(&event) == GO_SPEECH_RECOGNITION_TRUE; }
+
+	// The statistics of the session as JSON (see "GetStats()").
+	Result<std::string> stats() { return receive(library_->getStats); }
+
+	// The ID of the session (see "GetSessionID()").
+	std::string id() const { return library_->take(library_->getSessionID()); }
+
+private:
+	explicit Session(const Library& library) : library_(&library) {}
+
+	static GO_SPEECH_RECOGNITION_BOOL toBool(bool value) {
+		return value ? GO_SPEECH_RECOGNITION_TRUE : GO_SPEECH_RECOGNITION_FALSE;
+	}
+
+	Result<void> check(int code) const {
+		if (code != GO_SPEECH_RECOGNITION_OK) {
+			GSPEECH_FAIL(Error(code, library_->log()));
+		}
+		return Result<void>();
+	}
+
+	template <class Function>
+	Result<std::string> receive(Function function) const {
+		char* output = nullptr;
+		int code = function(&output);
+		std::string value = library_->take(output);
+		if (code != GO_SPEECH_RECOGNITION_OK) {
+			GSPEECH_FAIL(Error(code, library_->log()));
+		}
+		return value;
+	}
+
+	const Library* library_;
+};
+
+} // namespace gspeech
+
+#undef GSPEECH_FAIL
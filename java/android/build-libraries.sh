@@ -0,0 +1,29 @@
+#!/bin/sh
+# Builds the library for the ABIs of the AAR into jniLibs (run in java/android, needs Go and the NDK in ANDROID_NDK_HOME).
+# The minimum API level of the compilers matches the minSdk of build.gradle.
+set -e
+
+if [ -z "$ANDROID_NDK_HOME" ]; then
+	echo "ANDROID_NDK_HOME has to point to the NDK" >&2
+	exit 1
+fi
+
+case "$(uname -s)" in
+	Darwin) HOST=darwin-x86_64 ;;
+	Linux) HOST=linux-x86_64 ;;
+	*) HOST=windows-x86_64 SUFFIX=.cmd ;;
+esac
+TOOLCHAIN="$ANDROID_NDK_HOME/toolchains/llvm/prebuilt/$HOST/bin"
+SOURCE="$(cd ../.. && pwd)"
+
+build() {
+	ABI=$1
+	mkdir -p "jniLibs/$ABI"
+	(cd "$SOURCE" && CGO_ENABLED=1 GOOS=android GOARCH=$2 GOARM=$3 CC="$TOOLCHAIN/$4$SUFFIX" \
+		go build -o "java/android/jniLibs/$ABI/libgo-speech-recognition.so" -buildmode=c-shared .)
+	# The header written by cgo isn't needed (see go-speech-recognition.h).
+	rm -f "jniLibs/$ABI/libgo-speech-recognition.h"
+}
+
+build arm64-v8a arm64 "" aarch64-linux-android21-clang
+build armeabi-v7a arm 7 armv7a-linux-androideabi21-clang
@@ -0,0 +1,4 @@
+# JNA binds the interface of the library by reflection.
+-keep class com.sun.jna.** { *; }
+-keep class * implements com.sun.jna.** { *; }
+-keep class com.github.drizzy3d.gspeech.NativeLibrary { *; }
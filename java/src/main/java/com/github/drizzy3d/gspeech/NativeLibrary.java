@@ -0,0 +1,69 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The exports of the library (see go-speech-recognition.h) declared for JNA.
+	Strings returned by the library are declared as Pointer, so they can be released with FreeString after copying them.
+*/
+
+package com.github.drizzy3d.gspeech;
+
+import com.sun.jna.Library;
+import com.sun.jna.Native;
+import com.sun.jna.Pointer;
+import com.sun.jna.ptr.IntByReference;
+import com.sun.jna.ptr.PointerByReference;
+
+import java.util.Collections;
+
+interface NativeLibrary extends Library {
+
+	// Loaded by its name (go-speech-recognition.dll, libgo-speech-recognition.so, ...),
+	// the property "gspeech.library" overrides it with a path.
+	NativeLibrary INSTANCE = load();
+
+	void SetLegacyReturnCodes(int legacy);
+	int InitializeStream(String language, int sampleRate, String model, int maxAlternatives, int interimResults);
+	int CreateSessionWithCredentials(String credentials, String quotaProject, String language, int sampleRate, String model, int maxAlternatives, int interimResults);
+	int CreateSessionWithAccessToken(String accessToken, int expiresInSeconds, String quotaProject, String language, int sampleRate, String model, int maxAlternatives, int interimResults);
+	int SetAccessToken(String accessToken, int expiresInSeconds);
+	int Reconfigure(String language, int sampleRate, String model, int maxAlternatives, int interimResults);
+	int SendAudio(short[] recording, int recordingLength);
+	int SendAudioWithTimestamp(short[] recording, int recordingLength, long timestampUs);
+	int SendAudioBytes(byte[] data, int dataLength);
+	int ReceiveTranscript(PointerByReference output);
+	int ReceiveTranscriptJSON(PointerByReference output);
+	void CancelPendingReceive();
+	int PollEvent(IntByReference event);
+	int GetStats(PointerByReference output);
+	Pointer GetSessionID();
+	int SetSessionLabel(String key, String value);
+	int AckResult(long sequence);
+	long GetLastResultSequence();
+	int IsInitialized();
+	Pointer GetLog();
+	Pointer GetLastErrorJSON();
+	void FreeString(Pointer string);
+	void CloseStream();
+	void Shutdown();
+
+	static NativeLibrary load() {
+		String name = System.getProperty("gspeech.library", "go-speech-recognition");
+		NativeLibrary library = Native.load(name, NativeLibrary.class,
+			Collections.singletonMap(Library.OPTION_STRING_ENCODING, "UTF-8"));
+		// The binding relies on the negative error codes.
+		library.SetLegacyReturnCodes(0);
+		return library;
+	}
+
+	// Copies a string returned by the library and releases it.
+	static String take(Pointer string) {
+		if (string == null) {
+			return "";
+		}
+		try {
+			return string.getString(0, "UTF-8");
+		} finally {
+			INSTANCE.FreeString(string);
+		}
+	}
+}
@@ -0,0 +1,60 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The options of a session (see "InitializeStream()"), the setters return the options to allow chaining.
+*/
+
+package com.github.drizzy3d.gspeech;
+
+public class SessionOptions {
+
+	String language = "en-US";
+	int sampleRate = 16000;
+	String model = "default";
+	int maxAlternatives = 1;
+	boolean interimResults = false;
+	// The credentials (a key file or the JSON key) and the quota project of a customer, null for the defaults (see "CreateSessionWithCredentials()")
+	String credentials = null;
+	String quotaProject = null;
+	// An OAuth access token of the host (e.g. of the Google sign-in on Android) and its lifetime, null for none (see "CreateSessionWithAccessToken()")
+	String accessToken = null;
+	int accessTokenExpiresInSeconds = 0;
+
+	public SessionOptions language(String language) {
+		this.language = language;
+		return this;
+	}
+
+	public SessionOptions sampleRate(int sampleRate) {
+		this.sampleRate = sampleRate;
+		return this;
+	}
+
+	public SessionOptions model(String model) {
+		this.model = model;
+		return this;
+	}
+
+	public SessionOptions maxAlternatives(int maxAlternatives) {
+		this.maxAlternatives = maxAlternatives;
+		return this;
+	}
+
+	public SessionOptions interimResults(boolean interimResults) {
+		this.interimResults = interimResults;
+		return this;
+	}
+
+	public SessionOptions credentials(String credentials, String quotaProject) {
+		this.credentials = credentials;
+		this.quotaProject = quotaProject;
+		return this;
+	}
+
+	// Authenticates the session with an access token (expiresInSeconds is 0 if unknown), replace it with "SpeechSession.setAccessToken()" before it expires.
+	public SessionOptions accessToken(String accessToken, int expiresInSeconds) {
+		this.accessToken = accessToken;
+		this.accessTokenExpiresInSeconds = expiresInSeconds;
+		return this;
+	}
+}
@@ -0,0 +1,42 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The events of the library (GO_SPEECH_RECOGNITION_EVENT of go-speech-recognition.h, see "PollEvent()").
+*/
+
+package com.github.drizzy3d.gspeech;
+
+public enum SpeechEvent {
+	SILENCE_TIMEOUT(1),
+	MAX_DURATION(2),
+	BUDGET_EXCEEDED(3),
+	CLIPPING(4),
+	DC_OFFSET(5),
+	NEAR_SILENCE(6),
+	QUOTA_EXCEEDED(7),
+	OFFLINE(8),
+	BACKFILLED(9),
+	FORMAT_MISMATCH(10),
+	WAKE_WORD(11),
+	WAKE_WORD_REARMED(12);
+
+	private final int code;
+
+	SpeechEvent(int code) {
+		this.code = code;
+	}
+
+	public int code() {
+		return code;
+	}
+
+	// Returns null for unknown events (of a newer library).
+	public static SpeechEvent of(int code) {
+		for (SpeechEvent event : values()) {
+			if (event.code == code) {
+				return event;
+			}
+		}
+		return null;
+	}
+}
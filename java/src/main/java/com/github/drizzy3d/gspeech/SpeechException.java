@@ -0,0 +1,21 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	A failed call of the library with its result code and the error log ("GetLog()").
+*/
+
+package com.github.drizzy3d.gspeech;
+
+public class SpeechException extends RuntimeException {
+
+	private final SpeechResult result;
+
+	public SpeechException(SpeechResult result, String message) {
+		super(message);
+		this.result = result;
+	}
+
+	public SpeechResult getResult() {
+		return result;
+	}
+}
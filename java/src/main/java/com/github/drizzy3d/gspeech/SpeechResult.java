@@ -0,0 +1,44 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The result codes of the library (GO_SPEECH_RECOGNITION_RESULT of go-speech-recognition.h).
+*/
+
+package com.github.drizzy3d.gspeech;
+
+public enum SpeechResult {
+	OK(0),
+	ERROR(-1),
+	NOT_INITIALIZED(-2),
+	INVALID_ARGUMENT(-3),
+	FINALIZED(-4),
+	QUEUE_FULL(-5),
+	STREAM_ENDED(-6),
+	BUDGET_EXCEEDED(-7),
+	CANCELED(-8),
+	RATE_LIMITED(-9),
+	QUOTA_EXCEEDED(-10),
+	WRONG_THREAD(-11),
+	BUSY(-12),
+	MODEL_UNAVAILABLE(-13);
+
+	private final int code;
+
+	SpeechResult(int code) {
+		this.code = code;
+	}
+
+	public int code() {
+		return code;
+	}
+
+	// Unknown codes (of a newer library) are mapped to ERROR.
+	public static SpeechResult of(int code) {
+		for (SpeechResult result : values()) {
+			if (result.code == code) {
+				return result;
+			}
+		}
+		return ERROR;
+	}
+}
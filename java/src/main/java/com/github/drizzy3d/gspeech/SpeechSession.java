@@ -0,0 +1,246 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	The streaming session of the library, results are either received by the blocking calls or pushed to a listener
+	by the session's receive thread (see "listen()").
+	The library runs one session at a time, opening a session closes the running one.
+*/
+
+package com.github.drizzy3d.gspeech;
+
+import com.sun.jna.ptr.IntByReference;
+import com.sun.jna.ptr.PointerByReference;
+
+import java.util.List;
+
+public final class SpeechSession implements AutoCloseable {
+
+	private static final NativeLibrary LIBRARY = NativeLibrary.INSTANCE;
+
+	private final Object lock = new Object();
+	private boolean closed = false;
+	private Thread receiveThread = null;
+
+	private SpeechSession() {
+	}
+
+	// Opens the session (see "InitializeStream()", "CreateSessionWithCredentials()" and "CreateSessionWithAccessToken()").
+	public static SpeechSession open(SessionOptions options) {
+		if (options == null) {
+			options = new SessionOptions();
+		}
+		int interimResults = options.interimResults ? 1 : 0;
+		int code;
+		if (options.accessToken != null) {
+			code = LIBRARY.CreateSessionWithAccessToken(options.accessToken, options.accessTokenExpiresInSeconds,
+				options.quotaProject == null ? "" : options.quotaProject,
+				options.language, options.sampleRate, options.model, options.maxAlternatives, interimResults);
+		} else if (options.credentials == null && options.quotaProject == null) {
+			code = LIBRARY.InitializeStream(options.language, options.sampleRate, options.model, options.maxAlternatives, interimResults);
+		} else {
+			code = LIBRARY.CreateSessionWithCredentials(options.credentials == null ? "" : options.credentials,
+				options.quotaProject == null ? "" : options.quotaProject,
+				options.language, options.sampleRate, options.model, options.maxAlternatives, interimResults);
+		}
+		check(code);
+		return new SpeechSession();
+	}
+
+	public boolean isOpen() {
+		synchronized (lock) {
+			return !closed && LIBRARY.IsInitialized() == 1;
+		}
+	}
+
+	// The ID of the session (see "GetSessionID()").
+	public String getId() {
+		return NativeLibrary.take(LIBRARY.GetSessionID());
+	}
+
+	// The sequence number of the last received result (see "GetLastResultSequence()").
+	public long getLastResultSequence() {
+		return LIBRARY.GetLastResultSequence();
+	}
+
+	// Sends 16 bit PCM samples (see "SendAudio()").
+	public void sendAudio(short[] samples, int length) {
+		if (length <= 0) {
+			return;
+		}
+		check(LIBRARY.SendAudio(samples, Math.min(length, samples.length)));
+	}
+
+	// Sends 16 bit PCM samples with the capture timestamp of the first sample (see "SendAudioWithTimestamp()").
+	public void sendAudio(short[] samples, int length, long timestampUs) {
+		if (length <= 0) {
+			return;
+		}
+		check(LIBRARY.SendAudioWithTimestamp(samples, Math.min(length, samples.length), timestampUs));
+	}
+
+	// Sends encoded audio in the encoding of "SetAudioEncoding()" (see "SendAudioBytes()").
+	public void sendAudioBytes(byte[] data, int length) {
+		if (length <= 0) {
+			return;
+		}
+		check(LIBRARY.SendAudioBytes(data, Math.min(length, data.length)));
+	}
+
+	// Waits for the next transcript (see "ReceiveTranscript()"), the alternatives are separated by ';'.
+	public String receiveTranscript() {
+		PointerByReference output = new PointerByReference();
+		int code = LIBRARY.ReceiveTranscript(output);
+		String transcript = NativeLibrary.take(output.getValue());
+		check(code);
+		return transcript;
+	}
+
+	// Waits for the next results as JSON (see "ReceiveTranscriptJSON()").
+	public String receiveTranscriptJSON() {
+		PointerByReference output = new PointerByReference();
+		int code = LIBRARY.ReceiveTranscriptJSON(output);
+		String json = NativeLibrary.take(output.getValue());
+		check(code);
+		return json;
+	}
+
+	// Waits for the next results (see "ReceiveTranscriptJSON()"), empty for responses without results.
+	public List<Transcript> receiveResults() {
+		return TranscriptParser.parse(receiveTranscriptJSON());
+	}
+
+	/*
+		Starts the session's receive thread, which passes every result to the listener until the session ends or is closed
+		(the blocking receive calls must not be used meanwhile).
+	*/
+	public void listen(TranscriptListener listener) {
+		synchronized (lock) {
+			if (closed) {
+				throw new SpeechException(SpeechResult.NOT_INITIALIZED, "The session is closed");
+			}
+			if (receiveThread != null) {
+				throw new IllegalStateException("The session is already listening");
+			}
+			receiveThread = new Thread(() -> receiveLoop(listener), "gspeech-receive");
+			receiveThread.setDaemon(true);
+			receiveThread.start();
+		}
+	}
+
+	private void receiveLoop(TranscriptListener listener) {
+		while (true) {
+			synchronized (lock) {
+				// The library may run the next session already.
+				if (closed) {
+					listener.onEnd();
+					return;
+				}
+			}
+			List<Transcript> transcripts;
+			try {
+				transcripts = receiveResults();
+			} catch (SpeechException e) {
+				SpeechResult result = e.getResult();
+				if (result != SpeechResult.STREAM_ENDED && result != SpeechResult.CANCELED && result != SpeechResult.NOT_INITIALIZED) {
+					listener.onError(e);
+				}
+				listener.onEnd();
+				return;
+			}
+			for (Transcript transcript : transcripts) {
+				if (transcript.isFinal()) {
+					listener.onFinal(transcript);
+				} else {
+					listener.onPartial(transcript);
+				}
+			}
+		}
+	}
+
+	// Switches the configuration of the running session (see "Reconfigure()").
+	public void reconfigure(String language, int sampleRate, String model, int maxAlternatives, boolean interimResults) {
+		check(LIBRARY.Reconfigure(language, sampleRate, model, maxAlternatives, interimResults ? 1 : 0));
+	}
+
+	// Acknowledges a final result (see "AckResult()").
+	public void ack(long sequence) {
+		check(LIBRARY.AckResult(sequence));
+	}
+
+	// Attaches a label to the session (see "SetSessionLabel()").
+	public void setLabel(String key, String value) {
+		check(LIBRARY.SetSessionLabel(key, value));
+	}
+
+	// Returns the next event or null if none is pending (see "PollEvent()").
+	public SpeechEvent pollEvent() {
+		IntByReference event = new IntByReference();
+		if (LIBRARY.PollEvent(event) != 1) {
+			return null;
+		}
+		return SpeechEvent.of(event.getValue());
+	}
+
+	// The statistics of the session as JSON (see "GetStats()").
+	public String getStats() {
+		PointerByReference output = new PointerByReference();
+		int code = LIBRARY.GetStats(output);
+		String stats = NativeLibrary.take(output.getValue());
+		check(code);
+		return stats;
+	}
+
+	// Replaces the access token of a session opened with one, e.g. after the sign-in was refreshed (see "SetAccessToken()").
+	public void setAccessToken(String accessToken, int expiresInSeconds) {
+		check(LIBRARY.SetAccessToken(accessToken, expiresInSeconds));
+	}
+
+	// Aborts a blocking receive call of another thread (see "CancelPendingReceive()").
+	public void cancelReceive() {
+		LIBRARY.CancelPendingReceive();
+	}
+
+	// Closes the session (see "CloseStream()") and waits until the receive thread of a listening session stopped
+	// (unless it's the calling thread, e.g. a listener closing the session).
+	@Override
+	public void close() {
+		Thread thread;
+		synchronized (lock) {
+			if (closed) {
+				return;
+			}
+			closed = true;
+			thread = receiveThread;
+		}
+		// Closing the session ends the pending receive call of the thread.
+		LIBRARY.CloseStream();
+		if (thread != null && thread != Thread.currentThread()) {
+			try {
+				thread.join();
+			} catch (InterruptedException e) {
+				Thread.currentThread().interrupt();
+			}
+		}
+	}
+
+	// Releases everything the library holds (see "Shutdown()"), has to be called before the process unloads the library.
+	public static void shutdown() {
+		LIBRARY.Shutdown();
+	}
+
+	// The error log of the last failure (see "GetLog()")
+	public static String getLastLog() {
+		return NativeLibrary.take(LIBRARY.GetLog());
+	}
+
+	// The last error as JSON (see "GetLastErrorJSON()")
+	public static String getLastErrorJSON() {
+		return NativeLibrary.take(LIBRARY.GetLastErrorJSON());
+	}
+
+	private static void check(int code) {
+		if (code != SpeechResult.OK.code()) {
+			throw new SpeechException(SpeechResult.of(code), getLastLog());
+		}
+	}
+}
@@ -0,0 +1,56 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	A result of the session (an entry of "ReceiveTranscriptJSON()"), the timestamps are in microseconds of the host's clock (-1 if unknown).
+*/
+
+package com.github.drizzy3d.gspeech;
+
+public final class Transcript {
+
+	private final String text;
+	private final boolean isFinal;
+	private final float stability;
+	private final float confidence;
+	private final long endTimestamp;
+	private final long sequence;
+
+	Transcript(String text, boolean isFinal, float stability, float confidence, long endTimestamp, long sequence) {
+		this.text = text;
+		this.isFinal = isFinal;
+		this.stability = stability;
+		this.confidence = confidence;
+		this.endTimestamp = endTimestamp;
+		this.sequence = sequence;
+	}
+
+	public String getText() {
+		return text;
+	}
+
+	public boolean isFinal() {
+		return isFinal;
+	}
+
+	public float getStability() {
+		return stability;
+	}
+
+	public float getConfidence() {
+		return confidence;
+	}
+
+	public long getEndTimestamp() {
+		return endTimestamp;
+	}
+
+	// The sequence number to be passed to "SpeechSession.ack()", 0 if the result has none (see "SetResultAcknowledgment()")
+	public long getSequence() {
+		return sequence;
+	}
+
+	@Override
+	public String toString() {
+		return text;
+	}
+}
@@ -0,0 +1,25 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Receives the results of a listening session (see "SpeechSession.listen()"), called on the session's receive thread.
+*/
+
+package com.github.drizzy3d.gspeech;
+
+public interface TranscriptListener {
+
+	// An interim result (only with interim results enabled)
+	default void onPartial(Transcript transcript) {
+	}
+
+	// A final result
+	void onFinal(Transcript transcript);
+
+	// A failure ending the listening, the session has to be closed
+	default void onError(SpeechException exception) {
+	}
+
+	// The session ended (e.g. after a silence timeout or when it's closed)
+	default void onEnd() {
+	}
+}
@@ -0,0 +1,212 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Reads the results of "ReceiveTranscriptJSON()", a minimal JSON reader, so the binding needs no JSON library
+	(unknown fields like the words are skipped).
+*/
+
+package com.github.drizzy3d.gspeech;
+
+import java.util.ArrayList;
+import java.util.List;
+
+final class TranscriptParser {
+
+	private final String json;
+	private int position = 0;
+
+	private TranscriptParser(String json) {
+		this.json = json;
+	}
+
+	// Parses the array of results.
+	static List<Transcript> parse(String json) {
+		TranscriptParser parser = new TranscriptParser(json);
+		List<Transcript> transcripts = new ArrayList<>();
+
+		parser.expect('[');
+		if (parser.consume(']')) {
+			return transcripts;
+		}
+		do {
+			transcripts.add(parser.readTranscript());
+		} while (parser.consume(','));
+		parser.expect(']');
+		return transcripts;
+	}
+
+	private Transcript readTranscript() {
+		String text = "";
+		boolean isFinal = false;
+		double stability = 0;
+		double confidence = 0;
+		double endTimestamp = -1;
+		double sequence = 0;
+
+		expect('{');
+		if (consume('}')) {
+			return new Transcript(text, isFinal, 0, 0, -1, 0);
+		}
+		do {
+			String field = readString();
+			expect(':');
+			switch (field) {
+			case "transcript":
+				text = readString();
+				break;
+			case "isFinal":
+				isFinal = readBoolean();
+				break;
+			case "stability":
+				stability = readNumber();
+				break;
+			case "confidence":
+				confidence = readNumber();
+				break;
+			case "endTimestamp":
+				endTimestamp = readNumber();
+				break;
+			case "sequence":
+				sequence = readNumber();
+				break;
+			default:
+				skipValue();
+			}
+		} while (consume(','));
+		expect('}');
+		return new Transcript(text, isFinal, (float) stability, (float) confidence, (long) endTimestamp, (long) sequence);
+	}
+
+	private String readString() {
+		expect('"');
+		StringBuilder builder = new StringBuilder();
+		while (true) {
+			char c = next();
+			if (c == '"') {
+				return builder.toString();
+			}
+			if (c != '\\') {
+				builder.append(c);
+				continue;
+			}
+			char escaped = next();
+			switch (escaped) {
+			case 'b':
+				builder.append('\b');
+				break;
+			case 'f':
+				builder.append('\f');
+				break;
+			case 'n':
+				builder.append('\n');
+				break;
+			case 'r':
+				builder.append('\r');
+				break;
+			case 't':
+				builder.append('\t');
+				break;
+			case 'u':
+				if (position + 4 > json.length()) {
+					throw malformed();
+				}
+				builder.append((char) Integer.parseInt(json.substring(position, position + 4), 16));
+				position += 4;
+				break;
+			default:
+				// '"', '\\' and '/'
+				builder.append(escaped);
+			}
+		}
+	}
+
+	private boolean readBoolean() {
+		skipWhitespace();
+		if (json.startsWith("true", position)) {
+			position += 4;
+			return true;
+		}
+		if (json.startsWith("false", position)) {
+			position += 5;
+			return false;
+		}
+		throw malformed();
+	}
+
+	private double readNumber() {
+		skipWhitespace();
+		int start = position;
+		while (position < json.length() && "+-0123456789.eE".indexOf(json.charAt(position)) >= 0) {
+			position++;
+		}
+		try {
+			return Double.parseDouble(json.substring(start, position));
+		} catch (NumberFormatException e) {
+			throw malformed();
+		}
+	}
+
+	private void skipValue() {
+		skipWhitespace();
+		if (position >= json.length()) {
+			throw malformed();
+		}
+		char c = json.charAt(position);
+		if (c == '"') {
+			readString();
+		} else if (c == '{' || c == '[') {
+			char close = c == '{' ? '}' : ']';
+			position++;
+			if (consume(close)) {
+				return;
+			}
+			do {
+				if (c == '{') {
+					readString();
+					expect(':');
+				}
+				skipValue();
+			} while (consume(','));
+			expect(close);
+		} else if (c == 't' || c == 'f') {
+			readBoolean();
+		} else if (json.startsWith("null", position)) {
+			position += 4;
+		} else {
+			readNumber();
+		}
+	}
+
+	private void skipWhitespace() {
+		while (position < json.length() && Character.isWhitespace(json.charAt(position))) {
+			position++;
+		}
+	}
+
+	private char next() {
+		if (position >= json.length()) {
+			throw malformed();
+		}
+		return json.charAt(position++);
+	}
+
+	// Consumes the character if it's next (after whitespace).
+	private boolean consume(char c) {
+		skipWhitespace();
+		if (position < json.length() && json.charAt(position) == c) {
+			position++;
+			return true;
+		}
+		return false;
+	}
+
+	private void expect(char c) {
+		if (!consume(c)) {
+			throw malformed();
+		}
+	}
+
+	private SpeechException malformed() {
+		return new SpeechException(SpeechResult.ERROR, "Malformed results at position " + position + ": " + json);
+	}
+}
@@ -0,0 +1,13 @@
+{
+	"targets": [
+		{
+			"target_name": "gspeech",
+			"sources": [ "src/addon.cc" ],
+			"include_dirs": [ ".." ],
+			"defines": [ "NAPI_VERSION=4" ],
+			"conditions": [
+				[ "OS!='win'", { "libraries": [ "-ldl" ] } ]
+			]
+		}
+	]
+}
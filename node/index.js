@@ -0,0 +1,229 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Node.js binding of the go-speech-recognition library: SpeechSession emits the results of the session
+	('partial' and 'final'), its events ('event'), failures ('error') and its end ('end').
+	The library runs one session at a time, opening a session closes the running one.
+*/
+
+'use strict';
+
+const { EventEmitter } = require('events');
+
+const native = require('./build/Release/gspeech.node');
+
+// GO_SPEECH_RECOGNITION_RESULT
+const Result = Object.freeze({
+	OK: 0,
+	ERROR: -1,
+	NOT_INITIALIZED: -2,
+	INVALID_ARGUMENT: -3,
+	FINALIZED: -4,
+	QUEUE_FULL: -5,
+	STREAM_ENDED: -6,
+	BUDGET_EXCEEDED: -7,
+	CANCELED: -8,
+	RATE_LIMITED: -9,
+	QUOTA_EXCEEDED: -10,
+	WRONG_THREAD: -11,
+	BUSY: -12,
+	MODEL_UNAVAILABLE: -13,
+});
+
+// GO_SPEECH_RECOGNITION_EVENT
+const Event = Object.freeze({
+	SILENCE_TIMEOUT: 1,
+	MAX_DURATION: 2,
+	BUDGET_EXCEEDED: 3,
+	CLIPPING: 4,
+	DC_OFFSET: 5,
+	NEAR_SILENCE: 6,
+	QUOTA_EXCEEDED: 7,
+	OFFLINE: 8,
+	BACKFILLED: 9,
+	FORMAT_MISMATCH: 10,
+	WAKE_WORD: 11,
+	WAKE_WORD_REARMED: 12,
+});
+
+// A failed call of the library with its result code and the error log ("GetLog()")
+class SpeechError extends Error {
+	constructor(code, message) {
+		super(message);
+		this.name = 'SpeechError';
+		this.code = code;
+	}
+}
+
+let loaded = false;
+
+// Loads the library from the path (GSPEECH_LIBRARY or the library's name by default), called by the first "SpeechSession.open()".
+function load(path) {
+	if (loaded) {
+		return;
+	}
+	if (path === undefined) {
+		path = process.env.GSPEECH_LIBRARY;
+	}
+	if (path === undefined) {
+		path = process.platform === 'win32' ? 'go-speech-recognition.dll'
+			: process.platform === 'darwin' ? 'libgo-speech-recognition.dylib' : 'libgo-speech-recognition.so';
+	}
+	native.load(path);
+	loaded = true;
+}
+
+function check(code) {
+	if (code !== Result.OK) {
+		throw new SpeechError(code, native.getLog());
+	}
+}
+
+// Returns the output of a call returning a string ({ code, output }).
+function checkOutput(result) {
+	check(result.code);
+	return result.output;
+}
+
+class SpeechSession extends EventEmitter {
+
+	/*
+		Opens the session (see "InitializeStream()" and "CreateSessionWithCredentials()"), the results are emitted
+		once the listeners have been attached:
+
+			const session = SpeechSession.open({ language: 'de-DE', interimResults: true });
+			session.on('partial', (result) => showPartial(result.transcript));
+			session.on('final', (result) => showFinal(result.transcript));
+	*/
+	static open(options = {}) {
+		load();
+		const {
+			language = 'en-US', sampleRate = 16000, model = 'default', maxAlternatives = 1, interimResults = false,
+			credentials, quotaProject,
+		} = options;
+
+		if (credentials === undefined && quotaProject === undefined) {
+			check(native.initializeStream(language, sampleRate, model, maxAlternatives, interimResults));
+		} else {
+			check(native.createSessionWithCredentials(credentials || '', quotaProject || '', language, sampleRate, model, maxAlternatives, interimResults));
+		}
+		return new SpeechSession();
+	}
+
+	constructor() {
+		super();
+		this.closed = false;
+		process.nextTick(() => this._receive());
+	}
+
+	get isOpen() {
+		return !this.closed && native.isInitialized();
+	}
+
+	// The ID of the session (see "GetSessionID()")
+	get id() {
+		return native.getSessionId();
+	}
+
+	// Sends 16 bit PCM samples (an Int16Array), optionally with the capture timestamp of the first sample (see "SendAudioWithTimestamp()").
+	sendAudio(samples, timestampUs) {
+		if (samples.length === 0) {
+			return;
+		}
+		check(native.sendAudio(samples, timestampUs));
+	}
+
+	// Sends encoded audio (a Buffer or Uint8Array) in the encoding of "SetAudioEncoding()" (see "SendAudioBytes()").
+	sendAudioBytes(data) {
+		if (data.length === 0) {
+			return;
+		}
+		check(native.sendAudioBytes(data));
+	}
+
+	// Switches the configuration of the running session (see "Reconfigure()").
+	reconfigure({ language = 'en-US', sampleRate = 16000, model = 'default', maxAlternatives = 1, interimResults = false } = {}) {
+		check(native.reconfigure(language, sampleRate, model, maxAlternatives, interimResults));
+	}
+
+	// Acknowledges a final result by its "sequence" (see "AckResult()").
+	ack(sequence) {
+		check(native.ackResult(sequence));
+	}
+
+	// Attaches a label to the session (see "SetSessionLabel()").
+	setLabel(key, value) {
+		check(native.setSessionLabel(key, value));
+	}
+
+	// The statistics of the session (see "GetStats()").
+	stats() {
+		return JSON.parse(checkOutput(native.getStats()));
+	}
+
+	// Closes the session (see "CloseStream()"), 'end' is emitted once the pending receive call returned.
+	close() {
+		if (this.closed) {
+			return;
+		}
+		this.closed = true;
+		native.closeStream();
+	}
+
+	// Receives the results until the session ends or is closed.
+	async _receive() {
+		while (!this.closed) {
+			const { code, output } = await native.receiveTranscriptJSON();
+			this._emitEvents();
+			if (this.closed || code === Result.STREAM_ENDED || code === Result.NOT_INITIALIZED) {
+				break;
+			}
+			if (code === Result.CANCELED) {
+				continue;
+			}
+			if (code !== Result.OK) {
+				this.emit('error', new SpeechError(code, native.getLog()));
+				break;
+			}
+			for (const result of JSON.parse(output)) {
+				this.emit(result.isFinal ? 'final' : 'partial', result);
+			}
+		}
+		this.emit('end');
+	}
+
+	// Emits the pending events of the session (see "PollEvent()").
+	_emitEvents() {
+		for (let event = native.pollEvent(); event !== 0; event = native.pollEvent()) {
+			this.emit('event', event);
+		}
+	}
+}
+
+module.exports = {
+	Event,
+	Result,
+	SpeechError,
+	SpeechSession,
+	load,
+	// The error log of the last failure (see "GetLog()")
+	lastLog: () => {
+		load();
+		return native.getLog();
+	},
+	// The last error as JSON (see "GetLastErrorJSON()")
+	lastErrorJSON: () => {
+		load();
+		return native.getLastErrorJSON();
+	},
+	// Aborts the pending receive call (see "CancelPendingReceive()")
+	cancelPendingReceive: () => {
+		load();
+		native.cancelPendingReceive();
+	},
+	// Releases everything the library holds (see "Shutdown()"), has to be called before the process exits
+	shutdown: () => {
+		load();
+		native.shutdown();
+	},
+};
@@ -0,0 +1,465 @@
+/*
+Author: Christopher Dreide(https://github.com/Drizzy3D)
+
+Thin N-API addon loading the go-speech-recognition library at runtime (see go-speech-recognition.h),
+the session and its events are implemented in index.js.
+The functions return the result codes of the library, the blocking receive call runs on the thread pool and returns a promise.
+*/
+
+#include <node_api.h>
+
+#include "go-speech-recognition.h"
+
+#include <string>
+
+#if defined(_WIN32)
+#include <windows.h>
+#else
+#include <dlfcn.h>
+#endif
+
+namespace {
+
+// The functions of the loaded library
+struct Library {
+	void* handle = nullptr;
+	GO_SPEECH_RECOGNITION_SET_LEGACY_RETURN_CODES setLegacyReturnCodes = nullptr;
+	GO_SPEECH_RECOGNITION_INITIALIZE_STREAM initializeStream = nullptr;
+	GO_SPEECH_RECOGNITION_CREATE_SESSION_WITH_CREDENTIALS createSessionWithCredentials = nullptr;
+	GO_SPEECH_RECOGNITION_RECONFIGURE reconfigure = nullptr;
+	GO_SPEECH_RECOGNITION_SEND_AUDIO sendAudio = nullptr;
+	GO_SPEECH_RECOGNITION_SEND_AUDIO_WITH_TIMESTAMP sendAudioWithTimestamp = nullptr;
+	GO_SPEECH_RECOGNITION_SEND_AUDIO_BYTES sendAudioBytes = nullptr;
+	GO_SPEECH_RECOGNITION_RECEIVE_TRANSCRIPT_JSON receiveTranscriptJSON = nullptr;
+	GO_SPEECH_RECOGNITION_CANCEL_PENDING_RECEIVE cancelPendingReceive = nullptr;
+	GO_SPEECH_RECOGNITION_POLL_EVENT pollEvent = nullptr;
+	GO_SPEECH_RECOGNITION_GET_STATS getStats = nullptr;
+	GO_SPEECH_RECOGNITION_GET_SESSION_ID getSessionID = nullptr;
+	GO_SPEECH_RECOGNITION_SET_SESSION_LABEL setSessionLabel = nullptr;
+	GO_SPEECH_RECOGNITION_ACK_RESULT ackResult = nullptr;
+	GO_SPEECH_RECOGNITION_IS_INITIALIZED isInitialized = nullptr;
+	GO_SPEECH_RECOGNITION_GET_LOG getLog = nullptr;
+	GO_SPEECH_RECOGNITION_GET_LAST_ERROR_JSON getLastErrorJSON = nullptr;
+	GO_SPEECH_RECOGNITION_FREE_STRING freeString = nullptr;
+	GO_SPEECH_RECOGNITION_CLOSE_STREAM closeStream = nullptr;
+	GO_SPEECH_RECOGNITION_SHUTDOWN shutdown = nullptr;
+};
+
+// The library is loaded once per process (like the library's session it's global).
+Library library;
+
+#if defined(_WIN32)
+void* loadLibrary(const std::string& path) { return LoadLibraryA(path.c_str()); }
+void* symbol(void* handle, const char* name) { return reinterpret_cast<void*>(GetProcAddress(static_cast<HMODULE>(handle), name)); }
+#else
+void* loadLibrary(const std::string& path) { return dlopen(path.c_str(), RTLD_NOW | RTLD_LOCAL); }
+void* symbol(void* handle, const char* name) { return dlsym(handle, name); }
+#endif
+
+// Throws a JavaScript error and returns nullptr (for "return fail(...)").
+napi_value fail(napi_env env, const std::string& message) {
+	napi_throw_error(env, nullptr, message.c_str());
+	return nullptr;
+}
+
+// Copies a string returned by the library and releases it.
+std::string take(char* value) {
+	if (value == nullptr) {
+		return std::string();
+	}
+	std::string copy(value);
+	library.freeString(value);
+	return copy;
+}
+
+// Reads the arguments of a call, returns false (with a pending exception) if fewer than required were passed.
+bool arguments(napi_env env, napi_callback_info info, size_t required, napi_value* args, size_t capacity) {
+	size_t count = capacity;
+	if (napi_get_cb_info(env, info, &count, args, nullptr, nullptr) != napi_ok) {
+		return false;
+	}
+	if (count < required) {
+		napi_throw_type_error(env, nullptr, "Missing arguments");
+		return false;
+	}
+	return true;
+}
+
+bool toString(napi_env env, napi_value value, std::string& result) {
+	size_t length = 0;
+	if (napi_get_value_string_utf8(env, value, nullptr, 0, &length) != napi_ok) {
+		napi_throw_type_error(env, nullptr, "Expected a string");
+		return false;
+	}
+	result.resize(length + 1);
+	napi_get_value_string_utf8(env, value, &result[0], result.size(), &length);
+	result.resize(length);
+	return true;
+}
+
+bool toInt(napi_env env, napi_value value, int& result) {
+	int32_t number = 0;
+	if (napi_get_value_int32(env, value, &number) != napi_ok) {
+		napi_throw_type_error(env, nullptr, "Expected a number");
+		return false;
+	}
+	result = number;
+	return true;
+}
+
+bool toInt64(napi_env env, napi_value value, long long& result) {
+	int64_t number = 0;
+	if (napi_get_value_int64(env, value, &number) != napi_ok) {
+		napi_throw_type_error(env, nullptr, "Expected a number");
+		return false;
+	}
+	result = number;
+	return true;
+}
+
+bool toBool(napi_env env, napi_value value, GO_SPEECH_RECOGNITION_BOOL& result) {
+	bool flag = false;
+	if (napi_get_value_bool(env, value, &flag) != napi_ok) {
+		napi_throw_type_error(env, nullptr, "Expected a boolean");
+		return false;
+	}
+	result = flag ? GO_SPEECH_RECOGNITION_TRUE : GO_SPEECH_RECOGNITION_FALSE;
+	return true;
+}
+
+// Reads a typed array of the expected type (a Buffer is accepted as Uint8Array).
+bool toTypedArray(napi_env env, napi_value value, napi_typedarray_type expected, void*& data, size_t& length) {
+	bool isTypedArray = false;
+	napi_is_typedarray(env, value, &isTypedArray);
+	napi_typedarray_type type;
+	if (isTypedArray == false || napi_get_typedarray_info(env, value, &type, &length, &data, nullptr, nullptr) != napi_ok || type != expected) {
+		napi_throw_type_error(env, nullptr, expected == napi_int16_array ? "Expected an Int16Array" : "Expected a Uint8Array");
+		return false;
+	}
+	return true;
+}
+
+napi_value fromInt(napi_env env, int value) {
+	napi_value result;
+	napi_create_int32(env, value, &result);
+	return result;
+}
+
+napi_value fromString(napi_env env, const std::string& value) {
+	napi_value result;
+	napi_create_string_utf8(env, value.c_str(), value.size(), &result);
+	return result;
+}
+
+// The result of a call returning a string: { code, output }
+napi_value fromOutput(napi_env env, int code, const std::string& output) {
+	napi_value result;
+	napi_create_object(env, &result);
+	napi_set_named_property(env, result, "code", fromInt(env, code));
+	napi_set_named_property(env, result, "output", fromString(env, output));
+	return result;
+}
+
+bool ensureLoaded(napi_env env) {
+	if (library.handle == nullptr) {
+		napi_throw_error(env, nullptr, "The library isn't loaded (see load())");
+		return false;
+	}
+	return true;
+}
+
+// Resolves a function, false if the library doesn't export it (e.g. an older version).
+template <class Function>
+bool resolve(void* handle, Function& function, const char* name, std::string& missing) {
+	function = reinterpret_cast<Function>(symbol(handle, name));
+	if (function == nullptr && missing.empty()) {
+		missing = name;
+	}
+	return function != nullptr;
+}
+
+
+// load(path): loads the library (once per process), the binding relies on the negative error codes.
+napi_value Load(napi_env env, napi_callback_info info) {
+	napi_value args[1];
+	std::string path;
+	if (!arguments(env, info, 1, args, 1) || !toString(env, args[0], path)) {
+		return nullptr;
+	}
+	if (library.handle != nullptr) {
+		return nullptr;
+	}
+
+	void* handle = loadLibrary(path);
+	if (handle == nullptr) {
+		return fail(env, "Could not load " + path);
+	}
+	Library loaded;
+	std::string missing;
+	resolve(handle, loaded.setLegacyReturnCodes, "SetLegacyReturnCodes", missing);
+	resolve(handle, loaded.initializeStream, "InitializeStream", missing);
+	resolve(handle, loaded.createSessionWithCredentials, "CreateSessionWithCredentials", missing);
+	resolve(handle, loaded.reconfigure, "Reconfigure", missing);
+	resolve(handle, loaded.sendAudio, "SendAudio", missing);
+	resolve(handle, loaded.sendAudioWithTimestamp, "SendAudioWithTimestamp", missing);
+	resolve(handle, loaded.sendAudioBytes, "SendAudioBytes", missing);
+	resolve(handle, loaded.receiveTranscriptJSON, "ReceiveTranscriptJSON", missing);
+	resolve(handle, loaded.cancelPendingReceive, "CancelPendingReceive", missing);
+	resolve(handle, loaded.pollEvent, "PollEvent", missing);
+	resolve(handle, loaded.getStats, "GetStats", missing);
+	resolve(handle, loaded.getSessionID, "GetSessionID", missing);
+	resolve(handle, loaded.setSessionLabel, "SetSessionLabel", missing);
+	resolve(handle, loaded.ackResult, "AckResult", missing);
+	resolve(handle, loaded.isInitialized, "IsInitialized", missing);
+	resolve(handle, loaded.getLog, "GetLog", missing);
+	resolve(handle, loaded.getLastErrorJSON, "GetLastErrorJSON", missing);
+	resolve(handle, loaded.freeString, "FreeString", missing);
+	resolve(handle, loaded.closeStream, "CloseStream", missing);
+	resolve(handle, loaded.shutdown, "Shutdown", missing);
+	if (!missing.empty()) {
+		return fail(env, "The library doesn't export " + missing);
+	}
+
+	loaded.handle = handle;
+	library = loaded;
+	library.setLegacyReturnCodes(GO_SPEECH_RECOGNITION_FALSE);
+	return nullptr;
+}
+
+// initializeStream(language, sampleRate, model, maxAlternatives, interimResults): code
+napi_value InitializeStream(napi_env env, napi_callback_info info) {
+	napi_value args[5];
+	std::string language, model;
+	int sampleRate = 0, maxAlternatives = 0;
+	GO_SPEECH_RECOGNITION_BOOL interimResults;
+	if (!ensureLoaded(env) || !arguments(env, info, 5, args, 5) || !toString(env, args[0], language) || !toInt(env, args[1], sampleRate)
+		|| !toString(env, args[2], model) || !toInt(env, args[3], maxAlternatives) || !toBool(env, args[4], interimResults)) {
+		return nullptr;
+	}
+	return fromInt(env, library.initializeStream(&language[0], sampleRate, &model[0], maxAlternatives, interimResults));
+}
+
+// createSessionWithCredentials(credentials, quotaProject, language, sampleRate, model, maxAlternatives, interimResults): code
+napi_value CreateSessionWithCredentials(napi_env env, napi_callback_info info) {
+	napi_value args[7];
+	std::string credentials, quotaProject, language, model;
+	int sampleRate = 0, maxAlternatives = 0;
+	GO_SPEECH_RECOGNITION_BOOL interimResults;
+	if (!ensureLoaded(env) || !arguments(env, info, 7, args, 7) || !toString(env, args[0], credentials) || !toString(env, args[1], quotaProject)
+		|| !toString(env, args[2], language) || !toInt(env, args[3], sampleRate) || !toString(env, args[4], model)
+		|| !toInt(env, args[5], maxAlternatives) || !toBool(env, args[6], interimResults)) {
+		return nullptr;
+	}
+	return fromInt(env, library.createSessionWithCredentials(credentials.c_str(), quotaProject.c_str(), language.c_str(), sampleRate, model.c_str(), maxAlternatives, interimResults));
+}
+
+// reconfigure(language, sampleRate, model, maxAlternatives, interimResults): code
+napi_value Reconfigure(napi_env env, napi_callback_info info) {
+	napi_value args[5];
+	std::string language, model;
+	int sampleRate = 0, maxAlternatives = 0;
+	GO_SPEECH_RECOGNITION_BOOL interimResults;
+	if (!ensureLoaded(env) || !arguments(env, info, 5, args, 5) || !toString(env, args[0], language) || !toInt(env, args[1], sampleRate)
+		|| !toString(env, args[2], model) || !toInt(env, args[3], maxAlternatives) || !toBool(env, args[4], interimResults)) {
+		return nullptr;
+	}
+	return fromInt(env, library.reconfigure(&language[0], sampleRate, &model[0], maxAlternatives, interimResults));
+}
+
+// sendAudio(samples: Int16Array, timestampUs?): code
+napi_value SendAudio(napi_env env, napi_callback_info info) {
+	napi_value args[2] = { nullptr, nullptr };
+	void* data = nullptr;
+	size_t length = 0;
+	if (!ensureLoaded(env) || !arguments(env, info, 1, args, 2) || !toTypedArray(env, args[0], napi_int16_array, data, length)) {
+		return nullptr;
+	}
+	napi_valuetype type = napi_undefined;
+	if (args[1] != nullptr) {
+		napi_typeof(env, args[1], &type);
+	}
+	if (type == napi_undefined) {
+		return fromInt(env, library.sendAudio(static_cast<const short*>(data), static_cast<int>(length)));
+	}
+	long long timestampUs = 0;
+	if (!toInt64(env, args[1], timestampUs)) {
+		return nullptr;
+	}
+	return fromInt(env, library.sendAudioWithTimestamp(static_cast<const short*>(data), static_cast<int>(length), timestampUs));
+}
+
+// sendAudioBytes(data: Uint8Array): code
+napi_value SendAudioBytes(napi_env env, napi_callback_info info) {
+	napi_value args[1];
+	void* data = nullptr;
+	size_t length = 0;
+	if (!ensureLoaded(env) || !arguments(env, info, 1, args, 1) || !toTypedArray(env, args[0], napi_uint8_array, data, length)) {
+		return nullptr;
+	}
+	return fromInt(env, library.sendAudioBytes(static_cast<const uint8_t*>(data), static_cast<int>(length)));
+}
+
+// The state of a receive call on the thread pool
+struct ReceiveWork {
+	napi_async_work work = nullptr;
+	napi_deferred deferred = nullptr;
+	int code = 0;
+	std::string output;
+};
+
+void ExecuteReceive(napi_env, void* data) {
+	ReceiveWork* receive = static_cast<ReceiveWork*>(data);
+	char* output = nullptr;
+	receive->code = library.receiveTranscriptJSON(&output);
+	receive->output = take(output);
+}
+
+void CompleteReceive(napi_env env, napi_status, void* data) {
+	ReceiveWork* receive = static_cast<ReceiveWork*>(data);
+	napi_resolve_deferred(env, receive->deferred, fromOutput(env, receive->code, receive->output));
+	napi_delete_async_work(env, receive->work);
+	delete receive;
+}
+
+// receiveTranscriptJSON(): Promise<{ code, output }>, waits on the thread pool (see "CancelPendingReceive()")
+napi_value ReceiveTranscriptJSON(napi_env env, napi_callback_info) {
+	if (!ensureLoaded(env)) {
+		return nullptr;
+	}
+	ReceiveWork* receive = new ReceiveWork();
+	napi_value promise, name;
+	napi_create_promise(env, &receive->deferred, &promise);
+	napi_create_string_utf8(env, "gspeech:receive", NAPI_AUTO_LENGTH, &name);
+	napi_create_async_work(env, nullptr, name, ExecuteReceive, CompleteReceive, receive, &receive->work);
+	napi_queue_async_work(env, receive->work);
+	return promise;
+}
+
+// cancelPendingReceive()
+napi_value CancelPendingReceive(napi_env env, napi_callback_info) {
+	if (ensureLoaded(env)) {
+		library.cancelPendingReceive();
+	}
+	return nullptr;
+}
+
+// pollEvent(): the next event, 0 if none is pending
+napi_value PollEvent(napi_env env, napi_callback_info) {
+	if (!ensureLoaded(env)) {
+		return nullptr;
+	}
+	int event = 0;
+	if (library.pollEvent(&event) != GO_SPEECH_RECOGNITION_TRUE) {
+		event = 0;
+	}
+	return fromInt(env, event);
+}
+
+// getStats(): { code, output }
+napi_value GetStats(napi_env env, napi_callback_info) {
+	if (!ensureLoaded(env)) {
+		return nullptr;
+	}
+	char* output = nullptr;
+	int code = library.getStats(&output);
+	return fromOutput(env, code, take(output));
+}
+
+// getSessionId(): the ID of the session
+napi_value GetSessionID(napi_env env, napi_callback_info) {
+	if (!ensureLoaded(env)) {
+		return nullptr;
+	}
+	return fromString(env, take(library.getSessionID()));
+}
+
+// setSessionLabel(key, value): code
+napi_value SetSessionLabel(napi_env env, napi_callback_info info) {
+	napi_value args[2];
+	std::string key, value;
+	if (!ensureLoaded(env) || !arguments(env, info, 2, args, 2) || !toString(env, args[0], key) || !toString(env, args[1], value)) {
+		return nullptr;
+	}
+	return fromInt(env, library.setSessionLabel(key.c_str(), value.c_str()));
+}
+
+// ackResult(sequence): code
+napi_value AckResult(napi_env env, napi_callback_info info) {
+	napi_value args[1];
+	long long sequence = 0;
+	if (!ensureLoaded(env) || !arguments(env, info, 1, args, 1) || !toInt64(env, args[0], sequence)) {
+		return nullptr;
+	}
+	return fromInt(env, library.ackResult(sequence));
+}
+
+// isInitialized(): whether the session is running
+napi_value IsInitialized(napi_env env, napi_callback_info) {
+	if (!ensureLoaded(env)) {
+		return nullptr;
+	}
+	napi_value result;
+	napi_get_boolean(env, library.isInitialized() == GO_SPEECH_RECOGNITION_TRUE, &result);
+	return result;
+}
+
+// getLog(): the error log of the last failure
+napi_value GetLog(napi_env env, napi_callback_info) {
+	if (!ensureLoaded(env)) {
+		return nullptr;
+	}
+	return fromString(env, take(library.getLog()));
+}
+
+// getLastErrorJSON(): the last error as JSON
+napi_value GetLastErrorJSON(napi_env env, napi_callback_info) {
+	if (!ensureLoaded(env)) {
+		return nullptr;
+	}
+	return fromString(env, take(library.getLastErrorJSON()));
+}
+
+// closeStream(): closes the session, a pending receive call resolves
+napi_value CloseStream(napi_env env, napi_callback_info) {
+	if (ensureLoaded(env)) {
+		library.closeStream();
+	}
+	return nullptr;
+}
+
+// shutdown(): releases everything the library holds
+napi_value Shutdown(napi_env env, napi_callback_info) {
+	if (ensureLoaded(env)) {
+		library.shutdown();
+	}
+	return nullptr;
+}
+
+napi_value Init(napi_env env, napi_value exports) {
+	napi_property_descriptor properties[] = {
+		{ "load", nullptr, Load, nullptr, nullptr, nullptr, napi_default, nullptr },
+		{ "initializeStream", nullptr, InitializeStream, nullptr, nullptr, nullptr, napi_default, nullptr },
+		{ "createSessionWithCredentials", nullptr, CreateSessionWithCredentials, nullptr, nullptr, nullptr, napi_default, nullptr },
+		{ "reconfigure", nullptr, Reconfigure, nullptr, nullptr, nullptr, napi_default, nullptr },
+		{ "sendAudio", nullptr, SendAudio, nullptr, nullptr, nullptr, napi_default, nullptr },
+		{ "sendAudioBytes", nullptr, SendAudioBytes, nullptr, nullptr, nullptr, napi_default, nullptr },
+		{ "receiveTranscriptJSON", nullptr, ReceiveTranscriptJSON, nullptr, nullptr, nullptr, napi_default, nullptr },
+		{ "cancelPendingReceive", nullptr, CancelPendingReceive, nullptr, nullptr, nullptr, napi_default, nullptr },
+		{ "pollEvent", nullptr, PollEvent, nullptr, nullptr, nullptr, napi_default, nullptr },
+		{ "getStats", nullptr, GetStats, nullptr, nullptr, nullptr, napi_default, nullptr },
+		{ "getSessionId", nullptr, GetSessionID, nullptr, nullptr, nullptr, napi_default, nullptr },
+		{ "setSessionLabel", nullptr, SetSessionLabel, nullptr, nullptr, nullptr, napi_default, nullptr },
+		{ "ackResult", nullptr, AckResult, nullptr, nullptr, nullptr, napi_default, nullptr },
+		{ "isInitialized", nullptr, IsInitialized, nullptr, nullptr, nullptr, napi_default, nullptr },
+		{ "getLog", nullptr, GetLog, nullptr, nullptr, nullptr, napi_default, nullptr },
+		{ "getLastErrorJSON", nullptr, GetLastErrorJSON, nullptr, nullptr, nullptr, napi_default, nullptr },
+		{ "closeStream", nullptr, CloseStream, nullptr, nullptr, nullptr, napi_default, nullptr },
+		{ "shutdown", nullptr, Shutdown, nullptr, nullptr, nullptr, napi_default, nullptr },
+	};
+	napi_define_properties(env, exports, sizeof(properties) / sizeof(properties[0]), properties);
+	return exports;
+}
+
+}
+
+NAPI_MODULE(NODE_GYP_MODULE_NAME, Init)
@@ -0,0 +1,11 @@
+"""
+Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+Python binding of the go-speech-recognition library (ctypes, no compiler needed).
+The library is loaded from the GSPEECH_LIBRARY environment variable or found by its name
+(go-speech-recognition.dll, libgo-speech-recognition.so, ...).
+"""
+
+from .session import Event, Result, Session, SpeechError, last_log, shutdown
+
+__all__ = ["Event", "Result", "Session", "SpeechError", "last_log", "shutdown"]
@@ -0,0 +1,81 @@
+"""
+Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+Loads the library and declares the signatures of its exports (see go-speech-recognition.h).
+Strings returned by the library are declared as c_void_p, so they can be released with FreeString after copying them.
+"""
+
+import ctypes
+import ctypes.util
+import os
+import sys
+
+_library = None
+
+
+def _find_library():
+    path = os.environ.get("GSPEECH_LIBRARY")
+    if path:
+        return path
+    if sys.platform == "win32":
+        return "go-speech-recognition.dll"
+    found = ctypes.util.find_library("go-speech-recognition")
+    if found:
+        return found
+    return "libgo-speech-recognition.dylib" if sys.platform == "darwin" else "libgo-speech-recognition.so"
+
+
+def _declare(library, name, restype, *argtypes):
+    function = getattr(library, name)
+    function.restype = restype
+    function.argtypes = list(argtypes)
+
+
+def library():
+    """Returns the loaded library (loaded by the first call)."""
+    global _library
+    if _library is not None:
+        return _library
+
+    loaded = ctypes.CDLL(_find_library())
+    c_int, c_char_p, c_void_p, c_longlong = ctypes.c_int, ctypes.c_char_p, ctypes.c_void_p, ctypes.c_longlong
+    output = ctypes.POINTER(c_void_p)
+
+    _declare(loaded, "SetLegacyReturnCodes", None, c_int)
+    _declare(loaded, "InitializeStream", c_int, c_char_p, c_int, c_char_p, c_int, c_int)
+    _declare(loaded, "CreateSessionWithCredentials", c_int, c_char_p, c_char_p, c_char_p, c_int, c_char_p, c_int, c_int)
+    _declare(loaded, "Reconfigure", c_int, c_char_p, c_int, c_char_p, c_int, c_int)
+    _declare(loaded, "SendAudio", c_int, ctypes.POINTER(ctypes.c_short), c_int)
+    _declare(loaded, "SendAudioWithTimestamp", c_int, ctypes.POINTER(ctypes.c_short), c_int, c_longlong)
+    _declare(loaded, "SendAudioBytes", c_int, ctypes.POINTER(ctypes.c_uint8), c_int)
+    _declare(loaded, "ReceiveTranscript", c_int, output)
+    _declare(loaded, "ReceiveTranscriptJSON", c_int, output)
+    _declare(loaded, "CancelPendingReceive", None)
+    _declare(loaded, "PollEvent", c_int, ctypes.POINTER(c_int))
+    _declare(loaded, "GetStats", c_int, output)
+    _declare(loaded, "GetSessionID", c_void_p)
+    _declare(loaded, "SetSessionLabel", c_int, c_char_p, c_char_p)
+    _declare(loaded, "AckResult", c_int, c_longlong)
+    _declare(loaded, "GetLastResultSequence", c_longlong)
+    _declare(loaded, "IsInitialized", c_int)
+    _declare(loaded, "GetLog", c_void_p)
+    _declare(loaded, "GetLastErrorJSON", c_void_p)
+    _declare(loaded, "FreeString", None, c_void_p)
+    _declare(loaded, "CloseStream", None)
+    _declare(loaded, "Shutdown", None)
+
+    # The binding relies on the negative error codes.
+    loaded.SetLegacyReturnCodes(0)
+
+    _library = loaded
+    return _library
+
+
+def take(value):
+    """Copies a string returned by the library and releases it."""
+    if not value:
+        return ""
+    try:
+        return ctypes.string_at(value).decode("utf-8", errors="replace")
+    finally:
+        library().FreeString(value)
@@ -0,0 +1,215 @@
+"""
+Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+The streaming session of the library. The library runs one session at a time, opening a session closes the running one.
+"""
+
+import array
+import ctypes
+import enum
+
+from ._native import library, take
+
+
+class Result(enum.IntEnum):
+    """GO_SPEECH_RECOGNITION_RESULT"""
+    OK = 0
+    ERROR = -1
+    NOT_INITIALIZED = -2
+    INVALID_ARGUMENT = -3
+    FINALIZED = -4
+    QUEUE_FULL = -5
+    STREAM_ENDED = -6
+    BUDGET_EXCEEDED = -7
+    CANCELED = -8
+    RATE_LIMITED = -9
+    QUOTA_EXCEEDED = -10
+    WRONG_THREAD = -11
+    BUSY = -12
+    MODEL_UNAVAILABLE = -13
+
+
+class Event(enum.IntEnum):
+    """GO_SPEECH_RECOGNITION_EVENT"""
+    SILENCE_TIMEOUT = 1
+    MAX_DURATION = 2
+    BUDGET_EXCEEDED = 3
+    CLIPPING = 4
+    DC_OFFSET = 5
+    NEAR_SILENCE = 6
+    QUOTA_EXCEEDED = 7
+    OFFLINE = 8
+    BACKFILLED = 9
+    FORMAT_MISMATCH = 10
+    WAKE_WORD = 11
+    WAKE_WORD_REARMED = 12
+
+
+class SpeechError(Exception):
+    """A failed call of the library with its result code and the error log ("GetLog()")."""
+
+    def __init__(self, code, message):
+        super().__init__(message)
+        try:
+            self.result = Result(code)
+        except ValueError:
+            self.result = code
+
+
+def last_log():
+    """The error log of the last failure (see "GetLog()")."""
+    return take(library().GetLog())
+
+
+def shutdown():
+    """Releases everything the library holds (see "Shutdown()")."""
+    library().Shutdown()
+
+
+def _check(code):
+    if code != Result.OK:
+        raise SpeechError(code, last_log())
+
+
+def _encode(value):
+    return (value or "").encode("utf-8")
+
+
+class Session:
+    """
+    The streaming session (see "InitializeStream()"), closed by close() or when leaving the with block:
+
+        with gspeech.Session("de-DE", interim_results=True) as session:
+            session.send_audio(samples)
+            for transcript in session.transcripts():
+                print(transcript)
+    """
+
+    def __init__(self, language="en-US", sample_rate=16000, model="default", max_alternatives=1,
+                 interim_results=False, credentials=None, quota_project=None):
+        native = library()
+        interim = 1 if interim_results else 0
+        if credentials is None and quota_project is None:
+            code = native.InitializeStream(_encode(language), sample_rate, _encode(model), max_alternatives, interim)
+        else:
+            code = native.CreateSessionWithCredentials(_encode(credentials), _encode(quota_project), _encode(language),
+                                                       sample_rate, _encode(model), max_alternatives, interim)
+        _check(code)
+        self._open = True
+
+    def __enter__(self):
+        return self
+
+    def __exit__(self, *exc):
+        self.close()
+        return False
+
+    def __del__(self):
+        self.close()
+
+    @property
+    def is_open(self):
+        return self._open and library().IsInitialized() == 1
+
+    @property
+    def id(self):
+        """The ID of the session (see "GetSessionID()")."""
+        return take(library().GetSessionID())
+
+    @property
+    def last_result_sequence(self):
+        """The sequence number of the last received result (see "GetLastResultSequence()")."""
+        return library().GetLastResultSequence()
+
+    def close(self):
+        """Closes the session (see "CloseStream()")."""
+        if getattr(self, "_open", False):
+            self._open = False
+            library().CloseStream()
+
+    def send_audio(self, samples, timestamp_us=None):
+        """
+        Sends 16 bit PCM samples: a sequence of ints, an array("h") or bytes of little endian samples
+        (see "SendAudio()" and "SendAudioWithTimestamp()").
+        """
+        if isinstance(samples, (bytes, bytearray, memoryview)):
+            buffer = array.array("h")
+            buffer.frombytes(bytes(samples))
+        elif isinstance(samples, array.array) and samples.typecode == "h":
+            buffer = samples
+        else:
+            buffer = array.array("h", samples)
+        if len(buffer) == 0:
+            return
+
+        pointer = (ctypes.c_short * len(buffer)).from_buffer(buffer)
+        if timestamp_us is None:
+            _check(library().SendAudio(pointer, len(buffer)))
+        else:
+            _check(library().SendAudioWithTimestamp(pointer, len(buffer), timestamp_us))
+
+    def send_audio_bytes(self, data):
+        """Sends encoded audio in the encoding of "SetAudioEncoding()" (see "SendAudioBytes()")."""
+        data = bytes(data)
+        if not data:
+            return
+        buffer = (ctypes.c_uint8 * len(data)).from_buffer_copy(data)
+        _check(library().SendAudioBytes(buffer, len(data)))
+
+    def receive_transcript(self):
+        """Waits for the next transcript (see "ReceiveTranscript()"), the alternatives are separated by ';'."""
+        return self._receive(library().ReceiveTranscript)
+
+    def receive_transcript_json(self):
+        """Waits for the next results as JSON (see "ReceiveTranscriptJSON()")."""
+        return self._receive(library().ReceiveTranscriptJSON)
+
+    def transcripts(self):
+        """Yields the transcripts until the session ends (responses without results are skipped)."""
+        while self.is_open:
+            try:
+                transcript = self.receive_transcript()
+            except SpeechError as error:
+                if error.result in (Result.STREAM_ENDED, Result.CANCELED):
+                    return
+                raise
+            if transcript:
+                yield transcript
+
+    def cancel_receive(self):
+        """Aborts a blocking receive call of another thread (see "CancelPendingReceive()")."""
+        library().CancelPendingReceive()
+
+    def reconfigure(self, language, sample_rate, model, max_alternatives, interim_results):
+        """Switches the configuration of the running session (see "Reconfigure()")."""
+        _check(library().Reconfigure(_encode(language), sample_rate, _encode(model), max_alternatives, 1 if interim_results else 0))
+
+    def ack(self, sequence):
+        """Acknowledges a final result (see "AckResult()")."""
+        _check(library().AckResult(sequence))
+
+    def set_label(self, key, value):
+        """Attaches a label to the session (see "SetSessionLabel()")."""
+        _check(library().SetSessionLabel(_encode(key), _encode(value)))
+
+    def poll_event(self):
+        """Returns the next event or None if none is pending (see "PollEvent()")."""
+        event = ctypes.c_int()
+        if library().PollEvent(ctypes.byref(event)) != 1:
+            return None
+        try:
+            return Event(event.value)
+        except ValueError:
+            return event.value
+
+    def stats(self):
+        """The statistics of the session as JSON (see "GetStats()")."""
+        return self._receive(library().GetStats)
+
+    @staticmethod
+    def _receive(function):
+        output = ctypes.c_void_p()
+        code = function(ctypes.byref(output))
+        value = take(output.value)
+        _check(code)
+        return value
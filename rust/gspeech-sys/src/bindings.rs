@@ -0,0 +1,754 @@
+/* Bindings of go-speech-recognition.h in the layout of bindgen, regenerate them after changes of the header with:
+bindgen ../../go-speech-recognition.h --no-prepend-enum-name --no-layout-tests --allowlist-type "GO_SPEECH_RECOGNITION_.*" --allowlist-var "GO_SPEECH_RECOGNITION_.*" -o src/bindings.rs -- -x c++ */
+
+pub const GO_SPEECH_RECOGNITION_TRUE: GO_SPEECH_RECOGNITION_BOOL = 1;
+pub const GO_SPEECH_RECOGNITION_FALSE: GO_SPEECH_RECOGNITION_BOOL = 0;
+pub type GO_SPEECH_RECOGNITION_BOOL = ::std::os::raw::c_uint;
+pub const GO_SPEECH_RECOGNITION_OK: GO_SPEECH_RECOGNITION_RESULT = 0;
+pub const GO_SPEECH_RECOGNITION_ERROR: GO_SPEECH_RECOGNITION_RESULT = -1;
+pub const GO_SPEECH_RECOGNITION_ERROR_NOT_INITIALIZED: GO_SPEECH_RECOGNITION_RESULT = -2;
+pub const GO_SPEECH_RECOGNITION_ERROR_INVALID_ARGUMENT: GO_SPEECH_RECOGNITION_RESULT = -3;
+pub const GO_SPEECH_RECOGNITION_ERROR_FINALIZED: GO_SPEECH_RECOGNITION_RESULT = -4;
+pub const GO_SPEECH_RECOGNITION_ERROR_QUEUE_FULL: GO_SPEECH_RECOGNITION_RESULT = -5;
+pub const GO_SPEECH_RECOGNITION_ERROR_STREAM_ENDED: GO_SPEECH_RECOGNITION_RESULT = -6;
+pub const GO_SPEECH_RECOGNITION_ERROR_BUDGET_EXCEEDED: GO_SPEECH_RECOGNITION_RESULT = -7;
+pub const GO_SPEECH_RECOGNITION_ERROR_CANCELED: GO_SPEECH_RECOGNITION_RESULT = -8;
+pub const GO_SPEECH_RECOGNITION_ERROR_RATE_LIMITED: GO_SPEECH_RECOGNITION_RESULT = -9;
+pub const GO_SPEECH_RECOGNITION_ERROR_QUOTA_EXCEEDED: GO_SPEECH_RECOGNITION_RESULT = -10;
+pub const GO_SPEECH_RECOGNITION_ERROR_WRONG_THREAD: GO_SPEECH_RECOGNITION_RESULT = -11;
+pub const GO_SPEECH_RECOGNITION_ERROR_BUSY: GO_SPEECH_RECOGNITION_RESULT = -12;
+pub const GO_SPEECH_RECOGNITION_ERROR_MODEL_UNAVAILABLE: GO_SPEECH_RECOGNITION_RESULT = -13;
+pub type GO_SPEECH_RECOGNITION_RESULT = ::std::os::raw::c_int;
+pub const GO_SPEECH_RECOGNITION_OVERFLOW_BLOCK: GO_SPEECH_RECOGNITION_OVERFLOW_POLICY = 0;
+pub const GO_SPEECH_RECOGNITION_OVERFLOW_DROP_OLDEST: GO_SPEECH_RECOGNITION_OVERFLOW_POLICY = 1;
+pub const GO_SPEECH_RECOGNITION_OVERFLOW_DROP_NEWEST: GO_SPEECH_RECOGNITION_OVERFLOW_POLICY = 2;
+pub const GO_SPEECH_RECOGNITION_OVERFLOW_ERROR: GO_SPEECH_RECOGNITION_OVERFLOW_POLICY = 3;
+pub type GO_SPEECH_RECOGNITION_OVERFLOW_POLICY = ::std::os::raw::c_uint;
+pub const GO_SPEECH_RECOGNITION_EVENT_SILENCE_TIMEOUT: GO_SPEECH_RECOGNITION_EVENT = 1;
+pub const GO_SPEECH_RECOGNITION_EVENT_MAX_DURATION: GO_SPEECH_RECOGNITION_EVENT = 2;
+pub const GO_SPEECH_RECOGNITION_EVENT_BUDGET_EXCEEDED: GO_SPEECH_RECOGNITION_EVENT = 3;
+pub const GO_SPEECH_RECOGNITION_EVENT_CLIPPING: GO_SPEECH_RECOGNITION_EVENT = 4;
+pub const GO_SPEECH_RECOGNITION_EVENT_DC_OFFSET: GO_SPEECH_RECOGNITION_EVENT = 5;
+pub const GO_SPEECH_RECOGNITION_EVENT_NEAR_SILENCE: GO_SPEECH_RECOGNITION_EVENT = 6;
+pub const GO_SPEECH_RECOGNITION_EVENT_QUOTA_EXCEEDED: GO_SPEECH_RECOGNITION_EVENT = 7;
+pub const GO_SPEECH_RECOGNITION_EVENT_OFFLINE: GO_SPEECH_RECOGNITION_EVENT = 8;
+pub const GO_SPEECH_RECOGNITION_EVENT_BACKFILLED: GO_SPEECH_RECOGNITION_EVENT = 9;
+pub const GO_SPEECH_RECOGNITION_EVENT_FORMAT_MISMATCH: GO_SPEECH_RECOGNITION_EVENT = 10;
+pub const GO_SPEECH_RECOGNITION_EVENT_WAKE_WORD: GO_SPEECH_RECOGNITION_EVENT = 11;
+pub const GO_SPEECH_RECOGNITION_EVENT_WAKE_WORD_REARMED: GO_SPEECH_RECOGNITION_EVENT = 12;
+pub type GO_SPEECH_RECOGNITION_EVENT = ::std::os::raw::c_uint;
+pub const GO_SPEECH_RECOGNITION_RAW_JSON: GO_SPEECH_RECOGNITION_RAW_FORMAT = 0;
+pub const GO_SPEECH_RECOGNITION_RAW_PROTOBUF: GO_SPEECH_RECOGNITION_RAW_FORMAT = 1;
+pub type GO_SPEECH_RECOGNITION_RAW_FORMAT = ::std::os::raw::c_uint;
+pub const GO_SPEECH_RECOGNITION_FORMAT_LOWERCASE: GO_SPEECH_RECOGNITION_FORMATTING = 1;
+pub const GO_SPEECH_RECOGNITION_FORMAT_SENTENCE_CASE: GO_SPEECH_RECOGNITION_FORMATTING = 2;
+pub const GO_SPEECH_RECOGNITION_FORMAT_STRIP_FILLERS: GO_SPEECH_RECOGNITION_FORMATTING = 4;
+pub const GO_SPEECH_RECOGNITION_FORMAT_COLLAPSE_REPEATS: GO_SPEECH_RECOGNITION_FORMATTING = 8;
+pub type GO_SPEECH_RECOGNITION_FORMATTING = ::std::os::raw::c_uint;
+pub const GO_SPEECH_RECOGNITION_NUMBERS_AS_DELIVERED: GO_SPEECH_RECOGNITION_NUMBER_FORMATTING = 0;
+pub const GO_SPEECH_RECOGNITION_NUMBERS_DIGITS: GO_SPEECH_RECOGNITION_NUMBER_FORMATTING = 1;
+pub const GO_SPEECH_RECOGNITION_NUMBERS_VERBATIM: GO_SPEECH_RECOGNITION_NUMBER_FORMATTING = 2;
+pub type GO_SPEECH_RECOGNITION_NUMBER_FORMATTING = ::std::os::raw::c_uint;
+pub const GO_SPEECH_RECOGNITION_UTTERANCE_STARTED: GO_SPEECH_RECOGNITION_UTTERANCE_EVENT = 1;
+pub const GO_SPEECH_RECOGNITION_UTTERANCE_UPDATED: GO_SPEECH_RECOGNITION_UTTERANCE_EVENT = 2;
+pub const GO_SPEECH_RECOGNITION_UTTERANCE_FINALIZED: GO_SPEECH_RECOGNITION_UTTERANCE_EVENT = 3;
+pub const GO_SPEECH_RECOGNITION_UTTERANCE_ABORTED: GO_SPEECH_RECOGNITION_UTTERANCE_EVENT = 4;
+pub type GO_SPEECH_RECOGNITION_UTTERANCE_EVENT = ::std::os::raw::c_uint;
+pub const GO_SPEECH_RECOGNITION_SPEECH_END_OF_SINGLE_UTTERANCE: GO_SPEECH_RECOGNITION_SPEECH_EVENT =
+    1;
+pub const GO_SPEECH_RECOGNITION_SPEECH_ACTIVITY_BEGIN: GO_SPEECH_RECOGNITION_SPEECH_EVENT = 2;
+pub const GO_SPEECH_RECOGNITION_SPEECH_ACTIVITY_END: GO_SPEECH_RECOGNITION_SPEECH_EVENT = 3;
+pub const GO_SPEECH_RECOGNITION_SPEECH_ACTIVITY_TIMEOUT: GO_SPEECH_RECOGNITION_SPEECH_EVENT = 4;
+pub type GO_SPEECH_RECOGNITION_SPEECH_EVENT = ::std::os::raw::c_uint;
+pub const GO_SPEECH_RECOGNITION_ENCODING_LINEAR16: GO_SPEECH_RECOGNITION_ENCODING = 1;
+pub const GO_SPEECH_RECOGNITION_ENCODING_FLAC: GO_SPEECH_RECOGNITION_ENCODING = 2;
+pub const GO_SPEECH_RECOGNITION_ENCODING_MULAW: GO_SPEECH_RECOGNITION_ENCODING = 3;
+pub const GO_SPEECH_RECOGNITION_ENCODING_AMR: GO_SPEECH_RECOGNITION_ENCODING = 4;
+pub const GO_SPEECH_RECOGNITION_ENCODING_AMR_WB: GO_SPEECH_RECOGNITION_ENCODING = 5;
+pub const GO_SPEECH_RECOGNITION_ENCODING_OGG_OPUS: GO_SPEECH_RECOGNITION_ENCODING = 6;
+pub const GO_SPEECH_RECOGNITION_ENCODING_SPEEX_WITH_HEADER_BYTE: GO_SPEECH_RECOGNITION_ENCODING = 7;
+pub type GO_SPEECH_RECOGNITION_ENCODING = ::std::os::raw::c_uint;
+pub const GO_SPEECH_RECOGNITION_AUDIO_SOURCE_UNKNOWN: GO_SPEECH_RECOGNITION_AUDIO_SOURCE = 0;
+pub const GO_SPEECH_RECOGNITION_AUDIO_SOURCE_MICROPHONE: GO_SPEECH_RECOGNITION_AUDIO_SOURCE = 1;
+pub const GO_SPEECH_RECOGNITION_AUDIO_SOURCE_TELEPHONE: GO_SPEECH_RECOGNITION_AUDIO_SOURCE = 2;
+pub const GO_SPEECH_RECOGNITION_AUDIO_SOURCE_MEDIA: GO_SPEECH_RECOGNITION_AUDIO_SOURCE = 3;
+pub type GO_SPEECH_RECOGNITION_AUDIO_SOURCE = ::std::os::raw::c_uint;
+pub const GO_SPEECH_RECOGNITION_DISPATCH_IMMEDIATE: GO_SPEECH_RECOGNITION_CALLBACK_DISPATCH = 0;
+pub const GO_SPEECH_RECOGNITION_DISPATCH_QUEUED: GO_SPEECH_RECOGNITION_CALLBACK_DISPATCH = 1;
+pub type GO_SPEECH_RECOGNITION_CALLBACK_DISPATCH = ::std::os::raw::c_uint;
+pub const GO_SPEECH_RECOGNITION_PARTY_LOCAL: GO_SPEECH_RECOGNITION_PARTY = 0;
+pub const GO_SPEECH_RECOGNITION_PARTY_REMOTE: GO_SPEECH_RECOGNITION_PARTY = 1;
+pub type GO_SPEECH_RECOGNITION_PARTY = ::std::os::raw::c_uint;
+pub const GO_SPEECH_RECOGNITION_TEST_SIGNAL_TONE: GO_SPEECH_RECOGNITION_TEST_SIGNAL = 0;
+pub const GO_SPEECH_RECOGNITION_TEST_SIGNAL_DIGITS: GO_SPEECH_RECOGNITION_TEST_SIGNAL = 1;
+pub type GO_SPEECH_RECOGNITION_TEST_SIGNAL = ::std::os::raw::c_uint;
+pub const GO_SPEECH_RECOGNITION_SYSTEM_LOG_NONE: GO_SPEECH_RECOGNITION_SYSTEM_LOG = 0;
+pub const GO_SPEECH_RECOGNITION_SYSTEM_LOG_EVENT_LOG: GO_SPEECH_RECOGNITION_SYSTEM_LOG = 1;
+pub const GO_SPEECH_RECOGNITION_SYSTEM_LOG_SYSLOG: GO_SPEECH_RECOGNITION_SYSTEM_LOG = 2;
+pub type GO_SPEECH_RECOGNITION_SYSTEM_LOG = ::std::os::raw::c_uint;
+pub const GO_SPEECH_RECOGNITION_TURN_IDLE: GO_SPEECH_RECOGNITION_TURN_STATE = 0;
+pub const GO_SPEECH_RECOGNITION_TURN_LISTENING: GO_SPEECH_RECOGNITION_TURN_STATE = 1;
+pub const GO_SPEECH_RECOGNITION_TURN_THINKING: GO_SPEECH_RECOGNITION_TURN_STATE = 2;
+pub const GO_SPEECH_RECOGNITION_TURN_SPEAKING: GO_SPEECH_RECOGNITION_TURN_STATE = 3;
+pub type GO_SPEECH_RECOGNITION_TURN_STATE = ::std::os::raw::c_uint;
+pub const GO_SPEECH_RECOGNITION_NORMALIZATION_NONE: GO_SPEECH_RECOGNITION_NORMALIZATION = 0;
+pub const GO_SPEECH_RECOGNITION_NORMALIZATION_NFC: GO_SPEECH_RECOGNITION_NORMALIZATION = 1;
+pub const GO_SPEECH_RECOGNITION_NORMALIZATION_NFD: GO_SPEECH_RECOGNITION_NORMALIZATION = 2;
+pub type GO_SPEECH_RECOGNITION_NORMALIZATION = ::std::os::raw::c_uint;
+#[repr(C)]
+#[derive(Debug, Copy, Clone)]
+pub struct GO_SPEECH_RECOGNITION_ALTERNATIVE {
+    pub transcript: *mut ::std::os::raw::c_char,
+    pub confidence: f32,
+}
+pub type GO_SPEECH_RECOGNITION_SET_LEGACY_RETURN_CODES =
+    ::std::option::Option<unsafe extern "C" fn(cLegacy: GO_SPEECH_RECOGNITION_BOOL)>;
+pub type GO_SPEECH_RECOGNITION_INITIALIZE_STREAM = ::std::option::Option<
+    unsafe extern "C" fn(
+        cTranscriptLanguage: *mut ::std::os::raw::c_char,
+        cSampleRate: ::std::os::raw::c_int,
+        cTranscriptionModel: *mut ::std::os::raw::c_char,
+        cMaxAlternatives: ::std::os::raw::c_int,
+        cInterimResults: GO_SPEECH_RECOGNITION_BOOL,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_RECONFIGURE = ::std::option::Option<
+    unsafe extern "C" fn(
+        cTranscriptLanguage: *mut ::std::os::raw::c_char,
+        cSampleRate: ::std::os::raw::c_int,
+        cTranscriptionModel: *mut ::std::os::raw::c_char,
+        cMaxAlternatives: ::std::os::raw::c_int,
+        cInterimResults: GO_SPEECH_RECOGNITION_BOOL,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_SINGLE_UTTERANCE =
+    ::std::option::Option<unsafe extern "C" fn(cSingleUtterance: GO_SPEECH_RECOGNITION_BOOL)>;
+pub type GO_SPEECH_RECOGNITION_SET_NEXT_UTTERANCE_LANGUAGE = ::std::option::Option<
+    unsafe extern "C" fn(
+        cTranscriptLanguage: *mut ::std::os::raw::c_char,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SEND_AUDIO = ::std::option::Option<
+    unsafe extern "C" fn(
+        recording: *const ::std::os::raw::c_short,
+        recording_size: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_RECEIVE_TRANSCRIPT = ::std::option::Option<
+    unsafe extern "C" fn(arg1: *mut *mut ::std::os::raw::c_char) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_GET_LOG =
+    ::std::option::Option<unsafe extern "C" fn() -> *mut ::std::os::raw::c_char>;
+pub type GO_SPEECH_RECOGNITION_FREE_STRING =
+    ::std::option::Option<unsafe extern "C" fn(cString: *mut ::std::os::raw::c_char)>;
+pub type GO_SPEECH_RECOGNITION_CLOSE_STREAM = ::std::option::Option<unsafe extern "C" fn()>;
+pub type GO_SPEECH_RECOGNITION_IS_INITIALIZED =
+    ::std::option::Option<unsafe extern "C" fn() -> GO_SPEECH_RECOGNITION_BOOL>;
+pub type GO_SPEECH_RECOGNITION_SET_OVERFLOW_POLICY = ::std::option::Option<
+    unsafe extern "C" fn(
+        cPolicy: GO_SPEECH_RECOGNITION_OVERFLOW_POLICY,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_GET_STATS = ::std::option::Option<
+    unsafe extern "C" fn(arg1: *mut *mut ::std::os::raw::c_char) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_GET_QUEUE_DEPTHS = ::std::option::Option<
+    unsafe extern "C" fn(
+        audioMs: *mut ::std::os::raw::c_int,
+        results: *mut ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_KEEP_ALIVE = ::std::option::Option<
+    unsafe extern "C" fn(
+        cEnabled: GO_SPEECH_RECOGNITION_BOOL,
+        cThresholdMs: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_NO_SPEECH_TIMEOUT = ::std::option::Option<
+    unsafe extern "C" fn(cSeconds: ::std::os::raw::c_int) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_POLL_EVENT = ::std::option::Option<
+    unsafe extern "C" fn(event: *mut ::std::os::raw::c_int) -> GO_SPEECH_RECOGNITION_BOOL,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_MAX_SESSION_DURATION = ::std::option::Option<
+    unsafe extern "C" fn(cSeconds: ::std::os::raw::c_int) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_GET_BILLED_SECONDS_ESTIMATE = ::std::option::Option<
+    unsafe extern "C" fn(session: *mut f64, today: *mut f64) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_GET_BILLED_TIME = ::std::option::Option<
+    unsafe extern "C" fn(session: *mut f64, batches: *mut f64) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_BILLED_SECONDS_BUDGET = ::std::option::Option<
+    unsafe extern "C" fn(cSeconds: ::std::os::raw::c_int) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_RATE_LIMITS = ::std::option::Option<
+    unsafe extern "C" fn(
+        cMaxConcurrent: ::std::os::raw::c_int,
+        cRequestsPerMinute: ::std::os::raw::c_int,
+        cQueueTimeoutMs: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_IS_RETRYABLE_CODE = ::std::option::Option<
+    unsafe extern "C" fn(cCode: ::std::os::raw::c_int) -> GO_SPEECH_RECOGNITION_BOOL,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_RETRYABLE_CODE = ::std::option::Option<
+    unsafe extern "C" fn(
+        cCode: ::std::os::raw::c_int,
+        cRetryable: GO_SPEECH_RECOGNITION_BOOL,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_GET_LAST_ERROR_JSON =
+    ::std::option::Option<unsafe extern "C" fn() -> *mut ::std::os::raw::c_char>;
+pub type GO_SPEECH_RECOGNITION_GET_LAST_RESPONSE_RAW = ::std::option::Option<
+    unsafe extern "C" fn(
+        cFormat: GO_SPEECH_RECOGNITION_RAW_FORMAT,
+        output: *mut *mut ::std::os::raw::c_char,
+        outputLength: *mut ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_GET_SESSION_ID =
+    ::std::option::Option<unsafe extern "C" fn() -> *mut ::std::os::raw::c_char>;
+pub type GO_SPEECH_RECOGNITION_GET_SESSION_INFO = ::std::option::Option<
+    unsafe extern "C" fn(output: *mut *mut ::std::os::raw::c_char) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_SNAPSHOT_INTERVAL = ::std::option::Option<
+    unsafe extern "C" fn(cSeconds: ::std::os::raw::c_int) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_GET_SESSION_SNAPSHOT = ::std::option::Option<
+    unsafe extern "C" fn(output: *mut *mut ::std::os::raw::c_char) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_RESTORE_SESSION = ::std::option::Option<
+    unsafe extern "C" fn(cSnapshot: *const ::std::os::raw::c_char) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_SESSION_LABEL = ::std::option::Option<
+    unsafe extern "C" fn(
+        cKey: *const ::std::os::raw::c_char,
+        cValue: *const ::std::os::raw::c_char,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_CLEAR_SESSION_LABELS = ::std::option::Option<unsafe extern "C" fn()>;
+pub type GO_SPEECH_RECOGNITION_ENABLE_TRACING =
+    ::std::option::Option<unsafe extern "C" fn(cEnabled: GO_SPEECH_RECOGNITION_BOOL)>;
+pub type GO_SPEECH_RECOGNITION_ENABLE_OTLP_EXPORT = ::std::option::Option<
+    unsafe extern "C" fn(cEndpoint: *mut ::std::os::raw::c_char) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_DISABLE_OTLP_EXPORT = ::std::option::Option<unsafe extern "C" fn()>;
+pub type GO_SPEECH_RECOGNITION_SHUTDOWN = ::std::option::Option<unsafe extern "C" fn()>;
+pub type GO_SPEECH_RECOGNITION_INITIALIZE_LIBRARY = ::std::option::Option<unsafe extern "C" fn()>;
+pub type GO_SPEECH_RECOGNITION_CANCEL_PENDING_RECEIVE =
+    ::std::option::Option<unsafe extern "C" fn()>;
+pub type GO_SPEECH_RECOGNITION_CANCEL_PENDING_SEND = ::std::option::Option<unsafe extern "C" fn()>;
+pub type GO_SPEECH_RECOGNITION_GET_SESSION_LOG = ::std::option::Option<
+    unsafe extern "C" fn(cSessionID: *mut ::std::os::raw::c_char) -> *mut ::std::os::raw::c_char,
+>;
+pub type GO_SPEECH_RECOGNITION_GET_GLOBAL_LOG =
+    ::std::option::Option<unsafe extern "C" fn() -> *mut ::std::os::raw::c_char>;
+pub type GO_SPEECH_RECOGNITION_SET_SPEAKER_DIARIZATION = ::std::option::Option<
+    unsafe extern "C" fn(
+        cEnabled: GO_SPEECH_RECOGNITION_BOOL,
+        cMinSpeakers: ::std::os::raw::c_int,
+        cMaxSpeakers: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_GET_SPEAKER_TRANSCRIPT = ::std::option::Option<
+    unsafe extern "C" fn(arg1: *mut *mut ::std::os::raw::c_char) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_TRANSCRIPT_FILES = ::std::option::Option<
+    unsafe extern "C" fn(
+        cDirectory: *mut ::std::os::raw::c_char,
+        cPerChannel: GO_SPEECH_RECOGNITION_BOOL,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_RECEIVE_TRANSCRIPT_JSON = ::std::option::Option<
+    unsafe extern "C" fn(arg1: *mut *mut ::std::os::raw::c_char) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_WORD_CONFIDENCE =
+    ::std::option::Option<unsafe extern "C" fn(cEnabled: GO_SPEECH_RECOGNITION_BOOL)>;
+pub type GO_SPEECH_RECOGNITION_ADD_REPLACEMENT = ::std::option::Option<
+    unsafe extern "C" fn(
+        cSearch: *mut ::std::os::raw::c_char,
+        cReplace: *mut ::std::os::raw::c_char,
+        cRegex: GO_SPEECH_RECOGNITION_BOOL,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_LOAD_REPLACEMENTS_FROM_FILE = ::std::option::Option<
+    unsafe extern "C" fn(cPath: *mut ::std::os::raw::c_char) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_CLEAR_REPLACEMENTS = ::std::option::Option<unsafe extern "C" fn()>;
+pub type GO_SPEECH_RECOGNITION_SET_OUTPUT_FORMATTING = ::std::option::Option<
+    unsafe extern "C" fn(cFormatting: ::std::os::raw::c_int) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_NUMBER_FORMATTING = ::std::option::Option<
+    unsafe extern "C" fn(
+        cMode: GO_SPEECH_RECOGNITION_NUMBER_FORMATTING,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_LOAD_PHRASE_HINTS_FROM_FILE = ::std::option::Option<
+    unsafe extern "C" fn(cPath: *mut ::std::os::raw::c_char) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_FINAL_RESULT_CALLBACK = ::std::option::Option<
+    unsafe extern "C" fn(
+        transcript: *mut ::std::os::raw::c_char,
+        durationSeconds: f64,
+        confidence: f32,
+        userData: *mut ::std::os::raw::c_void,
+    ),
+>;
+pub type GO_SPEECH_RECOGNITION_SET_FINAL_RESULT_CALLBACK = ::std::option::Option<
+    unsafe extern "C" fn(
+        cCallback: GO_SPEECH_RECOGNITION_FINAL_RESULT_CALLBACK,
+        cUserData: *mut ::std::os::raw::c_void,
+    ),
+>;
+pub type GO_SPEECH_RECOGNITION_POLL_UTTERANCE_EVENT = ::std::option::Option<
+    unsafe extern "C" fn(
+        id: *mut ::std::os::raw::c_int,
+        event: *mut ::std::os::raw::c_int,
+        transcript: *mut *mut ::std::os::raw::c_char,
+    ) -> GO_SPEECH_RECOGNITION_BOOL,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_VOICE_ACTIVITY_EVENTS =
+    ::std::option::Option<unsafe extern "C" fn(cEnabled: GO_SPEECH_RECOGNITION_BOOL)>;
+pub type GO_SPEECH_RECOGNITION_POLL_SPEECH_EVENT = ::std::option::Option<
+    unsafe extern "C" fn(
+        event: *mut ::std::os::raw::c_int,
+        timestampUs: *mut ::std::os::raw::c_longlong,
+    ) -> GO_SPEECH_RECOGNITION_BOOL,
+>;
+pub type GO_SPEECH_RECOGNITION_RECEIVE_ALTERNATIVES = ::std::option::Option<
+    unsafe extern "C" fn(
+        list: *mut *mut GO_SPEECH_RECOGNITION_ALTERNATIVE,
+        count: *mut ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_FREE_ALTERNATIVES = ::std::option::Option<
+    unsafe extern "C" fn(
+        list: *mut GO_SPEECH_RECOGNITION_ALTERNATIVE,
+        count: ::std::os::raw::c_int,
+    ),
+>;
+pub type GO_SPEECH_RECOGNITION_RERANK_CALLBACK = ::std::option::Option<
+    unsafe extern "C" fn(
+        list: *mut GO_SPEECH_RECOGNITION_ALTERNATIVE,
+        count: ::std::os::raw::c_int,
+        userData: *mut ::std::os::raw::c_void,
+    ) -> ::std::os::raw::c_int,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_RERANK_CALLBACK = ::std::option::Option<
+    unsafe extern "C" fn(
+        cCallback: GO_SPEECH_RECOGNITION_RERANK_CALLBACK,
+        cUserData: *mut ::std::os::raw::c_void,
+    ),
+>;
+pub type GO_SPEECH_RECOGNITION_SET_MIN_STABILITY =
+    ::std::option::Option<unsafe extern "C" fn(cStability: f32) -> GO_SPEECH_RECOGNITION_RESULT>;
+pub type GO_SPEECH_RECOGNITION_GET_INPUT_LEVEL = ::std::option::Option<
+    unsafe extern "C" fn(rmsDB: *mut f64, peakDB: *mut f64) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_LEVEL_CALLBACK = ::std::option::Option<
+    unsafe extern "C" fn(rmsDB: f64, peakDB: f64, userData: *mut ::std::os::raw::c_void),
+>;
+pub type GO_SPEECH_RECOGNITION_SET_LEVEL_CALLBACK = ::std::option::Option<
+    unsafe extern "C" fn(
+        cCallback: GO_SPEECH_RECOGNITION_LEVEL_CALLBACK,
+        cUserData: *mut ::std::os::raw::c_void,
+    ),
+>;
+pub type GO_SPEECH_RECOGNITION_SET_AUTOMATIC_GAIN_CONTROL = ::std::option::Option<
+    unsafe extern "C" fn(
+        cEnabled: GO_SPEECH_RECOGNITION_BOOL,
+        cTargetDB: f64,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_NOISE_SUPPRESSION = ::std::option::Option<
+    unsafe extern "C" fn(
+        cEnabled: GO_SPEECH_RECOGNITION_BOOL,
+        cStrength: f64,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_HIGH_PASS_FILTER = ::std::option::Option<
+    unsafe extern "C" fn(
+        cEnabled: GO_SPEECH_RECOGNITION_BOOL,
+        cCutoffHz: f64,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SEND_AUDIO_WITH_TIMESTAMP = ::std::option::Option<
+    unsafe extern "C" fn(
+        recording: *const ::std::os::raw::c_short,
+        recording_size: ::std::os::raw::c_int,
+        timestampUs: ::std::os::raw::c_longlong,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_WORD_TIME_OFFSETS =
+    ::std::option::Option<unsafe extern "C" fn(cEnabled: GO_SPEECH_RECOGNITION_BOOL)>;
+pub type GO_SPEECH_RECOGNITION_SET_WAV_HEADER_DETECTION =
+    ::std::option::Option<unsafe extern "C" fn(cEnabled: GO_SPEECH_RECOGNITION_BOOL)>;
+pub type GO_SPEECH_RECOGNITION_SET_AUDIO_ENCODING = ::std::option::Option<
+    unsafe extern "C" fn(cEncoding: GO_SPEECH_RECOGNITION_ENCODING) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SEND_AUDIO_BYTES = ::std::option::Option<
+    unsafe extern "C" fn(
+        data: *const u8,
+        data_size: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_BATCH_OPTIONS = ::std::option::Option<
+    unsafe extern "C" fn(
+        cLanguage: *const ::std::os::raw::c_char,
+        cModel: *const ::std::os::raw::c_char,
+    ),
+>;
+pub type GO_SPEECH_RECOGNITION_BATCH_CALLBACK = ::std::option::Option<
+    unsafe extern "C" fn(
+        handle: ::std::os::raw::c_int,
+        path: *const ::std::os::raw::c_char,
+        result: GO_SPEECH_RECOGNITION_RESULT,
+        transcript: *const ::std::os::raw::c_char,
+        completed: ::std::os::raw::c_int,
+        total: ::std::os::raw::c_int,
+        userData: *mut ::std::os::raw::c_void,
+    ),
+>;
+pub type GO_SPEECH_RECOGNITION_SET_BATCH_CALLBACK = ::std::option::Option<
+    unsafe extern "C" fn(
+        cCallback: GO_SPEECH_RECOGNITION_BATCH_CALLBACK,
+        cUserData: *mut ::std::os::raw::c_void,
+    ),
+>;
+pub type GO_SPEECH_RECOGNITION_TRANSCRIBE_FILES = ::std::option::Option<
+    unsafe extern "C" fn(
+        cPaths: *mut *const ::std::os::raw::c_char,
+        cCount: ::std::os::raw::c_int,
+        cConcurrency: ::std::os::raw::c_int,
+    ) -> ::std::os::raw::c_int,
+>;
+pub type GO_SPEECH_RECOGNITION_GET_OPERATION_PROGRESS = ::std::option::Option<
+    unsafe extern "C" fn(
+        cHandle: ::std::os::raw::c_int,
+        percent: *mut f64,
+        etaSeconds: *mut f64,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_OPERATION_STATE_FILE =
+    ::std::option::Option<unsafe extern "C" fn(cPath: *const ::std::os::raw::c_char)>;
+pub type GO_SPEECH_RECOGNITION_RESUME_OPERATIONS =
+    ::std::option::Option<unsafe extern "C" fn() -> ::std::os::raw::c_int>;
+pub type GO_SPEECH_RECOGNITION_SET_RESULT_CACHE = ::std::option::Option<
+    unsafe extern "C" fn(cLocation: *const ::std::os::raw::c_char) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_CREATE_SESSION_WITH_CREDENTIALS = ::std::option::Option<
+    unsafe extern "C" fn(
+        cCredentials: *const ::std::os::raw::c_char,
+        cQuotaProject: *const ::std::os::raw::c_char,
+        cTranscriptLanguage: *const ::std::os::raw::c_char,
+        cSampleRate: ::std::os::raw::c_int,
+        cTranscriptionModel: *const ::std::os::raw::c_char,
+        cMaxAlternatives: ::std::os::raw::c_int,
+        cInterimResults: GO_SPEECH_RECOGNITION_BOOL,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_VALIDATE_CONFIG = ::std::option::Option<
+    unsafe extern "C" fn(
+        cCredentials: *const ::std::os::raw::c_char,
+        cQuotaProject: *const ::std::os::raw::c_char,
+        cTranscriptLanguage: *const ::std::os::raw::c_char,
+        cSampleRate: ::std::os::raw::c_int,
+        cTranscriptionModel: *const ::std::os::raw::c_char,
+        cMaxAlternatives: ::std::os::raw::c_int,
+        cInterimResults: GO_SPEECH_RECOGNITION_BOOL,
+        output: *mut *mut ::std::os::raw::c_char,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_IS_MODEL_AVAILABLE = ::std::option::Option<
+    unsafe extern "C" fn(
+        cTranscriptLanguage: *const ::std::os::raw::c_char,
+        cTranscriptionModel: *const ::std::os::raw::c_char,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_MODEL_HINTS = ::std::option::Option<
+    unsafe extern "C" fn(
+        cAudioSource: GO_SPEECH_RECOGNITION_AUDIO_SOURCE,
+        cExpectedUtteranceSeconds: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_APPLY_PROFILE = ::std::option::Option<
+    unsafe extern "C" fn(cProfile: *const ::std::os::raw::c_char) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_INITIALIZE_STREAM_WITH_PROFILE = ::std::option::Option<
+    unsafe extern "C" fn(
+        cProfile: *const ::std::os::raw::c_char,
+        cTranscriptLanguage: *const ::std::os::raw::c_char,
+        cSampleRate: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_BATCH_CREDENTIALS = ::std::option::Option<
+    unsafe extern "C" fn(
+        cCredentials: *const ::std::os::raw::c_char,
+        cQuotaProject: *const ::std::os::raw::c_char,
+    ),
+>;
+pub type GO_SPEECH_RECOGNITION_SET_RESULT_ACKNOWLEDGMENT = ::std::option::Option<
+    unsafe extern "C" fn(
+        cEnabled: GO_SPEECH_RECOGNITION_BOOL,
+        cRedeliveryMs: ::std::os::raw::c_int,
+        cJournalFile: *const ::std::os::raw::c_char,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_ACK_RESULT = ::std::option::Option<
+    unsafe extern "C" fn(cSequence: ::std::os::raw::c_longlong) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_GET_LAST_RESULT_SEQUENCE =
+    ::std::option::Option<unsafe extern "C" fn() -> ::std::os::raw::c_longlong>;
+pub type GO_SPEECH_RECOGNITION_SET_BACKFILL = ::std::option::Option<
+    unsafe extern "C" fn(
+        cDirectory: *const ::std::os::raw::c_char,
+        cMaxMegabytes: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_ENCRYPTION_KEY = ::std::option::Option<
+    unsafe extern "C" fn(
+        cKey: *const u8,
+        cKeyLength: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_DECRYPT_FILE = ::std::option::Option<
+    unsafe extern "C" fn(
+        cPath: *const ::std::os::raw::c_char,
+        cOutputPath: *const ::std::os::raw::c_char,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_DATA_RETENTION = ::std::option::Option<
+    unsafe extern "C" fn(
+        cMaxAgeSeconds: ::std::os::raw::c_int,
+        cMaxMegabytes: ::std::os::raw::c_int,
+        cSecureDelete: GO_SPEECH_RECOGNITION_BOOL,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_PURGE_LOCAL_DATA =
+    ::std::option::Option<unsafe extern "C" fn() -> GO_SPEECH_RECOGNITION_RESULT>;
+pub type GO_SPEECH_RECOGNITION_SET_IN_MEMORY_ONLY = ::std::option::Option<
+    unsafe extern "C" fn(cEnabled: GO_SPEECH_RECOGNITION_BOOL) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_CREATE_SESSION_WITH_ACCESS_TOKEN = ::std::option::Option<
+    unsafe extern "C" fn(
+        cAccessToken: *const ::std::os::raw::c_char,
+        cExpiresInSeconds: ::std::os::raw::c_int,
+        cQuotaProject: *const ::std::os::raw::c_char,
+        cTranscriptLanguage: *const ::std::os::raw::c_char,
+        cSampleRate: ::std::os::raw::c_int,
+        cTranscriptionModel: *const ::std::os::raw::c_char,
+        cMaxAlternatives: ::std::os::raw::c_int,
+        cInterimResults: GO_SPEECH_RECOGNITION_BOOL,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_ACCESS_TOKEN = ::std::option::Option<
+    unsafe extern "C" fn(
+        cAccessToken: *const ::std::os::raw::c_char,
+        cExpiresInSeconds: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_CALLBACK_DISPATCH = ::std::option::Option<
+    unsafe extern "C" fn(
+        cMode: GO_SPEECH_RECOGNITION_CALLBACK_DISPATCH,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_PUMP_CALLBACKS = ::std::option::Option<
+    unsafe extern "C" fn(cMaxCallbacks: ::std::os::raw::c_int) -> ::std::os::raw::c_int,
+>;
+pub type GO_SPEECH_RECOGNITION_SEND_RAW_REQUEST = ::std::option::Option<
+    unsafe extern "C" fn(
+        data: *const u8,
+        dataLength: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_RECEIVE_RAW_RESPONSE = ::std::option::Option<
+    unsafe extern "C" fn(
+        output: *mut *mut ::std::os::raw::c_char,
+        outputLength: *mut ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_START_CONVERSATION = ::std::option::Option<
+    unsafe extern "C" fn(
+        cTranscriptLanguage: *const ::std::os::raw::c_char,
+        cSampleRate: ::std::os::raw::c_int,
+        cTranscriptionModel: *const ::std::os::raw::c_char,
+        cLocalSpeaker: *const ::std::os::raw::c_char,
+        cRemoteSpeaker: *const ::std::os::raw::c_char,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SEND_CONVERSATION_AUDIO = ::std::option::Option<
+    unsafe extern "C" fn(
+        cParty: GO_SPEECH_RECOGNITION_PARTY,
+        recording: *mut ::std::os::raw::c_short,
+        recordingLength: ::std::os::raw::c_int,
+        timestampUs: ::std::os::raw::c_longlong,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_GET_CONVERSATION_TRANSCRIPT = ::std::option::Option<
+    unsafe extern "C" fn(output: *mut *mut ::std::os::raw::c_char) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_END_CONVERSATION = ::std::option::Option<unsafe extern "C" fn()>;
+pub type GO_SPEECH_RECOGNITION_ADD_MIXER_SOURCE = ::std::option::Option<
+    unsafe extern "C" fn(
+        cGainDB: f64,
+        sourceID: *mut ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_MIXER_SOURCE_GAIN = ::std::option::Option<
+    unsafe extern "C" fn(
+        cSourceID: ::std::os::raw::c_int,
+        cGainDB: f64,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_REMOVE_MIXER_SOURCE = ::std::option::Option<
+    unsafe extern "C" fn(cSourceID: ::std::os::raw::c_int) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SEND_MIXER_AUDIO = ::std::option::Option<
+    unsafe extern "C" fn(
+        cSourceID: ::std::os::raw::c_int,
+        recording: *mut ::std::os::raw::c_short,
+        recordingLength: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_DRIFT_COMPENSATION =
+    ::std::option::Option<unsafe extern "C" fn(cEnabled: ::std::os::raw::c_int)>;
+pub type GO_SPEECH_RECOGNITION_GET_CLOCK_DRIFT = ::std::option::Option<
+    unsafe extern "C" fn(driftMs: *mut f64, ppm: *mut f64) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_RUN_BENCHMARK = ::std::option::Option<
+    unsafe extern "C" fn(
+        cWavPath: *const ::std::os::raw::c_char,
+        cTranscriptLanguage: *const ::std::os::raw::c_char,
+        cTranscriptionModel: *const ::std::os::raw::c_char,
+        cRealtime: ::std::os::raw::c_int,
+        output: *mut *mut ::std::os::raw::c_char,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_EVALUATE_TRANSCRIPT = ::std::option::Option<
+    unsafe extern "C" fn(
+        cHypothesis: *const ::std::os::raw::c_char,
+        cReference: *const ::std::os::raw::c_char,
+        output: *mut *mut ::std::os::raw::c_char,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_EVALUATE_FILE = ::std::option::Option<
+    unsafe extern "C" fn(
+        cPath: *const ::std::os::raw::c_char,
+        cReference: *const ::std::os::raw::c_char,
+        output: *mut *mut ::std::os::raw::c_char,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_GENERATE_TEST_SIGNAL = ::std::option::Option<
+    unsafe extern "C" fn(
+        cSignal: ::std::os::raw::c_int,
+        cSampleRate: ::std::os::raw::c_int,
+        buffer: *mut ::std::os::raw::c_short,
+        bufferLength: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_STREAM_TEST_SIGNAL = ::std::option::Option<
+    unsafe extern "C" fn(
+        cSignal: ::std::os::raw::c_int,
+        cDurationMs: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_GET_LOG_ENTRIES = ::std::option::Option<
+    unsafe extern "C" fn(
+        cSinceSequence: ::std::os::raw::c_longlong,
+        output: *mut *mut ::std::os::raw::c_char,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_ENABLE_FILE_LOGGING = ::std::option::Option<
+    unsafe extern "C" fn(
+        cPath: *const ::std::os::raw::c_char,
+        cMaxMB: ::std::os::raw::c_int,
+        cMaxFiles: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_ENABLE_SYSTEM_LOG = ::std::option::Option<
+    unsafe extern "C" fn(
+        cSystemLog: GO_SPEECH_RECOGNITION_SYSTEM_LOG,
+        cSource: *const ::std::os::raw::c_char,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_WRITE_DIAGNOSTICS_BUNDLE = ::std::option::Option<
+    unsafe extern "C" fn(cPath: *const ::std::os::raw::c_char) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_ENABLE_DEBUG_SERVER = ::std::option::Option<
+    unsafe extern "C" fn(cPort: ::std::os::raw::c_int) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_WATCHDOG = ::std::option::Option<
+    unsafe extern "C" fn(
+        cMaxGoroutines: ::std::os::raw::c_int,
+        cMaxHeapMB: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_GC_PERCENT = ::std::option::Option<
+    unsafe extern "C" fn(cPercent: ::std::os::raw::c_int) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_MEMORY_LIMIT = ::std::option::Option<
+    unsafe extern "C" fn(cLimitMB: ::std::os::raw::c_int) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_REALTIME_AUDIO = ::std::option::Option<
+    unsafe extern "C" fn(cCapacityMs: ::std::os::raw::c_int) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SEND_AUDIO_REALTIME = ::std::option::Option<
+    unsafe extern "C" fn(
+        recording: *const ::std::os::raw::c_short,
+        recording_size: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_RECEIVE_ALL_PENDING = ::std::option::Option<
+    unsafe extern "C" fn(output: *mut *mut ::std::os::raw::c_char) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_GET_RESULT_EVENT_HANDLE = ::std::option::Option<
+    unsafe extern "C" fn(handle: *mut ::std::os::raw::c_longlong) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_MUTE_RECOGNITION = ::std::option::Option<
+    unsafe extern "C" fn(
+        cMuted: GO_SPEECH_RECOGNITION_BOOL,
+        cEchoWindowMs: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_ENABLE_TURN_TAKING = ::std::option::Option<
+    unsafe extern "C" fn(
+        cEnabled: GO_SPEECH_RECOGNITION_BOOL,
+        cEchoWindowMs: ::std::os::raw::c_int,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_SET_TURN_STATE = ::std::option::Option<
+    unsafe extern "C" fn(cState: ::std::os::raw::c_int) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_GET_TURN_STATE =
+    ::std::option::Option<unsafe extern "C" fn() -> ::std::os::raw::c_int>;
+pub type GO_SPEECH_RECOGNITION_TURN_CALLBACK = ::std::option::Option<
+    unsafe extern "C" fn(
+        state: ::std::os::raw::c_int,
+        transcript: *mut ::std::os::raw::c_char,
+        userData: *mut ::std::os::raw::c_void,
+    ),
+>;
+pub type GO_SPEECH_RECOGNITION_SET_TURN_CALLBACK = ::std::option::Option<
+    unsafe extern "C" fn(
+        cCallback: GO_SPEECH_RECOGNITION_TURN_CALLBACK,
+        cUserData: *mut ::std::os::raw::c_void,
+    ),
+>;
+pub type GO_SPEECH_RECOGNITION_SET_WAKE_WORD = ::std::option::Option<
+    unsafe extern "C" fn(
+        cPath: *const ::std::os::raw::c_char,
+        cSensitivity: f64,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_ADD_SENSITIVE_TERM = ::std::option::Option<
+    unsafe extern "C" fn(
+        cTerm: *mut ::std::os::raw::c_char,
+        cRegex: GO_SPEECH_RECOGNITION_BOOL,
+    ) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
+pub type GO_SPEECH_RECOGNITION_CLEAR_SENSITIVE_TERMS =
+    ::std::option::Option<unsafe extern "C" fn()>;
+pub type GO_SPEECH_RECOGNITION_SET_UNICODE_NORMALIZATION = ::std::option::Option<
+    unsafe extern "C" fn(cForm: ::std::os::raw::c_int) -> GO_SPEECH_RECOGNITION_RESULT,
+>;
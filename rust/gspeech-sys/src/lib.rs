@@ -0,0 +1,96 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Raw bindings of the go-speech-recognition library: the types of go-speech-recognition.h (see bindings.rs)
+	and the loading of the library at runtime (like the header is used by C++ hosts).
+	The safe wrapper is the "gspeech" crate.
+*/
+
+#![allow(non_camel_case_types, non_snake_case, non_upper_case_globals)]
+
+include!("bindings.rs");
+
+use std::ffi::{c_void, CStr, CString};
+
+/// The loaded library, unloaded when it's dropped (which shuts the library down, see "Shutdown()").
+pub struct Library {
+	handle: *mut c_void,
+}
+
+// The exports of the library can be called from any thread.
+unsafe impl Send for Library {}
+unsafe impl Sync for Library {}
+
+impl Library {
+	/// Loads the library (e.g. "go-speech-recognition.dll" or "./libgo-speech-recognition.so").
+	pub fn open(path: &str) -> Result<Library, String> {
+		let c_path = CString::new(path).map_err(|_| format!("Invalid path {}", path))?;
+		let handle = unsafe { platform::open(&c_path) };
+		if handle.is_null() {
+			return Err(format!("Could not load {}", path));
+		}
+		Ok(Library { handle })
+	}
+
+	/// Resolves an export as its function type of the bindings (e.g. GO_SPEECH_RECOGNITION_SEND_AUDIO), None if the library doesn't export it.
+	///
+	/// # Safety
+	/// The type has to be the function type of the export.
+	pub unsafe fn get<T: Copy>(&self, name: &CStr) -> Option<T> {
+		// The function types are Option<fn> (a nullable pointer).
+		assert_eq!(std::mem::size_of::<T>(), std::mem::size_of::<*mut c_void>());
+		let symbol = platform::symbol(self.handle, name);
+		if symbol.is_null() {
+			return None;
+		}
+		Some(std::mem::transmute_copy(&symbol))
+	}
+}
+
+impl Drop for Library {
+	fn drop(&mut self) {
+		unsafe { platform::close(self.handle) };
+	}
+}
+
+#[cfg(unix)]
+mod platform {
+	use std::ffi::{c_void, CStr};
+
+	pub unsafe fn open(path: &CStr) -> *mut c_void {
+		libc::dlopen(path.as_ptr(), libc::RTLD_NOW | libc::RTLD_LOCAL)
+	}
+
+	pub unsafe fn symbol(handle: *mut c_void, name: &CStr) -> *mut c_void {
+		libc::dlsym(handle, name.as_ptr())
+	}
+
+	pub unsafe fn close(handle: *mut c_void) {
+		libc::dlclose(handle);
+	}
+}
+
+#[cfg(windows)]
+mod platform {
+	use std::ffi::{c_void, CStr};
+	use std::os::raw::c_char;
+
+	#[link(name = "kernel32")]
+	extern "system" {
+		fn LoadLibraryA(name: *const c_char) -> *mut c_void;
+		fn GetProcAddress(module: *mut c_void, name: *const c_char) -> *mut c_void;
+		fn FreeLibrary(module: *mut c_void) -> i32;
+	}
+
+	pub unsafe fn open(path: &CStr) -> *mut c_void {
+		LoadLibraryA(path.as_ptr())
+	}
+
+	pub unsafe fn symbol(handle: *mut c_void, name: &CStr) -> *mut c_void {
+		GetProcAddress(handle, name.as_ptr())
+	}
+
+	pub unsafe fn close(handle: *mut c_void) {
+		FreeLibrary(handle);
+	}
+}
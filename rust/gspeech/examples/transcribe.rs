@@ -0,0 +1,23 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Transcribes a raw 16 bit PCM file (16 kHz, mono): cargo run --example transcribe -- audio.raw
+*/
+
+use gspeech::{Library, Session, SessionOptions};
+
+fn main() -> Result<(), Box<dyn std::error::Error>> {
+	let path = std::env::args().nth(1).ok_or("Usage: transcribe <audio.raw>")?;
+	let audio = std::fs::read(path)?;
+	let samples: Vec<i16> = audio.chunks_exact(2).map(|pair| i16::from_le_bytes([pair[0], pair[1]])).collect();
+
+	let library = Library::open_default()?;
+	let session = Session::open(&library, &SessionOptions::default())?;
+	for chunk in samples.chunks(1600) {
+		session.send_audio(chunk)?;
+	}
+	for transcript in session.transcripts().take(1) {
+		println!("{}", transcript?);
+	}
+	Ok(())
+}
@@ -0,0 +1,521 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Safe binding of the go-speech-recognition library: the strings returned by the library are owned by LibraryString
+	(released when they're dropped) and the session by Session (closed when it's dropped).
+	The library runs one session at a time, opening a session closes the running one.
+*/
+
+use std::ffi::{c_char, CStr, CString};
+use std::fmt;
+use std::ops::Deref;
+use std::ptr;
+use std::sync::Arc;
+
+use gspeech_sys as sys;
+
+/// The result codes of the library (GO_SPEECH_RECOGNITION_RESULT)
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum ResultCode {
+	Error,
+	NotInitialized,
+	InvalidArgument,
+	Finalized,
+	QueueFull,
+	StreamEnded,
+	BudgetExceeded,
+	Canceled,
+	RateLimited,
+	QuotaExceeded,
+	WrongThread,
+	Busy,
+	ModelUnavailable,
+	/// A code of a newer library
+	Unknown(i32),
+}
+
+impl ResultCode {
+	fn from_code(code: sys::GO_SPEECH_RECOGNITION_RESULT) -> ResultCode {
+		match code {
+			sys::GO_SPEECH_RECOGNITION_ERROR => ResultCode::Error,
+			sys::GO_SPEECH_RECOGNITION_ERROR_NOT_INITIALIZED => ResultCode::NotInitialized,
+			sys::GO_SPEECH_RECOGNITION_ERROR_INVALID_ARGUMENT => ResultCode::InvalidArgument,
+			sys::GO_SPEECH_RECOGNITION_ERROR_FINALIZED => ResultCode::Finalized,
+			sys::GO_SPEECH_RECOGNITION_ERROR_QUEUE_FULL => ResultCode::QueueFull,
+			sys::GO_SPEECH_RECOGNITION_ERROR_STREAM_ENDED => ResultCode::StreamEnded,
+			sys::GO_SPEECH_RECOGNITION_ERROR_BUDGET_EXCEEDED => ResultCode::BudgetExceeded,
+			sys::GO_SPEECH_RECOGNITION_ERROR_CANCELED => ResultCode::Canceled,
+			sys::GO_SPEECH_RECOGNITION_ERROR_RATE_LIMITED => ResultCode::RateLimited,
+			sys::GO_SPEECH_RECOGNITION_ERROR_QUOTA_EXCEEDED => ResultCode::QuotaExceeded,
+			sys::GO_SPEECH_RECOGNITION_ERROR_WRONG_THREAD => ResultCode::WrongThread,
+			sys::GO_SPEECH_RECOGNITION_ERROR_BUSY => ResultCode::Busy,
+			sys::GO_SPEECH_RECOGNITION_ERROR_MODEL_UNAVAILABLE => ResultCode::ModelUnavailable,
+			code => ResultCode::Unknown(code),
+		}
+	}
+}
+
+/// The events of the library (GO_SPEECH_RECOGNITION_EVENT, see "PollEvent()")
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum Event {
+	SilenceTimeout,
+	MaxDuration,
+	BudgetExceeded,
+	Clipping,
+	DcOffset,
+	NearSilence,
+	QuotaExceeded,
+	Offline,
+	Backfilled,
+	FormatMismatch,
+	WakeWord,
+	WakeWordRearmed,
+	/// An event of a newer library
+	Unknown(i32),
+}
+
+impl Event {
+	fn from_code(code: i32) -> Event {
+		match code as sys::GO_SPEECH_RECOGNITION_EVENT {
+			sys::GO_SPEECH_RECOGNITION_EVENT_SILENCE_TIMEOUT => Event::SilenceTimeout,
+			sys::GO_SPEECH_RECOGNITION_EVENT_MAX_DURATION => Event::MaxDuration,
+			sys::GO_SPEECH_RECOGNITION_EVENT_BUDGET_EXCEEDED => Event::BudgetExceeded,
+			sys::GO_SPEECH_RECOGNITION_EVENT_CLIPPING => Event::Clipping,
+			sys::GO_SPEECH_RECOGNITION_EVENT_DC_OFFSET => Event::DcOffset,
+			sys::GO_SPEECH_RECOGNITION_EVENT_NEAR_SILENCE => Event::NearSilence,
+			sys::GO_SPEECH_RECOGNITION_EVENT_QUOTA_EXCEEDED => Event::QuotaExceeded,
+			sys::GO_SPEECH_RECOGNITION_EVENT_OFFLINE => Event::Offline,
+			sys::GO_SPEECH_RECOGNITION_EVENT_BACKFILLED => Event::Backfilled,
+			sys::GO_SPEECH_RECOGNITION_EVENT_FORMAT_MISMATCH => Event::FormatMismatch,
+			sys::GO_SPEECH_RECOGNITION_EVENT_WAKE_WORD => Event::WakeWord,
+			sys::GO_SPEECH_RECOGNITION_EVENT_WAKE_WORD_REARMED => Event::WakeWordRearmed,
+			_ => Event::Unknown(code),
+		}
+	}
+}
+
+/// A failed call of the library with its result code and the error log ("GetLog()")
+#[derive(Debug, Clone)]
+pub struct Error {
+	pub code: ResultCode,
+	pub message: String,
+}
+
+impl fmt::Display for Error {
+	fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+		write!(f, "{:?}: {}", self.code, self.message)
+	}
+}
+
+impl std::error::Error for Error {}
+
+pub type Result<T> = std::result::Result<T, Error>;
+
+// The functions of the library used by the binding
+struct Api {
+	set_legacy_return_codes: unsafe extern "C" fn(sys::GO_SPEECH_RECOGNITION_BOOL),
+	initialize_stream: unsafe extern "C" fn(*mut c_char, i32, *mut c_char, i32, sys::GO_SPEECH_RECOGNITION_BOOL) -> sys::GO_SPEECH_RECOGNITION_RESULT,
+	create_session_with_credentials: unsafe extern "C" fn(
+		*const c_char,
+		*const c_char,
+		*const c_char,
+		i32,
+		*const c_char,
+		i32,
+		sys::GO_SPEECH_RECOGNITION_BOOL,
+	) -> sys::GO_SPEECH_RECOGNITION_RESULT,
+	reconfigure: unsafe extern "C" fn(*mut c_char, i32, *mut c_char, i32, sys::GO_SPEECH_RECOGNITION_BOOL) -> sys::GO_SPEECH_RECOGNITION_RESULT,
+	send_audio: unsafe extern "C" fn(*const i16, i32) -> sys::GO_SPEECH_RECOGNITION_RESULT,
+	send_audio_with_timestamp: unsafe extern "C" fn(*const i16, i32, i64) -> sys::GO_SPEECH_RECOGNITION_RESULT,
+	send_audio_bytes: unsafe extern "C" fn(*const u8, i32) -> sys::GO_SPEECH_RECOGNITION_RESULT,
+	receive_transcript: unsafe extern "C" fn(*mut *mut c_char) -> sys::GO_SPEECH_RECOGNITION_RESULT,
+	receive_transcript_json: unsafe extern "C" fn(*mut *mut c_char) -> sys::GO_SPEECH_RECOGNITION_RESULT,
+	cancel_pending_receive: unsafe extern "C" fn(),
+	poll_event: unsafe extern "C" fn(*mut i32) -> sys::GO_SPEECH_RECOGNITION_BOOL,
+	get_stats: unsafe extern "C" fn(*mut *mut c_char) -> sys::GO_SPEECH_RECOGNITION_RESULT,
+	get_session_id: unsafe extern "C" fn() -> *mut c_char,
+	set_session_label: unsafe extern "C" fn(*const c_char, *const c_char) -> sys::GO_SPEECH_RECOGNITION_RESULT,
+	ack_result: unsafe extern "C" fn(i64) -> sys::GO_SPEECH_RECOGNITION_RESULT,
+	get_last_result_sequence: unsafe extern "C" fn() -> i64,
+	get_log: unsafe extern "C" fn() -> *mut c_char,
+	get_last_error_json: unsafe extern "C" fn() -> *mut c_char,
+	free_string: unsafe extern "C" fn(*mut c_char),
+	close_stream: unsafe extern "C" fn(),
+	shutdown: unsafe extern "C" fn(),
+	// Keeps the library loaded as long as its functions are used (dropped last)
+	_library: sys::Library,
+}
+
+// Resolves an export, an error if the library doesn't export it (e.g. an older version).
+macro_rules! resolve {
+	($library:expr, $type:ty, $name:literal) => {
+		unsafe { $library.get::<$type>(CStr::from_bytes_with_nul(concat!($name, "\0").as_bytes()).unwrap()) }
+			.flatten()
+			.ok_or_else(|| wrapper_error(concat!("The library doesn't export ", $name)))?
+	};
+}
+
+fn wrapper_error(message: &str) -> Error {
+	Error {
+		code: ResultCode::Error,
+		message: message.to_string(),
+	}
+}
+
+fn c_string(value: &str) -> Result<CString> {
+	CString::new(value).map_err(|_| Error {
+		code: ResultCode::InvalidArgument,
+		message: format!("Invalid string {:?} (contains a NUL)", value),
+	})
+}
+
+fn c_bool(value: bool) -> sys::GO_SPEECH_RECOGNITION_BOOL {
+	if value {
+		sys::GO_SPEECH_RECOGNITION_TRUE
+	} else {
+		sys::GO_SPEECH_RECOGNITION_FALSE
+	}
+}
+
+/// The loaded library, it stays loaded as long as a Library (or a Session using it) exists.
+#[derive(Clone)]
+pub struct Library {
+	api: Arc<Api>,
+}
+
+impl Library {
+	/// Loads the library (e.g. "go-speech-recognition.dll" or "./libgo-speech-recognition.so").
+	pub fn open(path: &str) -> Result<Library> {
+		let library = sys::Library::open(path).map_err(|message| wrapper_error(&message))?;
+		let api = Api {
+			set_legacy_return_codes: resolve!(library, sys::GO_SPEECH_RECOGNITION_SET_LEGACY_RETURN_CODES, "SetLegacyReturnCodes"),
+			initialize_stream: resolve!(library, sys::GO_SPEECH_RECOGNITION_INITIALIZE_STREAM, "InitializeStream"),
+			create_session_with_credentials: resolve!(
+				library,
+				sys::GO_SPEECH_RECOGNITION_CREATE_SESSION_WITH_CREDENTIALS,
+				"CreateSessionWithCredentials"
+			),
+			reconfigure: resolve!(library, sys::GO_SPEECH_RECOGNITION_RECONFIGURE, "Reconfigure"),
+			send_audio: resolve!(library, sys::GO_SPEECH_RECOGNITION_SEND_AUDIO, "SendAudio"),
+			send_audio_with_timestamp: resolve!(library, sys::GO_SPEECH_RECOGNITION_SEND_AUDIO_WITH_TIMESTAMP, "SendAudioWithTimestamp"),
+			send_audio_bytes: resolve!(library, sys::GO_SPEECH_RECOGNITION_SEND_AUDIO_BYTES, "SendAudioBytes"),
+			receive_transcript: resolve!(library, sys::GO_SPEECH_RECOGNITION_RECEIVE_TRANSCRIPT, "ReceiveTranscript"),
+			receive_transcript_json: resolve!(library, sys::GO_SPEECH_RECOGNITION_RECEIVE_TRANSCRIPT_JSON, "ReceiveTranscriptJSON"),
+			cancel_pending_receive: resolve!(library, sys::GO_SPEECH_RECOGNITION_CANCEL_PENDING_RECEIVE, "CancelPendingReceive"),
+			poll_event: resolve!(library, sys::GO_SPEECH_RECOGNITION_POLL_EVENT, "PollEvent"),
+			get_stats: resolve!(library, sys::GO_SPEECH_RECOGNITION_GET_STATS, "GetStats"),
+			get_session_id: resolve!(library, sys::GO_SPEECH_RECOGNITION_GET_SESSION_ID, "GetSessionID"),
+			set_session_label: resolve!(library, sys::GO_SPEECH_RECOGNITION_SET_SESSION_LABEL, "SetSessionLabel"),
+			ack_result: resolve!(library, sys::GO_SPEECH_RECOGNITION_ACK_RESULT, "AckResult"),
+			get_last_result_sequence: resolve!(library, sys::GO_SPEECH_RECOGNITION_GET_LAST_RESULT_SEQUENCE, "GetLastResultSequence"),
+			get_log: resolve!(library, sys::GO_SPEECH_RECOGNITION_GET_LOG, "GetLog"),
+			get_last_error_json: resolve!(library, sys::GO_SPEECH_RECOGNITION_GET_LAST_ERROR_JSON, "GetLastErrorJSON"),
+			free_string: resolve!(library, sys::GO_SPEECH_RECOGNITION_FREE_STRING, "FreeString"),
+			close_stream: resolve!(library, sys::GO_SPEECH_RECOGNITION_CLOSE_STREAM, "CloseStream"),
+			shutdown: resolve!(library, sys::GO_SPEECH_RECOGNITION_SHUTDOWN, "Shutdown"),
+			_library: library,
+		};
+
+		// The binding relies on the negative error codes.
+		unsafe { (api.set_legacy_return_codes)(sys::GO_SPEECH_RECOGNITION_FALSE) };
+		Ok(Library { api: Arc::new(api) })
+	}
+
+	/// Loads the library from the GSPEECH_LIBRARY environment variable or by its name (go-speech-recognition.dll, libgo-speech-recognition.so, ...).
+	pub fn open_default() -> Result<Library> {
+		match std::env::var("GSPEECH_LIBRARY") {
+			Ok(path) => Library::open(&path),
+			Err(_) if cfg!(windows) => Library::open("go-speech-recognition.dll"),
+			Err(_) if cfg!(target_os = "macos") => Library::open("libgo-speech-recognition.dylib"),
+			Err(_) => Library::open("libgo-speech-recognition.so"),
+		}
+	}
+
+	/// The error log of the last failure (see "GetLog()")
+	pub fn log(&self) -> String {
+		self.take(unsafe { (self.api.get_log)() }).to_string()
+	}
+
+	/// The last error as JSON (see "GetLastErrorJSON()")
+	pub fn last_error_json(&self) -> String {
+		self.take(unsafe { (self.api.get_last_error_json)() }).to_string()
+	}
+
+	/// Aborts the blocking receive calls of other threads (see "CancelPendingReceive()").
+	pub fn cancel_pending_receive(&self) {
+		unsafe { (self.api.cancel_pending_receive)() }
+	}
+
+	/// Releases everything the library holds (see "Shutdown()"), done as well when the library gets unloaded.
+	pub fn release(&self) {
+		unsafe { (self.api.shutdown)() }
+	}
+
+	// Takes the ownership of a string returned by the library.
+	fn take(&self, value: *mut c_char) -> LibraryString {
+		LibraryString { api: self.api.clone(), value }
+	}
+
+	fn check(&self, code: sys::GO_SPEECH_RECOGNITION_RESULT) -> Result<()> {
+		if code == sys::GO_SPEECH_RECOGNITION_OK {
+			return Ok(());
+		}
+		Err(Error {
+			code: ResultCode::from_code(code),
+			message: self.log(),
+		})
+	}
+
+	// Calls an export returning a string.
+	fn output(&self, function: unsafe extern "C" fn(*mut *mut c_char) -> sys::GO_SPEECH_RECOGNITION_RESULT) -> Result<LibraryString> {
+		let mut value: *mut c_char = ptr::null_mut();
+		let code = unsafe { function(&mut value) };
+		let output = self.take(value);
+		self.check(code)?;
+		Ok(output)
+	}
+}
+
+/// A string returned by the library, released by the library when it's dropped.
+pub struct LibraryString {
+	api: Arc<Api>,
+	value: *mut c_char,
+}
+
+// The library's strings can be released from any thread.
+unsafe impl Send for LibraryString {}
+unsafe impl Sync for LibraryString {}
+
+impl LibraryString {
+	/// The string (invalid UTF-8 is replaced).
+	pub fn as_str(&self) -> &str {
+		if self.value.is_null() {
+			return "";
+		}
+		let bytes = unsafe { CStr::from_ptr(self.value) }.to_bytes();
+		std::str::from_utf8(bytes).unwrap_or("\u{FFFD}")
+	}
+}
+
+impl Deref for LibraryString {
+	type Target = str;
+
+	fn deref(&self) -> &str {
+		self.as_str()
+	}
+}
+
+impl fmt::Display for LibraryString {
+	fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+		f.write_str(self.as_str())
+	}
+}
+
+impl fmt::Debug for LibraryString {
+	fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+		fmt::Debug::fmt(self.as_str(), f)
+	}
+}
+
+impl Drop for LibraryString {
+	fn drop(&mut self) {
+		if !self.value.is_null() {
+			unsafe { (self.api.free_string)(self.value) };
+		}
+	}
+}
+
+/// The options of a session (see "InitializeStream()").
+#[derive(Debug, Clone)]
+pub struct SessionOptions {
+	pub language: String,
+	pub sample_rate: i32,
+	pub model: String,
+	pub max_alternatives: i32,
+	pub interim_results: bool,
+	/// The credentials (a key file or the JSON key) and the quota project of a customer, None for the defaults (see "CreateSessionWithCredentials()")
+	pub credentials: Option<String>,
+	pub quota_project: Option<String>,
+}
+
+impl Default for SessionOptions {
+	fn default() -> SessionOptions {
+		SessionOptions {
+			language: "en-US".to_string(),
+			sample_rate: 16000,
+			model: "default".to_string(),
+			max_alternatives: 1,
+			interim_results: false,
+			credentials: None,
+			quota_project: None,
+		}
+	}
+}
+
+/// The streaming session, closed when it's dropped.
+pub struct Session {
+	library: Library,
+}
+
+impl Session {
+	/// Opens the session (see "InitializeStream()" and "CreateSessionWithCredentials()").
+	pub fn open(library: &Library, options: &SessionOptions) -> Result<Session> {
+		let api = &library.api;
+		let language = c_string(&options.language)?;
+		let model = c_string(&options.model)?;
+		let interim_results = c_bool(options.interim_results);
+
+		let code = if options.credentials.is_none() && options.quota_project.is_none() {
+			unsafe {
+				(api.initialize_stream)(
+					language.as_ptr() as *mut c_char,
+					options.sample_rate,
+					model.as_ptr() as *mut c_char,
+					options.max_alternatives,
+					interim_results,
+				)
+			}
+		} else {
+			let credentials = c_string(options.credentials.as_deref().unwrap_or(""))?;
+			let quota_project = c_string(options.quota_project.as_deref().unwrap_or(""))?;
+			unsafe {
+				(api.create_session_with_credentials)(
+					credentials.as_ptr(),
+					quota_project.as_ptr(),
+					language.as_ptr(),
+					options.sample_rate,
+					model.as_ptr(),
+					options.max_alternatives,
+					interim_results,
+				)
+			}
+		};
+		library.check(code)?;
+		Ok(Session { library: library.clone() })
+	}
+
+	/// The ID of the session (see "GetSessionID()").
+	pub fn id(&self) -> LibraryString {
+		self.library.take(unsafe { (self.library.api.get_session_id)() })
+	}
+
+	/// Sends 16 bit PCM samples (see "SendAudio()").
+	pub fn send_audio(&self, samples: &[i16]) -> Result<()> {
+		if samples.is_empty() {
+			return Ok(());
+		}
+		self.library
+			.check(unsafe { (self.library.api.send_audio)(samples.as_ptr(), samples.len() as i32) })
+	}
+
+	/// Sends 16 bit PCM samples with the capture timestamp of the first sample (see "SendAudioWithTimestamp()").
+	pub fn send_audio_with_timestamp(&self, samples: &[i16], timestamp_us: i64) -> Result<()> {
+		if samples.is_empty() {
+			return Ok(());
+		}
+		self.library
+			.check(unsafe { (self.library.api.send_audio_with_timestamp)(samples.as_ptr(), samples.len() as i32, timestamp_us) })
+	}
+
+	/// Sends encoded audio in the encoding of "SetAudioEncoding()" (see "SendAudioBytes()").
+	pub fn send_audio_bytes(&self, data: &[u8]) -> Result<()> {
+		if data.is_empty() {
+			return Ok(());
+		}
+		self.library
+			.check(unsafe { (self.library.api.send_audio_bytes)(data.as_ptr(), data.len() as i32) })
+	}
+
+	/// Waits for the next transcript (see "ReceiveTranscript()"), the alternatives are separated by ';'.
+	pub fn receive_transcript(&self) -> Result<LibraryString> {
+		self.library.output(self.library.api.receive_transcript)
+	}
+
+	/// Waits for the next results as JSON (see "ReceiveTranscriptJSON()").
+	pub fn receive_transcript_json(&self) -> Result<LibraryString> {
+		self.library.output(self.library.api.receive_transcript_json)
+	}
+
+	/// Iterates the transcripts until the session ends (responses without results are skipped).
+	pub fn transcripts(&self) -> Transcripts<'_> {
+		Transcripts { session: self, ended: false }
+	}
+
+	/// Switches the configuration of the running session (see "Reconfigure()").
+	pub fn reconfigure(&self, options: &SessionOptions) -> Result<()> {
+		let language = c_string(&options.language)?;
+		let model = c_string(&options.model)?;
+		self.library.check(unsafe {
+			(self.library.api.reconfigure)(
+				language.as_ptr() as *mut c_char,
+				options.sample_rate,
+				model.as_ptr() as *mut c_char,
+				options.max_alternatives,
+				c_bool(options.interim_results),
+			)
+		})
+	}
+
+	/// Acknowledges a final result (see "AckResult()").
+	pub fn ack(&self, sequence: i64) -> Result<()> {
+		self.library.check(unsafe { (self.library.api.ack_result)(sequence) })
+	}
+
+	/// The sequence number of the last received result (see "GetLastResultSequence()").
+	pub fn last_result_sequence(&self) -> i64 {
+		unsafe { (self.library.api.get_last_result_sequence)() }
+	}
+
+	/// Attaches a label to the session (see "SetSessionLabel()").
+	pub fn set_label(&self, key: &str, value: &str) -> Result<()> {
+		let key = c_string(key)?;
+		let value = c_string(value)?;
+		self.library
+			.check(unsafe { (self.library.api.set_session_label)(key.as_ptr(), value.as_ptr()) })
+	}
+
+	/// Returns the next event, None if none is pending (see "PollEvent()").
+	pub fn poll_event(&self) -> Option<Event> {
+		let mut event = 0;
+		if unsafe { (self.library.api.poll_event)(&mut event) } != sys::GO_SPEECH_RECOGNITION_TRUE {
+			return None;
+		}
+		Some(Event::from_code(event))
+	}
+
+	/// The statistics of the session as JSON (see "GetStats()").
+	pub fn stats(&self) -> Result<LibraryString> {
+		self.library.output(self.library.api.get_stats)
+	}
+}
+
+impl Drop for Session {
+	fn drop(&mut self) {
+		unsafe { (self.library.api.close_stream)() };
+	}
+}
+
+/// Iterator of the transcripts of a session (see "Session::transcripts()"), it ends with the session.
+pub struct Transcripts<'a> {
+	session: &'a Session,
+	ended: bool,
+}
+
+impl Iterator for Transcripts<'_> {
+	type Item = Result<LibraryString>;
+
+	fn next(&mut self) -> Option<Result<LibraryString>> {
+		while !self.ended {
+			match self.session.receive_transcript() {
+				Ok(transcript) if transcript.is_empty() => continue,
+				Ok(transcript) => return Some(Ok(transcript)),
+				Err(error) if error.code == ResultCode::StreamEnded || error.code == ResultCode::NotInitialized => self.ended = true,
+				Err(error) => {
+					self.ended = true;
+					return Some(Err(error));
+				}
+			}
+		}
+		None
+	}
+}
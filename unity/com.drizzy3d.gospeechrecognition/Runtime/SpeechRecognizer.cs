@@ -0,0 +1,312 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Speech input for Unity: feeds the microphone or AudioClips into the session of the library
+	and raises UnityEvents for the transcripts (on the main thread, the results are received on a background thread).
+	The library runs one session at a time, so only one SpeechRecognizer should be enabled.
+*/
+
+using System;
+using System.Collections.Concurrent;
+using System.Threading;
+using UnityEngine;
+using UnityEngine.Events;
+
+namespace GoSpeechRecognition.Unity
+{
+	[Serializable]
+	public class TranscriptEvent : UnityEvent<string> { }
+
+	[Serializable]
+	public class SpeechEventEvent : UnityEvent<SpeechEvent> { }
+
+	public class SpeechRecognizer : MonoBehaviour
+	{
+		[Header("Session")]
+		[Tooltip("The language of the speech (BCP-47, e.g. \"en-US\")")]
+		public string language = "en-US";
+		[Tooltip("The sample rate the audio is sent with (the microphone records with it, AudioClips are resampled)")]
+		public int sampleRate = 16000;
+		public string model = "default";
+		[Tooltip("Raise onPartialTranscript with the interim results")]
+		public bool interimResults = true;
+
+		[Header("Microphone")]
+		[Tooltip("Record the microphone while the component is enabled")]
+		public bool useMicrophone = true;
+		[Tooltip("The device to record (see Microphone.devices), empty for the default device")]
+		public string microphoneDevice = "";
+
+		[Header("Events")]
+		public TranscriptEvent onPartialTranscript = new TranscriptEvent();
+		public TranscriptEvent onFinalTranscript = new TranscriptEvent();
+		public SpeechEventEvent onSpeechEvent = new SpeechEventEvent();
+		[Tooltip("Raised with the error log if the session fails")]
+		public TranscriptEvent onError = new TranscriptEvent();
+
+		// Length of the looping microphone clip in seconds
+		private const int MicrophoneClipSeconds = 1;
+
+		private SpeechSession session;
+		private Thread receiveThread;
+		private volatile bool receiving;
+		private readonly ConcurrentQueue<Action> mainThreadActions = new ConcurrentQueue<Action>();
+
+		private AudioClip microphoneClip;
+		private int microphonePosition;
+		private float[] microphoneBuffer = new float[0];
+
+		public bool IsRunning => session != null && session.IsOpen;
+
+		// The result of "ReceiveTranscriptJSON()" read by JsonUtility (which can't read top-level arrays)
+#pragma warning disable 0649 // Assigned by JsonUtility
+		[Serializable]
+		private class ResultList
+		{
+			public TranscriptResult[] results;
+		}
+
+		[Serializable]
+		private class TranscriptResult
+		{
+			public string transcript;
+			public bool isFinal;
+		}
+#pragma warning restore 0649
+
+		private void OnEnable()
+		{
+			StartRecognition();
+		}
+
+		private void OnDisable()
+		{
+			StopRecognition();
+		}
+
+		private void OnApplicationQuit()
+		{
+			// The library has to release its goroutines before Unity unloads it (see "Shutdown()").
+			StopRecognition();
+			SpeechSession.Shutdown();
+		}
+
+		// Opens the session and starts the microphone (if enabled), called when the component gets enabled.
+		public void StartRecognition()
+		{
+			if (session != null)
+			{
+				return;
+			}
+			try
+			{
+				session = SpeechSession.Open(new SessionOptions
+				{
+					Language = language,
+					SampleRate = sampleRate,
+					Model = model,
+					InterimResults = interimResults,
+				});
+			}
+			catch (SpeechException e)
+			{
+				onError.Invoke(e.Message);
+				return;
+			}
+
+			receiving = true;
+			receiveThread = new Thread(ReceiveLoop) { IsBackground = true, Name = "SpeechRecognizer" };
+			receiveThread.Start();
+
+			if (useMicrophone)
+			{
+				StartMicrophone();
+			}
+		}
+
+		// Stops the microphone and closes the session, called when the component gets disabled.
+		public void StopRecognition()
+		{
+			StopMicrophone();
+			if (session == null)
+			{
+				return;
+			}
+
+			// Closing the session ends the pending receive call of the thread.
+			receiving = false;
+			session.Dispose();
+			receiveThread.Join();
+			receiveThread = null;
+			session = null;
+		}
+
+		// Sends the audio of a clip (downmixed to mono and resampled to the sample rate of the session),
+		// the clip's load type has to allow reading its data (e.g. "Decompress On Load").
+		public void SendClip(AudioClip clip)
+		{
+			if (session == null || clip == null)
+			{
+				return;
+			}
+			float[] data = new float[clip.samples * clip.channels];
+			if (!clip.GetData(data, 0))
+			{
+				onError.Invoke("Could not read the AudioClip " + clip.name);
+				return;
+			}
+			SendSamples(data, data.Length, clip.channels, clip.frequency);
+		}
+
+		// Sends PCM samples in Unity's float format (e.g. from OnAudioFilterRead).
+		public void SendSamples(float[] data, int length, int channels, int frequency)
+		{
+			if (session == null || length <= 0)
+			{
+				return;
+			}
+
+			int frames = length / channels;
+			int outputLength = (int)((long)frames * sampleRate / frequency);
+			short[] samples = new short[outputLength];
+			for (int i = 0; i < outputLength; i++)
+			{
+				// Linear interpolation of the downmixed frames
+				double position = (double)i * frequency / sampleRate;
+				int frame = (int)position;
+				int next = Math.Min(frame + 1, frames - 1);
+				float fraction = (float)(position - frame);
+				float value = Mathf.Lerp(Downmix(data, frame, channels), Downmix(data, next, channels), fraction);
+				samples[i] = (short)(Mathf.Clamp(value, -1f, 1f) * short.MaxValue);
+			}
+
+			try
+			{
+				session.SendAudio(samples);
+			}
+			catch (SpeechException e)
+			{
+				onError.Invoke(e.Message);
+			}
+		}
+
+		private static float Downmix(float[] data, int frame, int channels)
+		{
+			float sum = 0;
+			for (int channel = 0; channel < channels; channel++)
+			{
+				sum += data[frame * channels + channel];
+			}
+			return sum / channels;
+		}
+
+		private void StartMicrophone()
+		{
+			string device = string.IsNullOrEmpty(microphoneDevice) ? null : microphoneDevice;
+			microphoneClip = Microphone.Start(device, true, MicrophoneClipSeconds, sampleRate);
+			microphonePosition = 0;
+			if (microphoneClip == null)
+			{
+				onError.Invoke("Could not start the microphone");
+			}
+		}
+
+		private void StopMicrophone()
+		{
+			if (microphoneClip == null)
+			{
+				return;
+			}
+			Microphone.End(string.IsNullOrEmpty(microphoneDevice) ? null : microphoneDevice);
+			microphoneClip = null;
+		}
+
+		// Sends the audio recorded since the last frame (the microphone clip is a ring buffer).
+		private void SendMicrophone()
+		{
+			string device = string.IsNullOrEmpty(microphoneDevice) ? null : microphoneDevice;
+			int position = Microphone.GetPosition(device);
+			if (position < 0 || position == microphonePosition)
+			{
+				return;
+			}
+
+			int available = position - microphonePosition;
+			if (available < 0)
+			{
+				available += microphoneClip.samples;
+			}
+			int length = available * microphoneClip.channels;
+			if (microphoneBuffer.Length < length)
+			{
+				microphoneBuffer = new float[length];
+			}
+			// GetData wraps around the end of a looping clip.
+			microphoneClip.GetData(microphoneBuffer, microphonePosition);
+			microphonePosition = position;
+
+			SendSamples(microphoneBuffer, length, microphoneClip.channels, microphoneClip.frequency);
+		}
+
+		private void Update()
+		{
+			if (microphoneClip != null)
+			{
+				SendMicrophone();
+			}
+
+			while (mainThreadActions.TryDequeue(out Action action))
+			{
+				action();
+			}
+			if (session != null)
+			{
+				while (session.TryPollEvent(out SpeechEvent speechEvent))
+				{
+					onSpeechEvent.Invoke(speechEvent);
+				}
+			}
+		}
+
+		// Receives the results on the background thread, the events are raised by Update.
+		private void ReceiveLoop()
+		{
+			SpeechSession receivingSession = session;
+			while (receiving)
+			{
+				string json;
+				try
+				{
+					json = receivingSession.ReceiveTranscriptJSON();
+				}
+				catch (SpeechException e)
+				{
+					if (receiving && e.Result != SpeechResult.StreamEnded && e.Result != SpeechResult.NotInitialized)
+					{
+						string message = e.Message;
+						mainThreadActions.Enqueue(() => onError.Invoke(message));
+					}
+					return;
+				}
+
+				ResultList list = JsonUtility.FromJson<ResultList>("{\"results\":" + json + "}");
+				if (list == null || list.results == null)
+				{
+					continue;
+				}
+				foreach (TranscriptResult result in list.results)
+				{
+					string transcript = result.transcript;
+					if (result.isFinal)
+					{
+						mainThreadActions.Enqueue(() => onFinalTranscript.Invoke(transcript));
+					}
+					else
+					{
+						mainThreadActions.Enqueue(() => onPartialTranscript.Invoke(transcript));
+					}
+				}
+			}
+		}
+	}
+}
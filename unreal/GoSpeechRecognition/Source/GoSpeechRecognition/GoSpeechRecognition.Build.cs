@@ -0,0 +1,43 @@
+/*
+	Author: Christopher Dreide (https://github.com/Drizzy3D)
+
+	Build rules of the plugin: the library is loaded at runtime from Source/ThirdParty/GoSpeechRecognitionLibrary/<Platform>
+	and staged with the packaged game (on Android it's packaged into the APK by GoSpeechRecognition_UPL.xml).
+*/
+
+using System.IO;
+using UnrealBuildTool;
+
+public class GoSpeechRecognition : ModuleRules
+{
+	public GoSpeechRecognition(ReadOnlyTargetRules Target) : base(Target)
+	{
+		PCHUsage = PCHUsageMode.UseExplicitOrSharedPCHs;
+
+		PublicDependencyModuleNames.AddRange(new string[] { "Core", "CoreUObject", "Engine" });
+		PrivateDependencyModuleNames.AddRange(new string[] { "Json", "Projects" });
+
+		// The header of the library is taken from the repository if the plugin is used from it,
+		// otherwise go-speech-recognition.h has to be copied next to the binaries.
+		string LibraryDirectory = Path.Combine(PluginDirectory, "Source", "ThirdParty", "GoSpeechRecognitionLibrary");
+		string RepositoryDirectory = Path.GetFullPath(Path.Combine(PluginDirectory, "..", ".."));
+		PrivateIncludePaths.Add(File.Exists(Path.Combine(RepositoryDirectory, "go-speech-recognition.h")) ? RepositoryDirectory : LibraryDirectory);
+
+		if (Target.Platform == UnrealTargetPlatform.Win64)
+		{
+			RuntimeDependencies.Add(Path.Combine(LibraryDirectory, "Win64", "go-speech-recognition.dll"));
+		}
+		else if (Target.Platform == UnrealTargetPlatform.Linux)
+		{
+			RuntimeDependencies.Add(Path.Combine(LibraryDirectory, "Linux", "libgo-speech-recognition.so"));
+		}
+		else if (Target.Platform == UnrealTargetPlatform.Mac)
+		{
+			RuntimeDependencies.Add(Path.Combine(LibraryDirectory, "Mac", "libgo-speech-recognition.dylib"));
+		}
+		else if (Target.Platform == UnrealTargetPlatform.Android)
+		{
+			AdditionalPropertiesForReceipt.Add("AndroidPlugin", Path.Combine(ModuleDirectory, "GoSpeechRecognition_UPL.xml"));
+		}
+	}
+}
@@ -0,0 +1,9 @@
+/*
+Author: Christopher Dreide(https://github.com/Drizzy3D)
+
+The module of the plugin, the library is loaded by UGspeechSubsystem.
+*/
+
+#include "Modules/ModuleManager.h"
+
+IMPLEMENT_MODULE(FDefaultModuleImpl, GoSpeechRecognition)
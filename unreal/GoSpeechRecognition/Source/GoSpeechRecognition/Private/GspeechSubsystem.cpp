@@ -0,0 +1,277 @@
+/*
+Author: Christopher Dreide(https://github.com/Drizzy3D)
+
+Loads the go-speech-recognition library at runtime (see go-speech-recognition.h) and runs its session for UGspeechSubsystem.
+*/
+
+#include "GspeechSubsystem.h"
+
+#include "Async/Async.h"
+#include "Dom/JsonObject.h"
+#include "HAL/PlatformProcess.h"
+#include "Interfaces/IPluginManager.h"
+#include "Misc/Paths.h"
+#include "Serialization/JsonReader.h"
+#include "Serialization/JsonSerializer.h"
+
+#include "go-speech-recognition.h"
+
+DEFINE_LOG_CATEGORY_STATIC(LogGoSpeechRecognition, Log, All);
+
+namespace
+{
+	// The functions of the loaded library (it's loaded once per process, like the library's session is global)
+	struct FGspeechLibrary
+	{
+		void* Handle = nullptr;
+		GO_SPEECH_RECOGNITION_SET_LEGACY_RETURN_CODES SetLegacyReturnCodes = nullptr;
+		GO_SPEECH_RECOGNITION_INITIALIZE_STREAM InitializeStream = nullptr;
+		GO_SPEECH_RECOGNITION_SEND_AUDIO SendAudio = nullptr;
+		GO_SPEECH_RECOGNITION_RECEIVE_TRANSCRIPT_JSON ReceiveTranscriptJSON = nullptr;
+		GO_SPEECH_RECOGNITION_POLL_EVENT PollEvent = nullptr;
+		GO_SPEECH_RECOGNITION_IS_INITIALIZED IsInitialized = nullptr;
+		GO_SPEECH_RECOGNITION_GET_LOG GetLog = nullptr;
+		GO_SPEECH_RECOGNITION_FREE_STRING FreeString = nullptr;
+		GO_SPEECH_RECOGNITION_CLOSE_STREAM CloseStream = nullptr;
+		GO_SPEECH_RECOGNITION_SHUTDOWN Shutdown = nullptr;
+	};
+
+	FGspeechLibrary Library;
+
+	// Resolves a function, false if the library doesn't export it (e.g. an older version).
+	template <class FunctionType>
+	bool Resolve(FunctionType& Function, const TCHAR* Name)
+	{
+		Function = reinterpret_cast<FunctionType>(FPlatformProcess::GetDllExport(Library.Handle, Name));
+		if (Function == nullptr)
+		{
+			UE_LOG(LogGoSpeechRecognition, Error, TEXT("The library doesn't export %s"), Name);
+		}
+		return Function != nullptr;
+	}
+
+	// The path of the library staged by the build rules (on Android it's found by its name in the APK)
+	FString LibraryPath()
+	{
+#if PLATFORM_ANDROID
+		return TEXT("libgo-speech-recognition.so");
+#else
+#if PLATFORM_WINDOWS
+		const TCHAR* Name = TEXT("Win64/go-speech-recognition.dll");
+#elif PLATFORM_MAC
+		const TCHAR* Name = TEXT("Mac/libgo-speech-recognition.dylib");
+#else
+		const TCHAR* Name = TEXT("Linux/libgo-speech-recognition.so");
+#endif
+		const FString BaseDirectory = IPluginManager::Get().FindPlugin(TEXT("GoSpeechRecognition"))->GetBaseDir();
+		return FPaths::Combine(BaseDirectory, TEXT("Source/ThirdParty/GoSpeechRecognitionLibrary"), Name);
+#endif
+	}
+
+	bool LoadGspeechLibrary()
+	{
+		if (Library.Handle != nullptr)
+		{
+			return true;
+		}
+		const FString Path = LibraryPath();
+		Library.Handle = FPlatformProcess::GetDllHandle(*Path);
+		if (Library.Handle == nullptr)
+		{
+			UE_LOG(LogGoSpeechRecognition, Error, TEXT("Could not load %s"), *Path);
+			return false;
+		}
+
+		const bool bResolved = Resolve(Library.SetLegacyReturnCodes, TEXT("SetLegacyReturnCodes"))
+			&& Resolve(Library.InitializeStream, TEXT("InitializeStream"))
+			&& Resolve(Library.SendAudio, TEXT("SendAudio"))
+			&& Resolve(Library.ReceiveTranscriptJSON, TEXT("ReceiveTranscriptJSON"))
+			&& Resolve(Library.PollEvent, TEXT("PollEvent"))
+			&& Resolve(Library.IsInitialized, TEXT("IsInitialized"))
+			&& Resolve(Library.GetLog, TEXT("GetLog"))
+			&& Resolve(Library.FreeString, TEXT("FreeString"))
+			&& Resolve(Library.CloseStream, TEXT("CloseStream"))
+			&& Resolve(Library.Shutdown, TEXT("Shutdown"));
+		if (!bResolved)
+		{
+			FPlatformProcess::FreeDllHandle(Library.Handle);
+			Library = FGspeechLibrary();
+			return false;
+		}
+
+		// The subsystem relies on the negative error codes.
+		Library.SetLegacyReturnCodes(GO_SPEECH_RECOGNITION_FALSE);
+		return true;
+	}
+
+	// Copies a string returned by the library and releases it.
+	FString Take(char* Value)
+	{
+		if (Value == nullptr)
+		{
+			return FString();
+		}
+		FString Copy = UTF8_TO_TCHAR(Value);
+		Library.FreeString(Value);
+		return Copy;
+	}
+}
+
+void UGspeechSubsystem::Initialize(FSubsystemCollectionBase& Collection)
+{
+	Super::Initialize(Collection);
+	LoadGspeechLibrary();
+}
+
+void UGspeechSubsystem::Deinitialize()
+{
+	StopRecognition();
+	// The library has to release its goroutines before the process exits (see "Shutdown()"),
+	// it stays loaded, the Go runtime can't be unloaded.
+	if (Library.Handle != nullptr)
+	{
+		Library.Shutdown();
+	}
+	Super::Deinitialize();
+}
+
+bool UGspeechSubsystem::StartRecognition(const FString& Language, int32 SampleRate, bool bInterimResults)
+{
+	if (!LoadGspeechLibrary())
+	{
+		OnError.Broadcast(GO_SPEECH_RECOGNITION_ERROR, TEXT("The library couldn't be loaded"));
+		return false;
+	}
+	StopRecognition();
+
+	FTCHARToUTF8 LanguageUTF8(*Language);
+	char Model[] = "default";
+	const int32 Code = Library.InitializeStream(const_cast<char*>(LanguageUTF8.Get()), SampleRate, Model, 1,
+		bInterimResults ? GO_SPEECH_RECOGNITION_TRUE : GO_SPEECH_RECOGNITION_FALSE);
+	if (Code != GO_SPEECH_RECOGNITION_OK)
+	{
+		BroadcastError(Code);
+		return false;
+	}
+
+	bRecognizing = true;
+	ReceiveFuture = Async(EAsyncExecution::Thread, [this]() { ReceiveLoop(); });
+	return true;
+}
+
+void UGspeechSubsystem::StopRecognition()
+{
+	if (!bRecognizing)
+	{
+		return;
+	}
+	// Closing the session ends the pending receive call of the thread.
+	bRecognizing = false;
+	Library.CloseStream();
+	ReceiveFuture.Wait();
+}
+
+bool UGspeechSubsystem::IsRecognizing() const
+{
+	return bRecognizing && Library.IsInitialized() == GO_SPEECH_RECOGNITION_TRUE;
+}
+
+bool UGspeechSubsystem::SendAudioSamples(const TArray<float>& Samples)
+{
+	TArray<int16> Converted;
+	Converted.SetNumUninitialized(Samples.Num());
+	for (int32 Index = 0; Index < Samples.Num(); Index++)
+	{
+		Converted[Index] = static_cast<int16>(FMath::Clamp(Samples[Index], -1.0f, 1.0f) * MAX_int16);
+	}
+	return SendAudio(Converted);
+}
+
+bool UGspeechSubsystem::SendAudio(TArrayView<const int16> Samples)
+{
+	if (!bRecognizing || Samples.Num() == 0)
+	{
+		return false;
+	}
+	const int32 Code = Library.SendAudio(Samples.GetData(), Samples.Num());
+	if (Code != GO_SPEECH_RECOGNITION_OK)
+	{
+		BroadcastError(Code);
+		return false;
+	}
+	return true;
+}
+
+void UGspeechSubsystem::BroadcastError(int32 Code)
+{
+	const FString Message = Take(Library.GetLog());
+	UE_LOG(LogGoSpeechRecognition, Warning, TEXT("Speech recognition failed (%d): %s"), Code, *Message);
+
+	TWeakObjectPtr<UGspeechSubsystem> WeakThis(this);
+	AsyncTask(ENamedThreads::GameThread, [WeakThis, Code, Message]()
+	{
+		if (UGspeechSubsystem* Subsystem = WeakThis.Get())
+		{
+			Subsystem->OnError.Broadcast(Code, Message);
+		}
+	});
+}
+
+void UGspeechSubsystem::ReceiveLoop()
+{
+	TWeakObjectPtr<UGspeechSubsystem> WeakThis(this);
+	while (bRecognizing)
+	{
+		char* Output = nullptr;
+		const int32 Code = Library.ReceiveTranscriptJSON(&Output);
+		const FString Json = Take(Output);
+
+		int Event = 0;
+		while (Library.PollEvent(&Event) == GO_SPEECH_RECOGNITION_TRUE)
+		{
+			AsyncTask(ENamedThreads::GameThread, [WeakThis, Event]()
+			{
+				if (UGspeechSubsystem* Subsystem = WeakThis.Get())
+				{
+					Subsystem->OnSpeechEvent.Broadcast(Event);
+				}
+			});
+		}
+
+		if (Code == GO_SPEECH_RECOGNITION_ERROR_STREAM_ENDED || Code == GO_SPEECH_RECOGNITION_ERROR_NOT_INITIALIZED)
+		{
+			return;
+		}
+		if (Code != GO_SPEECH_RECOGNITION_OK)
+		{
+			if (bRecognizing)
+			{
+				BroadcastError(Code);
+			}
+			return;
+		}
+
+		TArray<TSharedPtr<FJsonValue>> Results;
+		if (!FJsonSerializer::Deserialize(TJsonReaderFactory<>::Create(Json), Results))
+		{
+			continue;
+		}
+		for (const TSharedPtr<FJsonValue>& Value : Results)
+		{
+			const TSharedPtr<FJsonObject>* Result = nullptr;
+			if (!Value.IsValid() || !Value->TryGetObject(Result))
+			{
+				continue;
+			}
+			const FString Transcript = (*Result)->GetStringField(TEXT("transcript"));
+			const bool bIsFinal = (*Result)->GetBoolField(TEXT("isFinal"));
+			AsyncTask(ENamedThreads::GameThread, [WeakThis, Transcript, bIsFinal]()
+			{
+				if (UGspeechSubsystem* Subsystem = WeakThis.Get())
+				{
+					(bIsFinal ? Subsystem->OnFinalTranscript : Subsystem->OnInterimTranscript).Broadcast(Transcript);
+				}
+			});
+		}
+	}
+}
@@ -0,0 +1,72 @@
+#pragma once
+
+/*
+Author: Christopher Dreide(https://github.com/Drizzy3D)
+
+Speech recognition for Unreal: the game instance subsystem runs the session of the go-speech-recognition library
+and broadcasts the interim and final transcripts as Blueprint events (on the game thread).
+The library runs one session at a time.
+*/
+
+#include "CoreMinimal.h"
+#include "Async/Future.h"
+#include "Subsystems/GameInstanceSubsystem.h"
+#include <atomic>
+#include "GspeechSubsystem.generated.h"
+
+DECLARE_DYNAMIC_MULTICAST_DELEGATE_OneParam(FGspeechTranscriptDelegate, const FString&, Transcript);
+DECLARE_DYNAMIC_MULTICAST_DELEGATE_OneParam(FGspeechEventDelegate, int32, Event);
+DECLARE_DYNAMIC_MULTICAST_DELEGATE_TwoParams(FGspeechErrorDelegate, int32, Code, const FString&, Message);
+
+UCLASS()
+class GOSPEECHRECOGNITION_API UGspeechSubsystem : public UGameInstanceSubsystem
+{
+	GENERATED_BODY()
+
+public:
+	virtual void Initialize(FSubsystemCollectionBase& Collection) override;
+	virtual void Deinitialize() override;
+
+	// Opens the session (see "InitializeStream()"), false if it failed (OnError has been broadcast).
+	UFUNCTION(BlueprintCallable, Category = "Speech Recognition")
+	bool StartRecognition(const FString& Language = TEXT("en-US"), int32 SampleRate = 16000, bool bInterimResults = true);
+
+	// Closes the session (see "CloseStream()").
+	UFUNCTION(BlueprintCallable, Category = "Speech Recognition")
+	void StopRecognition();
+
+	UFUNCTION(BlueprintPure, Category = "Speech Recognition")
+	bool IsRecognizing() const;
+
+	// Sends mono samples between -1 and 1 in the sample rate of the session (e.g. of an audio capture).
+	UFUNCTION(BlueprintCallable, Category = "Speech Recognition")
+	bool SendAudioSamples(const TArray<float>& Samples);
+
+	// Sends 16 bit PCM samples in the sample rate of the session (see "SendAudio()").
+	bool SendAudio(TArrayView<const int16> Samples);
+
+	// Raised with the interim results (if enabled)
+	UPROPERTY(BlueprintAssignable, Category = "Speech Recognition")
+	FGspeechTranscriptDelegate OnInterimTranscript;
+
+	// Raised with the final results
+	UPROPERTY(BlueprintAssignable, Category = "Speech Recognition")
+	FGspeechTranscriptDelegate OnFinalTranscript;
+
+	// Raised with the events of the session (GO_SPEECH_RECOGNITION_EVENT, see "PollEvent()")
+	UPROPERTY(BlueprintAssignable, Category = "Speech Recognition")
+	FGspeechEventDelegate OnSpeechEvent;
+
+	// Raised with the result code and the error log of a failure
+	UPROPERTY(BlueprintAssignable, Category = "Speech Recognition")
+	FGspeechErrorDelegate OnError;
+
+private:
+	// Receives the results on its own thread and broadcasts them on the game thread.
+	void ReceiveLoop();
+	void BroadcastError(int32 Code);
+
+	// Read by the receive thread
+	std::atomic<bool> bRecognizing{ false };
+	TFuture<void> ReceiveFuture;
+};